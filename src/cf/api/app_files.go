@@ -1,14 +1,21 @@
 package api
 
 import (
+	"bufio"
 	"cf"
 	"cf/configuration"
 	"cf/net"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 type AppFilesRepository interface {
-	ListFiles(app cf.Application, path string) (files string, apiResponse net.ApiResponse)
+	ListFiles(app cf.Application, instance int, path string) (files string, apiResponse net.ApiResponse)
+	ListDirectory(app cf.Application, instance int, path string) (entries []cf.InstanceFile, apiResponse net.ApiResponse)
+	DownloadFile(app cf.Application, instance int, path string, destination io.Writer, onProgress func(downloaded, total int64)) (apiResponse net.ApiResponse)
 }
 
 type CloudControllerAppFilesRepository struct {
@@ -22,9 +29,8 @@ func NewCloudControllerAppFilesRepository(config *configuration.Configuration, g
 	return
 }
 
-func (repo CloudControllerAppFilesRepository) ListFiles(app cf.Application, path string) (files string, apiResponse net.ApiResponse) {
-	url := fmt.Sprintf("%s/v2/apps/%s/instances/0/files/%s", repo.config.Target, app.Guid, path)
-	request, apiResponse := repo.gateway.NewRequest("GET", url, repo.config.AccessToken, nil)
+func (repo CloudControllerAppFilesRepository) ListFiles(app cf.Application, instance int, path string) (files string, apiResponse net.ApiResponse) {
+	request, apiResponse := repo.gateway.NewRequest("GET", repo.filesUrl(app, instance, path), repo.config.AccessToken, nil)
 	if apiResponse.IsNotSuccessful() {
 		return
 	}
@@ -32,3 +38,83 @@ func (repo CloudControllerAppFilesRepository) ListFiles(app cf.Application, path
 	files, _, apiResponse = repo.gateway.PerformRequestForTextResponse(request)
 	return
 }
+
+var listingSizeRegex = regexp.MustCompile(`[ \t]+(-|[0-9]+(\.[0-9]+)?[BKMGT]?)$`)
+
+// ListDirectory parses the listing the DEA serves for a directory, where
+// every line holds a name and a size, and directory names end with a slash.
+// Names may contain spaces, so only a trailing size column is split off.
+func (repo CloudControllerAppFilesRepository) ListDirectory(app cf.Application, instance int, path string) (entries []cf.InstanceFile, apiResponse net.ApiResponse) {
+	listing, apiResponse := repo.ListFiles(app, instance, path)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(listing))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		entry := cf.InstanceFile{Name: line}
+		match := listingSizeRegex.FindStringSubmatchIndex(line)
+		if match != nil && match[0] > 0 {
+			entry.Name = line[:match[0]]
+			entry.Size = line[match[2]:]
+		}
+
+		if strings.HasSuffix(entry.Name, "/") {
+			entry.Name = strings.TrimSuffix(entry.Name, "/")
+			entry.IsDir = true
+		}
+		entries = append(entries, entry)
+	}
+	return
+}
+
+// DownloadFile copies the contents of the file at path to destination as
+// they are received. onProgress, when given, is called after every chunk
+// with the bytes written so far and the size of the file, which is 0 when
+// the server does not tell.
+func (repo CloudControllerAppFilesRepository) DownloadFile(app cf.Application, instance int, path string, destination io.Writer, onProgress func(downloaded, total int64)) (apiResponse net.ApiResponse) {
+	request, apiResponse := repo.gateway.NewRequest("GET", repo.filesUrl(app, instance, path), repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	body, headers, apiResponse := repo.gateway.PerformRequestForResponseBody(request)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+	defer body.Close()
+
+	total, _ := strconv.ParseInt(headers.Get("Content-Length"), 10, 64)
+	if onProgress != nil {
+		destination = &progressWriter{writer: destination, total: total, onProgress: onProgress}
+	}
+
+	_, err := io.Copy(destination, body)
+	if err != nil {
+		apiResponse = net.NewApiStatusWithError(fmt.Sprintf("Error downloading %s", path), err)
+	}
+	return
+}
+
+func (repo CloudControllerAppFilesRepository) filesUrl(app cf.Application, instance int, path string) string {
+	return fmt.Sprintf("%s/v2/apps/%s/instances/%d/files/%s", repo.config.Target, app.Guid, instance, strings.TrimPrefix(path, "/"))
+}
+
+type progressWriter struct {
+	writer     io.Writer
+	written    int64
+	total      int64
+	onProgress func(downloaded, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (n int, err error) {
+	n, err = w.writer.Write(p)
+	w.written += int64(n)
+	w.onProgress(w.written, w.total)
+	return
+}
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"bytes"
 	"cf"
 	. "cf/api"
 	"cf/configuration"
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testhelpers"
 	"testing"
 )
@@ -56,10 +58,128 @@ func TestListFiles(t *testing.T) {
 	}
 
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(listFilesRedirectServer.TLS.Certificates)
 	repo := NewCloudControllerAppFilesRepository(config, gateway)
 
-	list, err := repo.ListFiles(cf.Application{Guid: "my-app-guid"}, "some/path")
+	list, err := repo.ListFiles(cf.Application{Guid: "my-app-guid"}, 0, "some/path")
 
 	assert.False(t, err.IsNotSuccessful())
 	assert.Equal(t, list, expectedResponse)
 }
+
+func TestListFilesOfAnotherInstance(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/apps/my-app-guid/instances/2/files/app",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: "config.ru"},
+	)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(endpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		Target:      ts.URL,
+		AccessToken: "BEARER my_access_token",
+	}
+
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerAppFilesRepository(config, gateway)
+
+	list, err := repo.ListFiles(cf.Application{Guid: "my-app-guid"}, 2, "app")
+
+	assert.False(t, err.IsNotSuccessful())
+	assert.Equal(t, strings.TrimSpace(list), "config.ru")
+}
+
+func TestListDirectory(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/apps/my-app-guid/instances/0/files/app",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `
+heapdumps/                                -
+config.ru                              1.2K
+my notes.txt                             5B
+notes from 2014/                          -
+README
+`},
+	)
+
+	ts, repo := createAppFilesRepo(endpoint)
+	defer ts.Close()
+
+	entries, err := repo.ListDirectory(cf.Application{Guid: "my-app-guid"}, 0, "/app")
+
+	assert.False(t, err.IsNotSuccessful())
+	assert.Equal(t, entries, []cf.InstanceFile{
+		cf.InstanceFile{Name: "heapdumps", Size: "-", IsDir: true},
+		cf.InstanceFile{Name: "config.ru", Size: "1.2K"},
+		cf.InstanceFile{Name: "my notes.txt", Size: "5B"},
+		cf.InstanceFile{Name: "notes from 2014", Size: "-", IsDir: true},
+		cf.InstanceFile{Name: "README"},
+	})
+}
+
+func TestDownloadFile(t *testing.T) {
+	contents := []byte{0x00, 0xff, 0x0a, 0x0d, 0x1f, 0x8b, 0x00}
+
+	endpoint := func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != "GET" || request.URL.Path != "/v2/apps/my-app-guid/instances/1/files/app/heap.hprof" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.Header().Set("Content-Length", fmt.Sprintf("%d", len(contents)))
+		writer.WriteHeader(http.StatusOK)
+		writer.Write(contents)
+	}
+
+	ts, repo := createAppFilesRepo(endpoint)
+	defer ts.Close()
+
+	var lastDownloaded, lastTotal int64
+	onProgress := func(downloaded, total int64) {
+		lastDownloaded, lastTotal = downloaded, total
+	}
+
+	destination := &bytes.Buffer{}
+	err := repo.DownloadFile(cf.Application{Guid: "my-app-guid"}, 1, "/app/heap.hprof", destination, onProgress)
+
+	assert.False(t, err.IsNotSuccessful())
+	assert.Equal(t, destination.Bytes(), contents)
+	assert.Equal(t, lastDownloaded, int64(len(contents)))
+	assert.Equal(t, lastTotal, int64(len(contents)))
+}
+
+func TestDownloadFileWhenTheFileIsMissing(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/apps/my-app-guid/instances/0/files/app/other-file",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK},
+	)
+
+	ts, repo := createAppFilesRepo(endpoint)
+	defer ts.Close()
+
+	destination := &bytes.Buffer{}
+	err := repo.DownloadFile(cf.Application{Guid: "my-app-guid"}, 0, "/app/missing-file", destination, nil)
+
+	assert.True(t, err.IsNotSuccessful())
+	assert.Equal(t, destination.Len(), 0)
+}
+
+func createAppFilesRepo(endpoint http.HandlerFunc) (ts *httptest.Server, repo AppFilesRepository) {
+	ts = httptest.NewTLSServer(endpoint)
+
+	config := &configuration.Configuration{
+		Target:      ts.URL,
+		AccessToken: "BEARER my_access_token",
+	}
+
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo = NewCloudControllerAppFilesRepository(config, gateway)
+	return
+}
@@ -6,6 +6,7 @@ import (
 	"cf/net"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 type AppSummaryRepository interface {
@@ -49,6 +50,7 @@ type InstanceStatsApiResponse struct {
 	Stats struct {
 		DiskQuota uint64 `json:"disk_quota"`
 		MemQuota  uint64 `json:"mem_quota"`
+		Uptime    int64  // in seconds
 		Usage     struct {
 			Cpu  float64
 			Disk uint64
@@ -84,6 +86,7 @@ func (repo CloudControllerAppSummaryRepository) updateInstancesWithStats(app cf.
 		instance.DiskUsage = v.Stats.Usage.Disk
 		instance.MemQuota = v.Stats.MemQuota
 		instance.MemUsage = v.Stats.Usage.Mem
+		instance.Uptime = time.Duration(v.Stats.Uptime) * time.Second
 
 		updatedInst[index] = instance
 	}
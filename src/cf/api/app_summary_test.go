@@ -52,6 +52,7 @@ var statsEndpoint = testhelpers.CreateEndpoint(
     "stats": {
         "disk_quota": 1073741824,
         "mem_quota": 67108864,
+        "uptime": 5400,
         "usage": {
             "cpu": 3.659571249238058e-05,
             "disk": 56037376,
@@ -82,6 +83,7 @@ func TestAppSummaryGetSummary(t *testing.T) {
 	}
 
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	appRepo := NewCloudControllerApplicationRepository(config, gateway)
 	summaryRepo := NewCloudControllerAppSummaryRepository(config, gateway, appRepo)
@@ -107,4 +109,6 @@ func TestAppSummaryGetSummary(t *testing.T) {
 	assert.Exactly(t, instance0.MemQuota, uint64(67108864))
 	assert.Exactly(t, instance0.MemUsage, uint64(19218432))
 	assert.Equal(t, instance0.CpuUsage, 3.659571249238058e-05)
+	assert.Equal(t, instance0.Uptime, 90*time.Minute)
+	assert.Equal(t, instance1.Index, 1)
 }
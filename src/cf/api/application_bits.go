@@ -7,77 +7,145 @@ import (
 	"cf/configuration"
 	"cf/net"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/textproto"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
 
 type ApplicationBitsRepository interface {
-	UploadApp(app cf.Application, dir string) (apiResponse net.ApiResponse)
+	UploadApp(app cf.Application, dir string, onProgress func(uploaded, total int64)) (apiResponse net.ApiResponse)
 }
 
 type CloudControllerApplicationBitsRepository struct {
-	config  *configuration.Configuration
-	gateway net.Gateway
-	zipper  cf.Zipper
+	config   *configuration.Configuration
+	gateway  net.Gateway
+	zipper   cf.Zipper
+	cacheDir string
 }
 
-func NewCloudControllerApplicationBitsRepository(config *configuration.Configuration, gateway net.Gateway, zipper cf.Zipper) (repo CloudControllerApplicationBitsRepository) {
+// NewCloudControllerApplicationBitsRepository keeps a resource cache for each
+// app in cacheDir. Nothing is cached when cacheDir is empty.
+func NewCloudControllerApplicationBitsRepository(config *configuration.Configuration, gateway net.Gateway, zipper cf.Zipper, cacheDir string) (repo CloudControllerApplicationBitsRepository) {
 	repo.config = config
 	repo.gateway = gateway
 	repo.zipper = zipper
+	repo.cacheDir = cacheDir
 	return
 }
 
-func (repo CloudControllerApplicationBitsRepository) UploadApp(app cf.Application, dir string) (apiResponse net.ApiResponse) {
-	dir, resourcesJson, apiResponse := repo.createUploadDir(app, dir)
+// UploadApp uploads the files in dir, or in the archive dir points to, that
+// the cloud controller does not already have. The zip is built while it is
+// being sent, so memory use does not grow with the size of the app. An
+// archive whose files all have to be uploaded is sent as it is rather than
+// zipped again. onProgress, if given, is called with the number of bytes
+// zipped or sent so far and the total size of what is being uploaded.
+//
+// Files the cloud controller confirmed it has on an earlier push are not
+// offered to resource_match again. Since it may have dropped them since, an
+// upload that relied on those matches and failed because resources were
+// missing is retried once without them.
+func (repo CloudControllerApplicationBitsRepository) UploadApp(app cf.Application, dir string, onProgress func(uploaded, total int64)) (apiResponse net.ApiResponse) {
+	cache := repo.loadResourceCache(app, dir)
+
+	appDir, archive, appFilesToUpload, resourcesJson, usedCachedMatches, apiResponse := repo.findFilesToUpload(app, dir, cache)
 	if apiResponse.IsNotSuccessful() {
 		return
 	}
 
-	zipBuffer, err := repo.zipper.Zip(dir)
-	if err != nil {
-		return
+	apiResponse = repo.uploadBits(app, appDir, archive, appFilesToUpload, resourcesJson, onProgress)
+
+	if apiResponse.ErrorCode == RESOURCE_NOT_FOUND && usedCachedMatches {
+		cache.ForgetMatched()
+
+		appDir, archive, appFilesToUpload, resourcesJson, _, apiResponse = repo.findFilesToUpload(app, dir, cache)
+		if apiResponse.IsSuccessful() {
+			apiResponse = repo.uploadBits(app, appDir, archive, appFilesToUpload, resourcesJson, onProgress)
+		}
 	}
 
-	apiResponse = repo.uploadBits(app, zipBuffer, resourcesJson)
-	if apiResponse.IsNotSuccessful() {
+	if cache != nil {
+		// A cache that cannot be written only makes the next push slower
+		cache.Save()
+	}
+	return
+}
+
+func (repo CloudControllerApplicationBitsRepository) loadResourceCache(app cf.Application, dir string) (cache *cf.ResourceCache) {
+	if repo.cacheDir == "" {
 		return
 	}
 
+	file := filepath.Join(repo.cacheDir, app.Guid+".json")
+	cache = cf.LoadResourceCache(file, dir, repo.config.Target)
 	return
 }
 
-func (repo CloudControllerApplicationBitsRepository) uploadBits(app cf.Application, zipBuffer *bytes.Buffer, resourcesJson []byte) (apiResponse net.ApiResponse) {
+// uploadBits sends archive as the zip of the app when it is given, and zips
+// appFilesToUpload from appDir otherwise.
+func (repo CloudControllerApplicationBitsRepository) uploadBits(app cf.Application, appDir, archive string, appFilesToUpload []cf.AppFile, resourcesJson []byte, onProgress func(uploaded, total int64)) (apiResponse net.ApiResponse) {
 	url := fmt.Sprintf("%s/v2/apps/%s/bits", repo.config.Target, app.Guid)
 
-	body, boundary, err := createApplicationUploadBody(zipBuffer, resourcesJson)
-	if err != nil {
-		apiResponse = net.NewApiStatusWithError("Error creating upload", err)
+	var total int64
+	if archive != "" {
+		fileInfo, err := os.Stat(archive)
+		if err != nil {
+			apiResponse = net.NewApiStatusWithError("Error reading archive", err)
+			return
+		}
+		total = fileInfo.Size()
+	} else {
+		for _, file := range appFilesToUpload {
+			total += file.Size
+		}
+	}
+
+	var onZipProgress func(int64)
+	if onProgress != nil {
+		onZipProgress = func(zipped int64) {
+			onProgress(zipped, total)
+		}
+	}
+
+	boundary := multipart.NewWriter(ioutil.Discard).Boundary()
+	getBody := func() (body io.ReadCloser, err error) {
+		pipeReader, pipeWriter := io.Pipe()
+		go func() {
+			err := repo.writeUploadBody(pipeWriter, boundary, appDir, archive, appFilesToUpload, resourcesJson, onZipProgress)
+			pipeWriter.CloseWithError(err)
+		}()
+		body = pipeReader
 		return
 	}
 
-	request, apiResponse := repo.gateway.NewRequest("PUT", url, repo.config.AccessToken, body)
-	contentType := fmt.Sprintf("multipart/form-data; boundary=%s", boundary)
-	request.Header.Set("Content-Type", contentType)
+	request, apiResponse := repo.gateway.NewStreamingRequest("PUT", url, repo.config.AccessToken, getBody)
 	if apiResponse.IsNotSuccessful() {
 		return
 	}
+	contentType := fmt.Sprintf("multipart/form-data; boundary=%s", boundary)
+	request.Header.Set("Content-Type", contentType)
 
 	apiResponse = repo.gateway.PerformRequest(request)
 	return
 }
 
-func (repo CloudControllerApplicationBitsRepository) createUploadDir(app cf.Application, appDir string) (uploadDir string, resourcesJson []byte, apiResponse net.ApiResponse) {
+// findFilesToUpload lists the files of appDir the cloud controller does not
+// have. When appDir is an archive they are listed from a temporary copy of
+// its contents, and archive is set to appDir if none of its files can be
+// left out of the upload.
+func (repo CloudControllerApplicationBitsRepository) findFilesToUpload(app cf.Application, appDir string, cache *cf.ResourceCache) (dir, archive string, appFilesToUpload []cf.AppFile, resourcesJson []byte, usedCachedMatches bool, apiResponse net.ApiResponse) {
 	var err error
+	dir = appDir
 
 	// If appDir is a zip, first extract it to a temporary directory
 	if fileIsZip(appDir) {
-		appDir, err = extractZip(app, appDir)
+		dir, err = extractZip(app, appDir)
 		if err != nil {
 			apiResponse = net.NewApiStatusWithError("Error extracting archive", err)
 			return
@@ -85,32 +153,20 @@ func (repo CloudControllerApplicationBitsRepository) createUploadDir(app cf.Appl
 	}
 
 	// Find which files need to be uploaded
-	allAppFiles, err := cf.AppFilesInDir(appDir)
+	allAppFiles, err := cf.AppFilesInDirWithCache(dir, cache)
 	if err != nil {
 		apiResponse = net.NewApiStatusWithError("Error listing app files", err)
 		return
 	}
 
-	appFilesToUpload, resourcesJson, apiResponse := repo.getFilesToUpload(allAppFiles)
+	appFilesToUpload, resourcesJson, usedCachedMatches, apiResponse = repo.getFilesToUpload(allAppFiles, cache)
 	if apiResponse.IsNotSuccessful() {
 		return
 	}
 
-	// Copy files into a temporary directory and return it
-	uploadDir = cf.TempDirForApp(app)
-
-	err = cf.InitializeDir(uploadDir)
-	if err != nil {
-		apiResponse = net.NewApiStatusWithError("Error creating upload directory", err)
-		return
+	if dir != appDir && archiveIsUploadedWhole(appDir, appFilesToUpload) {
+		archive = appDir
 	}
-
-	err = cf.CopyFiles(appFilesToUpload, appDir, uploadDir)
-	if err != nil {
-		apiResponse = net.NewApiStatusWithError("Error copying files to temp directory", err)
-		return
-	}
-
 	return
 }
 
@@ -122,6 +178,35 @@ func fileIsZip(file string) bool {
 	return isZip || isWar || isJar
 }
 
+// archiveIsUploadedWhole tells whether every file of zipFile is among
+// appFilesToUpload, so that zipping them again would only rebuild it.
+func archiveIsUploadedWhole(zipFile string, appFilesToUpload []cf.AppFile) bool {
+	r, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+
+	uploaded := map[string]bool{}
+	for _, file := range appFilesToUpload {
+		uploaded[filepath.ToSlash(file.Path)] = true
+	}
+
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() && !uploaded[path.Clean(f.Name)] {
+			return false
+		}
+	}
+	return true
+}
+
+// extractZip extracts an archive pushed by the user into a temporary
+// directory. Modes, empty directories and symlinks are kept. Entries and
+// symlinks that would end up outside of the directory are refused, as are
+// the ones going through a symlink of the archive, since where such a path
+// leads depends on the symlinks rather than on its text. Symlinks are only
+// created once everything else is written, so that no entry is ever written
+// through one.
 func extractZip(app cf.Application, zipFile string) (destDir string, err error) {
 	destDir = cf.TempDirForApp(app) + "-zip"
 	err = cf.InitializeDir(destDir)
@@ -135,133 +220,285 @@ func extractZip(app cf.Application, zipFile string) (destDir string, err error)
 	}
 	defer r.Close()
 
+	symlinks := map[string]bool{}
 	for _, f := range r.File {
-		// Don't try to extract directories
-		if f.FileInfo().IsDir() {
-			continue
+		if f.Mode()&os.ModeSymlink != 0 {
+			symlinks[path.Clean(f.Name)] = true
 		}
+	}
 
-		var (
-			rc       io.ReadCloser
-			destFile *os.File
-		)
+	links := []symlink{}
 
-		rc, err = f.Open()
-		if err != nil {
+	for _, f := range r.File {
+		name := path.Clean(f.Name)
+		destPath := filepath.Join(destDir, filepath.FromSlash(name))
+		if !cf.IsPathInDir(destDir, destPath) || goesThroughSymlink(path.Dir(name), "", symlinks) {
+			err = errors.New(fmt.Sprintf("%s contains a file outside of the app directory: %s", filepath.Base(zipFile), f.Name))
 			return
 		}
-		defer rc.Close()
 
-		destFilePath := filepath.Join(destDir, f.Name)
-		err = os.MkdirAll(filepath.Dir(destFilePath), os.ModePerm|os.ModeDir)
+		if f.Mode()&os.ModeSymlink == 0 {
+			err = extractFile(f, destPath)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		var target string
+		target, err = readSymlinkTarget(f)
 		if err != nil {
 			return
 		}
 
-		destFile, err = os.Create(destFilePath)
+		resolved := filepath.Join(filepath.Dir(destPath), filepath.FromSlash(target))
+		if path.IsAbs(target) || !cf.IsPathInDir(destDir, resolved) || goesThroughSymlink(path.Dir(name), target, symlinks) {
+			err = errors.New(fmt.Sprintf("symlink %s points outside of the app directory", f.Name))
+			return
+		}
+
+		links = append(links, symlink{path: destPath, target: filepath.FromSlash(target)})
+	}
+
+	for _, link := range links {
+		err = os.MkdirAll(filepath.Dir(link.path), os.ModePerm|os.ModeDir)
 		if err != nil {
 			return
 		}
 
-		_, err = io.Copy(destFile, rc)
+		err = os.Symlink(link.target, link.path)
 		if err != nil {
 			return
 		}
+	}
+	return
+}
 
-		rc.Close()
+type symlink struct {
+	path   string
+	target string
+}
+
+// goesThroughSymlink tells whether following target from dir, both slash
+// separated and relative to the root of the archive, goes through one of its
+// symlinks before reaching the last element of target. An empty target
+// checks dir itself.
+func goesThroughSymlink(dir, target string, symlinks map[string]bool) bool {
+	for current := dir; current != "." && current != "/"; current = path.Dir(current) {
+		if symlinks[current] {
+			return true
+		}
+	}
+
+	if target == "" {
+		return false
 	}
+
+	current := dir
+	elements := strings.Split(target, "/")
+	for _, element := range elements[:len(elements)-1] {
+		switch element {
+		case "", ".":
+			continue
+		case "..":
+			current = path.Dir(current)
+		default:
+			current = path.Join(current, element)
+		}
+
+		if symlinks[current] {
+			return true
+		}
+	}
+	return false
+}
+
+func readSymlinkTarget(f *zip.File) (target string, err error) {
+	rc, err := f.Open()
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+
+	linkTarget, err := ioutil.ReadAll(rc)
+	target = string(linkTarget)
 	return
 }
 
-func (repo CloudControllerApplicationBitsRepository) getFilesToUpload(allAppFiles []cf.AppFile) (appFilesToUpload []cf.AppFile, resourcesJson []byte, apiResponse net.ApiResponse) {
-	appFilesRequest := []AppFile{}
-	for _, file := range allAppFiles {
-		appFilesRequest = append(appFilesRequest, AppFile{
-			Path: file.Path,
-			Sha1: file.Sha1,
-			Size: file.Size,
-		})
+func extractFile(f *zip.File, destPath string) (err error) {
+	mode := f.Mode()
+
+	if mode.IsDir() {
+		err = os.MkdirAll(destPath, mode.Perm()|0700)
+		return
 	}
 
-	resourcesJson, err := json.Marshal(appFilesRequest)
+	err = os.MkdirAll(filepath.Dir(destPath), os.ModePerm|os.ModeDir)
 	if err != nil {
-		apiResponse = net.NewApiStatusWithError("Failed to create json for resource_match request", err)
 		return
 	}
 
-	path := fmt.Sprintf("%s/v2/resource_match", repo.config.Target)
-	req, apiResponse := repo.gateway.NewRequest("PUT", path, repo.config.AccessToken, bytes.NewReader(resourcesJson))
-	if apiResponse.IsNotSuccessful() {
+	rc, err := f.Open()
+	if err != nil {
+		return
+	}
+	defer rc.Close()
+
+	destFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
 		return
 	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, rc)
+	return
+}
+
+// getFilesToUpload asks the cloud controller which files it already has.
+// Only regular files that are not executable are offered, since the cloud
+// controller does not keep the mode of the files it has cached. Everything
+// else is always uploaded in the zip. Files whose SHA1 is matched in cache are
+// not asked about again.
+func (repo CloudControllerApplicationBitsRepository) getFilesToUpload(allAppFiles []cf.AppFile, cache *cf.ResourceCache) (appFilesToUpload []cf.AppFile, resourcesJson []byte, usedCachedMatches bool, apiResponse net.ApiResponse) {
+	resources := []AppFile{}
+	unmatchedResources := []AppFile{}
+	matchedPaths := map[string]bool{}
+	matchedSha1s := []string{}
 
-	res := []AppFile{}
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(req, &res)
+	for _, file := range allAppFiles {
+		if !isCacheable(file) {
+			continue
+		}
 
-	appFilesToUpload = make([]cf.AppFile, len(allAppFiles))
-	copy(appFilesToUpload, allAppFiles)
-	for _, file := range res {
-		appFile := cf.AppFile{
+		resource := AppFile{
 			Path: file.Path,
 			Sha1: file.Sha1,
 			Size: file.Size,
 		}
-		appFilesToUpload = deleteAppFile(appFilesToUpload, appFile)
+		resources = append(resources, resource)
+
+		if cache != nil && cache.IsMatched(file.Sha1) {
+			usedCachedMatches = true
+			matchedPaths[file.Path] = true
+			matchedSha1s = append(matchedSha1s, file.Sha1)
+			continue
+		}
+		unmatchedResources = append(unmatchedResources, resource)
 	}
 
-	return
-}
+	resourcesJson, err := json.Marshal(resources)
+	if err != nil {
+		apiResponse = net.NewApiStatusWithError("Failed to create json for resource_match request", err)
+		return
+	}
 
-func deleteAppFile(appFiles []cf.AppFile, targetFile cf.AppFile) []cf.AppFile {
-	for i, file := range appFiles {
-		if file.Path == targetFile.Path {
-			appFiles[i] = appFiles[len(appFiles)-1]
-			return appFiles[:len(appFiles)-1]
+	if len(unmatchedResources) > 0 {
+		var res []AppFile
+		res, apiResponse = repo.matchResources(unmatchedResources)
+		if apiResponse.IsNotSuccessful() {
+			return
 		}
-	}
-	return appFiles
-}
 
-func createApplicationUploadBody(zipBuffer *bytes.Buffer, resourcesJson []byte) (body *bytes.Buffer, boundary string, err error) {
-	body = new(bytes.Buffer)
+		for _, file := range res {
+			matchedPaths[file.Path] = true
+			matchedSha1s = append(matchedSha1s, file.Sha1)
+		}
+	}
 
-	writer := multipart.NewWriter(body)
-	defer writer.Close()
+	if cache != nil {
+		cache.SetMatched(matchedSha1s)
+	}
 
-	boundary = writer.Boundary()
+	appFilesToUpload = []cf.AppFile{}
+	for _, file := range allAppFiles {
+		if !matchedPaths[file.Path] {
+			appFilesToUpload = append(appFilesToUpload, file)
+		}
+	}
+	return
+}
 
-	part, err := writer.CreateFormField("resources")
+func (repo CloudControllerApplicationBitsRepository) matchResources(resources []AppFile) (matched []AppFile, apiResponse net.ApiResponse) {
+	resourcesJson, err := json.Marshal(resources)
 	if err != nil {
+		apiResponse = net.NewApiStatusWithError("Failed to create json for resource_match request", err)
 		return
 	}
 
-	_, err = io.Copy(part, bytes.NewBuffer(resourcesJson))
-	if err != nil {
+	path := fmt.Sprintf("%s/v2/resource_match", repo.config.Target)
+	req, apiResponse := repo.gateway.NewRequest("PUT", path, repo.config.AccessToken, bytes.NewReader(resourcesJson))
+	if apiResponse.IsNotSuccessful() {
 		return
 	}
 
-	if zipBuffer.Len() == 0 {
+	matched = []AppFile{}
+	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(req, &matched)
+	return
+}
+
+func isCacheable(file cf.AppFile) bool {
+	return file.Mode.IsRegular() && file.Mode&0111 == 0
+}
+
+func (repo CloudControllerApplicationBitsRepository) writeUploadBody(body io.Writer, boundary, appDir, archive string, appFilesToUpload []cf.AppFile, resourcesJson []byte, onProgress func(int64)) (err error) {
+	writer := multipart.NewWriter(body)
+	err = writer.SetBoundary(boundary)
+	if err != nil {
 		return
 	}
 
-	part, err = createZipPartWriter(zipBuffer, writer)
+	part, err := writer.CreateFormField("resources")
 	if err != nil {
 		return
 	}
 
-	_, err = io.Copy(part, zipBuffer)
+	_, err = part.Write(resourcesJson)
 	if err != nil {
 		return
 	}
 
+	switch {
+	case archive != "":
+		part, err = createZipPartWriter(writer)
+		if err != nil {
+			return
+		}
+
+		err = copyArchive(archive, part, onProgress)
+		if err != nil {
+			return
+		}
+	case len(appFilesToUpload) > 0:
+		part, err = createZipPartWriter(writer)
+		if err != nil {
+			return
+		}
+
+		err = repo.zipper.Zip(appDir, appFilesToUpload, part, onProgress)
+		if err != nil {
+			return
+		}
+	}
+
+	err = writer.Close()
 	return
 }
 
-func createZipPartWriter(zipBuffer *bytes.Buffer, writer *multipart.Writer) (io.Writer, error) {
+func createZipPartWriter(writer *multipart.Writer) (io.Writer, error) {
 	h := make(textproto.MIMEHeader)
 	h.Set("Content-Disposition", `form-data; name="application"; filename="application.zip"`)
 	h.Set("Content-Type", "application/zip")
-	h.Set("Content-Length", fmt.Sprintf("%d", zipBuffer.Len()))
 	h.Set("Content-Transfer-Encoding", "binary")
 	return writer.CreatePart(h)
 }
+
+func copyArchive(archive string, target io.Writer, onProgress func(int64)) (err error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	_, err = io.Copy(target, io.TeeReader(file, cf.NewProgressCounter(onProgress)))
+	return
+}
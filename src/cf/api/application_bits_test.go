@@ -1,20 +1,26 @@
 package api_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"cf"
 	. "cf/api"
 	"cf/configuration"
 	"cf/net"
+	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testhelpers"
 	"testing"
+	"time"
 )
 
 var expectedResources = testhelpers.RemoveWhiteSpaceFromBody(`[
@@ -65,9 +71,9 @@ var uploadBodyMatcher = func(request *http.Request) bool {
 	if !zipAttachmentContentTransferEncodingMatches {
 		println("Zip Attachment Content Transfer Encoding does not match")
 	}
-	zipAttachmentContentLengthPresent := strings.Contains(bodyString, `Content-Length:`)
-	if !zipAttachmentContentLengthPresent {
-		println("Zip Attachment Content Length missing")
+	bodyIsStreamed := request.ContentLength == -1
+	if !bodyIsStreamed {
+		println("Upload body was not streamed")
 	}
 	zipAttachmentContentPresent := strings.Contains(bodyString, `hello world!`)
 	if !zipAttachmentContentPresent {
@@ -86,7 +92,7 @@ var uploadBodyMatcher = func(request *http.Request) bool {
 	return zipAttachmentContentDispositionMatches &&
 		zipAttachmentContentTypeMatches &&
 		zipAttachmentContentTransferEncodingMatches &&
-		zipAttachmentContentLengthPresent &&
+		bodyIsStreamed &&
 		zipAttachmentContentPresent &&
 		resourcesContentDispositionMatches &&
 		resourcesPresent
@@ -153,21 +159,354 @@ func testUploadApp(t *testing.T, dir string) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	zipper := &testhelpers.FakeZipper{ZippedBuffer: bytes.NewBufferString("hello world!")}
-	repo := NewCloudControllerApplicationBitsRepository(config, gateway, zipper)
+	repo := NewCloudControllerApplicationBitsRepository(config, gateway, zipper, "")
 
 	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
 
-	apiResponse := repo.UploadApp(app, dir)
+	var uploaded, total int64
+	apiResponse := repo.UploadApp(app, dir, func(u, t int64) {
+		uploaded, total = u, t
+	})
 	assert.False(t, apiResponse.IsNotSuccessful())
 
-	uploadDir := cf.TempDirForApp(app)
-	files, err := filepath.Glob(filepath.Join(uploadDir, "*"))
+	files := []string{}
+	for _, file := range zipper.ZippedFiles {
+		files = append(files, file.Path)
+	}
+	sort.Strings(files)
+
+	assert.Equal(t, files, []string{"Gemfile", "Gemfile.lock", "manifest.yml"})
+	assert.Equal(t, uploaded, int64(len("hello world!")))
+	assert.Equal(t, total, int64(59+229+111))
+}
+
+type zipEntry struct {
+	name     string
+	mode     os.FileMode
+	contents string
+}
+
+func createZipFixture(t *testing.T, entries []zipEntry) (path string) {
+	file, err := ioutil.TempFile("", "archive")
 	assert.NoError(t, err)
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name}
+		header.SetMode(entry.mode)
+		w, err := writer.CreateHeader(header)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(entry.contents))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	path = file.Name() + ".zip"
+	assert.NoError(t, os.Rename(file.Name(), path))
+	return
+}
+
+// uploadArchive pushes archive to a cloud controller that has the files
+// named cached.txt.
+func uploadArchive(t *testing.T, archive string) (apiResponse net.ApiResponse, zipper *testhelpers.FakeZipper, matchRequestBody string, bitsRequestBody string) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		bodyBytes, _ := ioutil.ReadAll(request.Body)
+		if strings.Contains(request.URL.Path, "resource_match") {
+			matchRequestBody = string(bodyBytes)
+
+			resources := []AppFile{}
+			json.Unmarshal(bodyBytes, &resources)
+			matched := []AppFile{}
+			for _, resource := range resources {
+				if path.Base(resource.Path) == "cached.txt" {
+					matched = append(matched, resource)
+				}
+			}
+			json.NewEncoder(writer).Encode(matched)
+			return
+		}
+		bitsRequestBody = string(bodyBytes)
+		writer.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	zipper = &testhelpers.FakeZipper{ZippedBuffer: bytes.NewBufferString("hello world!")}
+	repo := NewCloudControllerApplicationBitsRepository(config, gateway, zipper, "")
+
+	apiResponse = repo.UploadApp(cf.Application{Name: "my-zip-app", Guid: "my-zip-app-guid"}, archive, nil)
+	return
+}
+
+func TestUploadAppFromArchiveKeepsModesSymlinksAndEmptyDirectories(t *testing.T) {
+	archive := createZipFixture(t, []zipEntry{
+		{name: "bin/", mode: os.ModeDir | 0755},
+		{name: "bin/start.sh", mode: 0755, contents: "#!/bin/sh"},
+		{name: "config.yml", mode: 0644, contents: "config"},
+		{name: "current.yml", mode: os.ModeSymlink | 0777, contents: "config.yml"},
+		{name: "tmp/", mode: os.ModeDir | 0755},
+		{name: "cached.txt", mode: 0644, contents: "cached"},
+	})
+	defer os.Remove(archive)
+
+	apiResponse, zipper, matchRequestBody, _ := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsSuccessful())
+
+	modes := map[string]os.FileMode{}
+	for _, file := range zipper.ZippedFiles {
+		modes[filepath.ToSlash(file.Path)] = file.Mode
+	}
+
+	assert.Equal(t, len(modes), 4)
+	assert.Equal(t, modes["bin/start.sh"].Perm(), os.FileMode(0755))
+	assert.True(t, modes["config.yml"].IsRegular())
+	assert.True(t, modes["current.yml"]&os.ModeSymlink != 0)
+	assert.True(t, modes["tmp"].IsDir())
+
+	assert.Contains(t, matchRequestBody, "config.yml")
+	assert.NotContains(t, matchRequestBody, "start.sh")
+	assert.NotContains(t, matchRequestBody, "current.yml")
+}
+
+func TestUploadAppRefusesArchivesWithPathsOutsideTheApp(t *testing.T) {
+	archive := createZipFixture(t, []zipEntry{
+		{name: "../../evil.sh", mode: 0755, contents: "#!/bin/sh"},
+	})
+	defer os.Remove(archive)
+
+	apiResponse, _, _, _ := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Contains(t, apiResponse.Message, "outside of the app directory")
+}
+
+func TestUploadAppRefusesArchivesWithSymlinksOutsideTheApp(t *testing.T) {
+	archive := createZipFixture(t, []zipEntry{
+		{name: "passwd", mode: os.ModeSymlink | 0777, contents: "../../../etc/passwd"},
+	})
+	defer os.Remove(archive)
+
+	apiResponse, _, _, _ := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Contains(t, apiResponse.Message, "outside of the app directory")
+}
+
+func TestUploadAppRefusesArchivesEscapingThroughASymlinkChain(t *testing.T) {
+	escapedFile := filepath.Join(os.TempDir(), "cf", "evil")
+	os.Remove(escapedFile)
+
+	archive := createZipFixture(t, []zipEntry{
+		{name: "b", mode: os.ModeSymlink | 0777, contents: "."},
+		{name: "a", mode: os.ModeSymlink | 0777, contents: "b/.."},
+		{name: "a/evil", mode: 0755, contents: "#!/bin/sh"},
+	})
+	defer os.Remove(archive)
+
+	apiResponse, _, _, _ := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Contains(t, apiResponse.Message, "outside of the app directory")
+
+	_, err := os.Lstat(escapedFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUploadAppRefusesSymlinksGoingThroughOtherSymlinks(t *testing.T) {
+	archive := createZipFixture(t, []zipEntry{
+		{name: "a", mode: os.ModeSymlink | 0777, contents: "b/.."},
+		{name: "b", mode: os.ModeSymlink | 0777, contents: "."},
+	})
+	defer os.Remove(archive)
+
+	apiResponse, _, _, _ := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Contains(t, apiResponse.Message, "symlink a points outside of the app directory")
+}
+
+func TestUploadAppFromArchiveKeepsSymlinksToSymlinks(t *testing.T) {
+	archive := createZipFixture(t, []zipEntry{
+		{name: "config/app.yml", mode: 0644, contents: "config"},
+		{name: "current", mode: os.ModeSymlink | 0777, contents: "config"},
+		{name: "latest", mode: os.ModeSymlink | 0777, contents: "current"},
+		{name: "bin/app.yml", mode: os.ModeSymlink | 0777, contents: "../config/app.yml"},
+		{name: "cached.txt", mode: 0644, contents: "cached"},
+	})
+	defer os.Remove(archive)
+
+	apiResponse, zipper, _, _ := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsSuccessful())
+
+	paths := []string{}
+	for _, file := range zipper.ZippedFiles {
+		paths = append(paths, filepath.ToSlash(file.Path))
+	}
+	assert.Contains(t, paths, "latest")
+	assert.Contains(t, paths, "bin/app.yml")
+}
 
-	assert.Equal(t, files, []string{
-		filepath.Join(uploadDir, "Gemfile"),
-		filepath.Join(uploadDir, "Gemfile.lock"),
-		filepath.Join(uploadDir, "manifest.yml"),
+func TestUploadAppSendsAnArchiveAsItIsWhenNoneOfItsFilesAreMatched(t *testing.T) {
+	archive := createZipFixture(t, []zipEntry{
+		{name: "bin/", mode: os.ModeDir | 0755},
+		{name: "bin/start.sh", mode: 0755, contents: "#!/bin/sh"},
+		{name: "config.yml", mode: 0644, contents: "config"},
 	})
+	defer os.Remove(archive)
+
+	archiveBytes, err := ioutil.ReadFile(archive)
+	assert.NoError(t, err)
+
+	apiResponse, zipper, _, bitsRequestBody := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, len(zipper.ZippedFiles), 0)
+	assert.Contains(t, bitsRequestBody, string(archiveBytes))
+}
+
+func TestUploadAppZipsAnArchiveAgainWhenSomeOfItsFilesAreMatched(t *testing.T) {
+	archive := createZipFixture(t, []zipEntry{
+		{name: "config.yml", mode: 0644, contents: "config"},
+		{name: "cached.txt", mode: 0644, contents: "cached"},
+	})
+	defer os.Remove(archive)
+
+	archiveBytes, err := ioutil.ReadFile(archive)
+	assert.NoError(t, err)
+
+	apiResponse, zipper, _, bitsRequestBody := uploadArchive(t, archive)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, len(zipper.ZippedFiles), 1)
+	assert.Equal(t, zipper.ZippedFiles[0].Path, "config.yml")
+	assert.NotContains(t, bitsRequestBody, string(archiveBytes))
+}
+
+type resourceCacheServer struct {
+	matchRequests []string
+	bitsRequests  []string
+	failNextBits  bool
+	failBitsWith  string
+}
+
+func (server *resourceCacheServer) handle(writer http.ResponseWriter, request *http.Request) {
+	bodyBytes, _ := ioutil.ReadAll(request.Body)
+
+	if strings.Contains(request.URL.Path, "resource_match") {
+		server.matchRequests = append(server.matchRequests, string(bodyBytes))
+		fmt.Fprint(writer, `[{"fn": "cached.txt", "sha1": "0c93713c1e43fccf897b7b4f02e822c65d557fdf", "size": 6}]`)
+		return
+	}
+
+	server.bitsRequests = append(server.bitsRequests, string(bodyBytes))
+	if server.failNextBits {
+		server.failNextBits = false
+		writer.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(writer, server.failBitsWith)
+		return
+	}
+	writer.WriteHeader(http.StatusCreated)
+}
+
+func createResourceCacheFixture(t *testing.T) (appDir, cacheDir string) {
+	appDir, err := ioutil.TempDir("", "resource-cache-app")
+	assert.NoError(t, err)
+	cacheDir, err = ioutil.TempDir("", "resource-cache")
+	assert.NoError(t, err)
+
+	aWhileAgo := time.Now().Add(-time.Hour)
+	for name, contents := range map[string]string{"cached.txt": "cached", "changed.txt": "changed"} {
+		path := filepath.Join(appDir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+		assert.NoError(t, os.Chtimes(path, aWhileAgo, aWhileAgo))
+	}
+	return
+}
+
+func uploadWithResourceCache(t *testing.T, ts *httptest.Server, appDir, cacheDir string) (apiResponse net.ApiResponse, zipper *testhelpers.FakeZipper) {
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	zipper = &testhelpers.FakeZipper{ZippedBuffer: bytes.NewBufferString("hello world!")}
+	repo := NewCloudControllerApplicationBitsRepository(config, gateway, zipper, cacheDir)
+
+	apiResponse = repo.UploadApp(cf.Application{Name: "my-cached-app", Guid: "my-cached-app-guid"}, appDir, nil)
+	return
+}
+
+func TestUploadAppDoesNotMatchResourcesConfirmedOnAnEarlierPush(t *testing.T) {
+	appDir, cacheDir := createResourceCacheFixture(t)
+	defer os.RemoveAll(appDir)
+	defer os.RemoveAll(cacheDir)
+
+	server := &resourceCacheServer{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+
+	apiResponse, zipper := uploadWithResourceCache(t, ts, appDir, cacheDir)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, len(server.matchRequests), 1)
+	assert.Contains(t, server.matchRequests[0], "cached.txt")
+	assert.Contains(t, server.matchRequests[0], "changed.txt")
+	assert.Equal(t, len(zipper.ZippedFiles), 1)
+	assert.Equal(t, zipper.ZippedFiles[0].Path, "changed.txt")
+
+	apiResponse, zipper = uploadWithResourceCache(t, ts, appDir, cacheDir)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, len(server.matchRequests), 2)
+	assert.NotContains(t, server.matchRequests[1], "cached.txt")
+	assert.Contains(t, server.matchRequests[1], "changed.txt")
+	assert.Equal(t, len(zipper.ZippedFiles), 1)
+	assert.Equal(t, zipper.ZippedFiles[0].Path, "changed.txt")
+	assert.Contains(t, server.bitsRequests[1], "cached.txt")
+}
+
+func TestUploadAppRetriesWithoutCachedMatchesWhenTheUploadFails(t *testing.T) {
+	appDir, cacheDir := createResourceCacheFixture(t)
+	defer os.RemoveAll(appDir)
+	defer os.RemoveAll(cacheDir)
+
+	server := &resourceCacheServer{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+
+	apiResponse, _ := uploadWithResourceCache(t, ts, appDir, cacheDir)
+	assert.True(t, apiResponse.IsSuccessful())
+
+	server.failNextBits = true
+	server.failBitsWith = `{"code": 10010, "description": "The resource could not be found"}`
+	apiResponse, _ = uploadWithResourceCache(t, ts, appDir, cacheDir)
+	assert.True(t, apiResponse.IsSuccessful())
+
+	assert.Equal(t, len(server.bitsRequests), 3)
+	assert.Equal(t, len(server.matchRequests), 3)
+	assert.NotContains(t, server.matchRequests[1], "cached.txt")
+	assert.Contains(t, server.matchRequests[2], "cached.txt")
+}
+
+func TestUploadAppDoesNotRetryWhenTheUploadFailsForAnotherReason(t *testing.T) {
+	appDir, cacheDir := createResourceCacheFixture(t)
+	defer os.RemoveAll(appDir)
+	defer os.RemoveAll(cacheDir)
+
+	server := &resourceCacheServer{}
+	ts := httptest.NewTLSServer(http.HandlerFunc(server.handle))
+	defer ts.Close()
+
+	apiResponse, _ := uploadWithResourceCache(t, ts, appDir, cacheDir)
+	assert.True(t, apiResponse.IsSuccessful())
+
+	server.failNextBits = true
+	server.failBitsWith = `{"code": 160001, "description": "The app upload is invalid"}`
+	apiResponse, _ = uploadWithResourceCache(t, ts, appDir, cacheDir)
+	assert.False(t, apiResponse.IsSuccessful())
+	assert.Equal(t, apiResponse.ErrorCode, "160001")
+
+	assert.Equal(t, len(server.bitsRequests), 2)
+	assert.Equal(t, len(server.matchRequests), 2)
 }
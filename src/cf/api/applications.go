@@ -17,12 +17,16 @@ type ApplicationRepository interface {
 	FindByName(name string) (app cf.Application, apiResponse net.ApiResponse)
 	SetEnv(app cf.Application, envVars map[string]string) (apiResponse net.ApiResponse)
 	Create(newApp cf.Application) (createdApp cf.Application, apiResponse net.ApiResponse)
+	Update(app cf.Application) (updatedApp cf.Application, apiResponse net.ApiResponse)
 	Delete(app cf.Application) (apiResponse net.ApiResponse)
 	Rename(app cf.Application, newName string) (apiResponse net.ApiResponse)
 	Scale(app cf.Application) (apiResponse net.ApiResponse)
 	Start(app cf.Application) (updatedApp cf.Application, apiResponse net.ApiResponse)
 	Stop(app cf.Application) (updatedApp cf.Application, apiResponse net.ApiResponse)
 	GetInstances(app cf.Application) (instances []cf.ApplicationInstance, apiResponse net.ApiResponse)
+	KillInstance(app cf.Application, index int) (apiResponse net.ApiResponse)
+	GetCrashes(app cf.Application) (crashes []cf.InstanceCrash, apiResponse net.ApiResponse)
+	GetEnv(app cf.Application) (env cf.AppEnvironment, apiResponse net.ApiResponse)
 }
 
 type CloudControllerApplicationRepository struct {
@@ -68,14 +72,18 @@ func (repo CloudControllerApplicationRepository) FindByName(name string) (app cf
 	}
 
 	urls := []string{}
+	routes := []cf.Route{}
 	// This is a little wonky but we made a concious effort
 	// to keep the domain very separate from the API repsonses
 	// to maintain flexibility.
-	domainRoute := cf.Route{}
 	for _, route := range summaryResponse.Routes {
-		domainRoute.Domain = cf.Domain{Name: route.Domain.Name}
-		domainRoute.Host = route.Host
+		domainRoute := cf.Route{
+			Guid:   route.Guid,
+			Host:   route.Host,
+			Domain: cf.Domain{Name: route.Domain.Name, Guid: route.Domain.Guid},
+		}
 		urls = append(urls, domainRoute.URL())
+		routes = append(routes, domainRoute)
 	}
 
 	app = cf.Application{
@@ -84,9 +92,21 @@ func (repo CloudControllerApplicationRepository) FindByName(name string) (app cf
 		Instances:        summaryResponse.Instances,
 		RunningInstances: summaryResponse.RunningInstances,
 		Memory:           summaryResponse.Memory,
+		DiskQuota:        summaryResponse.DiskQuota,
 		EnvironmentVars:  res.Entity.EnvironmentJson,
 		Urls:             urls,
+		Routes:           routes,
+		ServiceNames:     summaryResponse.ServiceNames,
 		State:            strings.ToLower(summaryResponse.State),
+		Command:          res.Entity.Command,
+		BuildpackUrl:     res.Entity.Buildpack,
+		Stack:            cf.Stack{Guid: res.Entity.StackGuid, Name: res.Entity.Stack.Entity.Name},
+
+		DetectedBuildpack:    summaryResponse.DetectedBuildpack,
+		DetectedStartCommand: summaryResponse.DetectedStartCommand,
+		PackageState:         summaryResponse.PackageState,
+		StagingTaskId:        summaryResponse.StagingTaskId,
+		StagingFailedReason:  summaryResponse.StagingFailedReason,
 	}
 
 	return
@@ -147,6 +167,29 @@ func (repo CloudControllerApplicationRepository) Create(newApp cf.Application) (
 	return
 }
 
+// Update changes the attributes that are set on app, leaving the rest as
+// they are on the server.
+func (repo CloudControllerApplicationRepository) Update(app cf.Application) (updatedApp cf.Application, apiResponse net.ApiResponse) {
+	updates := map[string]interface{}{}
+	if app.Instances > 0 {
+		updates["instances"] = app.Instances
+	}
+	if app.Memory > 0 {
+		updates["memory"] = app.Memory
+	}
+	if app.Command != "" {
+		updates["command"] = app.Command
+	}
+	if app.BuildpackUrl != "" {
+		updates["buildpack"] = app.BuildpackUrl
+	}
+	if app.Stack.Guid != "" {
+		updates["stack_guid"] = app.Stack.Guid
+	}
+
+	return repo.updateApplication(app, updates)
+}
+
 func stringOrNull(s string) string {
 	if s == "" {
 		return "null"
@@ -221,8 +264,9 @@ func (repo CloudControllerApplicationRepository) Stop(app cf.Application) (updat
 type InstancesApiResponse map[string]InstanceApiResponse
 
 type InstanceApiResponse struct {
-	State string
-	Since float64
+	State   string
+	Since   float64
+	Details string
 }
 
 func (repo CloudControllerApplicationRepository) GetInstances(app cf.Application) (instances []cf.ApplicationInstance, apiResponse net.ApiResponse) {
@@ -247,13 +291,72 @@ func (repo CloudControllerApplicationRepository) GetInstances(app cf.Application
 		}
 
 		instances[index] = cf.ApplicationInstance{
-			State: cf.InstanceState(strings.ToLower(v.State)),
-			Since: time.Unix(int64(v.Since), 0),
+			Index:   index,
+			State:   cf.InstanceState(strings.ToLower(v.State)),
+			Since:   time.Unix(int64(v.Since), 0),
+			Details: v.Details,
 		}
 	}
 	return
 }
 
+// KillInstance stops a single instance of app, which the health manager then
+// replaces with a new one.
+func (repo CloudControllerApplicationRepository) KillInstance(app cf.Application, index int) (apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/apps/%s/instances/%d", repo.config.Target, app.Guid, index)
+	request, apiResponse := repo.gateway.NewRequest("DELETE", path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.gateway.PerformRequest(request)
+	return
+}
+
+type CrashApiResponse struct {
+	Index           int
+	Since           float64
+	ExitStatus      int    `json:"exit_status"`
+	ExitDescription string `json:"exit_description"`
+}
+
+func (repo CloudControllerApplicationRepository) GetCrashes(app cf.Application) (crashes []cf.InstanceCrash, apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/apps/%s/crashes", repo.config.Target, app.Guid)
+	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	crashesResponse := []CrashApiResponse{}
+	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, &crashesResponse)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	for _, crash := range crashesResponse {
+		crashes = append(crashes, cf.InstanceCrash{
+			Index:           crash.Index,
+			Since:           time.Unix(int64(crash.Since), 0),
+			ExitStatus:      crash.ExitStatus,
+			ExitDescription: crash.ExitDescription,
+		})
+	}
+	return
+}
+
+// GetEnv fetches the variables the platform provides, such as VCAP_SERVICES,
+// and the env variable groups, along with the ones set by the user.
+func (repo CloudControllerApplicationRepository) GetEnv(app cf.Application) (env cf.AppEnvironment, apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/apps/%s/env", repo.config.Target, app.Guid)
+	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, &env)
+	return
+}
+
 func (repo CloudControllerApplicationRepository) updateApplication(app cf.Application, updates map[string]interface{}) (updatedApp cf.Application, apiResponse net.ApiResponse) {
 	path := fmt.Sprintf("%s/v2/apps/%s", repo.config.Target, app.Guid)
 
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"testhelpers"
 	"testing"
+	"time"
 )
 
 var singleAppResponse = testhelpers.TestResponse{Status: http.StatusOK, Body: `
@@ -30,6 +31,17 @@ var singleAppResponse = testhelpers.TestResponse{Status: http.StatusOK, Body: `
         "memory": 256,
         "instances": 1,
         "state": "STOPPED",
+        "command": "bundle exec rackup",
+        "buildpack": "https://example.com/buildpack.git",
+        "stack_guid": "stack-guid",
+        "stack": {
+          "metadata": {
+            "guid": "stack-guid"
+          },
+          "entity": {
+            "name": "lucid64"
+          }
+        },
         "routes": [
       	  {
       	    "metadata": {
@@ -76,7 +88,12 @@ var appSummaryResponse = testhelpers.TestResponse{Status: http.StatusOK, Body: `
   ],
   "running_instances": 1,
   "memory": 128,
-  "instances": 1
+  "disk_quota": 1024,
+  "instances": 1,
+  "detected_buildpack": "Ruby/Rack",
+  "detected_start_command": "bundle exec rackup config.ru -p $PORT",
+  "package_state": "STAGED",
+  "staging_task_id": "staging-task-guid"
 }`}
 
 var appSummaryEndpoint = testhelpers.CreateEndpoint(
@@ -106,6 +123,7 @@ func TestFindByName(t *testing.T) {
 		Space:       cf.Space{Name: "my-space", Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	app, apiResponse := repo.FindByName("App1")
@@ -115,9 +133,23 @@ func TestFindByName(t *testing.T) {
 	assert.Equal(t, app.Memory, uint64(128))
 	assert.Equal(t, app.Instances, 1)
 	assert.Equal(t, app.EnvironmentVars, map[string]string{"foo": "bar", "baz": "boom"})
+	assert.Equal(t, app.Command, "bundle exec rackup")
+	assert.Equal(t, app.BuildpackUrl, "https://example.com/buildpack.git")
+	assert.Equal(t, app.Stack.Guid, "stack-guid")
+	assert.Equal(t, app.Stack.Name, "lucid64")
+	assert.Equal(t, app.DiskQuota, uint64(1024))
+	assert.Equal(t, app.DetectedBuildpack, "Ruby/Rack")
+	assert.Equal(t, app.DetectedStartCommand, "bundle exec rackup config.ru -p $PORT")
+	assert.Equal(t, app.PackageState, "STAGED")
+	assert.Equal(t, app.StagingState(), "staged")
 
 	assert.Equal(t, len(app.Urls), 1)
 	assert.Equal(t, app.Urls[0], "app1.cfapps.io")
+
+	assert.Equal(t, len(app.Routes), 1)
+	assert.Equal(t, app.Routes[0].Guid, "route-1-guid")
+	assert.Equal(t, app.Routes[0].Host, "app1")
+	assert.Equal(t, app.Routes[0].Domain.Guid, "domain-1-guid")
 }
 
 var appNotFoundResponse = testhelpers.TestResponse{Status: http.StatusOK, Body: `
@@ -142,6 +174,7 @@ func TestFindByNameWhenAppIsNotFound(t *testing.T) {
 		Space:       cf.Space{Name: "my-space", Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	_, apiResponse := repo.FindByName("App1")
@@ -165,6 +198,7 @@ func TestSetEnv(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	app := cf.Application{Guid: "app1-guid", Name: "App1"}
@@ -205,6 +239,7 @@ func TestCreateApplication(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	newApp := cf.Application{
@@ -239,6 +274,7 @@ func TestCreateApplicationWithoutBuildpackStackOrCommand(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	newApp := cf.Application{
@@ -259,6 +295,7 @@ func TestCreateRejectsInproperNames(t *testing.T) {
 
 	config := &configuration.Configuration{Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	createdApp, apiResponse := repo.Create(cf.Application{Name: "name with space"})
@@ -291,6 +328,7 @@ func TestDeleteApplication(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
@@ -312,6 +350,7 @@ func TestRename(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	org := cf.Application{Guid: "my-app-guid"}
@@ -332,6 +371,7 @@ func testScale(t *testing.T, app cf.Application, expectedBody string) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	apiResponse := repo.Scale(app)
@@ -397,6 +437,7 @@ func TestStartApplication(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
@@ -408,6 +449,72 @@ func TestStartApplication(t *testing.T) {
 	assert.Equal(t, "my-updated-app-guid", updatedApp.Guid)
 }
 
+var updateApplicationEndpoint = testhelpers.CreateEndpoint(
+	"PUT",
+	"/v2/apps/my-cool-app-guid",
+	testhelpers.RequestBodyMatcher(`{"buildpack":"buildpack-url","command":"some-command","console":true,"instances":3,"memory":512,"stack_guid":"some-stack-guid"}`),
+	testhelpers.TestResponse{Status: http.StatusCreated, Body: `
+{
+  "metadata": {
+    "guid": "my-cool-app-guid"
+  },
+  "entity": {
+    "name": "my-cool-app",
+    "state": "STARTED"
+  }
+}`},
+)
+
+func TestUpdateApplication(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(updateApplicationEndpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerApplicationRepository(config, gateway)
+
+	app := cf.Application{
+		Name:         "my-cool-app",
+		Guid:         "my-cool-app-guid",
+		Instances:    3,
+		Memory:       512,
+		BuildpackUrl: "buildpack-url",
+		Stack:        cf.Stack{Guid: "some-stack-guid"},
+		Command:      "some-command",
+	}
+
+	updatedApp, apiResponse := repo.Update(app)
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, updatedApp.Guid, "my-cool-app-guid")
+}
+
+var updateApplicationMemoryOnlyEndpoint = testhelpers.CreateEndpoint(
+	"PUT",
+	"/v2/apps/my-cool-app-guid",
+	testhelpers.RequestBodyMatcher(`{"console":true,"memory":1024}`),
+	testhelpers.TestResponse{Status: http.StatusCreated, Body: `{"metadata":{"guid":"my-cool-app-guid"},"entity":{"name":"my-cool-app"}}`},
+)
+
+func TestUpdateApplicationOnlySendsChangedAttributes(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(updateApplicationMemoryOnlyEndpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerApplicationRepository(config, gateway)
+
+	_, apiResponse := repo.Update(cf.Application{Guid: "my-cool-app-guid", Memory: 1024})
+	assert.False(t, apiResponse.IsNotSuccessful())
+}
+
 var stopApplicationEndpoint = testhelpers.CreateEndpoint(
 	"PUT",
 	"/v2/apps/my-cool-app-guid",
@@ -433,6 +540,7 @@ func TestStopApplication(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
@@ -468,6 +576,7 @@ func TestGetInstances(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerApplicationRepository(config, gateway)
 
 	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
@@ -478,3 +587,170 @@ func TestGetInstances(t *testing.T) {
 	assert.Equal(t, instances[0].State, "running")
 	assert.Equal(t, instances[1].State, "starting")
 }
+
+func TestGetInstancesWithDetails(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/apps/my-cool-app-guid/instances",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `
+{
+  "1": {
+    "state": "DOWN",
+    "since": 1379522342.6783738,
+    "details": "insufficient resources"
+  },
+  "0": {
+    "state": "RUNNING",
+    "since": 1379522342.6783738
+  }
+}`},
+	)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(endpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerApplicationRepository(config, gateway)
+
+	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
+
+	instances, apiResponse := repo.GetInstances(app)
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, len(instances), 2)
+	assert.Equal(t, instances[0].Index, 0)
+	assert.Equal(t, instances[0].Details, "")
+	assert.Equal(t, instances[1].Index, 1)
+	assert.Equal(t, instances[1].State, cf.InstanceDown)
+	assert.Equal(t, instances[1].Details, "insufficient resources")
+}
+
+func TestKillInstance(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint(
+		"DELETE",
+		"/v2/apps/my-cool-app-guid/instances/1",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusNoContent},
+	)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(endpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerApplicationRepository(config, gateway)
+
+	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
+
+	apiResponse := repo.KillInstance(app, 1)
+	assert.False(t, apiResponse.IsNotSuccessful())
+}
+
+func TestGetCrashes(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/apps/my-cool-app-guid/crashes",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `
+[
+  {
+    "instance": "instance-guid-1",
+    "index": 1,
+    "since": 1379522342.6783738,
+    "exit_status": 137,
+    "exit_description": "out of memory"
+  },
+  {
+    "instance": "instance-guid-0",
+    "index": 0,
+    "since": 1379522400
+  }
+]`},
+	)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(endpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerApplicationRepository(config, gateway)
+
+	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
+
+	crashes, apiResponse := repo.GetCrashes(app)
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, len(crashes), 2)
+	assert.Equal(t, crashes[0].Index, 1)
+	assert.Equal(t, crashes[0].Since, time.Unix(1379522342, 0))
+	assert.Equal(t, crashes[0].ExitStatus, 137)
+	assert.Equal(t, crashes[0].ExitDescription, "out of memory")
+	assert.Equal(t, crashes[1].Index, 0)
+}
+
+func TestGetEnv(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/apps/my-cool-app-guid/env",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `
+{
+  "staging_env_json": {"STAGING_GROUP": "staging-value"},
+  "running_env_json": {"RUNNING_GROUP": "running-value", "PORT_OFFSET": 2},
+  "environment_json": {"my-key": "my-value"},
+  "system_env_json": {
+    "VCAP_SERVICES": {
+      "p-mysql": [
+        {
+          "name": "my-db",
+          "label": "p-mysql",
+          "credentials": {"username": "db-user", "password": "db-pa55"}
+        }
+      ]
+    }
+  },
+  "application_env_json": {
+    "VCAP_APPLICATION": {"application_name": "my-cool-app"}
+  }
+}`},
+	)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(endpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerApplicationRepository(config, gateway)
+
+	app := cf.Application{Name: "my-cool-app", Guid: "my-cool-app-guid"}
+
+	env, apiResponse := repo.GetEnv(app)
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, env.User, map[string]string{"my-key": "my-value"})
+	assert.Equal(t, env.Staging, map[string]interface{}{"STAGING_GROUP": "staging-value"})
+	assert.Equal(t, env.Running, map[string]interface{}{"RUNNING_GROUP": "running-value", "PORT_OFFSET": float64(2)})
+	assert.Equal(t, env.Application, map[string]interface{}{
+		"VCAP_APPLICATION": map[string]interface{}{"application_name": "my-cool-app"},
+	})
+
+	services := env.System["VCAP_SERVICES"].(map[string]interface{})
+	instance := services["p-mysql"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, instance["name"], "my-db")
+	assert.Equal(t, instance["credentials"], map[string]interface{}{"username": "db-user", "password": "db-pa55"})
+}
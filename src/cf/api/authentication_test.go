@@ -125,6 +125,7 @@ func setupAuthWithEndpoint(t *testing.T, handler func(http.ResponseWriter, *http
 	config.AccessToken = ""
 
 	gateway := net.NewUAAGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	auth = NewUAAAuthenticationRepository(gateway, configRepo)
 	return
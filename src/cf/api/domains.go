@@ -11,6 +11,7 @@ import (
 type DomainRepository interface {
 	FindAllInCurrentSpace() (domains []cf.Domain, apiResponse net.ApiResponse)
 	FindAllByOrg(org cf.Organization) (domains []cf.Domain, apiResponse net.ApiResponse)
+	ListDomainsForOrg(org cf.Organization, cb func([]cf.Domain) bool) (apiResponse net.ApiResponse)
 	FindByNameInCurrentSpace(name string) (domain cf.Domain, apiResponse net.ApiResponse)
 	FindByNameInOrg(name string, owningOrg cf.Organization) (domain cf.Domain, apiResponse net.ApiResponse)
 	Create(domainToCreate cf.Domain, owningOrg cf.Organization) (createdDomain cf.Domain, apiResponse net.ApiResponse)
@@ -31,59 +32,53 @@ func NewCloudControllerDomainRepository(config *configuration.Configuration, gat
 }
 
 func (repo CloudControllerDomainRepository) FindAllInCurrentSpace() (domains []cf.Domain, apiResponse net.ApiResponse) {
-	path := fmt.Sprintf("%s/v2/spaces/%s/domains", repo.config.Target, repo.config.Space.Guid)
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	response := new(ApiResponse)
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, response)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	for _, r := range response.Resources {
-		domains = append(domains, cf.Domain{Name: r.Entity.Name, Guid: r.Metadata.Guid})
-	}
-
+	path := fmt.Sprintf("/v2/spaces/%s/domains", repo.config.Space.Guid)
+	resources := []Resource{}
+	apiResponse = listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, path, &resources,
+		func() bool {
+			for _, r := range resources {
+				domains = append(domains, cf.Domain{Name: r.Entity.Name, Guid: r.Metadata.Guid})
+			}
+			return true
+		})
 	return
 }
 
 func (repo CloudControllerDomainRepository) FindAllByOrg(org cf.Organization) (domains []cf.Domain, apiResponse net.ApiResponse) {
-	orgGuid := org.Guid
-
-	path := fmt.Sprintf("%s/v2/organizations/%s/domains?inline-relations-depth=1", repo.config.Target, orgGuid)
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	response := new(DomainApiResponse)
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, response)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	for _, r := range response.Resources {
-		domain := cf.Domain{
-			Name: r.Entity.Name,
-			Guid: r.Metadata.Guid,
-		}
-		domain.Shared = r.Entity.OwningOrganizationGuid == ""
-
-		for _, space := range r.Entity.Spaces {
-			domain.Spaces = append(domain.Spaces, cf.Space{
-				Name: space.Entity.Name,
-				Guid: space.Metadata.Guid,
-			})
-		}
-		domains = append(domains, domain)
-	}
-
+	apiResponse = repo.ListDomainsForOrg(org, func(page []cf.Domain) bool {
+		domains = append(domains, page...)
+		return true
+	})
 	return
 }
 
+// ListDomainsForOrg calls cb with the domains of org on each page as soon as
+// it arrives. Returning false from cb stops listing.
+func (repo CloudControllerDomainRepository) ListDomainsForOrg(org cf.Organization, cb func([]cf.Domain) bool) (apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("/v2/organizations/%s/domains?inline-relations-depth=1", org.Guid)
+	resources := []DomainResource{}
+	return listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, path, &resources,
+		func() bool {
+			domains := []cf.Domain{}
+			for _, r := range resources {
+				domain := cf.Domain{
+					Name: r.Entity.Name,
+					Guid: r.Metadata.Guid,
+				}
+				domain.Shared = r.Entity.OwningOrganizationGuid == ""
+
+				for _, space := range r.Entity.Spaces {
+					domain.Spaces = append(domain.Spaces, cf.Space{
+						Name: space.Entity.Name,
+						Guid: space.Metadata.Guid,
+					})
+				}
+				domains = append(domains, domain)
+			}
+			return cb(domains)
+		})
+}
+
 func (repo CloudControllerDomainRepository) FindByNameInCurrentSpace(name string) (domain cf.Domain, apiResponse net.ApiResponse) {
 	domains, apiResponse := repo.FindAllInCurrentSpace()
 
@@ -55,6 +55,7 @@ func TestFindAllInCurrentSpace(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerDomainRepository(config, gateway)
 
 	domains, apiResponse := repo.FindAllInCurrentSpace()
@@ -134,6 +135,7 @@ func TestFindAllByOrg(t *testing.T) {
 		Organization: org,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerDomainRepository(config, gateway)
 
 	domains, apiResponse := repo.FindAllByOrg(org)
@@ -161,6 +163,7 @@ func TestFindByNameInCurrentSpaceReturnsTheDomainMatchingTheName(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerDomainRepository(config, gateway)
 
 	domain, apiResponse := repo.FindByNameInCurrentSpace("domain2.cf-app.com")
@@ -193,6 +196,7 @@ func TestFindByNameInCurrentSpaceReturnsTheFirstDomainIfNameEmpty(t *testing.T)
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerDomainRepository(config, gateway)
 
 	_, apiResponse := repo.FindByNameInCurrentSpace("")
@@ -209,6 +213,7 @@ func TestFindByNameInCurrentSpaceReturnsNotFoundIfNameEmptyAndNoDomains(t *testi
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerDomainRepository(config, gateway)
 
 	_, apiResponse := repo.FindByNameInCurrentSpace("")
@@ -226,6 +231,7 @@ func TestFindByNameInCurrentSpaceWhenTheDomainIsNotFound(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerDomainRepository(config, gateway)
 
 	_, apiResponse := repo.FindByNameInCurrentSpace("domain3.cf-app.com")
@@ -261,6 +267,7 @@ func TestReserveDomain(t *testing.T) {
 	}
 
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerDomainRepository(config, gateway)
 
 	domainToCreate := cf.Domain{Name: "example.com"}
@@ -279,6 +286,7 @@ func TestFindByNameInOrgWhenDomainExists(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	repo := NewCloudControllerDomainRepository(&config, gateway)
 
@@ -312,6 +320,7 @@ func TestMapDomainSuccess(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	repo := NewCloudControllerDomainRepository(&config, gateway)
 
@@ -334,6 +343,7 @@ func TestMapDomainWhenServerError(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	repo := NewCloudControllerDomainRepository(&config, gateway)
 
@@ -367,6 +377,7 @@ func TestUnmapDomainSuccess(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	repo := NewCloudControllerDomainRepository(&config, gateway)
 
@@ -400,6 +411,7 @@ func TestDeleteDomainSuccess(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	repo := NewCloudControllerDomainRepository(&config, gateway)
 
@@ -421,6 +433,7 @@ func TestDeleteDomainFailure(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	repo := NewCloudControllerDomainRepository(&config, gateway)
 
@@ -6,7 +6,7 @@ import (
 )
 
 type EndpointRepository interface {
-	UpdateEndpoint(endpoint string) (apiResponse net.ApiResponse)
+	UpdateEndpoint(endpoint string, sslDisabled bool, caCertFile string) (apiResponse net.ApiResponse)
 }
 
 type RemoteEndpointRepository struct {
@@ -22,7 +22,21 @@ func NewEndpointRepository(config *configuration.Configuration, gateway net.Gate
 	return
 }
 
-func (repo RemoteEndpointRepository) UpdateEndpoint(endpoint string) (apiResponse net.ApiResponse) {
+// UpdateEndpoint checks endpoint with the given SSL settings before saving
+// them along with it, so that they always belong to the current target. The
+// configuration is left as it was when the check fails.
+func (repo RemoteEndpointRepository) UpdateEndpoint(endpoint string, sslDisabled bool, caCertFile string) (apiResponse net.ApiResponse) {
+	previousSSLDisabled, previousCACertFile := repo.config.SSLDisabled, repo.config.CACertFile
+	defer func() {
+		if apiResponse.IsNotSuccessful() {
+			repo.config.SSLDisabled, repo.config.CACertFile = previousSSLDisabled, previousCACertFile
+		}
+	}()
+
+	// the gateway verifies the endpoint with these
+	repo.config.SSLDisabled = sslDisabled
+	repo.config.CACertFile = caCertFile
+
 	request, apiResponse := repo.gateway.NewRequest("GET", endpoint+"/v2/info", "", nil)
 	if apiResponse.IsNotSuccessful() {
 		return
@@ -52,7 +66,7 @@ func (repo RemoteEndpointRepository) UpdateEndpoint(endpoint string) (apiRespons
 
 	err := repo.configRepo.Save()
 	if err != nil {
-		apiResponse = net.NewApiStatusWithMessage(err.Error())
+		apiResponse = net.NewApiStatusWithMessage("%s", err.Error())
 	}
 
 	return
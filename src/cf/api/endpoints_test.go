@@ -38,7 +38,7 @@ func TestApiWhenUrlIsValidHttpsInfoEndpoint(t *testing.T) {
 	ts, repo := createRepo(configRepo, validApiInfoEndpoint)
 	defer ts.Close()
 
-	repo.UpdateEndpoint(ts.URL)
+	repo.UpdateEndpoint(ts.URL, false, "")
 
 	savedConfig := testhelpers.SavedConfiguration
 
@@ -59,7 +59,7 @@ func TestApiWhenUrlIsValidHttpInfoEndpoint(t *testing.T) {
 	config, _ := configRepo.Get()
 	gateway := net.NewCloudControllerGateway()
 	repo := NewEndpointRepository(config, gateway, configRepo)
-	repo.UpdateEndpoint(ts.URL)
+	repo.UpdateEndpoint(ts.URL, false, "")
 
 	savedConfig := testhelpers.SavedConfiguration
 
@@ -76,7 +76,7 @@ func TestApiWhenUrlIsMissingScheme(t *testing.T) {
 	gateway := net.NewCloudControllerGateway()
 	repo := NewEndpointRepository(config, gateway, configRepo)
 
-	apiResponse := repo.UpdateEndpoint("example.com")
+	apiResponse := repo.UpdateEndpoint("example.com", false, "")
 
 	assert.True(t, apiResponse.IsNotSuccessful())
 }
@@ -92,7 +92,7 @@ func TestApiWhenEndpointReturns404(t *testing.T) {
 	ts, repo := createRepo(configRepo, notFoundApiEndpoint)
 	defer ts.Close()
 
-	apiResponse := repo.UpdateEndpoint(ts.URL)
+	apiResponse := repo.UpdateEndpoint(ts.URL, false, "")
 
 	assert.True(t, apiResponse.IsNotSuccessful())
 }
@@ -108,16 +108,54 @@ func TestApiWhenEndpointReturnsInvalidJson(t *testing.T) {
 	ts, repo := createRepo(configRepo, invalidJsonResponseApiEndpoint)
 	defer ts.Close()
 
-	apiResponse := repo.UpdateEndpoint(ts.URL)
+	apiResponse := repo.UpdateEndpoint(ts.URL, false, "")
 
 	assert.True(t, apiResponse.IsNotSuccessful())
 }
 
+func TestApiSavesTheSSLSettingsWithTheTarget(t *testing.T) {
+	configRepo := testhelpers.FakeConfigRepository{}
+	configRepo.Delete()
+	configRepo.Login()
+
+	ts, repo := createRepo(configRepo, validApiInfoEndpoint)
+	defer ts.Close()
+
+	apiResponse := repo.UpdateEndpoint(ts.URL, true, "/path/to/ca.pem")
+	assert.True(t, apiResponse.IsSuccessful())
+
+	savedConfig := testhelpers.SavedConfiguration
+	assert.Equal(t, savedConfig.Target, ts.URL)
+	assert.True(t, savedConfig.SSLDisabled)
+	assert.Equal(t, savedConfig.CACertFile, "/path/to/ca.pem")
+}
+
+func TestApiKeepsTheSSLSettingsWhenTheEndpointFails(t *testing.T) {
+	configRepo := testhelpers.FakeConfigRepository{}
+	configRepo.Delete()
+	configRepo.Login()
+	config, _ := configRepo.Get()
+	config.Target = "https://api.example.com"
+	config.CACertFile = "/path/to/ca.pem"
+
+	ts, repo := createRepo(configRepo, notFoundApiEndpoint)
+	defer ts.Close()
+
+	apiResponse := repo.UpdateEndpoint(ts.URL, true, "")
+	assert.True(t, apiResponse.IsNotSuccessful())
+
+	config, _ = configRepo.Get()
+	assert.Equal(t, config.Target, "https://api.example.com")
+	assert.False(t, config.SSLDisabled)
+	assert.Equal(t, config.CACertFile, "/path/to/ca.pem")
+}
+
 func createRepo(configRepo testhelpers.FakeConfigRepository, endpoint func(w http.ResponseWriter, r *http.Request)) (ts *httptest.Server, repo EndpointRepository) {
 	ts = httptest.NewTLSServer(http.HandlerFunc(endpoint))
 
 	config, _ := configRepo.Get()
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo = NewEndpointRepository(config, gateway, configRepo)
 	return
 }
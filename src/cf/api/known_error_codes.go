@@ -1,8 +1,14 @@
 package api
 
 const (
-	ORG_EXISTS                  = "30002"
-	SPACE_EXISTS                = "40002"
-	APP_NOT_STAGED              = "170002"
-	SERVICE_INSTANCE_NAME_TAKEN = "60002"
+	ORG_EXISTS                   = "30002"
+	SPACE_EXISTS                 = "40002"
+	STAGING_FAILED               = "170001"
+	APP_NOT_STAGED               = "170002"
+	SERVICE_INSTANCE_NAME_TAKEN  = "60002"
+	APP_ALREADY_BOUND_TO_SERVICE = "90003"
+	USER_EXISTS                  = "scim_resource_already_exists"
+	USER_NOT_FOUND               = "20003"
+	QUOTA_EXISTS                 = "240001"
+	RESOURCE_NOT_FOUND           = "10010"
 )
@@ -6,11 +6,12 @@ import (
 	"cf/net"
 	"code.google.com/p/go.net/websocket"
 	"code.google.com/p/gogoprotobuf/proto"
-	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"regexp"
 	"sort"
+	"sync"
 	"time"
 )
 
@@ -18,18 +19,33 @@ const LOGGREGATOR_REDIRECTOR_PORT = "4443"
 
 type LogsRepository interface {
 	RecentLogsFor(app cf.Application, onConnect func(), onMessage func(logmessage.LogMessage), port string) (err error)
-	TailLogsFor(app cf.Application, onConnect func(), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) (err error)
+	TailLogsFor(app cf.Application, stop <-chan bool, onConnect func(), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) (err error)
+	TailLogsForApps(apps []cf.Application, stop <-chan bool, onConnect func(cf.Application), onDisconnect func(cf.Application, error), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) (err error)
 }
 
+const (
+	// logReconnectAttempts is how many times in a row the logs of an app may
+	// fail to connect before they are given up on.
+	logReconnectAttempts = 10
+	// maxLogReconnectDelay caps the exponential backoff between attempts.
+	maxLogReconnectDelay = 30 * time.Second
+	logKeepAliveInterval = 25 * time.Second
+)
+
 type LoggregatorLogsRepository struct {
 	config                  *configuration.Configuration
 	gateway                 net.Gateway
+	authRepo                AuthenticationRepository
 	loggregatorHostResolver func(string) string
 }
 
-func NewLoggregatorLogsRepository(config *configuration.Configuration, gateway net.Gateway, loggregatorHostResolver func(string) string) (repo LoggregatorLogsRepository) {
+// NewLoggregatorLogsRepository refreshes the token through authRepo when
+// loggregator refuses it. authRepo may be nil, in which case a refused token
+// ends the tail.
+func NewLoggregatorLogsRepository(config *configuration.Configuration, gateway net.Gateway, authRepo AuthenticationRepository, loggregatorHostResolver func(string) string) (repo LoggregatorLogsRepository) {
 	repo.config = config
 	repo.gateway = gateway
+	repo.authRepo = authRepo
 	repo.loggregatorHostResolver = loggregatorHostResolver
 	return
 }
@@ -37,66 +53,278 @@ func NewLoggregatorLogsRepository(config *configuration.Configuration, gateway n
 func (repo LoggregatorLogsRepository) RecentLogsFor(app cf.Application, onConnect func(), onMessage func(logmessage.LogMessage), port string) (err error) {
 	host := repo.loggregatorHostResolver(repo.config.Target) + ":" + port
 	location := host + fmt.Sprintf("/dump/?app=%s", app.Guid)
-	if err != nil {
+	return repo.connectToWebsocket(location, onConnect, onMessage)
+}
+
+// TailLogsFor is TailLogsForApps for a single app. onConnect is called again
+// each time the logs are reconnected.
+func (repo LoggregatorLogsRepository) TailLogsFor(app cf.Application, stop <-chan bool, onConnect func(), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) error {
+	return repo.TailLogsForApps(
+		[]cf.Application{app},
+		stop,
+		func(cf.Application) { onConnect() },
+		func(cf.Application, error) {},
+		onMessage,
+		printInterval,
+		port,
+	)
+}
+
+// TailLogsForApps tails the logs of all of apps at once, over a websocket per
+// app. Messages are passed to onMessage every printInterval seconds, sorted by
+// timestamp across all of the apps.
+//
+// When the logs of an app drop, onDisconnect is called and they are
+// reconnected with an exponential backoff, calling onConnect again once they
+// are back. Messages logged in between are lost. If loggregator refuses the
+// token it is refreshed once before giving up.
+//
+// It returns once the logs of every app have been given up on, or without an
+// error once stop is closed and the messages received so far have been passed
+// on. stop may be nil to tail until the logs are given up on. Signals are
+// left to the caller.
+func (repo LoggregatorLogsRepository) TailLogsForApps(apps []cf.Application, stop <-chan bool, onConnect func(cf.Application), onDisconnect func(cf.Application, error), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) (err error) {
+	host := repo.loggregatorHostResolver(repo.config.Target) + ":" + port
+
+	tail := &logTail{
+		repo:           repo,
+		token:          repo.config.AccessToken,
+		stop:           make(chan bool),
+		connectChan:    make(chan cf.Application),
+		disconnectChan: make(chan droppedLogs),
+		msgChan:        make(chan logmessage.LogMessage, 1000),
+		doneChan:       make(chan error),
+	}
+
+	for _, app := range apps {
+		location := host + fmt.Sprintf("/tail/?app=%s", app.Guid)
+		go tail.tailApp(app, location)
+	}
+
+	tickerChan := time.Tick(printInterval * time.Second)
+	sortableMsg := &sortableLogMessages{}
+	stopped := false
+
+	for running := len(apps); running > 0; {
+		select {
+		case app := <-tail.connectChan:
+			onConnect(app)
+		case dropped := <-tail.disconnectChan:
+			onDisconnect(dropped.app, dropped.err)
+		case msg := <-tail.msgChan:
+			sortableMsg.Messages = append(sortableMsg.Messages, msg)
+		case <-tickerChan:
+			invokeCallbackWithSortedMessages(sortableMsg, onMessage)
+			sortableMsg.Messages = []logmessage.LogMessage{}
+		case <-stop:
+			// A closed channel is always ready, so stop listening to it
+			stop = nil
+			if !stopped {
+				stopped = true
+				close(tail.stop)
+			}
+		case doneErr := <-tail.doneChan:
+			running--
+			if doneErr != nil {
+				err = doneErr
+			}
+		}
+	}
+
+	// Pick up what was received before the last stream ended
+	for len(tail.msgChan) > 0 {
+		sortableMsg.Messages = append(sortableMsg.Messages, <-tail.msgChan)
+	}
+	invokeCallbackWithSortedMessages(sortableMsg, onMessage)
+
+	if stopped {
+		err = nil
+	}
+	return
+}
+
+// logTail is the state shared by the streams of a single TailLogsForApps.
+type logTail struct {
+	repo           LoggregatorLogsRepository
+	stop           chan bool
+	connectChan    chan cf.Application
+	disconnectChan chan droppedLogs
+	msgChan        chan logmessage.LogMessage
+	doneChan       chan error
+
+	tokenMutex sync.Mutex
+	token      string
+}
+
+type droppedLogs struct {
+	app cf.Application
+	err error
+}
+
+// tailApp streams the logs of app until they are given up on or the tail is
+// stopped, then reports to doneChan.
+func (tail *logTail) tailApp(app cf.Application, location string) {
+	failures := 0
+	refreshedToken := false
+
+	for {
+		token := tail.currentToken()
+		ws, err := tail.repo.dialWebsocket(location, token)
+
+		switch {
+		case tail.isStopped():
+			if err == nil {
+				ws.Close()
+			}
+			tail.doneChan <- nil
+			return
+		case isRefusedByLoggregator(err) && !refreshedToken && tail.repo.authRepo != nil:
+			// The token may have expired while tailing
+			refreshedToken = true
+			apiResponse := tail.refreshToken(token)
+			if apiResponse.IsNotSuccessful() {
+				tail.doneChan <- errors.New(fmt.Sprintf("Could not get the logs of %s: %s", app.Name, apiResponse.Message))
+				return
+			}
+			continue
+		case isRefusedByLoggregator(err):
+			tail.doneChan <- errors.New(fmt.Sprintf("Could not get the logs of %s: %s", app.Name, err.Error()))
+			return
+		case err == nil:
+			failures = 0
+			refreshedToken = false
+			tail.connectChan <- app
+
+			err = tail.receive(ws)
+			if tail.isStopped() {
+				tail.doneChan <- nil
+				return
+			}
+			tail.disconnectChan <- droppedLogs{app: app, err: err}
+		}
+
+		failures++
+		if failures >= logReconnectAttempts {
+			tail.doneChan <- errors.New(fmt.Sprintf("Lost the logs of %s: %s", app.Name, err.Error()))
+			return
+		}
+
+		select {
+		case <-tail.stop:
+			tail.doneChan <- nil
+			return
+		case <-time.After(logReconnectDelay(failures)):
+		}
+	}
+}
+
+// receive passes the messages received on ws on until the connection drops
+// or the tail is stopped. ws is closed when it returns.
+func (tail *logTail) receive(ws *websocket.Conn) (err error) {
+	done := make(chan bool)
+	defer close(done)
+
+	go func() {
+		select {
+		case <-tail.stop:
+			ws.Close()
+		case <-done:
+		}
+	}()
+	go sendKeepAlive(ws, done)
+
+	err = receiveMessages(ws, func(msg logmessage.LogMessage) {
+		tail.msgChan <- msg
+	})
+	ws.Close()
+	return
+}
+
+func (tail *logTail) isStopped() bool {
+	select {
+	case <-tail.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (tail *logTail) currentToken() string {
+	tail.tokenMutex.Lock()
+	defer tail.tokenMutex.Unlock()
+	return tail.token
+}
+
+// refreshToken refreshes the token that loggregator refused, unless another
+// stream already did.
+func (tail *logTail) refreshToken(refusedToken string) (apiResponse net.ApiResponse) {
+	tail.tokenMutex.Lock()
+	defer tail.tokenMutex.Unlock()
+
+	if tail.token != refusedToken {
 		return
 	}
-	return repo.connectToWebsocket(location, app, onConnect, onMessage, nil)
+
+	token, apiResponse := tail.repo.authRepo.RefreshAuthToken()
+	if apiResponse.IsSuccessful() {
+		tail.token = token
+	}
+	return
 }
 
-func (repo LoggregatorLogsRepository) TailLogsFor(app cf.Application, onConnect func(), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) error {
-	host := repo.loggregatorHostResolver(repo.config.Target) + ":" + port
-	location := host + fmt.Sprintf("/tail/?app=%s", app.Guid)
-	return repo.connectToWebsocket(location, app, onConnect, onMessage, time.Tick(printInterval*time.Second))
+func logReconnectDelay(failures int) time.Duration {
+	delay := time.Second << uint(failures-1)
+	if delay > maxLogReconnectDelay || delay <= 0 {
+		delay = maxLogReconnectDelay
+	}
+	return delay
 }
 
-func (repo LoggregatorLogsRepository) connectToWebsocket(location string, app cf.Application, onConnect func(), onMessage func(logmessage.LogMessage), tickerChan <-chan time.Time) (err error) {
-	const EOF_ERROR = "EOF"
+// isRefusedByLoggregator tells whether loggregator answered but would not
+// stream the logs, for example because the token is no longer valid.
+func isRefusedByLoggregator(err error) bool {
+	dialErr, ok := err.(*websocket.DialError)
+	return ok && dialErr.Err == websocket.ErrBadStatus
+}
 
+func (repo LoggregatorLogsRepository) dialWebsocket(location, accessToken string) (ws *websocket.Conn, err error) {
 	config, err := websocket.NewConfig(location, "http://localhost")
 	if err != nil {
 		return
 	}
 
-	config.Header.Add("Authorization", repo.config.AccessToken)
-	config.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+	config.Header.Add("Authorization", accessToken)
+	config.TlsConfig, err = repo.gateway.TLSConfig()
+	if err != nil {
+		return
+	}
+
+	ws, err = websocket.DialConfig(config)
+	return
+}
+
+func (repo LoggregatorLogsRepository) connectToWebsocket(location string, onConnect func(), onMessage func(logmessage.LogMessage)) (err error) {
+	const EOF_ERROR = "EOF"
 
-	ws, err := websocket.DialConfig(config)
+	ws, err := repo.dialWebsocket(location, repo.config.AccessToken)
 	if err != nil {
 		return
 	}
 
 	onConnect()
 
-	msgChan := make(chan logmessage.LogMessage, 1000)
-	errChan := make(chan error, 0)
-
-	go repo.listenForMessages(ws, msgChan, errChan)
-	go repo.sendKeepAlive(ws)
+	done := make(chan bool)
+	defer close(done)
+	go sendKeepAlive(ws, done)
 
 	sortableMsg := &sortableLogMessages{}
 
-Loop:
-	for {
-		select {
-		case err = <-errChan:
-			break Loop
-		case msg, ok := <-msgChan:
-			if !ok {
-				break Loop
-			}
-			sortableMsg.Messages = append(sortableMsg.Messages, msg)
-		case <-tickerChan:
-			invokeCallbackWithSortedMessages(sortableMsg, onMessage)
-			sortableMsg.Messages = []logmessage.LogMessage{}
-		}
-		if err != nil {
-			break
-		}
-	}
+	err = receiveMessages(ws, func(msg logmessage.LogMessage) {
+		sortableMsg.Messages = append(sortableMsg.Messages, msg)
+	})
+	ws.Close()
 
-	if tickerChan == nil {
-		invokeCallbackWithSortedMessages(sortableMsg, onMessage)
-	}
+	invokeCallbackWithSortedMessages(sortableMsg, onMessage)
 
 	if err.Error() == EOF_ERROR {
 		err = nil
@@ -112,22 +340,30 @@ func invokeCallbackWithSortedMessages(messages *sortableLogMessages, callback fu
 	}
 }
 
-func (repo LoggregatorLogsRepository) sendKeepAlive(ws *websocket.Conn) {
+// sendKeepAlive pings loggregator until done is closed or ws stops working.
+func sendKeepAlive(ws *websocket.Conn, done <-chan bool) {
 	for {
-		websocket.Message.Send(ws, "I'm alive!")
-		time.Sleep(25 * time.Second)
+		err := websocket.Message.Send(ws, "I'm alive!")
+		if err != nil {
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		case <-time.After(logKeepAliveInterval):
+		}
 	}
 }
 
-func (repo LoggregatorLogsRepository) listenForMessages(ws *websocket.Conn, msgChan chan<- logmessage.LogMessage, errChan chan<- error) {
-	var err error
-	defer close(msgChan)
+// receiveMessages passes the messages received on ws to onMessage until the
+// connection ends.
+func receiveMessages(ws *websocket.Conn, onMessage func(logmessage.LogMessage)) (err error) {
 	for {
 		var data []byte
 		err = websocket.Message.Receive(ws, &data)
 		if err != nil {
-			errChan <- err
-			break
+			return
 		}
 
 		logMessage := logmessage.LogMessage{}
@@ -136,7 +372,7 @@ func (repo LoggregatorLogsRepository) listenForMessages(ws *websocket.Conn, msgC
 		if msgErr != nil {
 			continue
 		}
-		msgChan <- logMessage
+		onMessage(logMessage)
 	}
 }
 
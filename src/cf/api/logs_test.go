@@ -10,8 +10,12 @@ import (
 	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"github.com/stretchr/testify/assert"
 	"net"
+	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
+	"sync"
+	"testhelpers"
 	"testing"
 	"time"
 )
@@ -45,13 +49,14 @@ func TestRecentLogsFor(t *testing.T) {
 	assert.NoError(t, err)
 
 	gateway := cfnet.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(websocketServer.TLS.Certificates)
 	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
-	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: "https://localhost"}
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: "https://127.0.0.1"}
 	loggregatorHostResolver := func(hostname string) string {
 		return strings.Replace(hostname, "https", "wss", 1)
 	}
 
-	logsRepo := NewLoggregatorLogsRepository(config, gateway, loggregatorHostResolver)
+	logsRepo := NewLoggregatorLogsRepository(config, gateway, nil, loggregatorHostResolver)
 
 	connected := false
 	onConnect := func() {
@@ -67,6 +72,7 @@ func TestRecentLogsFor(t *testing.T) {
 	// method under test
 	err = logsRepo.RecentLogsFor(app, onConnect, onMessage, wsServerPort)
 	assert.NoError(t, err)
+	assert.True(t, connected)
 
 	assert.Equal(t, len(dumpedMessages), 1)
 	assert.Equal(t, dumpedMessages[0].GetMessage(), expectedMessage.GetLogMessage().GetMessage())
@@ -94,19 +100,21 @@ func TestTailsLogsFor(t *testing.T) {
 		time.Sleep(time.Duration(2) * time.Second)
 		conn.Close()
 	}
-	websocketServer := httptest.NewTLSServer(websocket.Handler(websocketEndpoint))
+	// The logs are tailed until loggregator refuses to reconnect
+	websocketServer := httptest.NewTLSServer(refuseAfterConnections(1, websocket.Handler(websocketEndpoint)))
 	str := strings.Replace(websocketServer.URL, "https://", "", 1)
 	_, wsServerPort, _ := net.SplitHostPort(str)
 	defer websocketServer.Close()
 
 	gateway := cfnet.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(websocketServer.TLS.Certificates)
 	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
-	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: "https://localhost"}
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: "https://127.0.0.1"}
 	loggregatorHostResolver := func(hostname string) string {
 		return strings.Replace(hostname, "https", "wss", 1)
 	}
 
-	logsRepo := NewLoggregatorLogsRepository(config, gateway, loggregatorHostResolver)
+	logsRepo := NewLoggregatorLogsRepository(config, gateway, nil, loggregatorHostResolver)
 
 	connected := false
 	onConnect := func() {
@@ -120,7 +128,7 @@ func TestTailsLogsFor(t *testing.T) {
 	}
 
 	// method under test
-	logsRepo.TailLogsFor(app, onConnect, onMessage, time.Duration(1), wsServerPort)
+	logsRepo.TailLogsFor(app, nil, onConnect, onMessage, time.Duration(1), wsServerPort)
 
 	assert.True(t, connected)
 
@@ -139,6 +147,145 @@ func TestTailsLogsFor(t *testing.T) {
 	assert.Equal(t, actualMessage, messagesSent[0])
 }
 
+func TestTailLogsForAppsSortsAcrossAppsAndReconnects(t *testing.T) {
+	messagesByConnection := map[string][][]byte{
+		"app1-guid": [][]byte{
+			marshalledAppLogMessage(t, "app1 first", "app1-guid", 1000),
+			marshalledAppLogMessage(t, "app1 after reconnecting", "app1-guid", 4000),
+		},
+		"app2-guid": [][]byte{
+			marshalledAppLogMessage(t, "app2 first", "app2-guid", 2000),
+			marshalledAppLogMessage(t, "app2 after reconnecting", "app2-guid", 3000),
+		},
+	}
+
+	mutex := new(sync.Mutex)
+	connections := map[string]int{}
+
+	websocketEndpoint := websocket.Handler(func(conn *websocket.Conn) {
+		appGuid := conn.Request().URL.Query().Get("app")
+
+		mutex.Lock()
+		msg := messagesByConnection[appGuid][connections[appGuid]-1]
+		mutex.Unlock()
+
+		conn.Write(msg)
+		time.Sleep(100 * time.Millisecond)
+		conn.Close()
+	})
+
+	websocketServer := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		appGuid := request.URL.Query().Get("app")
+
+		mutex.Lock()
+		connections[appGuid]++
+		refused := connections[appGuid] > len(messagesByConnection[appGuid])
+		mutex.Unlock()
+
+		if refused {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		websocketEndpoint.ServeHTTP(writer, request)
+	}))
+	str := strings.Replace(websocketServer.URL, "https://", "", 1)
+	_, wsServerPort, _ := net.SplitHostPort(str)
+	defer websocketServer.Close()
+
+	gateway := cfnet.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(websocketServer.TLS.Certificates)
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: "https://127.0.0.1"}
+	loggregatorHostResolver := func(hostname string) string {
+		return strings.Replace(hostname, "https", "wss", 1)
+	}
+
+	logsRepo := NewLoggregatorLogsRepository(config, gateway, nil, loggregatorHostResolver)
+
+	apps := []cf.Application{
+		cf.Application{Name: "app1", Guid: "app1-guid"},
+		cf.Application{Name: "app2", Guid: "app2-guid"},
+	}
+
+	connected := []string{}
+	onConnect := func(app cf.Application) {
+		connected = append(connected, app.Name)
+	}
+
+	disconnected := []string{}
+	onDisconnect := func(app cf.Application, err error) {
+		disconnected = append(disconnected, app.Name)
+	}
+
+	tailedMessages := []string{}
+	onMessage := func(message logmessage.LogMessage) {
+		tailedMessages = append(tailedMessages, string(message.GetMessage()))
+	}
+
+	err := logsRepo.TailLogsForApps(apps, nil, onConnect, onDisconnect, onMessage, time.Duration(10), wsServerPort)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not get the logs of")
+
+	sort.Strings(connected)
+	assert.Equal(t, connected, []string{"app1", "app1", "app2", "app2"})
+	sort.Strings(disconnected)
+	assert.Equal(t, disconnected, []string{"app1", "app1", "app2", "app2"})
+	assert.Equal(t, tailedMessages, []string{"app1 first", "app2 first", "app2 after reconnecting", "app1 after reconnecting"})
+}
+
+func TestTailLogsForAppsRefreshesARefusedToken(t *testing.T) {
+	websocketEndpoint := websocket.Handler(func(conn *websocket.Conn) {
+		conn.Write(marshalledAppLogMessage(t, "after refreshing", "my-app-guid", 1000))
+		time.Sleep(100 * time.Millisecond)
+		conn.Close()
+	})
+
+	mutex := new(sync.Mutex)
+	tokens := []string{}
+
+	websocketServer := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		token := request.Header.Get("Authorization")
+
+		mutex.Lock()
+		tokens = append(tokens, token)
+		firstWithNewToken := len(tokens) == 2
+		mutex.Unlock()
+
+		if !firstWithNewToken {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		websocketEndpoint.ServeHTTP(writer, request)
+	}))
+	str := strings.Replace(websocketServer.URL, "https://", "", 1)
+	_, wsServerPort, _ := net.SplitHostPort(str)
+	defer websocketServer.Close()
+
+	gateway := cfnet.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(websocketServer.TLS.Certificates)
+	config := &configuration.Configuration{AccessToken: "BEARER expired_token", Target: "https://127.0.0.1"}
+	loggregatorHostResolver := func(hostname string) string {
+		return strings.Replace(hostname, "https", "wss", 1)
+	}
+	authRepo := &testhelpers.FakeAuthenticationRepository{RefreshedAccessToken: "BEARER new_token"}
+
+	logsRepo := NewLoggregatorLogsRepository(config, gateway, authRepo, loggregatorHostResolver)
+
+	tailedMessages := []string{}
+	onMessage := func(message logmessage.LogMessage) {
+		tailedMessages = append(tailedMessages, string(message.GetMessage()))
+	}
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	err := logsRepo.TailLogsFor(app, nil, func() {}, onMessage, time.Duration(10), wsServerPort)
+
+	// Refused again after reconnecting with the refreshed token
+	assert.Error(t, err)
+	assert.True(t, authRepo.RefreshTokenCalled)
+	assert.Equal(t, tokens[0], "BEARER expired_token")
+	assert.Equal(t, tokens[1], "BEARER new_token")
+	assert.Equal(t, tailedMessages, []string{"after refreshing"})
+}
+
 func TestLoggregatorHost(t *testing.T) {
 	apiHost := "https://api.run.pivotal.io"
 	loggregatorHost := LoggregatorHost(apiHost)
@@ -162,3 +309,127 @@ func marshalledLogMessageWithTime(t *testing.T, messageString string, timestamp
 
 	return message
 }
+
+func marshalledAppLogMessage(t *testing.T, messageString, appGuid string, timestamp int64) []byte {
+	messageType := logmessage.LogMessage_OUT
+	sourceType := logmessage.LogMessage_WARDEN_CONTAINER
+	protoMessage := &logmessage.LogMessage{
+		Message:     []byte(messageString),
+		AppId:       proto.String(appGuid),
+		MessageType: &messageType,
+		SourceType:  &sourceType,
+		Timestamp:   proto.Int64(timestamp),
+	}
+
+	message, err := proto.Marshal(protoMessage)
+	assert.NoError(t, err)
+
+	return message
+}
+
+// refuseAfterConnections serves the first count connections with handler and
+// refuses the ones after that.
+func refuseAfterConnections(count int, handler http.Handler) http.Handler {
+	mutex := new(sync.Mutex)
+	connections := 0
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		connections++
+		refused := connections > count
+		mutex.Unlock()
+
+		if refused {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+func TestTailLogsForUntilStopped(t *testing.T) {
+	websocketEndpoint := func(conn *websocket.Conn) {
+		conn.Write(marshalledAppLogMessage(t, "-----> Downloaded app package", "my-app-guid", 1000))
+
+		// Block until the client hangs up
+		var data []byte
+		for websocket.Message.Receive(conn, &data) == nil {
+		}
+	}
+	websocketServer := httptest.NewTLSServer(websocket.Handler(websocketEndpoint))
+	str := strings.Replace(websocketServer.URL, "https://", "", 1)
+	_, wsServerPort, _ := net.SplitHostPort(str)
+	defer websocketServer.Close()
+
+	gateway := cfnet.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(websocketServer.TLS.Certificates)
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: "https://127.0.0.1"}
+	loggregatorHostResolver := func(hostname string) string {
+		return strings.Replace(hostname, "https", "wss", 1)
+	}
+
+	logsRepo := NewLoggregatorLogsRepository(config, gateway, nil, loggregatorHostResolver)
+
+	stop := make(chan bool)
+	tailedMessages := []string{}
+	onMessage := func(message logmessage.LogMessage) {
+		tailedMessages = append(tailedMessages, string(message.GetMessage()))
+	}
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		close(stop)
+	}()
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	err := logsRepo.TailLogsFor(app, stop, func() {}, onMessage, time.Duration(10), wsServerPort)
+
+	assert.NoError(t, err)
+	assert.Equal(t, tailedMessages, []string{"-----> Downloaded app package"})
+}
+
+func TestTailLogsForAppsClosesTheWebsocketsWhenStopped(t *testing.T) {
+	closed := make(chan bool, 1)
+	websocketEndpoint := func(conn *websocket.Conn) {
+		conn.Write(marshalledAppLogMessage(t, "before stopping", "my-app-guid", 1000))
+
+		// Block until the client hangs up
+		var data []byte
+		for websocket.Message.Receive(conn, &data) == nil {
+		}
+		closed <- true
+	}
+	websocketServer := httptest.NewTLSServer(websocket.Handler(websocketEndpoint))
+	str := strings.Replace(websocketServer.URL, "https://", "", 1)
+	_, wsServerPort, _ := net.SplitHostPort(str)
+	defer websocketServer.Close()
+
+	gateway := cfnet.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(websocketServer.TLS.Certificates)
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: "https://127.0.0.1"}
+	loggregatorHostResolver := func(hostname string) string {
+		return strings.Replace(hostname, "https", "wss", 1)
+	}
+
+	logsRepo := NewLoggregatorLogsRepository(config, gateway, nil, loggregatorHostResolver)
+
+	stop := make(chan bool)
+	onConnect := func(app cf.Application) {
+		close(stop)
+	}
+
+	tailedMessages := []string{}
+	onMessage := func(message logmessage.LogMessage) {
+		tailedMessages = append(tailedMessages, string(message.GetMessage()))
+	}
+
+	apps := []cf.Application{cf.Application{Name: "my-app", Guid: "my-app-guid"}}
+	err := logsRepo.TailLogsForApps(apps, stop, onConnect, func(cf.Application, error) {}, onMessage, time.Duration(10), wsServerPort)
+	assert.NoError(t, err)
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("The websocket was not closed")
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 type OrganizationRepository interface {
 	FindAll() (orgs []cf.Organization, apiResponse net.ApiResponse)
+	ListOrgs(cb func([]cf.Organization) bool) (apiResponse net.ApiResponse)
 	FindByName(name string) (org cf.Organization, apiResponse net.ApiResponse)
 	Create(name string) (apiResponse net.ApiResponse)
 	Rename(org cf.Organization, name string) (apiResponse net.ApiResponse)
@@ -30,30 +31,27 @@ func NewCloudControllerOrganizationRepository(config *configuration.Configuratio
 }
 
 func (repo CloudControllerOrganizationRepository) FindAll() (orgs []cf.Organization, apiResponse net.ApiResponse) {
-	path := repo.config.Target + "/v2/organizations"
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-	response := new(OrganizationsApiResponse)
-
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, response)
-
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	for _, r := range response.Resources {
-		orgs = append(orgs, cf.Organization{
-			Name: r.Entity.Name,
-			Guid: r.Metadata.Guid,
-		},
-		)
-	}
-
+	apiResponse = repo.ListOrgs(func(page []cf.Organization) bool {
+		orgs = append(orgs, page...)
+		return true
+	})
 	return
 }
 
+// ListOrgs calls cb with the orgs of each page as soon as it arrives.
+// Returning false from cb stops listing.
+func (repo CloudControllerOrganizationRepository) ListOrgs(cb func([]cf.Organization) bool) (apiResponse net.ApiResponse) {
+	resources := []OrganizationResource{}
+	return listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, "/v2/organizations", &resources,
+		func() bool {
+			orgs := []cf.Organization{}
+			for _, r := range resources {
+				orgs = append(orgs, cf.Organization{Name: r.Entity.Name, Guid: r.Metadata.Guid})
+			}
+			return cb(orgs)
+		})
+}
+
 func (repo CloudControllerOrganizationRepository) FindByName(name string) (org cf.Organization, apiResponse net.ApiResponse) {
 	path := fmt.Sprintf("%s/v2/organizations?q=name%s&inline-relations-depth=1", repo.config.Target, "%3A"+strings.ToLower(name))
 	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
@@ -91,6 +89,7 @@ func (repo CloudControllerOrganizationRepository) FindByName(name string) (org c
 		Guid:    r.Metadata.Guid,
 		Spaces:  spaces,
 		Domains: domains,
+		Quota:   quotaFromResource(r.Entity.QuotaDefinition),
 	}
 
 	return
@@ -134,30 +133,7 @@ func (repo CloudControllerOrganizationRepository) Delete(org cf.Organization) (a
 }
 
 func (repo CloudControllerOrganizationRepository) FindQuotaByName(name string) (quota cf.Quota, apiResponse net.ApiResponse) {
-	path := fmt.Sprintf("%s/v2/quota_definitions?q=name%%3A%s", repo.config.Target, name)
-
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	response := new(ApiResponse)
-
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, response)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	if len(response.Resources) == 0 {
-		apiResponse = net.NewNotFoundApiStatus("Org", name)
-		return
-	}
-
-	res := response.Resources[0]
-	quota.Guid = res.Metadata.Guid
-	quota.Name = res.Entity.Name
-
-	return
+	return NewCloudControllerQuotaRepository(repo.config, repo.gateway).FindByName(name)
 }
 
 func (repo CloudControllerOrganizationRepository) UpdateQuota(org cf.Organization, quota cf.Quota) (apiResponse net.ApiResponse) {
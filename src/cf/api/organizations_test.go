@@ -51,6 +51,7 @@ func TestOrganizationsFindAll(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	organizations, apiResponse := repo.FindAll()
@@ -72,6 +73,7 @@ func TestOrganizationsFindAllWithIncorrectToken(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER incorrect_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	var (
@@ -89,6 +91,103 @@ func TestOrganizationsFindAllWithIncorrectToken(t *testing.T) {
 	assert.Equal(t, 0, len(organizations))
 }
 
+var firstPageOrgsEndpoint = testhelpers.CreateEndpoint(
+	"GET",
+	"/v2/organizations",
+	nil,
+	testhelpers.TestResponse{Status: http.StatusOK, Body: `
+{
+  "total_results": 3,
+  "total_pages": 2,
+  "prev_url": null,
+  "next_url": "/v2/organizations?page=2",
+  "resources": [
+    {
+      "metadata": { "guid": "org1-guid" },
+      "entity": { "name": "Org1" }
+    },
+    {
+      "metadata": { "guid": "org2-guid" },
+      "entity": { "name": "Org2" }
+    }
+  ]
+}`},
+)
+
+var secondPageOrgsEndpoint = testhelpers.CreateEndpoint(
+	"GET",
+	"/v2/organizations?page=2",
+	nil,
+	testhelpers.TestResponse{Status: http.StatusOK, Body: `
+{
+  "total_results": 3,
+  "total_pages": 2,
+  "prev_url": "/v2/organizations?page=1",
+  "next_url": null,
+  "resources": [
+    {
+      "metadata": { "guid": "org3-guid" },
+      "entity": { "name": "Org3" }
+    }
+  ]
+}`},
+)
+
+func paginatedOrgsEndpoint(secondPageRequested *bool) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Query().Get("page") == "2" {
+			*secondPageRequested = true
+			secondPageOrgsEndpoint(writer, request)
+			return
+		}
+		firstPageOrgsEndpoint(writer, request)
+	}
+}
+
+func TestOrganizationsFindAllFollowsNextUrl(t *testing.T) {
+	secondPageRequested := false
+	ts := httptest.NewTLSServer(paginatedOrgsEndpoint(&secondPageRequested))
+	defer ts.Close()
+
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerOrganizationRepository(config, gateway)
+
+	organizations, apiResponse := repo.FindAll()
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.True(t, secondPageRequested)
+	assert.Equal(t, len(organizations), 3)
+	assert.Equal(t, organizations[0].Guid, "org1-guid")
+	assert.Equal(t, organizations[1].Guid, "org2-guid")
+	assert.Equal(t, organizations[2].Guid, "org3-guid")
+	assert.Equal(t, organizations[2].Name, "Org3")
+}
+
+func TestOrganizationsListOrgsStopsWhenCallbackReturnsFalse(t *testing.T) {
+	secondPageRequested := false
+	ts := httptest.NewTLSServer(paginatedOrgsEndpoint(&secondPageRequested))
+	defer ts.Close()
+
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerOrganizationRepository(config, gateway)
+
+	pages := [][]cf.Organization{}
+	apiResponse := repo.ListOrgs(func(orgs []cf.Organization) bool {
+		pages = append(pages, orgs)
+		return false
+	})
+
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.False(t, secondPageRequested)
+	assert.Equal(t, len(pages), 1)
+	assert.Equal(t, len(pages[0]), 2)
+	assert.Equal(t, pages[0][0].Name, "Org1")
+	assert.Equal(t, pages[0][1].Name, "Org2")
+}
+
 var findOrgByNameResponse = testhelpers.TestResponse{Status: http.StatusOK, Body: `
 {
   "total_results": 1,
@@ -121,7 +220,18 @@ var findOrgByNameResponse = testhelpers.TestResponse{Status: http.StatusOK, Body
               "name": "cfapps.io"
             }
           }
-        ]
+        ],
+        "quota_definition": {
+          "metadata": {
+            "guid": "quota1-guid"
+          },
+          "entity": {
+            "name": "free",
+            "memory_limit": 1024,
+            "total_services": 5,
+            "total_routes": 10
+          }
+        }
       }
     }
   ]
@@ -140,6 +250,7 @@ func TestOrganizationsFindByName(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	existingOrg := cf.Organization{Guid: "org1-guid", Name: "Org1"}
@@ -154,6 +265,7 @@ func TestOrganizationsFindByName(t *testing.T) {
 	assert.Equal(t, len(org.Domains), 1)
 	assert.Equal(t, org.Domains[0].Name, "cfapps.io")
 	assert.Equal(t, org.Domains[0].Guid, "domain1-guid")
+	assert.Equal(t, org.Quota, cf.Quota{Name: "free", Guid: "quota1-guid", MemoryLimit: 1024, ServicesLimit: 5, RoutesLimit: 10})
 
 	org, apiResponse = repo.FindByName("org1")
 	assert.False(t, apiResponse.IsNotSuccessful())
@@ -183,6 +295,7 @@ func TestOrganizationsFindByNameWhenDoesNotExist(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	_, apiResponse := repo.FindByName("org1")
@@ -203,6 +316,7 @@ func TestCreateOrganization(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	apiResponse := repo.Create("my-org")
@@ -222,6 +336,7 @@ func TestRenameOrganization(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	org := cf.Organization{Guid: "my-org-guid"}
@@ -242,6 +357,7 @@ func TestDeleteOrganization(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	org := cf.Organization{Guid: "my-org-guid"}
@@ -273,6 +389,7 @@ func TestFindQuotaByName(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	quota, apiResponse := repo.FindQuotaByName("my-quota")
@@ -293,6 +410,7 @@ func TestUpdateQuota(t *testing.T) {
 
 	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerOrganizationRepository(config, gateway)
 
 	quota := cf.Quota{Guid: "my-quota-guid"}
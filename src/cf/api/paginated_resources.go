@@ -0,0 +1,54 @@
+package api
+
+import (
+	"cf/net"
+	"encoding/json"
+	"reflect"
+)
+
+type PaginatedResources struct {
+	NextUrl   string          `json:"next_url"`
+	Resources json.RawMessage `json:"resources"`
+}
+
+// listAllResources requests path and every page after it by following
+// next_url. The resources of each page are decoded into a new slice that
+// replaces the one resources, a pointer to a slice of the expected resource
+// type, points to, and cb is called as soon as the page arrives. Nothing is
+// carried over from the previous page, not even fields a page leaves out. Returning false from cb stops before any further pages are
+// requested.
+func listAllResources(gateway net.Gateway, target, accessToken, path string, resources interface{}, cb func() bool) (apiResponse net.ApiResponse) {
+	for path != "" {
+		var request *net.Request
+		request, apiResponse = gateway.NewRequest("GET", target+path, accessToken, nil)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+
+		page := new(PaginatedResources)
+		_, apiResponse = gateway.PerformRequestForJSONResponse(request, page)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+
+		resourcesJson := []byte(page.Resources)
+		if len(resourcesJson) == 0 {
+			resourcesJson = []byte("[]")
+		}
+
+		pageResources := reflect.New(reflect.TypeOf(resources).Elem())
+		err := json.Unmarshal(resourcesJson, pageResources.Interface())
+		if err != nil {
+			apiResponse = net.NewApiStatusWithError("Invalid JSON response from server", err)
+			return
+		}
+		reflect.ValueOf(resources).Elem().Set(pageResources.Elem())
+
+		if !cb() {
+			return
+		}
+
+		path = page.NextUrl
+	}
+	return
+}
@@ -45,6 +45,7 @@ func testScore(t *testing.T, scoreBody string, expectedScore string) {
 		Target:      targetServer.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(scoreServer.TLS.Certificates)
 	repo := NewCloudControllerPasswordRepository(config, gateway)
 
 	score, apiResponse := repo.GetScore("new-password")
@@ -85,6 +86,7 @@ func TestUpdatePassword(t *testing.T) {
 		Target:      targetServer.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(passwordUpdateServer.TLS.Certificates)
 	repo := NewCloudControllerPasswordRepository(config, gateway)
 
 	apiResponse := repo.UpdatePassword("old-password", "new-password")
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bytes"
+	"cf"
+	"cf/configuration"
+	"cf/net"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+type QuotaRepository interface {
+	FindAll() (quotas []cf.Quota, apiResponse net.ApiResponse)
+	ListQuotas(cb func([]cf.Quota) bool) (apiResponse net.ApiResponse)
+	FindByName(name string) (quota cf.Quota, apiResponse net.ApiResponse)
+	Create(quota cf.Quota) (apiResponse net.ApiResponse)
+	Update(quota cf.Quota) (apiResponse net.ApiResponse)
+	Delete(quota cf.Quota) (apiResponse net.ApiResponse)
+	FindUsage(org cf.Organization) (usage cf.QuotaUsage, apiResponse net.ApiResponse)
+}
+
+type CloudControllerQuotaRepository struct {
+	config  *configuration.Configuration
+	gateway net.Gateway
+}
+
+func NewCloudControllerQuotaRepository(config *configuration.Configuration, gateway net.Gateway) (repo CloudControllerQuotaRepository) {
+	repo.config = config
+	repo.gateway = gateway
+	return
+}
+
+func (repo CloudControllerQuotaRepository) FindAll() (quotas []cf.Quota, apiResponse net.ApiResponse) {
+	apiResponse = repo.ListQuotas(func(page []cf.Quota) bool {
+		quotas = append(quotas, page...)
+		return true
+	})
+	return
+}
+
+// ListQuotas calls cb with the quotas of each page as soon as it arrives.
+// Returning false from cb stops listing.
+func (repo CloudControllerQuotaRepository) ListQuotas(cb func([]cf.Quota) bool) (apiResponse net.ApiResponse) {
+	resources := []QuotaResource{}
+	return listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, "/v2/quota_definitions", &resources,
+		func() bool {
+			quotas := []cf.Quota{}
+			for _, r := range resources {
+				quotas = append(quotas, quotaFromResource(r))
+			}
+			return cb(quotas)
+		})
+}
+
+func (repo CloudControllerQuotaRepository) FindByName(name string) (quota cf.Quota, apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("/v2/quota_definitions?q=%s", url.QueryEscape("name:"+name))
+
+	found := false
+	resources := []QuotaResource{}
+	apiResponse = listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, path, &resources,
+		func() bool {
+			if len(resources) == 0 {
+				return true
+			}
+			quota = quotaFromResource(resources[0])
+			found = true
+			return false
+		})
+
+	if apiResponse.IsSuccessful() && !found {
+		apiResponse = net.NewNotFoundApiStatus("Quota", name)
+	}
+	return
+}
+
+func (repo CloudControllerQuotaRepository) Create(quota cf.Quota) (apiResponse net.ApiResponse) {
+	path := repo.config.Target + "/v2/quota_definitions"
+	body, apiResponse := quotaRequestBody(quota)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	request, apiResponse := repo.gateway.NewRequest("POST", path, repo.config.AccessToken, bytes.NewReader(body))
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.gateway.PerformRequest(request)
+	return
+}
+
+func (repo CloudControllerQuotaRepository) Update(quota cf.Quota) (apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/quota_definitions/%s", repo.config.Target, quota.Guid)
+	body, apiResponse := quotaRequestBody(quota)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	request, apiResponse := repo.gateway.NewRequest("PUT", path, repo.config.AccessToken, bytes.NewReader(body))
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.gateway.PerformRequest(request)
+	return
+}
+
+func (repo CloudControllerQuotaRepository) Delete(quota cf.Quota) (apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/quota_definitions/%s", repo.config.Target, quota.Guid)
+	request, apiResponse := repo.gateway.NewRequest("DELETE", path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.gateway.PerformRequest(request)
+	return
+}
+
+func (repo CloudControllerQuotaRepository) FindUsage(org cf.Organization) (usage cf.QuotaUsage, apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/organizations/%s/memory_usage", repo.config.Target, org.Guid)
+	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	memoryResponse := new(MemoryUsageResponse)
+	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, memoryResponse)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+	usage.Memory = memoryResponse.MemoryUsageInMb
+
+	orgFilter := url.QueryEscape("organization_guid:" + org.Guid)
+
+	usage.Services, apiResponse = repo.countResources("/v2/service_instances?q=" + orgFilter)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	usage.Routes, apiResponse = repo.countResources("/v2/routes?q=" + orgFilter)
+	return
+}
+
+// countResources asks for a single result so only the total_results count
+// comes back over the wire.
+func (repo CloudControllerQuotaRepository) countResources(path string) (count int, apiResponse net.ApiResponse) {
+	request, apiResponse := repo.gateway.NewRequest("GET", repo.config.Target+path+"&results-per-page=1", repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	countResponse := new(TotalResultsResponse)
+	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, countResponse)
+	count = countResponse.TotalResults
+	return
+}
+
+func quotaFromResource(resource QuotaResource) cf.Quota {
+	return cf.Quota{
+		Name:                    resource.Entity.Name,
+		Guid:                    resource.Metadata.Guid,
+		MemoryLimit:             resource.Entity.MemoryLimit,
+		ServicesLimit:           resource.Entity.TotalServices,
+		RoutesLimit:             resource.Entity.TotalRoutes,
+		NonBasicServicesAllowed: resource.Entity.NonBasicServicesAllowed,
+	}
+}
+
+type quotaFields struct {
+	Name                    string `json:"name"`
+	MemoryLimit             uint64 `json:"memory_limit"`
+	ServicesLimit           int    `json:"total_services"`
+	RoutesLimit             int    `json:"total_routes"`
+	NonBasicServicesAllowed bool   `json:"non_basic_services_allowed"`
+}
+
+func quotaRequestBody(quota cf.Quota) (body []byte, apiResponse net.ApiResponse) {
+	body, err := json.Marshal(quotaFields{
+		Name:                    quota.Name,
+		MemoryLimit:             quota.MemoryLimit,
+		ServicesLimit:           quota.ServicesLimit,
+		RoutesLimit:             quota.RoutesLimit,
+		NonBasicServicesAllowed: quota.NonBasicServicesAllowed,
+	})
+	if err != nil {
+		apiResponse = net.NewApiStatusWithError("Error creating json for quota", err)
+	}
+	return
+}
@@ -0,0 +1,157 @@
+package api_test
+
+import (
+	"cf"
+	. "cf/api"
+	"cf/configuration"
+	"cf/net"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testhelpers"
+	"testing"
+)
+
+var quotaResourcesResponse = `{
+  "resources": [
+    {
+      "metadata": {
+        "guid": "my-quota-guid"
+      },
+      "entity": {
+        "name": "my-quota",
+        "memory_limit": 1024,
+        "total_services": 5,
+        "total_routes": 10,
+        "non_basic_services_allowed": true
+      }
+    }
+  ]
+}`
+
+var myQuota = cf.Quota{
+	Name:                    "my-quota",
+	Guid:                    "my-quota-guid",
+	MemoryLimit:             1024,
+	ServicesLimit:           5,
+	RoutesLimit:             10,
+	NonBasicServicesAllowed: true,
+}
+
+func TestQuotasFindAll(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint("GET", "/v2/quota_definitions", nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: quotaResourcesResponse})
+
+	ts, repo := createQuotaRepo(endpoint)
+	defer ts.Close()
+
+	quotas, apiResponse := repo.FindAll()
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, quotas, []cf.Quota{myQuota})
+}
+
+func TestQuotasFindByName(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint("GET", "/v2/quota_definitions?q=name%3Amy-quota", nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: quotaResourcesResponse})
+
+	ts, repo := createQuotaRepo(endpoint)
+	defer ts.Close()
+
+	quota, apiResponse := repo.FindByName("my-quota")
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, quota, myQuota)
+}
+
+func TestQuotasFindByNameWhenNotFound(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint("GET", "/v2/quota_definitions?q=name%3Amy-quota", nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `{"resources": []}`})
+
+	ts, repo := createQuotaRepo(endpoint)
+	defer ts.Close()
+
+	_, apiResponse := repo.FindByName("my-quota")
+	assert.False(t, apiResponse.IsError())
+	assert.True(t, apiResponse.IsNotFound())
+}
+
+func TestQuotasCreate(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint("POST", "/v2/quota_definitions",
+		testhelpers.RequestBodyMatcher(`{"name":"my-quota","memory_limit":1024,"total_services":5,"total_routes":10,"non_basic_services_allowed":true}`),
+		testhelpers.TestResponse{Status: http.StatusCreated})
+
+	ts, repo := createQuotaRepo(endpoint)
+	defer ts.Close()
+
+	quota := myQuota
+	quota.Guid = ""
+	apiResponse := repo.Create(quota)
+	assert.True(t, apiResponse.IsSuccessful())
+}
+
+func TestQuotasCreateEscapesTheName(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint("POST", "/v2/quota_definitions",
+		testhelpers.RequestBodyMatcher(`{"name":"my \"quota\"\\","memory_limit":1024,"total_services":5,"total_routes":10,"non_basic_services_allowed":true}`),
+		testhelpers.TestResponse{Status: http.StatusCreated})
+
+	ts, repo := createQuotaRepo(endpoint)
+	defer ts.Close()
+
+	quota := myQuota
+	quota.Guid = ""
+	quota.Name = `my "quota"\`
+	apiResponse := repo.Create(quota)
+	assert.True(t, apiResponse.IsSuccessful())
+}
+
+func TestQuotasUpdate(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint("PUT", "/v2/quota_definitions/my-quota-guid",
+		testhelpers.RequestBodyMatcher(`{"name":"my-quota","memory_limit":1024,"total_services":5,"total_routes":10,"non_basic_services_allowed":true}`),
+		testhelpers.TestResponse{Status: http.StatusCreated})
+
+	ts, repo := createQuotaRepo(endpoint)
+	defer ts.Close()
+
+	apiResponse := repo.Update(myQuota)
+	assert.True(t, apiResponse.IsSuccessful())
+}
+
+func TestQuotasDelete(t *testing.T) {
+	endpoint := testhelpers.CreateEndpoint("DELETE", "/v2/quota_definitions/my-quota-guid", nil,
+		testhelpers.TestResponse{Status: http.StatusNoContent})
+
+	ts, repo := createQuotaRepo(endpoint)
+	defer ts.Close()
+
+	apiResponse := repo.Delete(myQuota)
+	assert.True(t, apiResponse.IsSuccessful())
+}
+
+func TestQuotasFindUsage(t *testing.T) {
+	endpoints := map[string]http.HandlerFunc{
+		"/v2/organizations/my-org-guid/memory_usage": testhelpers.CreateEndpoint("GET", "/v2/organizations/my-org-guid/memory_usage", nil,
+			testhelpers.TestResponse{Status: http.StatusOK, Body: `{"memory_usage_in_mb": 512}`}),
+		"/v2/service_instances": testhelpers.CreateEndpoint("GET", "/v2/service_instances?q=organization_guid%3Amy-org-guid&results-per-page=1", nil,
+			testhelpers.TestResponse{Status: http.StatusOK, Body: `{"total_results": 3, "resources": []}`}),
+		"/v2/routes": testhelpers.CreateEndpoint("GET", "/v2/routes?q=organization_guid%3Amy-org-guid&results-per-page=1", nil,
+			testhelpers.TestResponse{Status: http.StatusOK, Body: `{"total_results": 7, "resources": []}`}),
+	}
+
+	ts, repo := createQuotaRepo(func(writer http.ResponseWriter, request *http.Request) {
+		endpoints[request.URL.Path](writer, request)
+	})
+	defer ts.Close()
+
+	usage, apiResponse := repo.FindUsage(cf.Organization{Guid: "my-org-guid"})
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, usage, cf.QuotaUsage{Memory: 512, Services: 3, Routes: 7})
+}
+
+func createQuotaRepo(endpoint http.HandlerFunc) (ts *httptest.Server, repo QuotaRepository) {
+	ts = httptest.NewTLSServer(endpoint)
+
+	config := &configuration.Configuration{AccessToken: "BEARER my_access_token", Target: ts.URL}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo = NewCloudControllerQuotaRepository(config, gateway)
+	return
+}
@@ -21,6 +21,8 @@ type RepositoryLocator struct {
 	stackRepo        CloudControllerStackRepository
 	serviceRepo      CloudControllerServiceRepository
 	passwordRepo     CloudControllerPasswordRepository
+	userRepo         CloudControllerUserRepository
+	quotaRepo        CloudControllerQuotaRepository
 	logsRepo         LoggregatorLogsRepository
 }
 
@@ -29,6 +31,10 @@ func NewRepositoryLocator(config *configuration.Configuration, configRepo config
 	cloudControllerGateway := gatewaysByName["cloud-controller"]
 	uaaGateway := gatewaysByName["uaa"]
 
+	authGateway.SetConfiguration(config)
+	cloudControllerGateway.SetConfiguration(config)
+	uaaGateway.SetConfiguration(config)
+
 	loc.authRepo = NewUAAAuthenticationRepository(authGateway, configRepo)
 
 	// ensure gateway refreshers are set before passing them by value to repositories
@@ -39,7 +45,7 @@ func NewRepositoryLocator(config *configuration.Configuration, configRepo config
 	loc.organizationRepo = NewCloudControllerOrganizationRepository(config, cloudControllerGateway)
 	loc.spaceRepo = NewCloudControllerSpaceRepository(config, cloudControllerGateway)
 	loc.appRepo = NewCloudControllerApplicationRepository(config, cloudControllerGateway)
-	loc.appBitsRepo = NewCloudControllerApplicationBitsRepository(config, cloudControllerGateway, cf.ApplicationZipper{})
+	loc.appBitsRepo = NewCloudControllerApplicationBitsRepository(config, cloudControllerGateway, cf.ApplicationZipper{}, configuration.ResourceCacheDir())
 	loc.appSummaryRepo = NewCloudControllerAppSummaryRepository(config, cloudControllerGateway, loc.appRepo)
 	loc.appFilesRepo = NewCloudControllerAppFilesRepository(config, cloudControllerGateway)
 	loc.domainRepo = NewCloudControllerDomainRepository(config, cloudControllerGateway)
@@ -47,7 +53,9 @@ func NewRepositoryLocator(config *configuration.Configuration, configRepo config
 	loc.stackRepo = NewCloudControllerStackRepository(config, cloudControllerGateway)
 	loc.serviceRepo = NewCloudControllerServiceRepository(config, cloudControllerGateway)
 	loc.passwordRepo = NewCloudControllerPasswordRepository(config, uaaGateway)
-	loc.logsRepo = NewLoggregatorLogsRepository(config, cloudControllerGateway, LoggregatorHost)
+	loc.userRepo = NewCloudControllerUserRepository(config, uaaGateway, cloudControllerGateway)
+	loc.quotaRepo = NewCloudControllerQuotaRepository(config, cloudControllerGateway)
+	loc.logsRepo = NewLoggregatorLogsRepository(config, cloudControllerGateway, loc.authRepo, LoggregatorHost)
 
 	return
 }
@@ -104,6 +112,14 @@ func (locator RepositoryLocator) GetPasswordRepository() PasswordRepository {
 	return locator.passwordRepo
 }
 
+func (locator RepositoryLocator) GetUserRepository() UserRepository {
+	return locator.userRepo
+}
+
+func (locator RepositoryLocator) GetQuotaRepository() QuotaRepository {
+	return locator.quotaRepo
+}
+
 func (locator RepositoryLocator) GetLogsRepository() LogsRepository {
 	return locator.logsRepo
 }
@@ -33,6 +33,10 @@ type ApplicationEntity struct {
 	State           string
 	Instances       int
 	Memory          int
+	Command         string
+	Buildpack       string
+	StackGuid       string `json:"stack_guid"`
+	Stack           StackResource
 	Routes          []RouteResource
 	EnvironmentJson map[string]string `json:"environment_json"`
 }
@@ -68,9 +72,31 @@ type OrganizationResource struct {
 }
 
 type OrganizationEntity struct {
-	Name    string
-	Spaces  []Resource
-	Domains []Resource
+	Name            string
+	Spaces          []Resource
+	Domains         []Resource
+	QuotaDefinition QuotaResource `json:"quota_definition"`
+}
+
+type QuotaResource struct {
+	Metadata Metadata
+	Entity   QuotaEntity
+}
+
+type QuotaEntity struct {
+	Name                    string
+	MemoryLimit             uint64 `json:"memory_limit"`
+	TotalServices           int    `json:"total_services"`
+	TotalRoutes             int    `json:"total_routes"`
+	NonBasicServicesAllowed bool   `json:"non_basic_services_allowed"`
+}
+
+type MemoryUsageResponse struct {
+	MemoryUsageInMb uint64 `json:"memory_usage_in_mb"`
+}
+
+type TotalResultsResponse struct {
+	TotalResults int `json:"total_results"`
 }
 
 type ApplicationSummary struct {
@@ -79,10 +105,17 @@ type ApplicationSummary struct {
 	Routes           []RouteSummary
 	RunningInstances int `json:"running_instances"`
 	Memory           uint64
+	DiskQuota        uint64 `json:"disk_quota"`
 	Instances        int
 	Urls             []string
 	State            string
 	ServiceNames     []string `json:"service_names"`
+
+	DetectedBuildpack    string `json:"detected_buildpack"`
+	DetectedStartCommand string `json:"detected_start_command"`
+	PackageState         string `json:"package_state"`
+	StagingTaskId        string `json:"staging_task_id"`
+	StagingFailedReason  string `json:"staging_failed_reason"`
 }
 
 type RouteSummary struct {
@@ -10,6 +10,7 @@ import (
 
 type RouteRepository interface {
 	FindAll() (routes []cf.Route, apiResponse net.ApiResponse)
+	ListRoutes(cb func([]cf.Route) bool) (apiResponse net.ApiResponse)
 	FindByHost(host string) (route cf.Route, apiResponse net.ApiResponse)
 	FindByHostAndDomain(host, domain string) (route cf.Route, apiResponse net.ApiResponse)
 	Create(newRoute cf.Route, domain cf.Domain) (createdRoute cf.Route, apiResponse net.ApiResponse)
@@ -32,42 +33,44 @@ func NewCloudControllerRouteRepository(config *configuration.Configuration, gate
 }
 
 func (repo CloudControllerRouteRepository) FindAll() (routes []cf.Route, apiResponse net.ApiResponse) {
-	path := fmt.Sprintf("%s/v2/routes?inline-relations-depth=1", repo.config.Target)
-
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	response := new(RoutesResponse)
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, response)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	for _, routeResponse := range response.Routes {
-		domainResource := routeResponse.Entity.Domain
-		appNames := []string{}
-
-		for _, appResource := range routeResponse.Entity.Apps {
-			appNames = append(appNames, appResource.Entity.Name)
-		}
-
-		routes = append(routes,
-			cf.Route{
-				Host: routeResponse.Entity.Host,
-				Guid: routeResponse.Metadata.Guid,
-				Domain: cf.Domain{
-					Name: domainResource.Entity.Name,
-					Guid: domainResource.Metadata.Guid,
-				},
-				AppNames: appNames,
-			},
-		)
-	}
+	apiResponse = repo.ListRoutes(func(page []cf.Route) bool {
+		routes = append(routes, page...)
+		return true
+	})
 	return
 }
 
+// ListRoutes calls cb with the routes of each page as soon as it arrives.
+// Returning false from cb stops listing.
+func (repo CloudControllerRouteRepository) ListRoutes(cb func([]cf.Route) bool) (apiResponse net.ApiResponse) {
+	resources := []RouteResource{}
+	return listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, "/v2/routes?inline-relations-depth=1", &resources,
+		func() bool {
+			routes := []cf.Route{}
+			for _, routeResponse := range resources {
+				domainResource := routeResponse.Entity.Domain
+				appNames := []string{}
+
+				for _, appResource := range routeResponse.Entity.Apps {
+					appNames = append(appNames, appResource.Entity.Name)
+				}
+
+				routes = append(routes,
+					cf.Route{
+						Host: routeResponse.Entity.Host,
+						Guid: routeResponse.Metadata.Guid,
+						Domain: cf.Domain{
+							Name: domainResource.Entity.Name,
+							Guid: domainResource.Metadata.Guid,
+						},
+						AppNames: appNames,
+					},
+				)
+			}
+			return cb(routes)
+		})
+}
+
 func (repo CloudControllerRouteRepository) FindByHost(host string) (route cf.Route, apiResponse net.ApiResponse) {
 	path := fmt.Sprintf("%s/v2/routes?q=host%s", repo.config.Target, "%3A"+host)
 
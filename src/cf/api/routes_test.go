@@ -5,6 +5,7 @@ import (
 	. "cf/api"
 	"cf/configuration"
 	"cf/net"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -93,7 +94,7 @@ func TestRoutesFindAll(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(findAllEndpoint))
 	defer ts.Close()
 
-	repo, _ := getRepo(ts.URL)
+	repo, _ := getRepo(ts)
 	routes, apiResponse := repo.FindAll()
 
 	assert.False(t, apiResponse.IsNotSuccessful())
@@ -111,6 +112,119 @@ func TestRoutesFindAll(t *testing.T) {
 	assert.Equal(t, route.AppNames, []string{"app-2", "app-3"})
 }
 
+var firstPageRoutesEndpoint = testhelpers.CreateEndpoint(
+	"GET",
+	"/v2/routes?inline-relations-depth=1",
+	nil,
+	testhelpers.TestResponse{Status: http.StatusOK, Body: `
+{
+  "next_url": "/v2/routes?inline-relations-depth=1&page=2",
+  "resources": [
+    {
+      "metadata": { "guid": "route-1-guid" },
+      "entity": {
+        "host": "route-1-host",
+        "domain": { "metadata": { "guid": "domain-1-guid" }, "entity": { "name": "cfapps.io" } },
+        "apps": []
+      }
+    }
+  ]
+}`},
+)
+
+var secondPageRoutesEndpoint = testhelpers.CreateEndpoint(
+	"GET",
+	"/v2/routes?inline-relations-depth=1&page=2",
+	nil,
+	testhelpers.TestResponse{Status: http.StatusOK, Body: `
+{
+  "next_url": null,
+  "resources": [
+    {
+      "metadata": { "guid": "route-2-guid" },
+      "entity": {
+        "host": "route-2-host",
+        "domain": { "metadata": { "guid": "domain-2-guid" }, "entity": { "name": "example.com" } },
+        "apps": [ { "metadata": { "guid": "app-2-guid" }, "entity": { "name": "app-2" } } ]
+      }
+    }
+  ]
+}`},
+)
+
+func paginatedRoutesEndpoint(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Query().Get("page") == "2" {
+		secondPageRoutesEndpoint(writer, request)
+		return
+	}
+	firstPageRoutesEndpoint(writer, request)
+}
+
+func TestRoutesFindAllFollowsNextUrl(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(paginatedRoutesEndpoint))
+	defer ts.Close()
+
+	repo, _ := getRepo(ts)
+	routes, apiResponse := repo.FindAll()
+
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, len(routes), 2)
+	assert.Equal(t, routes[0].Host, "route-1-host")
+	assert.Equal(t, routes[1].Host, "route-2-host")
+	assert.Equal(t, routes[1].Domain.Name, "example.com")
+	assert.Equal(t, routes[1].AppNames, []string{"app-2"})
+}
+
+func TestRoutesListRoutesCallsBackWithEachPage(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(paginatedRoutesEndpoint))
+	defer ts.Close()
+
+	repo, _ := getRepo(ts)
+	pages := [][]cf.Route{}
+	apiResponse := repo.ListRoutes(func(routes []cf.Route) bool {
+		pages = append(pages, routes)
+		return true
+	})
+
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, len(pages), 2)
+	assert.Equal(t, len(pages[0]), 1)
+	assert.Equal(t, pages[0][0].Host, "route-1-host")
+	assert.Equal(t, len(pages[1]), 1)
+	assert.Equal(t, pages[1][0].Host, "route-2-host")
+}
+
+func TestRoutesListRoutesDoesNotCarryFieldsOverFromThePreviousPage(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Query().Get("page") == "2" {
+			fmt.Fprint(writer, `{"resources": [{"metadata": {"guid": "route-2-guid"}, "entity": {"host": "route-2-host"}}]}`)
+			return
+		}
+		fmt.Fprint(writer, `{
+  "next_url": "/v2/routes?inline-relations-depth=1&page=2",
+  "resources": [{
+    "metadata": {"guid": "route-1-guid"},
+    "entity": {
+      "host": "route-1-host",
+      "domain": {"metadata": {"guid": "domain-1-guid"}, "entity": {"name": "example.com"}},
+      "apps": [{"entity": {"name": "app-1"}}]
+    }
+  }]
+}`)
+	}))
+	defer ts.Close()
+
+	repo, _ := getRepo(ts)
+	routes, apiResponse := repo.FindAll()
+
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, len(routes), 2)
+	assert.Equal(t, routes[0].AppNames, []string{"app-1"})
+	assert.Equal(t, routes[1].Host, "route-2-host")
+	assert.Equal(t, routes[1].Domain.Name, "")
+	assert.Equal(t, len(routes[1].AppNames), 0)
+}
+
 var findRouteByHostResponse = testhelpers.TestResponse{Status: http.StatusCreated, Body: `
 { "resources": [
     {
@@ -134,7 +248,7 @@ func TestFindByHost(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(findRouteByHostEndpoint))
 	defer ts.Close()
 
-	repo, _ := getRepo(ts.URL)
+	repo, _ := getRepo(ts)
 	route, apiResponse := repo.FindByHost("my-cool-app")
 
 	assert.False(t, apiResponse.IsNotSuccessful())
@@ -156,7 +270,7 @@ func TestFindByHostWhenHostIsNotFound(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(findRouteByHostNotFoundEndpoint))
 	defer ts.Close()
 
-	repo, _ := getRepo(ts.URL)
+	repo, _ := getRepo(ts)
 	_, apiResponse := repo.FindByHost("my-cool-app")
 
 	assert.True(t, apiResponse.IsNotSuccessful())
@@ -173,7 +287,7 @@ func TestFindByHostAndDomain(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(findRouteByHostAndDomainEndpoint))
 	defer ts.Close()
 
-	repo, domainRepo := getRepo(ts.URL)
+	repo, domainRepo := getRepo(ts)
 	domainRepo.FindByNameDomain = cf.Domain{Guid: "my-domain-guid"}
 	route, apiResponse := repo.FindByHostAndDomain("my-cool-app", "my-domain.com")
 
@@ -193,7 +307,7 @@ func TestFindByHostAndDomainWhenRouteIsNotFound(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(findRouteByHostAndDomainNotFoundEndpoint))
 	defer ts.Close()
 
-	repo, domainRepo := getRepo(ts.URL)
+	repo, domainRepo := getRepo(ts)
 	domainRepo.FindByNameDomain = cf.Domain{Guid: "my-domain-guid"}
 	_, apiResponse := repo.FindByHostAndDomain("my-cool-app", "my-domain.com")
 
@@ -222,7 +336,7 @@ func TestCreateRoute(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(createRouteEndpoint))
 	defer ts.Close()
 
-	repo, _ := getRepo(ts.URL)
+	repo, _ := getRepo(ts)
 	domain := cf.Domain{Guid: "my-domain-guid"}
 	newRoute := cf.Route{Host: "my-cool-app"}
 
@@ -243,7 +357,7 @@ func TestBind(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(bindRouteEndpoint))
 	defer ts.Close()
 
-	repo, _ := getRepo(ts.URL)
+	repo, _ := getRepo(ts)
 	route := cf.Route{Guid: "my-cool-route-guid"}
 	app := cf.Application{Guid: "my-cool-app-guid"}
 
@@ -262,7 +376,7 @@ func TestUnbind(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(unbindRouteEndpoint))
 	defer ts.Close()
 
-	repo, _ := getRepo(ts.URL)
+	repo, _ := getRepo(ts)
 	route := cf.Route{Guid: "my-cool-route-guid"}
 	app := cf.Application{Guid: "my-cool-app-guid"}
 
@@ -270,14 +384,15 @@ func TestUnbind(t *testing.T) {
 	assert.False(t, apiResponse.IsNotSuccessful())
 }
 
-func getRepo(targetURL string) (repo CloudControllerRouteRepository, domainRepo *testhelpers.FakeDomainRepository) {
+func getRepo(ts *httptest.Server) (repo CloudControllerRouteRepository, domainRepo *testhelpers.FakeDomainRepository) {
 	config := &configuration.Configuration{
 		AccessToken: "BEARER my_access_token",
-		Target:      targetURL,
+		Target:      ts.URL,
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	domainRepo = &testhelpers.FakeDomainRepository{}
 
 	repo = NewCloudControllerRouteRepository(config, gateway, domainRepo)
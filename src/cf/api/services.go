@@ -12,6 +12,7 @@ import (
 
 type ServiceRepository interface {
 	GetServiceOfferings() (offerings []cf.ServiceOffering, apiResponse net.ApiResponse)
+	ListServiceOfferings(cb func([]cf.ServiceOffering) bool) (apiResponse net.ApiResponse)
 	CreateServiceInstance(name string, plan cf.ServicePlan) (identicalAlreadyExists bool, apiResponse net.ApiResponse)
 	CreateUserProvidedServiceInstance(name string, params map[string]string) (apiResponse net.ApiResponse)
 	FindInstanceByName(name string) (instance cf.ServiceInstance, apiResponse net.ApiResponse)
@@ -33,35 +34,36 @@ func NewCloudControllerServiceRepository(config *configuration.Configuration, ga
 }
 
 func (repo CloudControllerServiceRepository) GetServiceOfferings() (offerings []cf.ServiceOffering, apiResponse net.ApiResponse) {
-	path := fmt.Sprintf("%s/v2/services?inline-relations-depth=1", repo.config.Target)
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	response := new(ServiceOfferingsApiResponse)
-
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, response)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
+	apiResponse = repo.ListServiceOfferings(func(page []cf.ServiceOffering) bool {
+		offerings = append(offerings, page...)
+		return true
+	})
+	return
+}
 
-	for _, r := range response.Resources {
-		plans := []cf.ServicePlan{}
-		for _, p := range r.Entity.ServicePlans {
-			plans = append(plans, cf.ServicePlan{Name: p.Entity.Name, Guid: p.Metadata.Guid})
-		}
-		offerings = append(offerings, cf.ServiceOffering{
-			Label:       r.Entity.Label,
-			Version:     r.Entity.Version,
-			Provider:    r.Entity.Provider,
-			Description: r.Entity.Description,
-			Guid:        r.Metadata.Guid,
-			Plans:       plans,
+// ListServiceOfferings calls cb with the offerings of each page as soon as
+// it arrives. Returning false from cb stops listing.
+func (repo CloudControllerServiceRepository) ListServiceOfferings(cb func([]cf.ServiceOffering) bool) (apiResponse net.ApiResponse) {
+	resources := []ServiceOfferingResource{}
+	return listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, "/v2/services?inline-relations-depth=1", &resources,
+		func() bool {
+			offerings := []cf.ServiceOffering{}
+			for _, r := range resources {
+				plans := []cf.ServicePlan{}
+				for _, p := range r.Entity.ServicePlans {
+					plans = append(plans, cf.ServicePlan{Name: p.Entity.Name, Guid: p.Metadata.Guid})
+				}
+				offerings = append(offerings, cf.ServiceOffering{
+					Label:       r.Entity.Label,
+					Version:     r.Entity.Version,
+					Provider:    r.Entity.Provider,
+					Description: r.Entity.Description,
+					Guid:        r.Metadata.Guid,
+					Plans:       plans,
+				})
+			}
+			return cb(offerings)
 		})
-	}
-
-	return
 }
 
 func (repo CloudControllerServiceRepository) CreateServiceInstance(name string, plan cf.ServicePlan) (identicalAlreadyExists bool, apiResponse net.ApiResponse) {
@@ -73,6 +73,7 @@ func TestGetServiceOfferings(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 	offerings, apiResponse := repo.GetServiceOfferings()
 
@@ -114,6 +115,7 @@ func TestCreateServiceInstance(t *testing.T) {
 		Space:       cf.Space{Guid: "space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	identicalAlreadyExists, apiResponse := repo.CreateServiceInstance("instance-name", cf.ServicePlan{Guid: "plan-guid"})
@@ -149,6 +151,7 @@ func TestCreateServiceInstanceWhenIdenticalServiceAlreadyExists(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	servicePlan := cf.ServicePlan{Guid: "plan-guid", Name: "plan-name"}
@@ -186,6 +189,7 @@ func TestCreateServiceInstanceWhenDifferentServiceAlreadyExists(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	servicePlan := cf.ServicePlan{Guid: "different-plan-guid", Name: "plan-name"}
@@ -212,6 +216,7 @@ func TestCreateUserProvidedServiceInstance(t *testing.T) {
 		Space:       cf.Space{Guid: "some-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	params := map[string]string{
@@ -291,6 +296,7 @@ func TestFindInstanceByName(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	instance, apiResponse := repo.FindInstanceByName("my-service")
@@ -330,6 +336,7 @@ func TestFindInstanceByNameForNonExistentService(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	_, apiResponse := repo.FindInstanceByName("my-service")
@@ -353,6 +360,7 @@ func TestBindService(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	serviceInstance := cf.ServiceInstance{Guid: "my-service-instance-guid"}
@@ -380,6 +388,7 @@ func TestBindServiceIfError(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	serviceInstance := cf.ServiceInstance{Guid: "my-service-instance-guid"}
@@ -406,6 +415,7 @@ func TestUnbindService(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	serviceBindings := []cf.ServiceBinding{
@@ -432,6 +442,7 @@ func TestUnbindServiceWhenBindingDoesNotExist(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	serviceBindings := []cf.ServiceBinding{}
@@ -462,6 +473,7 @@ func TestDeleteServiceWithoutServiceBindings(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	serviceInstance := cf.ServiceInstance{Guid: "my-service-instance-guid"}
@@ -508,6 +520,7 @@ func TestRenameService(t *testing.T) {
 	}
 
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerServiceRepository(config, gateway)
 
 	serviceInstance := cf.ServiceInstance{Guid: "my-service-instance-guid"}
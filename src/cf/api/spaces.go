@@ -11,8 +11,12 @@ import (
 type SpaceRepository interface {
 	GetCurrentSpace() (space cf.Space)
 	FindAll() (spaces []cf.Space, apiResponse net.ApiResponse)
+	FindAllInOrg(org cf.Organization) (spaces []cf.Space, apiResponse net.ApiResponse)
+	ListSpaces(cb func([]cf.Space) bool) (apiResponse net.ApiResponse)
 	FindByName(name string) (space cf.Space, apiResponse net.ApiResponse)
+	FindByNameInOrg(name string, org cf.Organization) (space cf.Space, apiResponse net.ApiResponse)
 	GetSummary() (space cf.Space, apiResponse net.ApiResponse)
+	GetSummaryForSpace(space cf.Space) (summary cf.Space, apiResponse net.ApiResponse)
 	Create(name string) (apiResponse net.ApiResponse)
 	Rename(space cf.Space, newName string) (apiResponse net.ApiResponse)
 	Delete(space cf.Space) (apiResponse net.ApiResponse)
@@ -34,30 +38,49 @@ func (repo CloudControllerSpaceRepository) GetCurrentSpace() (space cf.Space) {
 }
 
 func (repo CloudControllerSpaceRepository) FindAll() (spaces []cf.Space, apiResponse net.ApiResponse) {
-	path := fmt.Sprintf("%s/v2/organizations/%s/spaces", repo.config.Target, repo.config.Organization.Guid)
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	response := new(ApiResponse)
-
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, response)
+	apiResponse = repo.ListSpaces(func(page []cf.Space) bool {
+		spaces = append(spaces, page...)
+		return true
+	})
+	return
+}
 
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
+// FindAllInOrg lists the spaces of org page by page, rather than relying on
+// the spaces inlined in the org, which the cloud controller cuts off.
+func (repo CloudControllerSpaceRepository) FindAllInOrg(org cf.Organization) (spaces []cf.Space, apiResponse net.ApiResponse) {
+	apiResponse = repo.listSpacesInOrg(org.Guid, func(page []cf.Space) bool {
+		spaces = append(spaces, page...)
+		return true
+	})
+	return
+}
 
-	for _, r := range response.Resources {
-		spaces = append(spaces, cf.Space{Name: r.Entity.Name, Guid: r.Metadata.Guid})
-	}
+// ListSpaces calls cb with the spaces of the current org on each page as
+// soon as it arrives. Returning false from cb stops listing.
+func (repo CloudControllerSpaceRepository) ListSpaces(cb func([]cf.Space) bool) (apiResponse net.ApiResponse) {
+	return repo.listSpacesInOrg(repo.config.Organization.Guid, cb)
+}
 
-	return
+func (repo CloudControllerSpaceRepository) listSpacesInOrg(orgGuid string, cb func([]cf.Space) bool) (apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("/v2/organizations/%s/spaces", orgGuid)
+	resources := []Resource{}
+	return listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, path, &resources,
+		func() bool {
+			spaces := []cf.Space{}
+			for _, r := range resources {
+				spaces = append(spaces, cf.Space{Name: r.Entity.Name, Guid: r.Metadata.Guid})
+			}
+			return cb(spaces)
+		})
 }
 
 func (repo CloudControllerSpaceRepository) FindByName(name string) (space cf.Space, apiResponse net.ApiResponse) {
+	return repo.FindByNameInOrg(name, repo.config.Organization)
+}
+
+func (repo CloudControllerSpaceRepository) FindByNameInOrg(name string, org cf.Organization) (space cf.Space, apiResponse net.ApiResponse) {
 	path := fmt.Sprintf("%s/v2/organizations/%s/spaces?q=name%s&inline-relations-depth=1",
-		repo.config.Target, repo.config.Organization.Guid, "%3A"+strings.ToLower(name))
+		repo.config.Target, org.Guid, "%3A"+strings.ToLower(name))
 
 	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
 	if apiResponse.IsNotSuccessful() {
@@ -107,7 +130,11 @@ func (repo CloudControllerSpaceRepository) FindByName(name string) (space cf.Spa
 }
 
 func (repo CloudControllerSpaceRepository) GetSummary() (space cf.Space, apiResponse net.ApiResponse) {
-	path := fmt.Sprintf("%s/v2/spaces/%s/summary", repo.config.Target, repo.config.Space.Guid)
+	return repo.GetSummaryForSpace(repo.config.Space)
+}
+
+func (repo CloudControllerSpaceRepository) GetSummaryForSpace(space cf.Space) (summary cf.Space, apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/spaces/%s/summary", repo.config.Target, space.Guid)
 	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
 	if apiResponse.IsNotSuccessful() {
 		return
@@ -123,7 +150,7 @@ func (repo CloudControllerSpaceRepository) GetSummary() (space cf.Space, apiResp
 	applications := extractApplicationsFromSummary(response.Apps)
 	serviceInstances := extractServiceInstancesFromSummary(response.ServiceInstances, response.Apps)
 
-	space = cf.Space{Name: response.Name, Guid: response.Guid, Applications: applications, ServiceInstances: serviceInstances}
+	summary = cf.Space{Name: response.Name, Guid: response.Guid, Applications: applications, ServiceInstances: serviceInstances}
 
 	return
 }
@@ -176,6 +203,7 @@ func extractApplicationsFromSummary(appSummaries []ApplicationSummary) (applicat
 			Instances:        appSummary.Instances,
 			RunningInstances: appSummary.RunningInstances,
 			Memory:           appSummary.Memory,
+			DiskQuota:        appSummary.DiskQuota,
 		}
 		applications = append(applications, app)
 	}
@@ -51,6 +51,7 @@ func TestSpacesFindAll(t *testing.T) {
 		Organization: cf.Organization{Guid: "some-org-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 	spaces, apiResponse := repo.FindAll()
 
@@ -66,6 +67,55 @@ func TestSpacesFindAll(t *testing.T) {
 	assert.Equal(t, secondSpace.Guid, "staging-space-guid")
 }
 
+func TestSpacesFindAllInOrgListsEveryPage(t *testing.T) {
+	firstPageEndpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/organizations/other-org-guid/spaces",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `{
+  "next_url": "/v2/organizations/other-org-guid/spaces?page=2",
+  "resources": [
+    {"metadata": {"guid": "space1-guid"}, "entity": {"name": "space1"}}
+  ]
+}`},
+	)
+	secondPageEndpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/v2/organizations/other-org-guid/spaces?page=2",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `{
+  "resources": [
+    {"metadata": {"guid": "space2-guid"}, "entity": {"name": "space2"}}
+  ]
+}`},
+	)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Query().Get("page") == "2" {
+			secondPageEndpoint(writer, request)
+			return
+		}
+		firstPageEndpoint(writer, request)
+	}))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken:  "BEARER my_access_token",
+		Target:       ts.URL,
+		Organization: cf.Organization{Guid: "some-org-guid"},
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerSpaceRepository(config, gateway)
+	spaces, apiResponse := repo.FindAllInOrg(cf.Organization{Guid: "other-org-guid"})
+
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, spaces, []cf.Space{
+		cf.Space{Name: "space1", Guid: "space1-guid"},
+		cf.Space{Name: "space2", Guid: "space2-guid"},
+	})
+}
+
 func TestSpacesFindAllWithIncorrectToken(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(multipleSpacesEndpoint))
 	defer ts.Close()
@@ -76,6 +126,7 @@ func TestSpacesFindAllWithIncorrectToken(t *testing.T) {
 		Organization: cf.Organization{Guid: "some-org-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 
 	var (
@@ -175,6 +226,7 @@ func TestSpacesFindByName(t *testing.T) {
 		Organization: cf.Organization{Guid: "org-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 	existingOrg := cf.Organization{Guid: "org1-guid", Name: "Org1"}
 	apps := []cf.Application{
@@ -202,6 +254,24 @@ func TestSpacesFindByName(t *testing.T) {
 	assert.False(t, apiResponse.IsNotSuccessful())
 }
 
+func TestSpacesFindByNameInOrg(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(findSpaceByNameEndpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken:  "BEARER my_access_token",
+		Target:       ts.URL,
+		Organization: cf.Organization{Guid: "targeted-org-guid"},
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerSpaceRepository(config, gateway)
+
+	space, apiResponse := repo.FindByNameInOrg("Space1", cf.Organization{Guid: "org-guid"})
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, space.Guid, "space1-guid")
+}
+
 var didNotFindSpaceByNameResponse = testhelpers.TestResponse{Status: http.StatusOK, Body: `
 {
   "total_results": 0,
@@ -230,6 +300,7 @@ func TestSpacesDidNotFindByName(t *testing.T) {
 		Organization: cf.Organization{Guid: "org-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 
 	_, apiResponse := repo.FindByName("space1")
@@ -258,6 +329,7 @@ var spaceSummaryResponse = testhelpers.TestResponse{Status: http.StatusOK, Body:
       "running_instances":1,
       "name":"app1",
       "memory":128,
+      "disk_quota":1024,
       "instances":1,
       "state":"STARTED",
       "service_names":[
@@ -322,6 +394,7 @@ func TestSpacesGetSummary(t *testing.T) {
 		Space:       cf.Space{Guid: "my-space-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 
 	space, apiResponse := repo.GetSummary()
@@ -341,6 +414,7 @@ func TestSpacesGetSummary(t *testing.T) {
 	assert.Equal(t, app1.Instances, 1)
 	assert.Equal(t, app1.RunningInstances, 1)
 	assert.Equal(t, app1.Memory, uint64(128))
+	assert.Equal(t, app1.DiskQuota, uint64(1024))
 
 	app2 := space.Applications[1]
 	assert.Equal(t, app2.Name, "app2")
@@ -365,6 +439,25 @@ func TestSpacesGetSummary(t *testing.T) {
 	assert.Equal(t, instance1.ApplicationNames[1], "app2")
 }
 
+func TestSpacesGetSummaryForSpace(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(spaceSummaryEndpoint))
+	defer ts.Close()
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ts.URL,
+		Space:       cf.Space{Guid: "some-other-space-guid"},
+	}
+	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+	repo := NewCloudControllerSpaceRepository(config, gateway)
+
+	space, apiResponse := repo.GetSummaryForSpace(cf.Space{Guid: "my-space-guid"})
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, space.Name, "development")
+	assert.Equal(t, len(space.Applications), 2)
+}
+
 var createSpaceEndpoint = testhelpers.CreateEndpoint(
 	"POST",
 	"/v2/spaces",
@@ -382,6 +475,7 @@ func TestCreateSpace(t *testing.T) {
 		Organization: cf.Organization{Guid: "org-guid"},
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 
 	apiResponse := repo.Create("space-name")
@@ -404,6 +498,7 @@ func TestRenameSpace(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 
 	space := cf.Space{Guid: "my-space-guid"}
@@ -427,6 +522,7 @@ func TestDeleteSpace(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerSpaceRepository(config, gateway)
 
 	space := cf.Space{Guid: "my-space-guid"}
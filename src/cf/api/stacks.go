@@ -10,6 +10,7 @@ import (
 type StackRepository interface {
 	FindByName(name string) (stack cf.Stack, apiResponse net.ApiResponse)
 	FindAll() (stacks []cf.Stack, apiResponse net.ApiResponse)
+	ListStacks(cb func([]cf.Stack) bool) (apiResponse net.ApiResponse)
 }
 
 type CloudControllerStackRepository struct {
@@ -49,21 +50,23 @@ func (repo CloudControllerStackRepository) FindByName(name string) (stack cf.Sta
 }
 
 func (repo CloudControllerStackRepository) FindAll() (stacks []cf.Stack, apiResponse net.ApiResponse) {
-	path := fmt.Sprintf("%s/v2/stacks", repo.config.Target)
-	request, apiResponse := repo.gateway.NewRequest("GET", path, repo.config.AccessToken, nil)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	listResponse := new(StackApiResponse)
-	_, apiResponse = repo.gateway.PerformRequestForJSONResponse(request, listResponse)
-	if apiResponse.IsNotSuccessful() {
-		return
-	}
-
-	for _, r := range listResponse.Resources {
-		stacks = append(stacks, cf.Stack{Guid: r.Metadata.Guid, Name: r.Entity.Name, Description: r.Entity.Description})
-	}
-
+	apiResponse = repo.ListStacks(func(page []cf.Stack) bool {
+		stacks = append(stacks, page...)
+		return true
+	})
 	return
 }
+
+// ListStacks calls cb with the stacks of each page as soon as it arrives.
+// Returning false from cb stops listing.
+func (repo CloudControllerStackRepository) ListStacks(cb func([]cf.Stack) bool) (apiResponse net.ApiResponse) {
+	resources := []StackResource{}
+	return listAllResources(repo.gateway, repo.config.Target, repo.config.AccessToken, "/v2/stacks", &resources,
+		func() bool {
+			stacks := []cf.Stack{}
+			for _, r := range resources {
+				stacks = append(stacks, cf.Stack{Guid: r.Metadata.Guid, Name: r.Entity.Name, Description: r.Entity.Description})
+			}
+			return cb(stacks)
+		})
+}
@@ -41,6 +41,7 @@ func TestStacksFindByName(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerStackRepository(config, gateway)
 
 	stack, apiResponse := repo.FindByName("linux")
@@ -102,6 +103,7 @@ func TestStacksFindAll(t *testing.T) {
 		Target:      ts.URL,
 	}
 	gateway := net.NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 	repo := NewCloudControllerStackRepository(config, gateway)
 
 	stacks, apiResponse := repo.FindAll()
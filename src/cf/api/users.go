@@ -0,0 +1,373 @@
+package api
+
+import (
+	"bytes"
+	"cf"
+	"cf/configuration"
+	"cf/net"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var orgRoleToPathMap = map[string]string{
+	cf.ORG_MANAGER:     "managers",
+	cf.BILLING_MANAGER: "billing_managers",
+	cf.ORG_AUDITOR:     "auditors",
+}
+
+var spaceRoleToPathMap = map[string]string{
+	cf.SPACE_MANAGER:   "managers",
+	cf.SPACE_DEVELOPER: "developers",
+	cf.SPACE_AUDITOR:   "auditors",
+}
+
+type UserRepository interface {
+	FindByUsername(username string) (user cf.User, apiResponse net.ApiResponse)
+	FindAllInOrgByRole(org cf.Organization) (usersByRole map[string][]cf.User, apiResponse net.ApiResponse)
+	FindAllInSpaceByRole(space cf.Space) (usersByRole map[string][]cf.User, apiResponse net.ApiResponse)
+	Create(username, password string) (apiResponse net.ApiResponse)
+	Delete(user cf.User) (apiResponse net.ApiResponse)
+	SetOrgRole(user cf.User, org cf.Organization, role string) (apiResponse net.ApiResponse)
+	UnsetOrgRole(user cf.User, org cf.Organization, role string) (apiResponse net.ApiResponse)
+	SetSpaceRole(user cf.User, space cf.Space, role string) (apiResponse net.ApiResponse)
+	UnsetSpaceRole(user cf.User, space cf.Space, role string) (apiResponse net.ApiResponse)
+}
+
+type CloudControllerUserRepository struct {
+	config       *configuration.Configuration
+	uaaGateway   net.Gateway
+	ccGateway    net.Gateway
+	infoResponse *InfoResponse
+}
+
+func NewCloudControllerUserRepository(config *configuration.Configuration, uaaGateway net.Gateway, ccGateway net.Gateway) (repo CloudControllerUserRepository) {
+	repo.config = config
+	repo.uaaGateway = uaaGateway
+	repo.ccGateway = ccGateway
+	repo.infoResponse = new(InfoResponse)
+	return
+}
+
+type UAAUserResources struct {
+	Resources    []UAAUserResource
+	StartIndex   int `json:"startIndex"`
+	TotalResults int `json:"totalResults"`
+}
+
+// Guids are looked up in the UAA this many at a time, to keep the filter
+// within the URL length servers accept
+const uaaUserFilterBatchSize = 50
+
+type UAAUserResource struct {
+	Id       string
+	Username string `json:"userName"`
+}
+
+type uaaUserFields struct {
+	Username string         `json:"userName"`
+	Emails   []uaaUserEmail `json:"emails"`
+	Password string         `json:"password"`
+	Name     uaaUserName    `json:"name"`
+}
+
+type uaaUserEmail struct {
+	Value string `json:"value"`
+}
+
+type uaaUserName struct {
+	GivenName  string `json:"givenName"`
+	FamilyName string `json:"familyName"`
+}
+
+func (repo CloudControllerUserRepository) FindByUsername(username string) (user cf.User, apiResponse net.ApiResponse) {
+	filter := fmt.Sprintf(`userName Eq "%s"`, scimFilterValue(username))
+	users, apiResponse := repo.findUAAUsers(filter)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	if len(users) == 0 {
+		apiResponse = net.NewNotFoundApiStatus("User", username)
+		return
+	}
+
+	user = users[0]
+	return
+}
+
+func (repo CloudControllerUserRepository) FindAllInOrgByRole(org cf.Organization) (usersByRole map[string][]cf.User, apiResponse net.ApiResponse) {
+	usersByRole = map[string][]cf.User{}
+	for _, role := range cf.OrgRoles {
+		path := fmt.Sprintf("/v2/organizations/%s/%s", org.Guid, orgRoleToPathMap[role])
+		usersByRole[role], apiResponse = repo.findAllWithPath(path)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+	}
+	return
+}
+
+func (repo CloudControllerUserRepository) FindAllInSpaceByRole(space cf.Space) (usersByRole map[string][]cf.User, apiResponse net.ApiResponse) {
+	usersByRole = map[string][]cf.User{}
+	for _, role := range cf.SpaceRoles {
+		path := fmt.Sprintf("/v2/spaces/%s/%s", space.Guid, spaceRoleToPathMap[role])
+		usersByRole[role], apiResponse = repo.findAllWithPath(path)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+	}
+	return
+}
+
+func (repo CloudControllerUserRepository) Create(username, password string) (apiResponse net.ApiResponse) {
+	uaaEndpoint, apiResponse := repo.getAuthEndpoint()
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	body, err := json.Marshal(uaaUserFields{
+		Username: username,
+		Emails:   []uaaUserEmail{{Value: username}},
+		Password: password,
+		Name:     uaaUserName{GivenName: username, FamilyName: username},
+	})
+	if err != nil {
+		apiResponse = net.NewApiStatusWithError("Error creating json for user", err)
+		return
+	}
+
+	request, apiResponse := repo.uaaGateway.NewRequest("POST", uaaEndpoint+"/Users", repo.config.AccessToken, bytes.NewReader(body))
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	createdUser := UAAUserResource{}
+	_, apiResponse = repo.uaaGateway.PerformRequestForJSONResponse(request, &createdUser)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	path := fmt.Sprintf("%s/v2/users", repo.config.Target)
+	ccBody := fmt.Sprintf(`{"guid":"%s"}`, createdUser.Id)
+	request, apiResponse = repo.ccGateway.NewRequest("POST", path, repo.config.AccessToken, strings.NewReader(ccBody))
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.ccGateway.PerformRequest(request)
+	return
+}
+
+func (repo CloudControllerUserRepository) Delete(user cf.User) (apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/users/%s", repo.config.Target, user.Guid)
+	request, apiResponse := repo.ccGateway.NewRequest("DELETE", path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.ccGateway.PerformRequest(request)
+	if apiResponse.IsNotSuccessful() && apiResponse.ErrorCode != USER_NOT_FOUND {
+		return
+	}
+
+	uaaEndpoint, apiResponse := repo.getAuthEndpoint()
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	path = fmt.Sprintf("%s/Users/%s", uaaEndpoint, user.Guid)
+	request, apiResponse = repo.uaaGateway.NewRequest("DELETE", path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.uaaGateway.PerformRequest(request)
+	return
+}
+
+// SetOrgRole also makes the user a member of the org, which the cloud
+// controller requires before any org or space role can be given.
+func (repo CloudControllerUserRepository) SetOrgRole(user cf.User, org cf.Organization, role string) (apiResponse net.ApiResponse) {
+	rolePath, found := orgRoleToPathMap[role]
+	if !found {
+		apiResponse = net.NewApiStatusWithMessage("Invalid Role %s", role)
+		return
+	}
+
+	apiResponse = repo.addOrgUser(user, org.Guid)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	path := fmt.Sprintf("%s/v2/organizations/%s/%s/%s", repo.config.Target, org.Guid, rolePath, user.Guid)
+	return repo.callCC("PUT", path)
+}
+
+func (repo CloudControllerUserRepository) UnsetOrgRole(user cf.User, org cf.Organization, role string) (apiResponse net.ApiResponse) {
+	rolePath, found := orgRoleToPathMap[role]
+	if !found {
+		apiResponse = net.NewApiStatusWithMessage("Invalid Role %s", role)
+		return
+	}
+
+	path := fmt.Sprintf("%s/v2/organizations/%s/%s/%s", repo.config.Target, org.Guid, rolePath, user.Guid)
+	return repo.callCC("DELETE", path)
+}
+
+func (repo CloudControllerUserRepository) SetSpaceRole(user cf.User, space cf.Space, role string) (apiResponse net.ApiResponse) {
+	rolePath, found := spaceRoleToPathMap[role]
+	if !found {
+		apiResponse = net.NewApiStatusWithMessage("Invalid Role %s", role)
+		return
+	}
+
+	apiResponse = repo.addOrgUser(user, space.Organization.Guid)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	path := fmt.Sprintf("%s/v2/spaces/%s/%s/%s", repo.config.Target, space.Guid, rolePath, user.Guid)
+	return repo.callCC("PUT", path)
+}
+
+func (repo CloudControllerUserRepository) UnsetSpaceRole(user cf.User, space cf.Space, role string) (apiResponse net.ApiResponse) {
+	rolePath, found := spaceRoleToPathMap[role]
+	if !found {
+		apiResponse = net.NewApiStatusWithMessage("Invalid Role %s", role)
+		return
+	}
+
+	path := fmt.Sprintf("%s/v2/spaces/%s/%s/%s", repo.config.Target, space.Guid, rolePath, user.Guid)
+	return repo.callCC("DELETE", path)
+}
+
+func (repo CloudControllerUserRepository) addOrgUser(user cf.User, orgGuid string) (apiResponse net.ApiResponse) {
+	path := fmt.Sprintf("%s/v2/organizations/%s/users/%s", repo.config.Target, orgGuid, user.Guid)
+	return repo.callCC("PUT", path)
+}
+
+func (repo CloudControllerUserRepository) callCC(method, path string) (apiResponse net.ApiResponse) {
+	request, apiResponse := repo.ccGateway.NewRequest(method, path, repo.config.AccessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = repo.ccGateway.PerformRequest(request)
+	return
+}
+
+// findAllWithPath gets the guids of the users from the cloud controller and
+// then looks up their usernames in the UAA, which is where they are kept.
+func (repo CloudControllerUserRepository) findAllWithPath(path string) (users []cf.User, apiResponse net.ApiResponse) {
+	guids := []string{}
+	resources := []Resource{}
+	apiResponse = listAllResources(repo.ccGateway, repo.config.Target, repo.config.AccessToken, path, &resources,
+		func() bool {
+			for _, r := range resources {
+				guids = append(guids, r.Metadata.Guid)
+			}
+			return true
+		})
+	if apiResponse.IsNotSuccessful() || len(guids) == 0 {
+		return
+	}
+
+	usersByGuid := map[string]cf.User{}
+	for start := 0; start < len(guids); start += uaaUserFilterBatchSize {
+		end := start + uaaUserFilterBatchSize
+		if end > len(guids) {
+			end = len(guids)
+		}
+
+		filters := []string{}
+		for _, guid := range guids[start:end] {
+			filters = append(filters, fmt.Sprintf(`Id eq "%s"`, scimFilterValue(guid)))
+		}
+
+		var uaaUsers []cf.User
+		uaaUsers, apiResponse = repo.findUAAUsers(strings.Join(filters, " or "))
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+		for _, user := range uaaUsers {
+			usersByGuid[user.Guid] = user
+		}
+	}
+
+	for _, guid := range guids {
+		user, found := usersByGuid[guid]
+		if !found {
+			user = cf.User{Guid: guid}
+		}
+		users = append(users, user)
+	}
+	return
+}
+
+// findUAAUsers follows the pages of the UAA, which returns at most 100
+// users at a time by default.
+func (repo CloudControllerUserRepository) findUAAUsers(filter string) (users []cf.User, apiResponse net.ApiResponse) {
+	uaaEndpoint, apiResponse := repo.getAuthEndpoint()
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	startIndex := 1
+	for {
+		query := url.Values{
+			"attributes": []string{"id,userName"},
+			"filter":     []string{filter},
+			"startIndex": []string{strconv.Itoa(startIndex)},
+		}
+		path := fmt.Sprintf("%s/Users?%s", uaaEndpoint, query.Encode())
+
+		var request *net.Request
+		request, apiResponse = repo.uaaGateway.NewRequest("GET", path, repo.config.AccessToken, nil)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+
+		response := UAAUserResources{}
+		_, apiResponse = repo.uaaGateway.PerformRequestForJSONResponse(request, &response)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+
+		for _, resource := range response.Resources {
+			users = append(users, cf.User{Username: resource.Username, Guid: resource.Id})
+		}
+
+		startIndex += len(response.Resources)
+		if len(response.Resources) == 0 || startIndex > response.TotalResults {
+			return
+		}
+	}
+}
+
+// scimFilterValue escapes value to be quoted in a SCIM filter.
+func scimFilterValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
+func (repo CloudControllerUserRepository) getAuthEndpoint() (endpoint string, apiResponse net.ApiResponse) {
+	if repo.infoResponse.TokenEndpoint == "" {
+		path := fmt.Sprintf("%s/info", repo.config.Target)
+		var request *net.Request
+		request, apiResponse = repo.ccGateway.NewRequest("GET", path, repo.config.AccessToken, nil)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+
+		_, apiResponse = repo.ccGateway.PerformRequestForJSONResponse(request, repo.infoResponse)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+	}
+
+	endpoint = repo.infoResponse.TokenEndpoint
+	return
+}
@@ -0,0 +1,304 @@
+package api_test
+
+import (
+	"cf"
+	. "cf/api"
+	"cf/configuration"
+	"cf/net"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testhelpers"
+	"testing"
+)
+
+var emptyResourcesResponse = testhelpers.TestResponse{Status: http.StatusOK, Body: `{"resources": []}`}
+
+func TestFindByUsername(t *testing.T) {
+	uaaEndpoint := testhelpers.CreateEndpoint(
+		"GET",
+		"/Users?attributes=id%2CuserName&filter=userName+Eq+%22my-user%22",
+		nil,
+		testhelpers.TestResponse{Status: http.StatusOK, Body: `{"resources": [{"id": "my-user-guid", "userName": "my-user"}]}`},
+	)
+
+	ccServer, uaaServer, repo := createUsersRepo(nil, uaaEndpoint)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	user, apiResponse := repo.FindByUsername("my-user")
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, user, cf.User{Username: "my-user", Guid: "my-user-guid"})
+}
+
+func TestFindByUsernameWhenNotFound(t *testing.T) {
+	uaaEndpoint := testhelpers.CreateEndpoint("GET", "/Users", nil, emptyResourcesResponse)
+
+	ccServer, uaaServer, repo := createUsersRepo(nil, uaaEndpoint)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	_, apiResponse := repo.FindByUsername("my-user")
+	assert.False(t, apiResponse.IsError())
+	assert.True(t, apiResponse.IsNotFound())
+}
+
+func TestFindAllInOrgByRole(t *testing.T) {
+	ccEndpoints := map[string]http.HandlerFunc{
+		"/v2/organizations/my-org-guid/managers": testhelpers.CreateEndpoint("GET", "/v2/organizations/my-org-guid/managers", nil,
+			testhelpers.TestResponse{Status: http.StatusOK, Body: `{"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}]}`}),
+		"/v2/organizations/my-org-guid/billing_managers": testhelpers.CreateEndpoint("GET", "/v2/organizations/my-org-guid/billing_managers", nil,
+			emptyResourcesResponse),
+		"/v2/organizations/my-org-guid/auditors": testhelpers.CreateEndpoint("GET", "/v2/organizations/my-org-guid/auditors", nil,
+			testhelpers.TestResponse{Status: http.StatusOK, Body: `{"resources": [{"metadata": {"guid": "user-1-guid"}, "entity": {}}, {"metadata": {"guid": "user-2-guid"}, "entity": {}}]}`}),
+	}
+
+	uaaEndpoint := func(writer http.ResponseWriter, request *http.Request) {
+		filter := request.URL.Query().Get("filter")
+		users := []string{}
+		if strings.Contains(filter, `Id eq "user-1-guid"`) {
+			users = append(users, `{"id": "user-1-guid", "userName": "user1"}`)
+		}
+		if strings.Contains(filter, `Id eq "user-2-guid"`) {
+			users = append(users, `{"id": "user-2-guid", "userName": "user2"}`)
+		}
+		fmt.Fprintf(writer, `{"resources": [%s]}`, strings.Join(users, ","))
+	}
+
+	ccServer, uaaServer, repo := createUsersRepo(ccEndpoints, uaaEndpoint)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	usersByRole, apiResponse := repo.FindAllInOrgByRole(cf.Organization{Guid: "my-org-guid"})
+	assert.True(t, apiResponse.IsSuccessful())
+
+	assert.Equal(t, usersByRole[cf.ORG_MANAGER], []cf.User{{Username: "user1", Guid: "user-1-guid"}})
+	assert.Equal(t, len(usersByRole[cf.BILLING_MANAGER]), 0)
+	assert.Equal(t, usersByRole[cf.ORG_AUDITOR], []cf.User{
+		{Username: "user1", Guid: "user-1-guid"},
+		{Username: "user2", Guid: "user-2-guid"},
+	})
+}
+
+func TestFindByUsernameEscapesTheFilter(t *testing.T) {
+	var filter string
+	uaaEndpoint := func(writer http.ResponseWriter, request *http.Request) {
+		filter = request.URL.Query().Get("filter")
+		fmt.Fprint(writer, `{"resources": [{"id": "my-user-guid", "userName": "my\\\"user"}]}`)
+	}
+
+	ccServer, uaaServer, repo := createUsersRepo(nil, uaaEndpoint)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	_, apiResponse := repo.FindByUsername(`my\"user`)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.Equal(t, filter, `userName Eq "my\\\"user"`)
+}
+
+func TestFindAllInOrgByRoleWithManyUsers(t *testing.T) {
+	guids := []string{}
+	for i := 0; i < 120; i++ {
+		guids = append(guids, fmt.Sprintf(`{"metadata": {"guid": "user-%d-guid"}, "entity": {}}`, i))
+	}
+
+	ccEndpoints := map[string]http.HandlerFunc{
+		"/v2/organizations/my-org-guid/managers": testhelpers.CreateEndpoint("GET", "/v2/organizations/my-org-guid/managers", nil,
+			testhelpers.TestResponse{Status: http.StatusOK, Body: fmt.Sprintf(`{"resources": [%s]}`, strings.Join(guids, ","))}),
+		"/v2/organizations/my-org-guid/billing_managers": testhelpers.CreateEndpoint("GET", "/v2/organizations/my-org-guid/billing_managers", nil,
+			emptyResourcesResponse),
+		"/v2/organizations/my-org-guid/auditors": testhelpers.CreateEndpoint("GET", "/v2/organizations/my-org-guid/auditors", nil,
+			emptyResourcesResponse),
+	}
+
+	// Like the UAA, answer with pages of at most 20 users
+	maxIdsPerRequest := 0
+	uaaEndpoint := func(writer http.ResponseWriter, request *http.Request) {
+		ids := regexp.MustCompile(`Id eq "(user-\d+-guid)"`).FindAllStringSubmatch(request.URL.Query().Get("filter"), -1)
+		if len(ids) > maxIdsPerRequest {
+			maxIdsPerRequest = len(ids)
+		}
+
+		startIndex, _ := strconv.Atoi(request.URL.Query().Get("startIndex"))
+		users := []string{}
+		for i := startIndex - 1; i < len(ids) && i < startIndex-1+20; i++ {
+			users = append(users, fmt.Sprintf(`{"id": "%s", "userName": "name-of-%s"}`, ids[i][1], ids[i][1]))
+		}
+		fmt.Fprintf(writer, `{"resources": [%s], "startIndex": %d, "totalResults": %d}`, strings.Join(users, ","), startIndex, len(ids))
+	}
+
+	ccServer, uaaServer, repo := createUsersRepo(ccEndpoints, uaaEndpoint)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	usersByRole, apiResponse := repo.FindAllInOrgByRole(cf.Organization{Guid: "my-org-guid"})
+	assert.True(t, apiResponse.IsSuccessful())
+
+	managers := usersByRole[cf.ORG_MANAGER]
+	assert.Equal(t, len(managers), 120)
+	for i, user := range managers {
+		assert.Equal(t, user.Username, fmt.Sprintf("name-of-user-%d-guid", i))
+	}
+	assert.True(t, maxIdsPerRequest <= 50)
+}
+
+func TestCreateUser(t *testing.T) {
+	ccUserStatus := &testhelpers.RequestStatus{}
+	ccEndpoints := map[string]http.HandlerFunc{
+		"/v2/users": testhelpers.CreateEndpoint("POST", "/v2/users",
+			func(req *http.Request) bool {
+				ccUserStatus.Called = true
+				return testhelpers.RequestBodyMatcher(`{"guid":"my-user-guid"}`)(req)
+			},
+			testhelpers.TestResponse{Status: http.StatusCreated}),
+	}
+
+	uaaEndpoint := testhelpers.CreateEndpoint(
+		"POST",
+		"/Users",
+		func(req *http.Request) bool {
+			expectedBody := `{"userName":"my-user","emails":[{"value":"my-user"}],"password":"my-password","name":{"givenName":"my-user","familyName":"my-user"}}`
+			contentTypeMatches := req.Header.Get("Content-Type") == "application/json"
+			return contentTypeMatches && testhelpers.RequestBodyMatcher(expectedBody)(req)
+		},
+		testhelpers.TestResponse{Status: http.StatusCreated, Body: `{"id": "my-user-guid"}`},
+	)
+
+	ccServer, uaaServer, repo := createUsersRepo(ccEndpoints, uaaEndpoint)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	apiResponse := repo.Create("my-user", "my-password")
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.True(t, ccUserStatus.Called)
+}
+
+func TestDeleteUser(t *testing.T) {
+	ccEndpoint, ccStatus := deleteUserEndpoint("/v2/users/my-user-guid")
+	uaaEndpoint, uaaStatus := deleteUserEndpoint("/Users/my-user-guid")
+
+	ccServer, uaaServer, repo := createUsersRepo(map[string]http.HandlerFunc{"/v2/users/my-user-guid": ccEndpoint}, uaaEndpoint)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	apiResponse := repo.Delete(cf.User{Username: "my-user", Guid: "my-user-guid"})
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.True(t, ccStatus.Called)
+	assert.True(t, uaaStatus.Called)
+}
+
+func TestSetOrgRole(t *testing.T) {
+	orgUserEndpoint, orgUserStatus := roleEndpoint("PUT", "/v2/organizations/my-org-guid/users/my-user-guid")
+	managerEndpoint, managerStatus := roleEndpoint("PUT", "/v2/organizations/my-org-guid/managers/my-user-guid")
+
+	ccServer, uaaServer, repo := createUsersRepo(map[string]http.HandlerFunc{
+		"/v2/organizations/my-org-guid/users/my-user-guid":    orgUserEndpoint,
+		"/v2/organizations/my-org-guid/managers/my-user-guid": managerEndpoint,
+	}, nil)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	apiResponse := repo.SetOrgRole(cf.User{Guid: "my-user-guid"}, cf.Organization{Guid: "my-org-guid"}, cf.ORG_MANAGER)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.True(t, orgUserStatus.Called)
+	assert.True(t, managerStatus.Called)
+}
+
+func TestUnsetOrgRole(t *testing.T) {
+	auditorEndpoint, auditorStatus := roleEndpoint("DELETE", "/v2/organizations/my-org-guid/auditors/my-user-guid")
+
+	ccServer, uaaServer, repo := createUsersRepo(map[string]http.HandlerFunc{
+		"/v2/organizations/my-org-guid/auditors/my-user-guid": auditorEndpoint,
+	}, nil)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	apiResponse := repo.UnsetOrgRole(cf.User{Guid: "my-user-guid"}, cf.Organization{Guid: "my-org-guid"}, cf.ORG_AUDITOR)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.True(t, auditorStatus.Called)
+}
+
+func TestSetSpaceRole(t *testing.T) {
+	orgUserEndpoint, orgUserStatus := roleEndpoint("PUT", "/v2/organizations/my-org-guid/users/my-user-guid")
+	developerEndpoint, developerStatus := roleEndpoint("PUT", "/v2/spaces/my-space-guid/developers/my-user-guid")
+
+	ccServer, uaaServer, repo := createUsersRepo(map[string]http.HandlerFunc{
+		"/v2/organizations/my-org-guid/users/my-user-guid": orgUserEndpoint,
+		"/v2/spaces/my-space-guid/developers/my-user-guid": developerEndpoint,
+	}, nil)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	space := cf.Space{Guid: "my-space-guid", Organization: cf.Organization{Guid: "my-org-guid"}}
+	apiResponse := repo.SetSpaceRole(cf.User{Guid: "my-user-guid"}, space, cf.SPACE_DEVELOPER)
+	assert.True(t, apiResponse.IsSuccessful())
+	assert.True(t, orgUserStatus.Called)
+	assert.True(t, developerStatus.Called)
+}
+
+func TestSetOrgRoleWithInvalidRole(t *testing.T) {
+	ccServer, uaaServer, repo := createUsersRepo(nil, nil)
+	defer ccServer.Close()
+	defer uaaServer.Close()
+
+	apiResponse := repo.SetOrgRole(cf.User{Guid: "my-user-guid"}, cf.Organization{Guid: "my-org-guid"}, cf.SPACE_DEVELOPER)
+	assert.True(t, apiResponse.IsError())
+	assert.Contains(t, apiResponse.Message, "Invalid Role")
+}
+
+func roleEndpoint(method, path string) (hf http.HandlerFunc, status *testhelpers.RequestStatus) {
+	status = &testhelpers.RequestStatus{}
+	hf = testhelpers.CreateEndpoint(method, path, testhelpers.EndpointCalledMatcher(status), testhelpers.TestResponse{Status: http.StatusCreated})
+	return
+}
+
+func deleteUserEndpoint(path string) (hf http.HandlerFunc, status *testhelpers.RequestStatus) {
+	status = &testhelpers.RequestStatus{}
+	hf = testhelpers.CreateEndpoint("DELETE", path, testhelpers.EndpointCalledMatcher(status), testhelpers.TestResponse{Status: http.StatusOK})
+	return
+}
+
+func createUsersRepo(ccEndpoints map[string]http.HandlerFunc, uaaEndpoint http.HandlerFunc) (ccServer *httptest.Server, uaaServer *httptest.Server, repo UserRepository) {
+	if uaaEndpoint == nil {
+		uaaEndpoint = func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	uaaServer = httptest.NewTLSServer(uaaEndpoint)
+
+	infoEndpoint := testhelpers.CreateEndpoint("GET", "/info", nil, testhelpers.TestResponse{
+		Status: http.StatusOK,
+		Body:   fmt.Sprintf(`{"token_endpoint": "%s"}`, uaaServer.URL),
+	})
+
+	ccServer = httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/info" {
+			infoEndpoint(writer, request)
+			return
+		}
+
+		endpoint, found := ccEndpoints[request.URL.Path]
+		if !found {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		endpoint(writer, request)
+	}))
+
+	config := &configuration.Configuration{
+		AccessToken: "BEARER my_access_token",
+		Target:      ccServer.URL,
+	}
+
+	ccGateway := net.NewCloudControllerGateway()
+	ccGateway.SetTrustedCerts(ccServer.TLS.Certificates)
+	uaaGateway := net.NewUAAGateway()
+	uaaGateway.SetTrustedCerts(uaaServer.TLS.Certificates)
+
+	repo = NewCloudControllerUserRepository(config, uaaGateway, ccGateway)
+	return
+}
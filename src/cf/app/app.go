@@ -17,11 +17,19 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 	app.Name = cf.Name
 	app.Usage = cf.Usage
 	app.Version = cf.Version
+	app.Flags = []cli.Flag{
+		cli.StringFlag{"profile", "", "Use this profile instead of the active one"},
+		cli.StringFlag{"output", "", "Print results of read commands as json or yaml"},
+	}
 	app.Commands = []cli.Command{
 		{
 			Name:        "api",
 			Description: "Set or view target api url",
-			Usage:       fmt.Sprintf("%s api [URL]", cf.Name),
+			Usage:       fmt.Sprintf("%s api [URL] [--skip-ssl-validation] [--ca-cert PATH]", cf.Name),
+			Flags: []cli.Flag{
+				cli.BoolFlag{"skip-ssl-validation", "Do not verify the SSL certificate of the API endpoint (insecure)"},
+				cli.StringFlag{"ca-cert", "", "PEM file with additional CA certificates to trust for this target"},
+			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("api")
 				cmdRunner.Run(cmd, c)
@@ -30,7 +38,11 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 		{
 			Name:        "app",
 			Description: "Display health and status for app",
-			Usage:       fmt.Sprintf("%s app APP", cf.Name),
+			Usage:       fmt.Sprintf("%s app APP [--watch] [-i INDEX]", cf.Name),
+			Flags: []cli.Flag{
+				cli.BoolFlag{"watch", "Refresh the status every few seconds until interrupted"},
+				cli.IntFlag{"i", -1, "Also show the crashes of the instance with this index"},
+			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("app")
 				cmdRunner.Run(cmd, c)
@@ -66,6 +78,30 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "create-profile",
+			Description: "Create a profile with its own target and login",
+			Usage:       fmt.Sprintf("%s create-profile PROFILE", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("create-profile")
+				cmdRunner.Run(cmd, c)
+			},
+		},
+		{
+			Name:        "create-quota",
+			Description: "Define a new resource quota",
+			Usage:       fmt.Sprintf("%s create-quota QUOTA [-m MEMORY] [-s SERVICES] [-r ROUTES] [--allow-paid-service-plans]", cf.Name),
+			Flags: []cli.Flag{
+				cli.StringFlag{"m", "", "Total amount of memory (e.g. 1024M, 1G, 10G)"},
+				cli.StringFlag{"s", "", "Total number of service instances"},
+				cli.StringFlag{"r", "", "Total number of routes"},
+				cli.BoolFlag{"allow-paid-service-plans", "Can provision instances of paid service plans"},
+			},
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("create-quota")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "create-service",
 			ShortName:   "cs",
@@ -89,6 +125,15 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "create-user",
+			Description: "Create a new user",
+			Usage:       fmt.Sprintf("%s create-user USERNAME PASSWORD", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("create-user")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "create-user-provided-service",
 			ShortName:   "cups",
@@ -138,6 +183,30 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "delete-profile",
+			Description: "Delete a profile",
+			Usage:       fmt.Sprintf("%s delete-profile PROFILE [-f]", cf.Name),
+			Flags: []cli.Flag{
+				cli.BoolFlag{"f", "Force deletion without confirmation"},
+			},
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("delete-profile")
+				cmdRunner.Run(cmd, c)
+			},
+		},
+		{
+			Name:        "delete-quota",
+			Description: "Delete a quota",
+			Usage:       fmt.Sprintf("%s delete-quota QUOTA [-f]", cf.Name),
+			Flags: []cli.Flag{
+				cli.BoolFlag{"f", "Force deletion without confirmation"},
+			},
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("delete-quota")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "delete-service",
 			ShortName:   "ds",
@@ -160,6 +229,18 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "delete-user",
+			Description: "Delete a user",
+			Usage:       fmt.Sprintf("%s delete-user USERNAME [-f]", cf.Name),
+			Flags: []cli.Flag{
+				cli.BoolFlag{"f", "Force deletion without confirmation"},
+			},
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("delete-user")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "domains",
 			Description: "List domains in the target org",
@@ -172,8 +253,12 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 		{
 			Name:        "env",
 			ShortName:   "e",
-			Description: "Show all env variables for an app",
-			Usage:       fmt.Sprintf("%s env APP", cf.Name),
+			Description: "Show all env variables for an app, including the system-provided ones",
+			Usage:       fmt.Sprintf("%s env APP [--show-secrets] [--export FILE]", cf.Name),
+			Flags: []cli.Flag{
+				cli.BoolFlag{"show-secrets", "Show the values that look like secrets instead of masking them"},
+				cli.StringFlag{"export", "", "Write the user-provided env variables to FILE in .env format"},
+			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("env")
 				cmdRunner.Run(cmd, c)
@@ -183,7 +268,13 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 			Name:        "files",
 			ShortName:   "f",
 			Description: "Print out a list of files in a directory or the contents of a specific file",
-			Usage:       fmt.Sprintf("%s files APP [PATH]", cf.Name),
+			Usage: fmt.Sprintf("%s files APP [PATH] [-i INDEX] [--download LOCAL]\n\n", cf.Name) +
+				"TIP:\n" +
+				fmt.Sprintf("   Use '%s files APP PATH --download LOCAL' to copy a file, or a whole directory such as a heap dump directory, to LOCAL", cf.Name),
+			Flags: []cli.Flag{
+				cli.IntFlag{"i", 0, "Index of the instance whose files to show"},
+				cli.StringFlag{"download", "", "Save the file or directory at PATH to LOCAL instead of printing it"},
+			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("files")
 				cmdRunner.Run(cmd, c)
@@ -218,9 +309,25 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 		{
 			Name:        "logs",
 			Description: "Tail or show recent logs for an app",
-			Usage:       fmt.Sprintf("%s logs APP", cf.Name),
+			Usage: fmt.Sprintf("%s logs APP [--recent] [--source SOURCES] [--instance INDEX] [--stream STREAM] [--grep REGEX] [--json]\n", cf.Name) +
+				fmt.Sprintf("   %s logs APP --recent [--since TIME] [--until TIME]\n", cf.Name) +
+				fmt.Sprintf("   %s logs APP1 APP2... [--recent]\n", cf.Name) +
+				fmt.Sprintf("   %s logs --space [--recent]\n\n", cf.Name) +
+				"   Several apps, or every app in the targeted space with --space, are shown together,\n" +
+				"   each prefixed with its name in its own color and sorted by time.\n" +
+				"   SOURCES is a comma separated list of app, router, staging, dea, cf and uaa.\n" +
+				"   Staging output comes from the DEA, so staging and dea show the same messages.\n" +
+				"   TIME is a time such as 2014-01-31T15:04:05Z or a duration such as 10m for that long ago.",
 			Flags: []cli.Flag{
 				cli.BoolFlag{"recent", "dump recent logs instead of tailing"},
+				cli.BoolFlag{"space", "show the logs of every app in the targeted space"},
+				cli.StringFlag{"source", "", "only show messages from these sources"},
+				cli.IntFlag{"instance", -1, "only show messages from the app instance with this index"},
+				cli.StringFlag{"stream", "", "only show messages written to stdout or stderr"},
+				cli.StringFlag{"grep", "", "only show messages matching this regular expression"},
+				cli.BoolFlag{"json", "print each message as a JSON object on its own line"},
+				cli.StringFlag{"since", "", "only show recent messages logged after this time"},
+				cli.StringFlag{"until", "", "only show recent messages logged before this time"},
 			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("logs")
@@ -267,6 +374,15 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "org-users",
+			Description: "Show org users by role",
+			Usage:       fmt.Sprintf("%s org-users ORG", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("org-users")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "orgs",
 			ShortName:   "o",
@@ -287,24 +403,59 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "profiles",
+			Description: "List all profiles",
+			Usage:       fmt.Sprintf("%s profiles", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("profiles")
+				cmdRunner.Run(cmd, c)
+			},
+		},
+		{
+			Name:        "quota",
+			Description: "Show quota info",
+			Usage:       fmt.Sprintf("%s quota QUOTA", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("quota")
+				cmdRunner.Run(cmd, c)
+			},
+		},
+		{
+			Name:        "quotas",
+			Description: "List available usage quotas",
+			Usage:       fmt.Sprintf("%s quotas", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("quotas")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "push",
 			ShortName:   "p",
 			Description: "Push a new app or sync changes to an existing app",
-			Usage: fmt.Sprintf("%s push APP [-d DOMAIN] [-n HOST] [-i NUM_INSTANCES]\n", cf.Name) +
+			Usage: fmt.Sprintf("%s push [APP] [-d DOMAIN] [-n HOST] [-i NUM_INSTANCES]\n", cf.Name) +
 				"               [-m MEMORY] [-b URL] [--no-[re]start] [-p PATH]\n" +
-				"               [-s STACK] [-c COMMAND]",
+				"               [-s STACK] [-c COMMAND] [-f MANIFEST_PATH] [--blue-green [--keep-old]]\n" +
+				"               [--dry-run-files]\n\n" +
+				"TIP:\n" +
+				"   Omit APP to push every app in the manifest. Flags override values from the manifest\n" +
+				"   Use --blue-green to start the new version next to the running one and switch its routes over without downtime",
 			Flags: []cli.Flag{
 				cli.StringFlag{"d", "", "Domain (for example: example.com)"},
 				cli.StringFlag{"n", "", "Hostname (for example: my-subdomain)"},
-				cli.IntFlag{"i", 1, "Number of instances"},
-				cli.StringFlag{"m", "128", "Memory limit (for example: 256, 1G, 1024M)"},
+				cli.IntFlag{"i", 0, "Number of instances (default: 1)"},
+				cli.StringFlag{"m", "", "Memory limit (for example: 256, 1G, 1024M) (default: 128)"},
 				cli.StringFlag{"b", "", "Custom buildpack URL (for example: https://github.com/heroku/heroku-buildpack-play.git)"},
 				cli.BoolFlag{"no-start", "Do not start an app after pushing"},
 				cli.BoolFlag{"no-restart", "Do not restart an app after pushing"},
 				cli.StringFlag{"p", "", "Path of app directory or zip file"},
 				cli.StringFlag{"s", "", "Stack to use"},
 				cli.StringFlag{"c", "", "Startup command"},
+				cli.StringFlag{"f", "", "Path to manifest (default: manifest.yml in the app directory)"},
+				cli.BoolFlag{"blue-green", "Push to a temporary app and switch routes once all of its instances are running"},
+				cli.BoolFlag{"keep-old", "With --blue-green, stop and rename the previous version to APP-old instead of deleting it"},
+				cli.BoolFlag{"dry-run-files", "List the files that would be pushed after applying .cfignore, without pushing"},
 			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("push")
@@ -378,6 +529,15 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "restart-app-instance",
+			Description: "Restart a single instance of an app",
+			Usage:       fmt.Sprintf("%s restart-app-instance APP INDEX", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("restart-app-instance")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "routes",
 			ShortName:   "r",
@@ -391,11 +551,15 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 		{
 			Name:        "scale",
 			Description: "Change the disk quota, instance count, and memory limit for an app",
-			Usage:       fmt.Sprintf("%s scale APP -d DISK -i INSTANCES -m MEMORY", cf.Name),
+			Usage: fmt.Sprintf("%s scale APP [-d DISK] [-i INSTANCES] [-m MEMORY] [-f]\n\n", cf.Name) +
+				"TIP:\n" +
+				fmt.Sprintf("   Use '%s scale APP' without flags to show the current scale of the app.\n", cf.Name) +
+				"   Changing the disk quota or memory limit of a started app restarts it, while instances are changed live.",
 			Flags: []cli.Flag{
-				cli.StringFlag{"d", "", "disk quota"},
-				cli.IntFlag{"i", 0, "number of instances"},
-				cli.StringFlag{"m", "", "memory limit"},
+				cli.StringFlag{"d", "", "disk quota, like 512M or 2G"},
+				cli.IntFlag{"i", -1, "number of instances"},
+				cli.StringFlag{"m", "", "memory limit, like 256M or 1G"},
+				cli.BoolFlag{"f", "Restart the app without confirmation"},
 			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("scale")
@@ -425,12 +589,35 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 			Name:        "set-env",
 			ShortName:   "se",
 			Description: "Set an env variable for an app",
-			Usage:       fmt.Sprintf("%s set-env APP NAME VALUE", cf.Name),
+			Usage: fmt.Sprintf("%s set-env APP NAME VALUE\n", cf.Name) +
+				fmt.Sprintf("   %s set-env APP --from-file FILE [--replace]\n", cf.Name) +
+				fmt.Sprintf("   %s set-env APP --from-json FILE [--replace]\n\n", cf.Name) +
+				"TIP:\n" +
+				"   All variables in FILE are applied in a single update. Use '--replace' to also remove the variables FILE does not set",
+			Flags: []cli.Flag{
+				cli.StringFlag{"from-file", "", "Set the variables of a .env file, one NAME=VALUE per line"},
+				cli.StringFlag{"from-json", "", "Set the variables of a JSON file holding an object of names and values"},
+				cli.BoolFlag{"replace", "Remove the variables the file does not set"},
+			},
 			Action: func(c *cli.Context) {
 				cmd, _ := cmdFactory.GetByCmdName("set-env")
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "set-org-role",
+			Description: "Assign an org role to a user",
+			Usage: fmt.Sprintf("%s set-org-role USERNAME ORG ROLE", cf.Name) +
+				"\n\n" +
+				"ROLES:\n" +
+				"   OrgManager - Invite and manage users, select and change plans, and set spending limits\n" +
+				"   BillingManager - Create and manage the billing account and payment info\n" +
+				"   OrgAuditor - Read-only access to org info and reports\n",
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("set-org-role")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "set-quota",
 			Description: "Define the quota for an org",
@@ -442,6 +629,20 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "set-space-role",
+			Description: "Assign a space role to a user",
+			Usage: fmt.Sprintf("%s set-space-role USERNAME ORG SPACE ROLE", cf.Name) +
+				"\n\n" +
+				"ROLES:\n" +
+				"   SpaceManager - Invite and manage users, and enable features for a given space\n" +
+				"   SpaceDeveloper - Create and manage apps and services, and see logs and reports\n" +
+				"   SpaceAuditor - View logs, reports, and settings on this space\n",
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("set-space-role")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "space",
 			Description: "Show target space's info",
@@ -451,6 +652,15 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "space-users",
+			Description: "Show space users by role",
+			Usage:       fmt.Sprintf("%s space-users ORG SPACE", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("space-users")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "spaces",
 			Description: "List all spaces in an org",
@@ -489,6 +699,15 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "switch-profile",
+			Description: "Make a profile the active one",
+			Usage:       fmt.Sprintf("%s switch-profile PROFILE", cf.Name),
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("switch-profile")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 		{
 			Name:        "target",
 			ShortName:   "t",
@@ -543,6 +762,67 @@ func NewApp(cmdFactory commands.Factory, reqFactory requirements.Factory) (app *
 				cmdRunner.Run(cmd, c)
 			},
 		},
+		{
+			Name:        "unset-org-role",
+			Description: "Remove an org role from a user",
+			Usage: fmt.Sprintf("%s unset-org-role USERNAME ORG ROLE", cf.Name) +
+				"\n\n" +
+				"ROLES:\n" +
+				"   OrgManager - Invite and manage users, select and change plans, and set spending limits\n" +
+				"   BillingManager - Create and manage the billing account and payment info\n" +
+				"   OrgAuditor - Read-only access to org info and reports\n",
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("unset-org-role")
+				cmdRunner.Run(cmd, c)
+			},
+		},
+		{
+			Name:        "unset-space-role",
+			Description: "Remove a space role from a user",
+			Usage: fmt.Sprintf("%s unset-space-role USERNAME ORG SPACE ROLE", cf.Name) +
+				"\n\n" +
+				"ROLES:\n" +
+				"   SpaceManager - Invite and manage users, and enable features for a given space\n" +
+				"   SpaceDeveloper - Create and manage apps and services, and see logs and reports\n" +
+				"   SpaceAuditor - View logs, reports, and settings on this space\n",
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("unset-space-role")
+				cmdRunner.Run(cmd, c)
+			},
+		},
+		{
+			Name:        "update-quota",
+			Description: "Update an existing resource quota",
+			Usage:       fmt.Sprintf("%s update-quota QUOTA [-n NEW_NAME] [-m MEMORY] [-s SERVICES] [-r ROUTES] [--allow-paid-service-plans | --disallow-paid-service-plans]", cf.Name),
+			Flags: []cli.Flag{
+				cli.StringFlag{"n", "", "New name"},
+				cli.StringFlag{"m", "", "Total amount of memory (e.g. 1024M, 1G, 10G)"},
+				cli.StringFlag{"s", "", "Total number of service instances"},
+				cli.StringFlag{"r", "", "Total number of routes"},
+				cli.BoolFlag{"allow-paid-service-plans", "Can provision instances of paid service plans"},
+				cli.BoolFlag{"disallow-paid-service-plans", "Can not provision instances of paid service plans"},
+			},
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("update-quota")
+				cmdRunner.Run(cmd, c)
+			},
+		},
+		{
+			Name:        "usage",
+			Description: "Show memory, disk and service usage by space and org against the org quota",
+			Usage: fmt.Sprintf("%s usage [--org ORG | --all-orgs] [--csv]\n\n", cf.Name) +
+				"TIP:\n" +
+				"   Without --org or --all-orgs the targeted org is used",
+			Flags: []cli.Flag{
+				cli.StringFlag{"org", "", "Show usage for this org instead of the targeted one"},
+				cli.BoolFlag{"all-orgs", "Show a total for every org"},
+				cli.BoolFlag{"csv", "Print the report as CSV"},
+			},
+			Action: func(c *cli.Context) {
+				cmd, _ := cmdFactory.GetByCmdName("usage")
+				cmdRunner.Run(cmd, c)
+			},
+		},
 	}
 	return
 }
@@ -40,13 +40,19 @@ func TestCommands(t *testing.T) {
 		"apps",
 		"bind-service",
 		"create-org",
+		"create-profile",
+		"create-quota",
 		"create-service",
 		"create-space",
+		"create-user",
 		"create-user-provided-service",
 		"delete",
 		"delete-org",
+		"delete-profile",
+		"delete-quota",
 		"delete-service",
 		"delete-space",
+		"delete-user",
 		"env",
 		"files",
 		"login",
@@ -56,9 +62,13 @@ func TestCommands(t *testing.T) {
 		"map-route",
 		"marketplace",
 		"org",
+		"org-users",
 		"orgs",
 		"passwd",
+		"profiles",
 		"push",
+		"quota",
+		"quotas",
 		"rename",
 		"rename-org",
 		"rename-service",
@@ -66,22 +76,31 @@ func TestCommands(t *testing.T) {
 		"reserve-domain",
 		"reserve-route",
 		"restart",
+		"restart-app-instance",
 		"routes",
 		"scale",
 		"service",
 		"services",
 		"set-env",
+		"set-org-role",
 		"set-quota",
+		"set-space-role",
 		"space",
+		"space-users",
 		"spaces",
 		"stacks",
 		"start",
 		"stop",
+		"switch-profile",
 		"target",
 		"unbind-service",
 		"unmap-domain",
 		"unmap-route",
 		"unset-env",
+		"unset-org-role",
+		"unset-space-role",
+		"update-quota",
+		"usage",
 	}
 
 	for _, cmdName := range availableCmds {
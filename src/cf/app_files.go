@@ -4,87 +4,173 @@ import (
 	"crypto/sha1"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 )
 
+// AppFilesInDir lists the files in dir that are not excluded by .cfignore,
+// with their modes, sizes and SHA1 fingerprints. Files are fingerprinted in
+// parallel since large apps spend most of their time here before anything is
+// sent.
+//
+// Empty directories are listed so they exist once the app is staged. Symlinks
+// that point inside dir are listed as symlinks. Symlinks to files outside of
+// dir are listed as the file they point to, and dangling symlinks or symlinks
+// to directories outside of dir are left out.
 func AppFilesInDir(dir string) (appFiles []AppFile, err error) {
-	err = walkAppFiles(dir, func(fileName string, fullPath string) {
-		fileInfo, err := os.Lstat(fullPath)
-		if err != nil {
-			return
-		}
-		size := fileInfo.Size()
+	return AppFilesInDirWithCache(dir, nil)
+}
 
-		h := sha1.New()
-		fileBytes, err := ioutil.ReadFile(fullPath)
-		if err != nil {
-			return
+// AppFilesInDirWithCache is AppFilesInDir, except that files that did not
+// change since cache was last saved are not fingerprinted again. The cache is
+// updated with the files that were listed.
+func AppFilesInDirWithCache(dir string, cache *ResourceCache) (appFiles []AppFile, err error) {
+	startedAt := time.Now()
+
+	err = walkAppFiles(dir, func(fileName, fullPath string, fileInfo os.FileInfo) {
+		switch {
+		case fileInfo.IsDir():
+			isEmpty, _ := IsDirEmpty(fullPath)
+			if isEmpty {
+				appFiles = append(appFiles, AppFile{Path: fileName, Mode: fileInfo.Mode()})
+			}
+		case fileInfo.Mode()&os.ModeSymlink != 0:
+			appFile, found := symlinkAppFile(dir, fileName, fullPath, fileInfo)
+			if found {
+				appFiles = append(appFiles, appFile)
+			}
+		default:
+			appFiles = append(appFiles, AppFile{Path: fileName, Mode: fileInfo.Mode()})
 		}
+	})
+	if err != nil {
+		return
+	}
 
-		h.Write(fileBytes)
-		sha1Bytes := h.Sum(nil)
-		sha1 := fmt.Sprintf("%x", sha1Bytes)
+	if cache != nil {
+		cache.beginFingerprinting()
+	}
 
-		appFiles = append(appFiles, AppFile{
-			Path: fileName,
-			Sha1: sha1,
-			Size: size,
-		})
-	})
-	return
-}
+	jobs := make(chan int)
+	errs := make(chan error, len(appFiles))
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				if !appFiles[index].Mode.IsRegular() {
+					continue
+				}
+
+				fullPath := filepath.Join(dir, appFiles[index].Path)
+
+				var fingerprintErr error
+				if cache != nil {
+					fingerprintErr = cache.fingerprint(&appFiles[index], fullPath, startedAt)
+				} else {
+					fingerprintErr = fingerprint(&appFiles[index], fullPath)
+				}
+				if fingerprintErr != nil {
+					errs <- fingerprintErr
+				}
+			}
+		}()
+	}
 
-func TempDirForApp(app Application) (dir string) {
-	dir = filepath.Join(os.TempDir(), "cf", app.Guid)
+	for index := range appFiles {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	err = <-errs
+	if cache != nil && err == nil {
+		cache.endFingerprinting()
+	}
 	return
 }
 
-func InitializeDir(dir string) (err error) {
-	err = os.RemoveAll(dir)
+func fingerprint(appFile *AppFile, fullPath string) (err error) {
+	file, err := os.Open(fullPath)
 	if err != nil {
 		return
 	}
-	err = os.MkdirAll(dir, os.ModeDir|os.ModeTemporary|os.ModePerm)
+	defer file.Close()
+
+	h := sha1.New()
+	appFile.Size, err = io.Copy(h, file)
+	if err != nil {
+		return
+	}
+
+	appFile.Sha1 = fmt.Sprintf("%x", h.Sum(nil))
 	return
 }
 
-func CopyFiles(appFiles []AppFile, fromDir, toDir string) (err error) {
+func symlinkAppFile(dir, fileName, fullPath string, fileInfo os.FileInfo) (appFile AppFile, found bool) {
+	target, err := os.Readlink(fullPath)
 	if err != nil {
 		return
 	}
 
-	for _, file := range appFiles {
-		fromPath := filepath.Join(fromDir, file.Path)
-		toPath := filepath.Join(toDir, file.Path)
-		err = copyFile(fromPath, toPath)
-		if err != nil {
-			return
-		}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(fullPath), target)
+	}
+
+	if IsPathInDir(dir, target) {
+		appFile = AppFile{Path: fileName, Mode: fileInfo.Mode()}
+		found = true
+		return
 	}
+
+	targetInfo, err := os.Stat(fullPath)
+	if err != nil || !targetInfo.Mode().IsRegular() {
+		return
+	}
+
+	appFile = AppFile{Path: fileName, Mode: targetInfo.Mode()}
+	found = true
 	return
 }
 
-func copyFile(fromPath, toPath string) (err error) {
-	err = os.MkdirAll(filepath.Dir(toPath), os.ModeDir|os.ModeTemporary|os.ModePerm)
+// IsPathInDir tells whether path is dir or somewhere below it.
+func IsPathInDir(dir, path string) bool {
+	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		return
+		return false
 	}
 
-	src, err := os.Open(fromPath)
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return
+		return false
 	}
-	defer src.Close()
 
-	dst, err := os.Create(toPath)
+	rel, err := filepath.Rel(absDir, absPath)
 	if err != nil {
-		return
+		return false
 	}
-	defer dst.Close()
 
-	_, err = io.Copy(dst, src)
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func TempDirForApp(app Application) (dir string) {
+	dir = filepath.Join(os.TempDir(), "cf", app.Guid)
+	return
+}
+
+func InitializeDir(dir string) (err error) {
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(dir, os.ModeDir|os.ModeTemporary|os.ModePerm)
 	return
 }
 
@@ -93,6 +179,7 @@ func IsDirEmpty(dir string) (isEmpty bool, err error) {
 	if err != nil {
 		return
 	}
+	defer dirFile.Close()
 
 	_, readErr := dirFile.Readdirnames(1)
 	if readErr != nil {
@@ -103,10 +190,14 @@ func IsDirEmpty(dir string) (isEmpty bool, err error) {
 	return
 }
 
-type walkAppFileFunc func(fileName, fullPath string)
+type walkAppFileFunc func(fileName, fullPath string, fileInfo os.FileInfo)
 
+// walkAppFiles calls onEachFile for every file, directory and symlink in dir
+// that is not ignored.
+// Ignored directories are not walked into, so their files cannot be included
+// again by a negated pattern, as with .gitignore.
 func walkAppFiles(dir string, onEachFile walkAppFileFunc) (err error) {
-	exclusions := readCfIgnore(dir)
+	ignore := newCfIgnore()
 
 	walkFunc := func(fullPath string, f os.FileInfo, inErr error) (err error) {
 		err = inErr
@@ -114,17 +205,27 @@ func walkAppFiles(dir string, onEachFile walkAppFileFunc) (err error) {
 			return
 		}
 
-		if f.IsDir() {
+		fileName, _ := filepath.Rel(dir, fullPath)
+		if fileName == "." {
+			if f.IsDir() {
+				err = ignore.readFile(dir, "")
+			}
 			return
 		}
 
-		fileName, _ := filepath.Rel(dir, fullPath)
-		if fileShouldBeIgnored(exclusions, fileName) {
+		slashPath := filepath.ToSlash(fileName)
+		if ignore.ignores(slashPath, f.IsDir()) {
+			if f.IsDir() {
+				err = filepath.SkipDir
+			}
 			return
 		}
 
-		onEachFile(fileName, fullPath)
+		if f.IsDir() {
+			err = ignore.readFile(dir, slashPath)
+		}
 
+		onEachFile(fileName, fullPath, f)
 		return
 	}
 
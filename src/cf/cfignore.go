@@ -0,0 +1,181 @@
+package cf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// defaultIgnorePatterns are never uploaded. They come before the patterns of
+// any .cfignore, so an app can still include them with a negated pattern.
+var defaultIgnorePatterns = []string{
+	".cfignore",
+	".git",
+	".svn",
+	".DS_Store",
+	"_darcs",
+}
+
+// cfIgnore decides which app files are left out of an upload. It follows the
+// rules of .gitignore: the last matching pattern wins, "!" negates a pattern,
+// a trailing "/" only matches directories, a pattern containing a "/" is
+// anchored to the directory of its .cfignore and "**" matches across
+// directories.
+type cfIgnore struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	base    string
+	negated bool
+	dirOnly bool
+	matcher *regexp.Regexp
+}
+
+func newCfIgnore() (ignore *cfIgnore) {
+	ignore = new(cfIgnore)
+	ignore.addPatterns("", defaultIgnorePatterns)
+	return
+}
+
+// readFile adds the patterns of the .cfignore in dir, if there is one. dir is
+// relative to appDir and slash separated.
+func (ignore *cfIgnore) readFile(appDir, dir string) (err error) {
+	contents, err := ioutil.ReadFile(filepath.Join(appDir, filepath.FromSlash(dir), ".cfignore"))
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	ignore.addPatterns(dir, strings.Split(string(contents), "\n"))
+	return
+}
+
+func (ignore *cfIgnore) addPatterns(base string, lines []string) {
+	for _, line := range lines {
+		pattern, ok := parseIgnorePattern(base, line)
+		if ok {
+			ignore.patterns = append(ignore.patterns, pattern)
+		}
+	}
+}
+
+// ignores tells whether path, which is relative to the app directory and
+// slash separated, should be left out.
+func (ignore *cfIgnore) ignores(path string, isDir bool) (ignored bool) {
+	for _, pattern := range ignore.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+
+		relativePath := path
+		if pattern.base != "" {
+			if !strings.HasPrefix(path, pattern.base+"/") {
+				continue
+			}
+			relativePath = path[len(pattern.base)+1:]
+		}
+
+		if pattern.matcher.MatchString(relativePath) {
+			ignored = !pattern.negated
+		}
+	}
+	return
+}
+
+func parseIgnorePattern(base, line string) (pattern ignorePattern, ok bool) {
+	line = strings.TrimRight(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	if strings.HasPrefix(line, "!") {
+		pattern.negated = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if line == "" {
+		return
+	}
+
+	expr := globToRegexp(strings.TrimPrefix(line, "/"))
+	if strings.Contains(line, "/") {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+
+	matcher, err := regexp.Compile(expr)
+	if err != nil {
+		return
+	}
+
+	pattern.base = base
+	pattern.matcher = matcher
+	ok = true
+	return
+}
+
+func globToRegexp(glob string) string {
+	expr := new(bytes.Buffer)
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				startsSegment := i == 0 || glob[i-1] == '/'
+				if startsSegment && i+2 < len(glob) && glob[i+2] == '/' {
+					// "**/" matches zero or more directories
+					expr.WriteString("(?:.*/)?")
+					i += 2
+				} else {
+					expr.WriteString(".*")
+					i++
+				}
+				continue
+			}
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return expr.String()
+}
@@ -0,0 +1,132 @@
+package cf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func ignoreWithPatterns(patterns ...string) (ignore *cfIgnore) {
+	ignore = new(cfIgnore)
+	ignore.addPatterns("", patterns)
+	return
+}
+
+func TestCfIgnoreUnanchoredPatternsMatchAtAnyDepth(t *testing.T) {
+	ignore := ignoreWithPatterns("*.log", "node_modules")
+
+	assert.True(t, ignore.ignores("dev.log", false))
+	assert.True(t, ignore.ignores("logs/dev.log", false))
+	assert.True(t, ignore.ignores("node_modules", true))
+	assert.True(t, ignore.ignores("client/node_modules", true))
+	assert.False(t, ignore.ignores("dev.log.txt", false))
+	assert.False(t, ignore.ignores("node_modules_backup", true))
+}
+
+func TestCfIgnoreAnchoredPatterns(t *testing.T) {
+	ignore := ignoreWithPatterns("/tmp", "spec/fixtures")
+
+	assert.True(t, ignore.ignores("tmp", true))
+	assert.False(t, ignore.ignores("app/tmp", true))
+	assert.True(t, ignore.ignores("spec/fixtures", true))
+	assert.False(t, ignore.ignores("app/spec/fixtures", true))
+}
+
+func TestCfIgnoreDoubleStarPatterns(t *testing.T) {
+	ignore := ignoreWithPatterns("**/cache", "docs/**", "a/**/b.txt")
+
+	assert.True(t, ignore.ignores("cache", true))
+	assert.True(t, ignore.ignores("x/y/cache", true))
+	assert.True(t, ignore.ignores("docs/index.html", false))
+	assert.True(t, ignore.ignores("docs/api/index.html", false))
+	assert.False(t, ignore.ignores("docs", true))
+	assert.True(t, ignore.ignores("a/b.txt", false))
+	assert.True(t, ignore.ignores("a/x/y/b.txt", false))
+	assert.False(t, ignore.ignores("c/a/b.txt", false))
+}
+
+func TestCfIgnoreNegatedPatterns(t *testing.T) {
+	ignore := ignoreWithPatterns("*.log", "!important.log")
+
+	assert.True(t, ignore.ignores("dev.log", false))
+	assert.False(t, ignore.ignores("important.log", false))
+	assert.False(t, ignore.ignores("logs/important.log", false))
+}
+
+func TestCfIgnoreDirectoryOnlyPatterns(t *testing.T) {
+	ignore := ignoreWithPatterns("build/")
+
+	assert.True(t, ignore.ignores("build", true))
+	assert.False(t, ignore.ignores("build", false))
+}
+
+func TestCfIgnoreCommentsBlankLinesAndEscapes(t *testing.T) {
+	ignore := ignoreWithPatterns("# a comment", "", "   ", `\#notes`, `\!bang`, "file?.txt", "[abc].js", "[!x].css")
+
+	assert.Equal(t, len(ignore.patterns), 5)
+	assert.True(t, ignore.ignores("#notes", false))
+	assert.True(t, ignore.ignores("!bang", false))
+	assert.True(t, ignore.ignores("file1.txt", false))
+	assert.False(t, ignore.ignores("file10.txt", false))
+	assert.True(t, ignore.ignores("a.js", false))
+	assert.False(t, ignore.ignores("d.js", false))
+	assert.True(t, ignore.ignores("y.css", false))
+	assert.False(t, ignore.ignores("x.css", false))
+}
+
+func TestCfIgnoreDefaults(t *testing.T) {
+	ignore := newCfIgnore()
+
+	assert.True(t, ignore.ignores(".git", true))
+	assert.True(t, ignore.ignores("vendor/lib/.git", true))
+	assert.True(t, ignore.ignores(".svn", true))
+	assert.True(t, ignore.ignores("images/.DS_Store", false))
+	assert.True(t, ignore.ignores("_darcs", true))
+	assert.True(t, ignore.ignores(".cfignore", false))
+	assert.False(t, ignore.ignores(".gitignore", false))
+}
+
+func TestAppFilesInDirWithNestedCfIgnoreFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfignore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		".cfignore":                  "*.log\nnode_modules/\n/secret.txt\n",
+		"app.js":                     "app",
+		"secret.txt":                 "secret",
+		"debug.log":                  "log",
+		"node_modules/dep/index.js":  "dep",
+		".git/config":                "git",
+		"lib/.cfignore":              "!keep.log\nfixtures/\n",
+		"lib/keep.log":               "keep",
+		"lib/other.log":              "other",
+		"lib/secret.txt":             "not anchored here",
+		"lib/fixtures/big.bin":       "big",
+		"spec/fixtures/expected.txt": "outside of lib",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	appFiles, err := AppFilesInDir(dir)
+	assert.NoError(t, err)
+
+	paths := []string{}
+	for _, file := range appFiles {
+		paths = append(paths, filepath.ToSlash(file.Path))
+	}
+	sort.Strings(paths)
+
+	assert.Equal(t, paths, []string{
+		"app.js",
+		"lib/keep.log",
+		"lib/secret.txt",
+		"spec/fixtures/expected.txt",
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"cf/requirements"
 	"cf/terminal"
 	"github.com/codegangsta/cli"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -32,7 +34,7 @@ func (cmd Api) Run(c *cli.Context) {
 		return
 	}
 
-	cmd.setNewApiEndpoint(c.Args()[0])
+	cmd.setNewApiEndpoint(c.Args()[0], c.Bool("skip-ssl-validation"), c.String("ca-cert"))
 }
 
 func (cmd Api) showApiEndpoint() {
@@ -41,12 +43,30 @@ func (cmd Api) showApiEndpoint() {
 		terminal.EntityNameColor(cmd.config.Target),
 		terminal.EntityNameColor(cmd.config.ApiVersion),
 	)
+
+	if cmd.config.SSLDisabled {
+		cmd.ui.Say(terminal.WarningColor("SSL certificate validation is disabled for this endpoint"))
+	}
 }
 
-func (cmd Api) setNewApiEndpoint(endpoint string) {
+func (cmd Api) setNewApiEndpoint(endpoint string, sslDisabled bool, caCertFile string) {
 	cmd.ui.Say("Setting api endpoint to %s...", terminal.EntityNameColor(endpoint))
 
-	apiResponse := cmd.endpointRepo.UpdateEndpoint(endpoint)
+	// cf may run from another directory next time
+	if caCertFile != "" {
+		absCACertFile, err := filepath.Abs(caCertFile)
+		if err == nil {
+			_, err = os.Stat(absCACertFile)
+		}
+		if err != nil {
+			cmd.ui.Failed("Could not read CA certificate file %s: %s", caCertFile, err.Error())
+			return
+		}
+		caCertFile = absCACertFile
+	}
+
+	// the endpoint repository saves these along with the new target
+	apiResponse := cmd.endpointRepo.UpdateEndpoint(endpoint, sslDisabled, caCertFile)
 	if apiResponse.IsNotSuccessful() {
 		cmd.ui.Failed(apiResponse.Message)
 		return
@@ -4,6 +4,10 @@ import (
 	. "cf/commands"
 	"cf/configuration"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testhelpers"
 	"testing"
 )
@@ -33,6 +37,68 @@ func TestApiWhenChangingTheEndpoint(t *testing.T) {
 	assert.Contains(t, ui.Outputs[1], "OK")
 }
 
+func TestApiWithSkipSSLValidation(t *testing.T) {
+	caCertFile, err := ioutil.TempFile("", "ca.pem")
+	assert.NoError(t, err)
+	caCertFile.Close()
+	defer os.Remove(caCertFile.Name())
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	relativeCACertFile, err := filepath.Rel(wd, caCertFile.Name())
+	assert.NoError(t, err)
+
+	endpointRepo := &testhelpers.FakeEndpointRepo{}
+	config := &configuration.Configuration{}
+
+	ui := callApi([]string{"--skip-ssl-validation", "--ca-cert", relativeCACertFile, "https://example.com"}, config, endpointRepo)
+
+	assert.Equal(t, endpointRepo.UpdateEndpointEndpoint, "https://example.com")
+	assert.True(t, endpointRepo.UpdateEndpointSSLDisabled)
+	assert.Equal(t, endpointRepo.UpdateEndpointCACertFile, caCertFile.Name())
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	callApi([]string{"https://other.example.com"}, config, endpointRepo)
+
+	assert.False(t, endpointRepo.UpdateEndpointSSLDisabled)
+	assert.Equal(t, endpointRepo.UpdateEndpointCACertFile, "")
+}
+
+func TestApiWithAMissingCACertFile(t *testing.T) {
+	endpointRepo := &testhelpers.FakeEndpointRepo{}
+	config := &configuration.Configuration{}
+
+	ui := callApi([]string{"--ca-cert", "/path/to/missing-ca.pem", "https://example.com"}, config, endpointRepo)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "/path/to/missing-ca.pem")
+	assert.Equal(t, endpointRepo.UpdateEndpointEndpoint, "")
+}
+
+func TestApiWhenTheEndpointFails(t *testing.T) {
+	endpointRepo := &testhelpers.FakeEndpointRepo{UpdateEndpointErr: true}
+	config := &configuration.Configuration{}
+
+	ui := callApi([]string{"--skip-ssl-validation", "https://example.com"}, config, endpointRepo)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.False(t, config.SSLDisabled)
+}
+
+func TestApiShowsWhenSSLValidationIsDisabled(t *testing.T) {
+	endpointRepo := &testhelpers.FakeEndpointRepo{}
+	config := &configuration.Configuration{Target: "https://example.com", SSLDisabled: true}
+
+	ui := callApi([]string{}, config, endpointRepo)
+
+	assert.Contains(t, strings.Join(ui.Outputs, "\n"), "SSL certificate validation is disabled")
+
+	config.SSLDisabled = false
+	ui = callApi([]string{}, config, endpointRepo)
+
+	assert.NotContains(t, strings.Join(ui.Outputs, "\n"), "SSL certificate validation is disabled")
+}
+
 func callApi(args []string, config *configuration.Configuration, endpointRepo *testhelpers.FakeEndpointRepo) (ui *testhelpers.FakeUI) {
 	ui = new(testhelpers.FakeUI)
 
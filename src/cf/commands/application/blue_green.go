@@ -0,0 +1,338 @@
+package application
+
+import (
+	"cf"
+	"cf/api"
+	"cf/manifest"
+	"cf/net"
+	"cf/terminal"
+	"fmt"
+	"time"
+)
+
+// blueGreenPush deploys a new version of oldApp without taking it down. The
+// new version is pushed as a separate app on a temporary route and only
+// receives the routes of oldApp once all of its instances are running. If
+// anything fails before it has taken over, it is deleted and the routes it
+// took are given back, leaving oldApp as it was.
+//
+// A -new app left over from an earlier push that did not finish is deleted
+// first, as is a -old app kept by an earlier push when the old version is
+// kept again.
+//
+// The temporary route is unmapped but not deleted, so that later blue/green
+// pushes of the same app can reuse it.
+func (cmd Push) blueGreenPush(oldApp cf.Application, appParams manifest.Application, keepOld bool) (apiResponse net.ApiResponse) {
+	newName := oldApp.Name + "-new"
+	oldName := oldApp.Name + "-old"
+
+	apiResponse = cmd.deleteLeftoverApp(newName)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	if keepOld {
+		apiResponse = cmd.deleteLeftoverApp(oldName)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+	}
+
+	// Reporting a failure ends cf, so failures are held back until the new
+	// version is rolled back
+	ui := &heldFailureUI{UI: cmd.ui}
+	steps := cmd
+	steps.ui = ui
+
+	newParams := defaultAppParams.Merge(appParamsFromApp(oldApp)).Merge(appParams)
+	newParams.Name = newName
+
+	newApp, takenRoutes, apiResponse := steps.deployNewVersion(oldApp, newParams, appParams)
+	if apiResponse.IsNotSuccessful() {
+		if newApp.Guid != "" {
+			cmd.rollback(oldApp, newApp, takenRoutes)
+		}
+		ui.report(cmd.ui, apiResponse)
+		return
+	}
+
+	if keepOld {
+		cmd.stopper.ApplicationStop(oldApp)
+
+		cmd.ui.Say("Renaming %s to %s...", terminal.EntityNameColor(oldApp.Name), terminal.EntityNameColor(oldName))
+		apiResponse = cmd.appRepo.Rename(oldApp, oldName)
+	} else {
+		cmd.ui.Say("Deleting %s...", terminal.EntityNameColor(oldApp.Name))
+		apiResponse = cmd.appRepo.Delete(oldApp)
+	}
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+	cmd.ui.Ok()
+
+	cmd.ui.Say("Renaming %s to %s...", terminal.EntityNameColor(newApp.Name), terminal.EntityNameColor(oldApp.Name))
+	apiResponse = cmd.appRepo.Rename(newApp, oldApp.Name)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+	cmd.ui.Ok()
+	return
+}
+
+// deleteLeftoverApp deletes the app called name if there is one.
+func (cmd Push) deleteLeftoverApp(name string) (apiResponse net.ApiResponse) {
+	app, apiResponse := cmd.appRepo.FindByName(name)
+	if apiResponse.IsNotFound() {
+		apiResponse = net.ApiResponse{}
+		return
+	}
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+
+	cmd.ui.Say(terminal.WarningColor(fmt.Sprintf("Found %s left over from an earlier push", name)))
+	cmd.ui.Say("Deleting %s...", terminal.EntityNameColor(name))
+	apiResponse = cmd.appRepo.Delete(app)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+	cmd.ui.Ok()
+	return
+}
+
+// deployNewVersion creates the new version, starts it on a temporary route
+// and moves the routes of oldApp over to it once it is healthy. newApp has a
+// guid as soon as it exists, and takenRoutes lists the routes that were
+// unmapped from oldApp so far.
+func (cmd Push) deployNewVersion(oldApp cf.Application, newParams, appParams manifest.Application) (newApp cf.Application, takenRoutes []cf.Route, apiResponse net.ApiResponse) {
+	newApp, apiResponse = cmd.createAppWithoutRoutes(newParams, oldApp.Stack)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	domainName := ""
+	if len(appParams.Domains) > 0 {
+		domainName = appParams.Domains[0]
+	}
+
+	domain, apiResponse := cmd.domainRepo.FindByNameInCurrentSpace(domainName)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+
+	tempRoute, apiResponse := cmd.bindRoute(newApp, newApp.Name, domain)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+	tempUrl := fmt.Sprintf("%s.%s", tempRoute.Host, domain.Name)
+
+	apiResponse = cmd.bindServices(newApp, newParams.Services)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = cmd.uploadApp(newApp, newParams.Path)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	cmd.ui.Say("Starting %s...", terminal.EntityNameColor(newApp.Name))
+	_, apiResponse = cmd.appRepo.Start(newApp)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+	cmd.ui.Ok()
+
+	healthy, message := cmd.waitForAllInstances(newApp)
+	if !healthy {
+		apiResponse = net.NewApiStatusWithMessage("%s", message)
+		cmd.ui.Failed("%s", message)
+		return
+	}
+
+	for _, route := range oldApp.Routes {
+		url := route.URL()
+
+		cmd.ui.Say("Mapping %s to %s...", terminal.EntityNameColor(url), terminal.EntityNameColor(newApp.Name))
+		apiResponse = cmd.routeRepo.Bind(route, newApp)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Failed(apiResponse.Message)
+			return
+		}
+		cmd.ui.Ok()
+
+		cmd.ui.Say("Unmapping %s from %s...", terminal.EntityNameColor(url), terminal.EntityNameColor(oldApp.Name))
+		apiResponse = cmd.routeRepo.Unbind(route, oldApp)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Failed(apiResponse.Message)
+			return
+		}
+		takenRoutes = append(takenRoutes, route)
+		cmd.ui.Ok()
+	}
+
+	if len(appParams.Hosts) > 0 || len(appParams.Domains) > 0 {
+		routeParams := appParams
+		if len(routeParams.Hosts) == 0 {
+			routeParams.Hosts = []string{oldApp.Name}
+		}
+
+		newApp.Urls = oldApp.Urls
+		apiResponse = cmd.bindRoutes(newApp, routeParams)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+	}
+
+	cmd.ui.Say("Unmapping %s from %s...", terminal.EntityNameColor(tempUrl), terminal.EntityNameColor(newApp.Name))
+	apiResponse = cmd.routeRepo.Unbind(tempRoute, newApp)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+	cmd.ui.Ok()
+	return
+}
+
+// appParamsFromApp returns the attributes of app that a new version of it
+// should keep unless they are overridden.
+func appParamsFromApp(app cf.Application) (appParams manifest.Application) {
+	appParams = manifest.Application{
+		Instances:       app.Instances,
+		BuildpackUrl:    app.BuildpackUrl,
+		Command:         app.Command,
+		EnvironmentVars: app.EnvironmentVars,
+		Services:        app.ServiceNames,
+	}
+
+	if app.Memory > 0 {
+		appParams.Memory = fmt.Sprintf("%dM", app.Memory)
+	}
+	return
+}
+
+// waitForAllInstances polls the instances of app until every one of them is
+// running. Unlike start it does not settle for a single running instance,
+// since the app is about to take all the traffic of the old version.
+func (cmd Push) waitForAllInstances(app cf.Application) (healthy bool, message string) {
+	instances, apiResponse := cmd.appRepo.GetInstances(app)
+
+	for apiResponse.IsNotSuccessful() {
+		if apiResponse.ErrorCode != api.APP_NOT_STAGED {
+			cmd.ui.Say("")
+			message = apiResponse.Message
+			return
+		}
+
+		cmd.ui.Wait(1 * time.Second)
+		instances, apiResponse = cmd.appRepo.GetInstances(app)
+		cmd.ui.LoadingIndication()
+	}
+
+	cmd.ui.Say("")
+
+	startTime := time.Now()
+
+	for {
+		runningCount, flappingCount := 0, 0
+		for _, inst := range instances {
+			switch inst.State {
+			case cf.InstanceRunning:
+				runningCount++
+			case cf.InstanceFlapping:
+				flappingCount++
+			}
+		}
+
+		if flappingCount > 0 {
+			message = "Start unsuccessful"
+			return
+		}
+
+		cmd.ui.Say("%d of %d instances running", runningCount, len(instances))
+
+		if len(instances) > 0 && runningCount == len(instances) {
+			healthy = true
+			return
+		}
+
+		if time.Since(startTime) > cmd.config.ApplicationStartTimeout*time.Second {
+			message = "Start app timeout"
+			return
+		}
+
+		cmd.ui.Wait(1 * time.Second)
+		instances, apiResponse = cmd.appRepo.GetInstances(app)
+		if apiResponse.IsNotSuccessful() {
+			message = apiResponse.Message
+			return
+		}
+	}
+}
+
+// rollback gives takenRoutes back to oldApp and deletes newApp, which takes
+// its routes with it. It carries on when a route cannot be given back, so
+// that newApp is still deleted.
+func (cmd Push) rollback(oldApp, newApp cf.Application, takenRoutes []cf.Route) {
+	cmd.ui.Say(terminal.WarningColor("Rolling back " + newApp.Name))
+
+	for _, route := range takenRoutes {
+		cmd.ui.Say("Mapping %s to %s...", terminal.EntityNameColor(route.URL()), terminal.EntityNameColor(oldApp.Name))
+		apiResponse := cmd.routeRepo.Bind(route, oldApp)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Warn("Could not map %s back to %s: %s", route.URL(), oldApp.Name, apiResponse.Message)
+			continue
+		}
+		cmd.ui.Ok()
+	}
+
+	cmd.ui.Say("Deleting %s...", terminal.EntityNameColor(newApp.Name))
+	apiResponse := cmd.appRepo.Delete(newApp)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+	cmd.ui.Ok()
+}
+
+// heldFailureUI holds back the first failure reported through it instead of
+// ending cf, and passes everything else on to UI.
+type heldFailureUI struct {
+	terminal.UI
+	failed     bool
+	message    string
+	errorCode  string
+	statusCode int
+}
+
+func (ui *heldFailureUI) Failed(message string, args ...interface{}) {
+	ui.ApiFailure(fmt.Sprintf(message, args...), "", 0)
+}
+
+func (ui *heldFailureUI) ApiFailure(message string, errorCode string, statusCode int) {
+	if ui.failed {
+		return
+	}
+
+	ui.failed = true
+	ui.message = message
+	ui.errorCode = errorCode
+	ui.statusCode = statusCode
+}
+
+// report reports the failure that was held back to realUI, or apiResponse
+// when none was.
+func (ui *heldFailureUI) report(realUI terminal.UI, apiResponse net.ApiResponse) {
+	if ui.failed {
+		realUI.ApiFailure(ui.message, ui.errorCode, ui.statusCode)
+		return
+	}
+
+	realUI.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+}
@@ -0,0 +1,230 @@
+package application_test
+
+import (
+	"cf"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testhelpers"
+	"testing"
+)
+
+var blueGreenOldApp = cf.Application{
+	Name:         "my-app",
+	Guid:         "my-app-guid",
+	Instances:    2,
+	Memory:       256,
+	Command:      "bundle exec rackup",
+	ServiceNames: []string{"my-db"},
+	Urls:         []string{"my-app.example.com"},
+	Routes: []cf.Route{
+		cf.Route{Guid: "my-route-guid", Host: "my-app", Domain: cf.Domain{Name: "example.com"}},
+	},
+}
+
+func getBlueGreenDependencies(instances [][]cf.ApplicationInstance) (starter *testhelpers.FakeAppStarter,
+	stopper *testhelpers.FakeAppStopper,
+	appRepo *testhelpers.FakeApplicationRepository,
+	domainRepo *testhelpers.FakeDomainRepository,
+	routeRepo *testhelpers.FakeRouteRepository,
+	stackRepo *testhelpers.FakeStackRepository,
+	serviceRepo *testhelpers.FakeServiceRepo,
+	appBitsRepo *testhelpers.FakeApplicationBitsRepository) {
+
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo = getPushDependencies()
+
+	appRepo.FindByNameApps = map[string]cf.Application{"my-app": blueGreenOldApp}
+	appRepo.GetInstancesResponses = instances
+	appRepo.GetInstancesErrorCodes = make([]string, len(instances))
+	domainRepo.FindByNameDomain = cf.Domain{Name: "example.com", Guid: "example-domain-guid"}
+	routeRepo.FindByHostAndDomainNotFound = true
+	serviceRepo.FindInstanceByNameServiceInstance = cf.ServiceInstance{Name: "my-db"}
+	return
+}
+
+func TestBlueGreenPush(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+			cf.ApplicationInstance{State: cf.InstanceStarting},
+		},
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+		},
+	}
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(instances)
+
+	ui := callPush([]string{"--blue-green", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	output := strings.Join(ui.Outputs, "\n")
+	assert.NotContains(t, output, "FAILED")
+
+	assert.Equal(t, appRepo.CreatedApp.Name, "my-app-new")
+	assert.Equal(t, appRepo.CreatedApp.Instances, 2)
+	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(256))
+	assert.Equal(t, appRepo.CreatedApp.Command, "bundle exec rackup")
+	assert.Equal(t, serviceRepo.BindServiceApplication.Name, "my-app-new")
+	assert.Equal(t, appBitsRepo.UploadedApp.Guid, "my-app-new-guid")
+	assert.Equal(t, appRepo.StartAppToStart.Name, "my-app-new")
+	assert.Contains(t, output, "2 of 2 instances running")
+
+	assert.Equal(t, routeRepo.CreatedRoute.Host, "my-app-new")
+	assert.Equal(t, len(routeRepo.BoundRoutes), 2)
+	assert.Equal(t, routeRepo.BoundRoutes[0].Host, "my-app-new")
+	assert.Equal(t, routeRepo.BoundRoutes[1].Guid, "my-route-guid")
+
+	assert.Equal(t, len(routeRepo.UnboundRoutes), 2)
+	assert.Equal(t, routeRepo.UnboundRoutes[0].Guid, "my-route-guid")
+	assert.Equal(t, routeRepo.UnboundRoutes[1].Host, "my-app-new")
+	assert.Equal(t, routeRepo.UnboundApp.Name, "my-app-new")
+
+	assert.Equal(t, appRepo.DeletedApp.Guid, "my-app-guid")
+	assert.Equal(t, appRepo.RenameApp.Guid, "my-app-new-guid")
+	assert.Equal(t, appRepo.RenameNewName, "my-app")
+
+	assert.Equal(t, stopper.AppToStop.Name, "")
+	assert.Equal(t, starter.AppToStart.Name, "")
+}
+
+func TestBlueGreenPushKeepingTheOldApp(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+		},
+	}
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(instances)
+
+	ui := callPush([]string{"--blue-green", "--keep-old", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	output := strings.Join(ui.Outputs, "\n")
+	assert.NotContains(t, output, "FAILED")
+
+	assert.Equal(t, stopper.AppToStop.Guid, "my-app-guid")
+	assert.Contains(t, output, "my-app-old")
+	assert.Equal(t, appRepo.DeletedApp.Guid, "")
+	assert.Equal(t, appRepo.RenameNewName, "my-app")
+}
+
+func TestBlueGreenPushRollsBackWhenTheNewVersionIsNotHealthy(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+			cf.ApplicationInstance{State: cf.InstanceFlapping},
+		},
+	}
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(instances)
+
+	ui := callPush([]string{"--blue-green", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	output := strings.Join(ui.Outputs, "\n")
+
+	assert.Contains(t, output, "Rolling back")
+	assert.Contains(t, output, "FAILED")
+	assert.Contains(t, output, "Start unsuccessful")
+	assert.Equal(t, appRepo.DeletedApp.Guid, "my-app-new-guid")
+
+	assert.Equal(t, len(routeRepo.BoundRoutes), 1)
+	assert.Equal(t, routeRepo.BoundRoutes[0].Host, "my-app-new")
+	assert.Equal(t, len(routeRepo.UnboundRoutes), 0)
+	assert.Equal(t, appRepo.RenameNewName, "")
+}
+
+func TestBlueGreenPushWithNoStart(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(nil)
+
+	ui := callPush([]string{"--blue-green", "--no-start", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Equal(t, appRepo.FindByNameName, "")
+}
+
+func TestBlueGreenPushWhenTheAppDoesNotExist(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(nil)
+	appRepo.FindByNameNotFound = true
+
+	callPush([]string{"--blue-green", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, appRepo.CreatedApp.Name, "my-app")
+	assert.Equal(t, stopper.AppToStop.Name, "my-app")
+	assert.Equal(t, appRepo.DeletedApp.Guid, "")
+}
+
+func TestBlueGreenPushRollsBackWhenTheUploadFails(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(nil)
+	appBitsRepo.UploadErr = true
+
+	ui := callPush([]string{"--blue-green", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	output := strings.Join(ui.Outputs, "\n")
+
+	assert.Contains(t, output, "Rolling back")
+	assert.Equal(t, appRepo.StartAppToStart.Name, "")
+	assert.Equal(t, len(appRepo.DeletedApps), 1)
+	assert.Equal(t, appRepo.DeletedApps[0].Guid, "my-app-new-guid")
+
+	assert.Equal(t, ui.Outputs[len(ui.Outputs)-2], "FAILED")
+	assert.Equal(t, ui.Outputs[len(ui.Outputs)-1], "Error uploading app")
+}
+
+func TestBlueGreenPushGivesTheRoutesBackWhenRemappingFails(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+		},
+	}
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(instances)
+
+	oldApp := blueGreenOldApp
+	oldApp.Routes = []cf.Route{
+		cf.Route{Guid: "my-route-guid", Host: "my-app", Domain: cf.Domain{Name: "example.com"}},
+		cf.Route{Guid: "my-other-route-guid", Host: "www", Domain: cf.Domain{Name: "example.com"}},
+	}
+	appRepo.FindByNameApps = map[string]cf.Application{"my-app": oldApp}
+	routeRepo.BindErrRouteGuid = "my-other-route-guid"
+
+	ui := callPush([]string{"--blue-green", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Contains(t, strings.Join(ui.Outputs, "\n"), "Error binding route")
+	assert.Equal(t, len(routeRepo.UnboundRoutes), 1)
+	assert.Equal(t, routeRepo.UnboundRoutes[0].Guid, "my-route-guid")
+
+	lastBound := len(routeRepo.BoundRoutes) - 1
+	assert.Equal(t, routeRepo.BoundRoutes[lastBound].Guid, "my-route-guid")
+	assert.Equal(t, routeRepo.BoundApp.Guid, "my-app-guid")
+
+	assert.Equal(t, len(appRepo.DeletedApps), 1)
+	assert.Equal(t, appRepo.DeletedApps[0].Guid, "my-app-new-guid")
+	assert.Equal(t, appRepo.RenameNewName, "")
+}
+
+func TestBlueGreenPushDeletesANewAppLeftOverFromAnEarlierPush(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+		},
+	}
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(instances)
+	appRepo.FindByNameApps["my-app-new"] = cf.Application{Name: "my-app-new", Guid: "leftover-guid"}
+
+	ui := callPush([]string{"--blue-green", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	output := strings.Join(ui.Outputs, "\n")
+	assert.NotContains(t, output, "FAILED")
+	assert.Contains(t, output, "left over")
+
+	assert.Equal(t, appRepo.DeletedApps[0].Guid, "leftover-guid")
+	assert.Equal(t, appRepo.CreatedApp.Name, "my-app-new")
+	assert.Equal(t, appRepo.RenameNewName, "my-app")
+}
+
+func TestBlueGreenPushKeepingTheOldAppReplacesAnOldAppKeptEarlier(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+		},
+	}
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getBlueGreenDependencies(instances)
+	appRepo.FindByNameApps["my-app-old"] = cf.Application{Name: "my-app-old", Guid: "kept-guid"}
+
+	ui := callPush([]string{"--blue-green", "--keep-old", "my-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	assert.NotContains(t, strings.Join(ui.Outputs, "\n"), "FAILED")
+
+	assert.Equal(t, len(appRepo.DeletedApps), 1)
+	assert.Equal(t, appRepo.DeletedApps[0].Guid, "kept-guid")
+	assert.Equal(t, appRepo.RenameNewName, "my-app")
+}
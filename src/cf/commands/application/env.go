@@ -1,20 +1,30 @@
 package application
 
 import (
+	"cf"
+	"cf/api"
 	"cf/requirements"
 	"cf/terminal"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/codegangsta/cli"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 type Env struct {
-	ui     terminal.UI
-	appReq requirements.ApplicationRequirement
+	ui      terminal.UI
+	appRepo api.ApplicationRepository
+	appReq  requirements.ApplicationRequirement
 }
 
-func NewEnv(ui terminal.UI) (cmd *Env) {
+func NewEnv(ui terminal.UI, appRepo api.ApplicationRepository) (cmd *Env) {
 	cmd = new(Env)
 	cmd.ui = ui
+	cmd.appRepo = appRepo
 	return
 }
 
@@ -37,15 +47,141 @@ func (cmd *Env) GetRequirements(reqFactory requirements.Factory, c *cli.Context)
 func (cmd *Env) Run(c *cli.Context) {
 	app := cmd.appReq.GetApplication()
 
+	if c.String("export") != "" {
+		cmd.export(app.Name, app.EnvironmentVars, c.String("export"))
+		return
+	}
+
 	cmd.ui.Say("Getting env variables for %s...", terminal.EntityNameColor(app.Name))
-	envVars := app.EnvironmentVars
+
+	// Controllers without the env endpoint still have the user-provided
+	// variables to show
+	env, apiResponse := cmd.appRepo.GetEnv(app)
+	env.User = app.EnvironmentVars
 
 	cmd.ui.Ok()
-	if len(envVars) == 0 {
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Warn("Could not get the system-provided env variables: %s", apiResponse.Message)
+	}
+
+	shownEnv := env
+	if !c.Bool("show-secrets") {
+		shownEnv = maskAppEnvironment(env)
+	}
+
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(shownEnv)
+		return
+	}
+
+	cmd.displaySystemEnv(shownEnv)
+
+	cmd.ui.Say("\n%s", terminal.HeaderColor("User-Provided:"))
+	if len(shownEnv.User) == 0 {
 		cmd.ui.Say("No env variables exist")
+	}
+	for _, key := range sortedEnvVarNames(shownEnv.User) {
+		cmd.ui.Say("%s: %s", key, terminal.EntityNameColor(shownEnv.User[key]))
+	}
+
+	cmd.displayEnvGroup("Running Environment Variable Groups:", shownEnv.Running)
+	cmd.displayEnvGroup("Staging Environment Variable Groups:", shownEnv.Staging)
+
+	if !reflect.DeepEqual(shownEnv, env) {
+		cmd.ui.Say("\nTIP: Use '--show-secrets' to show the masked values")
+	}
+}
+
+// displaySystemEnv shows VCAP_SERVICES one bound service at a time, since
+// that is where the credentials to debug a binding are.
+func (cmd *Env) displaySystemEnv(env cf.AppEnvironment) {
+	if len(env.System) == 0 && len(env.Application) == 0 {
 		return
 	}
-	for key, value := range envVars {
+
+	cmd.ui.Say("\n%s", terminal.HeaderColor("System-Provided:"))
+
+	services, _ := env.System["VCAP_SERVICES"].(map[string]interface{})
+	if len(services) > 0 {
+		cmd.ui.Say("VCAP_SERVICES:")
+	}
+	for _, label := range sortedJsonKeys(services) {
+		instances, _ := services[label].([]interface{})
+		for _, instance := range instances {
+			name := label
+			if instanceMap, ok := instance.(map[string]interface{}); ok && instanceMap["name"] != nil {
+				name = fmt.Sprintf("%v", instanceMap["name"])
+			}
+			cmd.ui.Say("  %s (%s):", terminal.EntityNameColor(name), label)
+			cmd.ui.Say("    %s", strings.Replace(prettyJson(instance), "\n", "\n    ", -1))
+		}
+	}
+
+	others := map[string]interface{}{}
+	for key, value := range env.System {
+		if key != "VCAP_SERVICES" {
+			others[key] = value
+		}
+	}
+	for key, value := range env.Application {
+		others[key] = value
+	}
+
+	for _, key := range sortedJsonKeys(others) {
+		cmd.ui.Say("%s: %s", key, prettyJson(others[key]))
+	}
+}
+
+func (cmd *Env) displayEnvGroup(header string, group map[string]interface{}) {
+	if len(group) == 0 {
+		return
+	}
+
+	cmd.ui.Say("\n%s", terminal.HeaderColor(header))
+	for _, key := range sortedJsonKeys(group) {
+		value, isString := group[key].(string)
+		if !isString {
+			value = prettyJson(group[key])
+		}
 		cmd.ui.Say("%s: %s", key, terminal.EntityNameColor(value))
 	}
 }
+
+func sortedJsonKeys(values map[string]interface{}) (keys []string) {
+	for key, _ := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func prettyJson(value interface{}) string {
+	bytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
+
+// export writes a dotenv file holding the actual values, readable only by
+// the current user since they may be secrets.
+func (cmd *Env) export(appName string, envVars map[string]string, fileName string) {
+	cmd.ui.Say("Exporting env variables for %s to %s...", terminal.EntityNameColor(appName), terminal.EntityNameColor(fileName))
+
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		cmd.ui.Failed("Error creating %s: %s", fileName, err.Error())
+		return
+	}
+	defer file.Close()
+
+	err = writeDotEnv(file, envVars)
+	if err != nil {
+		cmd.ui.Failed("Error writing %s: %s", fileName, err.Error())
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("Exported %d env variables", len(envVars))
+}
@@ -0,0 +1,284 @@
+package application
+
+import (
+	"bufio"
+	"cf"
+	"cf/terminal"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const maskedEnvValue = "********"
+
+var secretEnvVarName = regexp.MustCompile(`(?i)(passw(or)?d|pwd|secret|token|credential|private|api_?key|access_?key|auth)`)
+
+// maskEnvValue hides values that look like secrets, either because of the
+// name of the variable or because they are URLs carrying a password, in which
+// case only the password is hidden.
+func maskEnvValue(name, value string) string {
+	if secretEnvVarName.MatchString(name) {
+		return maskedEnvValue
+	}
+
+	if strings.Contains(value, "://") {
+		parsedUrl, err := url.Parse(value)
+		if err == nil && parsedUrl.User != nil {
+			if _, hasPassword := parsedUrl.User.Password(); hasPassword {
+				// url escapes the asterisks, so the mask goes in afterwards
+				parsedUrl.User = url.UserPassword(parsedUrl.User.Username(), "MASKED")
+				return strings.Replace(parsedUrl.String(), ":MASKED@", ":"+maskedEnvValue+"@", 1)
+			}
+		}
+	}
+	return value
+}
+
+func maskEnvVars(envVars map[string]string) (masked map[string]string) {
+	if envVars == nil {
+		return
+	}
+
+	masked = map[string]string{}
+	for name, value := range envVars {
+		masked[name] = maskEnvValue(name, value)
+	}
+	return
+}
+
+// maskAppEnvironment masks every variable, looking into the JSON of the
+// system-provided ones, where the names of the fields tell what is secret.
+func maskAppEnvironment(env cf.AppEnvironment) (masked cf.AppEnvironment) {
+	masked.User = maskEnvVars(env.User)
+	masked.System = maskEnvJson(env.System)
+	masked.Application = maskEnvJson(env.Application)
+	masked.Running = maskEnvJson(env.Running)
+	masked.Staging = maskEnvJson(env.Staging)
+	return
+}
+
+func maskEnvJson(values map[string]interface{}) (masked map[string]interface{}) {
+	if values == nil {
+		return
+	}
+
+	masked = map[string]interface{}{}
+	for name, value := range values {
+		masked[name] = maskEnvJsonValue(name, value)
+	}
+	return
+}
+
+func maskEnvJsonValue(name string, value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		return maskEnvValue(name, value)
+	case map[string]interface{}:
+		return maskEnvJson(value)
+	case []interface{}:
+		masked := make([]interface{}, len(value))
+		for i, element := range value {
+			masked[i] = maskEnvJsonValue(name, element)
+		}
+		return masked
+	}
+	return value
+}
+
+func sortedEnvVarNames(envVars map[string]string) (names []string) {
+	for name, _ := range envVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+type envVarChanges struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+func (changes envVarChanges) IsEmpty() bool {
+	return len(changes.Added) == 0 && len(changes.Changed) == 0 && len(changes.Removed) == 0
+}
+
+func diffEnvVars(oldVars, newVars map[string]string) (changes envVarChanges) {
+	for _, name := range sortedEnvVarNames(newVars) {
+		oldValue, found := oldVars[name]
+		if !found {
+			changes.Added = append(changes.Added, name)
+		} else if oldValue != newVars[name] {
+			changes.Changed = append(changes.Changed, name)
+		}
+	}
+
+	for _, name := range sortedEnvVarNames(oldVars) {
+		if _, found := newVars[name]; !found {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+	return
+}
+
+func showEnvVarChanges(ui terminal.UI, changes envVarChanges, oldVars, newVars map[string]string) {
+	for _, name := range changes.Added {
+		ui.Say("  %s %s: %s", terminal.SuccessColor("+"), name, maskEnvValue(name, newVars[name]))
+	}
+	for _, name := range changes.Changed {
+		ui.Say("  %s %s: %s -> %s", terminal.WarningColor("~"), name, maskEnvValue(name, oldVars[name]), maskEnvValue(name, newVars[name]))
+	}
+	for _, name := range changes.Removed {
+		ui.Say("  %s %s", terminal.FailureColor("-"), name)
+	}
+}
+
+// parseDotEnv reads NAME=VALUE lines. Blank lines, comments and an "export"
+// prefix are skipped. Values can be single quoted, taken literally, or double
+// quoted, where \n, \r, \t, \" and \\ are unescaped.
+func parseDotEnv(reader io.Reader) (envVars map[string]string, err error) {
+	envVars = map[string]string{}
+	scanner := bufio.NewScanner(reader)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		separator := strings.Index(line, "=")
+		if separator < 0 {
+			err = errors.New(fmt.Sprintf("line %d: expected NAME=VALUE", lineNumber))
+			return
+		}
+
+		name := strings.TrimSpace(line[:separator])
+		if name == "" || strings.ContainsAny(name, " \t") {
+			err = errors.New(fmt.Sprintf("line %d: invalid variable name '%s'", lineNumber, name))
+			return
+		}
+
+		var value string
+		value, err = parseDotEnvValue(strings.TrimSpace(line[separator+1:]))
+		if err != nil {
+			err = errors.New(fmt.Sprintf("line %d: %s", lineNumber, err.Error()))
+			return
+		}
+		envVars[name] = value
+	}
+
+	err = scanner.Err()
+	return
+}
+
+func parseDotEnvValue(raw string) (value string, err error) {
+	if strings.HasPrefix(raw, "'") {
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			err = errors.New("unterminated single quote")
+			return
+		}
+		value = raw[1 : end+1]
+		err = checkDotEnvRest(raw[end+2:])
+		return
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		unescaped := []byte{}
+		for i := 1; i < len(raw); i++ {
+			switch raw[i] {
+			case '"':
+				value = string(unescaped)
+				err = checkDotEnvRest(raw[i+1:])
+				return
+			case '\\':
+				i++
+				if i == len(raw) {
+					break
+				}
+				switch raw[i] {
+				case 'n':
+					unescaped = append(unescaped, '\n')
+				case 'r':
+					unescaped = append(unescaped, '\r')
+				case 't':
+					unescaped = append(unescaped, '\t')
+				default:
+					unescaped = append(unescaped, raw[i])
+				}
+			default:
+				unescaped = append(unescaped, raw[i])
+			}
+		}
+		err = errors.New("unterminated double quote")
+		return
+	}
+
+	if comment := strings.Index(raw, " #"); comment >= 0 {
+		raw = raw[:comment]
+	}
+	value = strings.TrimSpace(raw)
+	return
+}
+
+func checkDotEnvRest(rest string) (err error) {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		err = errors.New(fmt.Sprintf("unexpected '%s' after quoted value", rest))
+	}
+	return
+}
+
+// parseEnvJson reads a JSON object. Values that are not strings are stored
+// as their JSON text, so that numbers and booleans keep their spelling.
+func parseEnvJson(reader io.Reader) (envVars map[string]string, err error) {
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return
+	}
+
+	rawVars := map[string]json.RawMessage{}
+	err = json.Unmarshal(bytes, &rawVars)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("expected a JSON object of env variables: %s", err.Error()))
+		return
+	}
+
+	envVars = map[string]string{}
+	for name, rawValue := range rawVars {
+		var value string
+		if json.Unmarshal(rawValue, &value) != nil {
+			value = string(rawValue)
+		}
+		envVars[name] = value
+	}
+	return
+}
+
+var plainDotEnvValue = regexp.MustCompile(`^[A-Za-z0-9_./:@,+=%-]*$`)
+
+// writeDotEnv writes envVars sorted by name, quoting the values
+// parseDotEnv could not read back unquoted.
+func writeDotEnv(writer io.Writer, envVars map[string]string) (err error) {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+	for _, name := range sortedEnvVarNames(envVars) {
+		value := envVars[name]
+		if !plainDotEnvValue.MatchString(value) {
+			value = `"` + replacer.Replace(value) + `"`
+		}
+
+		_, err = fmt.Fprintf(writer, "%s=%s\n", name, value)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
@@ -1,13 +1,22 @@
 package application
 
 import (
+	"cf"
 	"cf/api"
 	"cf/requirements"
 	"cf/terminal"
 	"errors"
+	"fmt"
 	"github.com/codegangsta/cli"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
+// Downloads of smaller files finish too quickly for a progress line to help
+const downloadProgressThreshold = 1024 * 1024
+
 type Files struct {
 	ui           terminal.UI
 	appFilesRepo api.AppFilesRepository
@@ -39,16 +48,29 @@ func (cmd *Files) GetRequirements(reqFactory requirements.Factory, c *cli.Contex
 }
 
 func (cmd *Files) Run(c *cli.Context) {
-	cmd.ui.Say("Getting files...")
-
 	app := cmd.appReq.GetApplication()
 
-	path := "/"
+	remotePath := "/"
 	if len(c.Args()) > 1 {
-		path = c.Args()[1]
+		remotePath = c.Args()[1]
 	}
 
-	list, apiResponse := cmd.appFilesRepo.ListFiles(app, path)
+	instance := c.Int("i")
+
+	if c.String("download") != "" {
+		cmd.download(app, instance, remotePath, c.String("download"))
+		return
+	}
+
+	cmd.ui.Say("Getting files...")
+
+	err := checkInstanceIndex(app, instance)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	list, apiResponse := cmd.appFilesRepo.ListFiles(app, instance, remotePath)
 	if apiResponse.IsNotSuccessful() {
 		cmd.ui.Failed(apiResponse.Message)
 		return
@@ -57,3 +79,142 @@ func (cmd *Files) Run(c *cli.Context) {
 	cmd.ui.Ok()
 	cmd.ui.Say(list)
 }
+
+func (cmd *Files) download(app cf.Application, instance int, remotePath, localPath string) {
+	cmd.ui.Say("Downloading %s from instance #%d of app %s to %s...",
+		terminal.EntityNameColor(remotePath),
+		instance,
+		terminal.EntityNameColor(app.Name),
+		terminal.EntityNameColor(localPath),
+	)
+
+	err := checkInstanceIndex(app, instance)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	isDir, err := cmd.isDirectory(app, instance, remotePath)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	remotePath = path.Join("/", remotePath)
+
+	var count int
+	if isDir {
+		count, err = cmd.downloadDirectory(app, instance, remotePath, localPath)
+	} else {
+		info, statErr := os.Stat(localPath)
+		if statErr == nil && info.IsDir() {
+			localPath = filepath.Join(localPath, path.Base(remotePath))
+		}
+		err = cmd.downloadFile(app, instance, remotePath, localPath)
+		count = 1
+	}
+
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("Downloaded %d file(s)", count)
+}
+
+// isDirectory looks remotePath up in the listing of its parent, since the
+// contents of a file cannot be told apart from a directory listing.
+func (cmd *Files) isDirectory(app cf.Application, instance int, remotePath string) (isDir bool, err error) {
+	trimmed := strings.Trim(remotePath, "/")
+	if trimmed == "" || strings.HasSuffix(remotePath, "/") {
+		isDir = true
+		return
+	}
+
+	entries, apiResponse := cmd.appFilesRepo.ListDirectory(app, instance, path.Dir("/"+trimmed))
+	if apiResponse.IsNotSuccessful() {
+		err = errors.New(apiResponse.Message)
+		return
+	}
+
+	name := path.Base(trimmed)
+	for _, entry := range entries {
+		if entry.Name == name {
+			isDir = entry.IsDir
+			return
+		}
+	}
+	return
+}
+
+func (cmd *Files) downloadDirectory(app cf.Application, instance int, remotePath, localPath string) (count int, err error) {
+	err = os.MkdirAll(localPath, os.ModePerm)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Error creating directory %s: %s", localPath, err.Error()))
+		return
+	}
+
+	entries, apiResponse := cmd.appFilesRepo.ListDirectory(app, instance, remotePath)
+	if apiResponse.IsNotSuccessful() {
+		err = errors.New(apiResponse.Message)
+		return
+	}
+
+	for _, entry := range entries {
+		remoteEntry := path.Join("/", remotePath, entry.Name)
+		localEntry := filepath.Join(localPath, entry.Name)
+
+		// The names come from the instance, so they must not be able to
+		// point anywhere outside of the directory being downloaded into.
+		if !isPlainFileName(entry.Name) || !cf.IsPathInDir(localPath, localEntry) {
+			err = errors.New(fmt.Sprintf("%s contains a file with an invalid name: %q", remotePath, entry.Name))
+			return
+		}
+
+		if entry.IsDir {
+			var dirCount int
+			dirCount, err = cmd.downloadDirectory(app, instance, remoteEntry, localEntry)
+			count += dirCount
+		} else {
+			err = cmd.downloadFile(app, instance, remoteEntry, localEntry)
+			count++
+		}
+
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func isPlainFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\")
+}
+
+// downloadFile leaves no partial file behind when the download fails.
+func (cmd *Files) downloadFile(app cf.Application, instance int, remotePath, localPath string) (err error) {
+	cmd.ui.Say("  %s", remotePath)
+
+	file, err := os.Create(localPath)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Error creating file %s: %s", localPath, err.Error()))
+		return
+	}
+
+	showProgress := progressByPercent(cmd.ui)
+	onProgress := func(downloaded, total int64) {
+		if total >= downloadProgressThreshold {
+			showProgress(downloaded, total)
+		}
+	}
+
+	apiResponse := cmd.appFilesRepo.DownloadFile(app, instance, remotePath, file, onProgress)
+	file.Close()
+
+	if apiResponse.IsNotSuccessful() {
+		os.Remove(localPath)
+		err = errors.New(apiResponse.Message)
+	}
+	return
+}
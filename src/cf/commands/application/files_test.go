@@ -4,6 +4,10 @@ import (
 	"cf"
 	. "cf/commands/application"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testhelpers"
 	"testing"
 )
@@ -50,6 +54,149 @@ func TestListingDirectoryEntries(t *testing.T) {
 	assert.Contains(t, ui.Outputs[2], "file 1\nfile 2")
 }
 
+func TestListingFilesOfAnotherInstance(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", Instances: 3}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	appFilesRepo := &testhelpers.FakeAppFilesRepo{FileList: "file 1"}
+
+	callFiles([]string{"my-app", "/foo"}, reqFactory, appFilesRepo)
+	assert.Equal(t, appFilesRepo.Instance, 0)
+
+	ui := callFiles([]string{"-i", "2", "my-app", "/foo"}, reqFactory, appFilesRepo)
+	assert.Equal(t, appFilesRepo.Instance, 2)
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	appFilesRepo = &testhelpers.FakeAppFilesRepo{}
+	ui = callFiles([]string{"-i", "3", "my-app", "/foo"}, reqFactory, appFilesRepo)
+	assert.Contains(t, ui.Outputs[2], "Invalid instance index 3")
+	assert.Equal(t, appFilesRepo.Application.Guid, "")
+}
+
+func TestDownloadingAFile(t *testing.T) {
+	localDir, err := ioutil.TempDir("", "files-download")
+	assert.NoError(t, err)
+	defer os.RemoveAll(localDir)
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", Instances: 2}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	appFilesRepo := &testhelpers.FakeAppFilesRepo{
+		Directories: map[string][]cf.InstanceFile{
+			"/app": []cf.InstanceFile{cf.InstanceFile{Name: "heap.hprof", Size: "9B"}},
+		},
+		FileContents: map[string]string{"/app/heap.hprof": "\x00heap\xffdump"},
+	}
+
+	ui := callFiles([]string{"-i", "1", "--download", localDir, "my-app", "/app/heap.hprof"}, reqFactory, appFilesRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Downloading")
+	assert.Contains(t, ui.Outputs[0], "#1")
+	assert.Equal(t, appFilesRepo.Instance, 1)
+	assert.Equal(t, appFilesRepo.DownloadedPaths, []string{"/app/heap.hprof"})
+
+	contents, err := ioutil.ReadFile(filepath.Join(localDir, "heap.hprof"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(contents), "\x00heap\xffdump")
+
+	assert.Contains(t, strings.Join(ui.Outputs, "\n"), "OK")
+	assert.Contains(t, ui.Outputs[len(ui.Outputs)-1], "Downloaded 1 file(s)")
+	assert.Equal(t, ui.ProgressTotal, int64(0))
+}
+
+func TestDownloadingADirectoryRecursively(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "files-download")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	localDir := filepath.Join(tmpDir, "dumps")
+
+	largeFile := strings.Repeat("x", 2*1024*1024)
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	appFilesRepo := &testhelpers.FakeAppFilesRepo{
+		Directories: map[string][]cf.InstanceFile{
+			"/app": []cf.InstanceFile{
+				cf.InstanceFile{Name: "heapdumps", Size: "-", IsDir: true},
+			},
+			"/app/heapdumps": []cf.InstanceFile{
+				cf.InstanceFile{Name: "index.txt", Size: "5B"},
+				cf.InstanceFile{Name: "old", Size: "-", IsDir: true},
+			},
+			"/app/heapdumps/old": []cf.InstanceFile{
+				cf.InstanceFile{Name: "heap.hprof", Size: "2M"},
+			},
+		},
+		FileContents: map[string]string{
+			"/app/heapdumps/index.txt":      "index",
+			"/app/heapdumps/old/heap.hprof": largeFile,
+		},
+	}
+
+	ui := callFiles([]string{"--download", localDir, "my-app", "app/heapdumps"}, reqFactory, appFilesRepo)
+
+	assert.Equal(t, appFilesRepo.DownloadedPaths, []string{"/app/heapdumps/index.txt", "/app/heapdumps/old/heap.hprof"})
+
+	contents, err := ioutil.ReadFile(filepath.Join(localDir, "index.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(contents), "index")
+
+	contents, err = ioutil.ReadFile(filepath.Join(localDir, "old", "heap.hprof"))
+	assert.NoError(t, err)
+	assert.Equal(t, len(contents), len(largeFile))
+
+	assert.Equal(t, ui.ProgressCurrent, int64(len(largeFile)))
+	assert.Equal(t, ui.ProgressTotal, int64(len(largeFile)))
+	assert.Contains(t, ui.Outputs[len(ui.Outputs)-1], "Downloaded 2 file(s)")
+}
+
+func TestDownloadingADirectoryRefusesNamesOutsideOfIt(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "files-download")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	localDir := filepath.Join(tmpDir, "dumps")
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+
+	for _, name := range []string{"", ".", "..", "../escape.txt", "sub/escape.txt"} {
+		appFilesRepo := &testhelpers.FakeAppFilesRepo{
+			Directories: map[string][]cf.InstanceFile{
+				"/app": []cf.InstanceFile{
+					cf.InstanceFile{Name: name, Size: "5B"},
+				},
+			},
+			FileContents: map[string]string{"/escape.txt": "escaped"},
+		}
+
+		ui := callFiles([]string{"--download", localDir, "my-app", "app/"}, reqFactory, appFilesRepo)
+
+		assert.Contains(t, strings.Join(ui.Outputs, "\n"), "FAILED")
+		assert.Contains(t, strings.Join(ui.Outputs, "\n"), "invalid name")
+		assert.Equal(t, len(appFilesRepo.DownloadedPaths), 0)
+	}
+
+	_, err = os.Stat(filepath.Join(tmpDir, "escape.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDownloadingAFileThatFails(t *testing.T) {
+	localDir, err := ioutil.TempDir("", "files-download")
+	assert.NoError(t, err)
+	defer os.RemoveAll(localDir)
+	localFile := filepath.Join(localDir, "heap.hprof")
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	appFilesRepo := &testhelpers.FakeAppFilesRepo{
+		Directories: map[string][]cf.InstanceFile{"/": []cf.InstanceFile{}},
+	}
+
+	ui := callFiles([]string{"--download", localFile, "my-app", "heap.hprof"}, reqFactory, appFilesRepo)
+
+	assert.Contains(t, strings.Join(ui.Outputs, "\n"), "FAILED")
+	_, err = os.Stat(localFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
 func callFiles(args []string, reqFactory *testhelpers.FakeReqFactory, appFilesRepo *testhelpers.FakeAppFilesRepo) (ui *testhelpers.FakeUI) {
 	ui = &testhelpers.FakeUI{}
 	ctxt := testhelpers.NewContext("files", args)
@@ -1,77 +1,24 @@
 package application
 
 import (
+	"cf"
+	"cf/formatters"
 	term "cf/terminal"
 	"errors"
 	"fmt"
 	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"reflect"
-	"strconv"
-	"strings"
 	"time"
 )
 
 const (
-	BYTE     = 1.0
-	KILOBYTE = 1024 * BYTE
-	MEGABYTE = 1024 * KILOBYTE
-	GIGABYTE = 1024 * MEGABYTE
-	TERABYTE = 1024 * GIGABYTE
+	BYTE     = formatters.BYTE
+	KILOBYTE = formatters.KILOBYTE
+	MEGABYTE = formatters.MEGABYTE
+	GIGABYTE = formatters.GIGABYTE
+	TERABYTE = formatters.TERABYTE
 )
 
-func byteSize(bytes uint64) string {
-	unit := ""
-	value := float32(bytes)
-
-	switch {
-	case bytes >= TERABYTE:
-		unit = "T"
-		value = value / TERABYTE
-	case bytes >= GIGABYTE:
-		unit = "G"
-		value = value / GIGABYTE
-	case bytes >= MEGABYTE:
-		unit = "M"
-		value = value / MEGABYTE
-	case bytes >= KILOBYTE:
-		unit = "K"
-		value = value / KILOBYTE
-	case bytes == 0:
-		return "0"
-	}
-
-	stringValue := fmt.Sprintf("%.1f", value)
-	stringValue = strings.TrimRight(stringValue, ".0")
-	return fmt.Sprintf("%s%s", stringValue, unit)
-}
-
-func bytesFromString(s string) (bytes uint64, err error) {
-	unit := string(s[len(s)-1])
-	stringValue := s[0 : len(s)-1]
-
-	value, err := strconv.ParseUint(stringValue, 10, 0)
-	if err != nil {
-		return
-	}
-
-	switch unit {
-	case "T":
-		bytes = value * TERABYTE
-	case "G":
-		bytes = value * GIGABYTE
-	case "M":
-		bytes = value * MEGABYTE
-	case "K":
-		bytes = value * KILOBYTE
-	}
-
-	if bytes == 0 {
-		err = errors.New("Could not parse byte string")
-	}
-
-	return
-}
-
 func coloredState(state string) (colored string) {
 	switch state {
 	case "started", "running":
@@ -89,16 +36,16 @@ func coloredState(state string) (colored string) {
 	return
 }
 
-func logMessageOutput(appName string, lm logmessage.LogMessage) string {
-	sourceTypeNames := map[logmessage.LogMessage_SourceType]string{
-		logmessage.LogMessage_CLOUD_CONTROLLER: "API",
-		logmessage.LogMessage_ROUTER:           "Router",
-		logmessage.LogMessage_UAA:              "UAA",
-		logmessage.LogMessage_DEA:              "Executor",
-		logmessage.LogMessage_WARDEN_CONTAINER: "App",
-	}
+var logSourceTypeNames = map[logmessage.LogMessage_SourceType]string{
+	logmessage.LogMessage_CLOUD_CONTROLLER: "API",
+	logmessage.LogMessage_ROUTER:           "Router",
+	logmessage.LogMessage_UAA:              "UAA",
+	logmessage.LogMessage_DEA:              "Executor",
+	logmessage.LogMessage_WARDEN_CONTAINER: "App",
+}
 
-	sourceType, _ := sourceTypeNames[*lm.SourceType]
+func logMessageOutput(appName string, lm logmessage.LogMessage) string {
+	sourceType, _ := logSourceTypeNames[*lm.SourceType]
 	sourceId := "?"
 	if lm.SourceId != nil {
 		sourceId = *lm.SourceId
@@ -120,6 +67,33 @@ func logMessageOutput(appName string, lm logmessage.LogMessage) string {
 	return fmt.Sprintf("%s %s %s %s%s", timeString, appName, sourceType, channel, msg)
 }
 
+// checkInstanceIndex tells whether app has an instance at index. Apps that
+// do not know their instance count accept any index that is not negative.
+func checkInstanceIndex(app cf.Application, index int) (err error) {
+	if index < 0 || (app.Instances > 0 && index >= app.Instances) {
+		err = errors.New(fmt.Sprintf("Invalid instance index %d: %s has instances 0 to %d", index, app.Name, app.Instances-1))
+	}
+	return
+}
+
+// progressByPercent returns a progress callback that only redraws the
+// progress line when the percentage changes.
+func progressByPercent(ui term.UI) func(current, total int64) {
+	lastPercent := int64(-1)
+	return func(current, total int64) {
+		if total <= 0 {
+			return
+		}
+
+		percent := current * 100 / total
+		if percent == lastPercent {
+			return
+		}
+		lastPercent = percent
+		ui.ShowProgress(current, total)
+	}
+}
+
 func envVarFound(varName string, existingEnvVars map[string]string) (found bool) {
 	for name, _ := range existingEnvVars {
 		if name == varName {
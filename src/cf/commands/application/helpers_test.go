@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"github.com/stretchr/testify/assert"
+	"os"
 	"testing"
 	"time"
 )
@@ -67,3 +68,38 @@ func TestLogMessageOutput(t *testing.T) {
 	msg.MessageType = &stderr
 	assert.Contains(t, logMessageOutput("my-app", msg), "Sep 20 09:33:30 my-app App/4 STDERR Hello World!")
 }
+
+func TestHandleInterruptsStopsOnTheFirstAndGivesUpOnTheSecond(t *testing.T) {
+	interrupts := make(chan os.Signal, 1)
+	interrupted := make(chan bool, 1)
+	stop, done := handleInterrupts(interrupts, func() { interrupted <- true })
+	defer done()
+
+	interrupts <- os.Interrupt
+	select {
+	case <-stop:
+	case <-time.After(time.Second):
+		t.Fatal("The first interrupt did not stop the tail")
+	}
+	assert.Equal(t, len(interrupted), 0)
+
+	interrupts <- os.Interrupt
+	select {
+	case <-interrupted:
+	case <-time.After(time.Second):
+		t.Fatal("The second interrupt was ignored")
+	}
+}
+
+func TestHandleInterruptsStopsListeningWhenDone(t *testing.T) {
+	interrupts := make(chan os.Signal, 1)
+	stop, done := handleInterrupts(interrupts, func() {})
+	done()
+
+	interrupts <- os.Interrupt
+	select {
+	case <-stop:
+		t.Error("An interrupt after done stopped the tail")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
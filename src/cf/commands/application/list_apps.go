@@ -2,6 +2,7 @@ package application
 
 import (
 	"cf/api"
+	"cf/formatters"
 	"cf/requirements"
 	"cf/terminal"
 	"fmt"
@@ -34,7 +35,7 @@ func (cmd ListApps) Run(c *cli.Context) {
 	space, apiResponse := cmd.spaceRepo.GetSummary()
 
 	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return
 	}
 
@@ -42,6 +43,11 @@ func (cmd ListApps) Run(c *cli.Context) {
 
 	cmd.ui.Ok()
 
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(apps)
+		return
+	}
+
 	table := [][]string{
 		[]string{"name", "status", "usage", "urls"},
 	}
@@ -50,7 +56,7 @@ func (cmd ListApps) Run(c *cli.Context) {
 		table = append(table, []string{
 			app.Name,
 			app.State,
-			fmt.Sprintf("%d x %s", app.Instances, byteSize(app.Memory*MEGABYTE)),
+			fmt.Sprintf("%d x %s", app.Instances, formatters.ByteSize(app.Memory*MEGABYTE)),
 			strings.Join(app.Urls, ", "),
 		})
 	}
@@ -3,6 +3,7 @@ package application_test
 import (
 	"cf"
 	. "cf/commands/application"
+	"cf/net"
 	"github.com/stretchr/testify/assert"
 	"testhelpers"
 	"testing"
@@ -40,6 +41,38 @@ func TestApps(t *testing.T) {
 	assert.Contains(t, ui.Outputs[4], "app2.cfapps.io")
 }
 
+func TestAppsWithStructuredOutput(t *testing.T) {
+	apps := []cf.Application{
+		cf.Application{Name: "Application-1", State: "started", Instances: 1, Memory: 512},
+	}
+	spaceRepo := &testhelpers.FakeSpaceRepository{
+		CurrentSpace: cf.Space{Name: "development", Guid: "development-guid"},
+		SummarySpace: cf.Space{Applications: apps},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true}
+
+	ui := &testhelpers.FakeUI{StructuredOutput: true}
+	ctxt := testhelpers.NewContext("apps", []string{})
+	testhelpers.RunCommand(NewListApps(ui, spaceRepo), ctxt, reqFactory)
+
+	assert.Equal(t, ui.DisplayedData, apps)
+	assert.Equal(t, len(ui.Outputs), 2)
+}
+
+func TestAppsWhenGettingTheSummaryFails(t *testing.T) {
+	spaceRepo := &testhelpers.FakeSpaceRepository{
+		SummaryErr: net.NewApiStatus("The space could not be found", "40004", 404),
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true}
+
+	ui := callApps(spaceRepo, reqFactory)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "The space could not be found")
+	assert.Equal(t, ui.FailureCode, "40004")
+	assert.Equal(t, ui.FailureStatus, 404)
+}
+
 func TestAppsRequiresLogin(t *testing.T) {
 	spaceRepo := &testhelpers.FakeSpaceRepository{}
 	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: false, TargetedSpaceSuccess: true}
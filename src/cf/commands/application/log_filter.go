@@ -0,0 +1,170 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cloudfoundry/loggregatorlib/logmessage"
+	"github.com/codegangsta/cli"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logSourceTypesByFlag maps the names accepted by --source to source types.
+// Staging output is sent by the DEA, so "staging" and "dea" select the same
+// messages.
+var logSourceTypesByFlag = map[string]logmessage.LogMessage_SourceType{
+	"app":      logmessage.LogMessage_WARDEN_CONTAINER,
+	"router":   logmessage.LogMessage_ROUTER,
+	"staging":  logmessage.LogMessage_DEA,
+	"dea":      logmessage.LogMessage_DEA,
+	"executor": logmessage.LogMessage_DEA,
+	"cf":       logmessage.LogMessage_CLOUD_CONTROLLER,
+	"api":      logmessage.LogMessage_CLOUD_CONTROLLER,
+	"uaa":      logmessage.LogMessage_UAA,
+}
+
+// logFilter selects the log messages to show. Zero values select everything.
+type logFilter struct {
+	sourceTypes map[logmessage.LogMessage_SourceType]bool
+	instance    string
+	messageType logmessage.LogMessage_MessageType
+	pattern     *regexp.Regexp
+	since       time.Time
+	until       time.Time
+}
+
+func newLogFilter(c *cli.Context, now time.Time) (filter logFilter, err error) {
+	if c.String("source") != "" {
+		filter.sourceTypes = map[logmessage.LogMessage_SourceType]bool{}
+		for _, name := range strings.Split(c.String("source"), ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			sourceType, found := logSourceTypesByFlag[name]
+			if !found {
+				err = errors.New(fmt.Sprintf("Invalid source %s, expected one of app, router, staging, dea, cf or uaa", name))
+				return
+			}
+			filter.sourceTypes[sourceType] = true
+		}
+	}
+
+	if c.Int("instance") >= 0 {
+		filter.instance = strconv.Itoa(c.Int("instance"))
+	}
+
+	switch strings.ToLower(c.String("stream")) {
+	case "":
+	case "stdout", "out":
+		filter.messageType = logmessage.LogMessage_OUT
+	case "stderr", "err":
+		filter.messageType = logmessage.LogMessage_ERR
+	default:
+		err = errors.New(fmt.Sprintf("Invalid stream %s, expected stdout or stderr", c.String("stream")))
+		return
+	}
+
+	if c.String("grep") != "" {
+		filter.pattern, err = regexp.Compile(c.String("grep"))
+		if err != nil {
+			err = errors.New(fmt.Sprintf("Invalid regular expression %s: %s", c.String("grep"), err.Error()))
+			return
+		}
+	}
+
+	if c.String("since") != "" {
+		filter.since, err = parseLogTime(c.String("since"), now)
+		if err != nil {
+			return
+		}
+	}
+
+	if c.String("until") != "" {
+		filter.until, err = parseLogTime(c.String("until"), now)
+	}
+	return
+}
+
+// parseLogTime accepts either a time such as 2014-01-31T15:04:05Z or a
+// duration such as 10m, meaning that long before now.
+func parseLogTime(value string, now time.Time) (t time.Time, err error) {
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		t = now.Add(-duration)
+		return
+	}
+
+	t, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Invalid time %s, expected a duration like 10m or a time like 2014-01-31T15:04:05Z", value))
+	}
+	return
+}
+
+func (filter logFilter) matches(msg logmessage.LogMessage) bool {
+	if filter.sourceTypes != nil && !filter.sourceTypes[msg.GetSourceType()] {
+		return false
+	}
+
+	// Only app messages come from a particular instance
+	if filter.instance != "" {
+		if msg.GetSourceType() != logmessage.LogMessage_WARDEN_CONTAINER || msg.GetSourceId() != filter.instance {
+			return false
+		}
+	}
+
+	if filter.messageType != 0 && msg.GetMessageType() != filter.messageType {
+		return false
+	}
+
+	if filter.pattern != nil && !filter.pattern.Match(msg.GetMessage()) {
+		return false
+	}
+
+	timestamp := time.Unix(0, msg.GetTimestamp())
+	if !filter.since.IsZero() && timestamp.Before(filter.since) {
+		return false
+	}
+
+	if !filter.until.IsZero() && timestamp.After(filter.until) {
+		return false
+	}
+
+	return true
+}
+
+type logEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	App       string    `json:"app"`
+	Source    string    `json:"source"`
+	Instance  string    `json:"instance,omitempty"`
+	Stream    string    `json:"stream"`
+	Message   string    `json:"message"`
+}
+
+func newLogEntry(appName string, msg logmessage.LogMessage) (entry logEntry) {
+	entry = logEntry{
+		Timestamp: time.Unix(0, msg.GetTimestamp()).UTC(),
+		App:       appName,
+		Source:    logSourceTypeNames[msg.GetSourceType()],
+		Stream:    "stdout",
+		Message:   string(msg.GetMessage()),
+	}
+
+	if msg.GetSourceType() == logmessage.LogMessage_WARDEN_CONTAINER {
+		entry.Instance = msg.GetSourceId()
+	}
+
+	if msg.GetMessageType() == logmessage.LogMessage_ERR {
+		entry.Stream = "stderr"
+	}
+	return
+}
+
+// logMessageJSON formats msg as a single line of JSON, so the output of
+// cf logs --json can be read one message per line.
+func logMessageJSON(appName string, msg logmessage.LogMessage) string {
+	bytes, _ := json.Marshal(newLogEntry(appName, msg))
+	return string(bytes)
+}
@@ -1,34 +1,68 @@
 package application
 
 import (
+	"cf"
 	"cf/api"
 	"cf/requirements"
 	"cf/terminal"
 	"errors"
+	"fmt"
 	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"github.com/codegangsta/cli"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
 )
 
 type Logs struct {
-	ui       terminal.UI
-	logsRepo api.LogsRepository
-	appReq   requirements.ApplicationRequirement
+	ui        terminal.UI
+	logsRepo  api.LogsRepository
+	appRepo   api.ApplicationRepository
+	spaceRepo api.SpaceRepository
+	appReq    requirements.ApplicationRequirement
+	filter    logFilter
 }
 
-func NewLogs(ui terminal.UI, logsRepo api.LogsRepository) (cmd *Logs) {
+func NewLogs(ui terminal.UI, logsRepo api.LogsRepository, appRepo api.ApplicationRepository, spaceRepo api.SpaceRepository) (cmd *Logs) {
 	cmd = new(Logs)
 	cmd.ui = ui
 	cmd.logsRepo = logsRepo
+	cmd.appRepo = appRepo
+	cmd.spaceRepo = spaceRepo
 	return
 }
 
 func (cmd *Logs) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
-	if len(c.Args()) != 1 {
+	allApps := c.Bool("space")
+	if (len(c.Args()) == 0) != allApps {
 		cmd.ui.FailWithUsage(c, "logs")
 		err = errors.New("Incorrect Usage")
 		return
 	}
 
+	if !c.Bool("recent") && (c.String("since") != "" || c.String("until") != "") {
+		cmd.ui.Failed("Incorrect Usage. --since and --until can only be used with --recent.")
+		err = errors.New("Incorrect Usage")
+		return
+	}
+
+	cmd.filter, err = newLogFilter(c, time.Now())
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	if len(c.Args()) != 1 {
+		cmd.appReq = nil
+		reqs = []requirements.Requirement{
+			reqFactory.NewLoginRequirement(),
+			reqFactory.NewTargetedSpaceRequirement(),
+		}
+		return
+	}
+
 	cmd.appReq = reqFactory.NewApplicationRequirement(c.Args()[0])
 
 	reqs = []requirements.Requirement{
@@ -40,30 +74,261 @@ func (cmd *Logs) GetRequirements(reqFactory requirements.Factory, c *cli.Context
 }
 
 func (cmd *Logs) Run(c *cli.Context) {
-	app := cmd.appReq.GetApplication()
+	if cmd.appReq != nil {
+		cmd.logApp(c, cmd.appReq.GetApplication())
+		return
+	}
+
+	apps, ok := cmd.findApps(c)
+	if !ok {
+		return
+	}
+
+	if c.Bool("recent") {
+		cmd.recentLogsForApps(c, apps)
+	} else {
+		cmd.tailLogsForApps(c, apps, appPrefixes(apps))
+	}
+}
+
+func (cmd *Logs) logApp(c *cli.Context, app cf.Application) {
+	if !c.Bool("recent") {
+		cmd.tailLogsForApps(c, []cf.Application{app}, map[string]appPrefix{
+			app.Guid: appPrefix{name: app.Name, coloredName: app.Name},
+		})
+		return
+	}
+
+	onConnect := func() {
+		cmd.say(c, "Connected, dumping recent logs...")
+	}
 
 	onMessage := func(msg logmessage.LogMessage) {
-		cmd.ui.Say(logMessageOutput(app.Name, msg))
+		cmd.showMessage(c, app.Name, app.Name, msg)
 	}
 
-	var err error
+	err := cmd.logsRepo.RecentLogsFor(app, onConnect, onMessage, "4443")
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+}
 
-	if c.Bool("recent") {
-		onConnect := func() {
-			cmd.ui.Say("Connected, dumping recent logs...")
+func (cmd *Logs) findApps(c *cli.Context) (apps []cf.Application, ok bool) {
+	if c.Bool("space") {
+		space, apiResponse := cmd.spaceRepo.GetSummary()
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Failed(apiResponse.Message)
+			return
 		}
 
-		err = cmd.logsRepo.RecentLogsFor(app, onConnect, onMessage, "4443")
-	} else {
-		onConnect := func() {
-			cmd.ui.Say("Connected, tailing...")
+		if len(space.Applications) == 0 {
+			cmd.ui.Failed("There are no apps in space %s", space.Name)
+			return
+		}
+
+		apps = space.Applications
+		ok = true
+		return
+	}
+
+	for _, name := range c.Args() {
+		app, apiResponse := cmd.appRepo.FindByName(name)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Failed(apiResponse.Message)
+			return
+		}
+		apps = append(apps, app)
+	}
+
+	ok = true
+	return
+}
+
+// recentLogsForApps dumps the recent logs of each of apps, then shows all of
+// them together in the order they were logged.
+func (cmd *Logs) recentLogsForApps(c *cli.Context, apps []cf.Application) {
+	cmd.say(c, fmt.Sprintf("Connected, dumping recent logs for %s...", appNames(apps)))
+
+	messages := logMessagesByTimestamp{}
+	for _, app := range apps {
+		onMessage := func(msg logmessage.LogMessage) {
+			messages = append(messages, msg)
+		}
+
+		err := cmd.logsRepo.RecentLogsFor(app, func() {}, onMessage, "4443")
+		if err != nil {
+			cmd.ui.Failed(err.Error())
+			return
+		}
+	}
+
+	sort.Stable(messages)
+
+	prefixes := appPrefixes(apps)
+	for _, msg := range messages {
+		cmd.showMessage(c, prefixes[msg.GetAppId()].name, prefixes[msg.GetAppId()].coloredName, msg)
+	}
+}
+
+// tailLogsForApps tails apps until the user interrupts it. When the logs of
+// an app drop and come back, the user is told how long they were gone, since
+// messages logged in the meantime are lost.
+func (cmd *Logs) tailLogsForApps(c *cli.Context, apps []cf.Application, prefixes map[string]appPrefix) {
+	disconnectedAt := map[string]time.Time{}
+
+	onConnect := func(app cf.Application) {
+		since, reconnected := disconnectedAt[app.Guid]
+		switch {
+		case reconnected:
+			delete(disconnectedAt, app.Guid)
+			gap := time.Since(since) / time.Second * time.Second
+			cmd.say(c, terminal.WarningColor(fmt.Sprintf("Reconnected to %s, messages logged in the last %s may be missing", app.Name, gap)))
+		case len(apps) == 1:
+			cmd.say(c, "Connected, tailing...")
+		default:
+			cmd.say(c, fmt.Sprintf("Connected to %s, tailing...", terminal.EntityNameColor(app.Name)))
 		}
+	}
 
-		err = cmd.logsRepo.TailLogsFor(app, onConnect, onMessage, 2, "4443")
+	onDisconnect := func(app cf.Application, err error) {
+		disconnectedAt[app.Guid] = time.Now()
+		cmd.say(c, terminal.WarningColor(fmt.Sprintf("Lost the logs of %s (%s), reconnecting...", app.Name, err.Error())))
 	}
 
+	onMessage := func(msg logmessage.LogMessage) {
+		prefix := prefixes[msg.GetAppId()]
+		if len(apps) == 1 {
+			prefix = prefixes[apps[0].Guid]
+		}
+		cmd.showMessage(c, prefix.name, prefix.coloredName, msg)
+	}
+
+	stop, done := stopOnInterrupt()
+	defer done()
+
+	err := cmd.logsRepo.TailLogsForApps(apps, stop, onConnect, onDisconnect, onMessage, 2, "4443")
 	if err != nil {
 		cmd.ui.Failed(err.Error())
 		return
 	}
 }
+
+// stopOnInterrupt returns a channel closed on the first Ctrl-C, so that the
+// tail can print what it has received and return. A second Ctrl-C interrupts
+// cf as usual, in case stopping hangs. done stops catching Ctrl-C.
+func stopOnInterrupt() (stop <-chan bool, done func()) {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	return handleInterrupts(interrupts, reraiseInterrupt)
+}
+
+func handleInterrupts(interrupts chan os.Signal, onSecondInterrupt func()) (stop <-chan bool, done func()) {
+	stopChan := make(chan bool)
+	doneChan := make(chan bool)
+
+	go func() {
+		select {
+		case <-interrupts:
+			select {
+			case <-doneChan:
+				// The tail ended before the interrupt was picked up
+				return
+			default:
+				close(stopChan)
+			}
+		case <-doneChan:
+			return
+		}
+
+		select {
+		case <-interrupts:
+			signal.Stop(interrupts)
+			onSecondInterrupt()
+		case <-doneChan:
+		}
+	}()
+
+	done = func() {
+		signal.Stop(interrupts)
+		close(doneChan)
+	}
+	return stopChan, done
+}
+
+// reraiseInterrupt interrupts cf once Ctrl-C is no longer caught, falling
+// back on exiting where a process cannot signal itself.
+func reraiseInterrupt() {
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(os.Interrupt)
+	}
+	if err != nil {
+		os.Exit(130)
+	}
+}
+
+// showMessage prints msg if it passes the filter. coloredName is the name of
+// the app as shown in front of each line of text.
+func (cmd *Logs) showMessage(c *cli.Context, appName, coloredName string, msg logmessage.LogMessage) {
+	if !cmd.filter.matches(msg) {
+		return
+	}
+
+	switch {
+	case cmd.ui.IsStructuredOutput():
+		cmd.ui.DisplayData(newLogEntry(appName, msg))
+	case c.Bool("json"):
+		cmd.ui.Say("%s", logMessageJSON(appName, msg))
+	default:
+		cmd.ui.Say(logMessageOutput(coloredName, msg))
+	}
+}
+
+// say prints a progress message, except with --json so that the output stays
+// one message per line.
+func (cmd *Logs) say(c *cli.Context, message string) {
+	if !c.Bool("json") {
+		cmd.ui.Say(message)
+	}
+}
+
+type appPrefix struct {
+	name        string
+	coloredName string
+}
+
+// appPrefixes gives each app its own color, keyed by app guid since that is
+// what log messages carry.
+func appPrefixes(apps []cf.Application) (prefixes map[string]appPrefix) {
+	prefixes = map[string]appPrefix{}
+	for index, app := range apps {
+		prefixes[app.Guid] = appPrefix{
+			name:        app.Name,
+			coloredName: terminal.AppNameColor(app.Name, index),
+		}
+	}
+	return
+}
+
+func appNames(apps []cf.Application) string {
+	names := []string{}
+	for _, app := range apps {
+		names = append(names, terminal.EntityNameColor(app.Name))
+	}
+	return strings.Join(names, ", ")
+}
+
+type logMessagesByTimestamp []logmessage.LogMessage
+
+func (messages logMessagesByTimestamp) Len() int {
+	return len(messages)
+}
+
+func (messages logMessagesByTimestamp) Less(i, j int) bool {
+	return messages[i].GetTimestamp() < messages[j].GetTimestamp()
+}
+
+func (messages logMessagesByTimestamp) Swap(i, j int) {
+	messages[i], messages[j] = messages[j], messages[i]
+}
@@ -3,7 +3,10 @@ package application_test
 import (
 	"cf"
 	. "cf/commands/application"
+	"cf/terminal"
 	"code.google.com/p/gogoprotobuf/proto"
+	"encoding/json"
+	"errors"
 	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"github.com/stretchr/testify/assert"
 	"testhelpers"
@@ -106,6 +109,220 @@ func TestLogsTailsTheAppLogs(t *testing.T) {
 	assert.Contains(t, ui.Outputs[1], "Log Line 1")
 }
 
+func TestLogsTellsWhenTheTailDropsAndReconnects(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+
+	reqFactory, logsRepo := getLogsDependencies()
+	reqFactory.Application = app
+	logsRepo.TailDropErr = errors.New("connection reset by peer")
+
+	ui := callLogs([]string{"my-app"}, reqFactory, logsRepo)
+
+	assert.Equal(t, len(ui.Outputs), 3)
+	assert.Contains(t, ui.Outputs[0], "Connected, tailing")
+	assert.Contains(t, ui.Outputs[1], "Lost the logs of my-app")
+	assert.Contains(t, ui.Outputs[1], "connection reset by peer")
+	assert.Contains(t, ui.Outputs[2], "Reconnected to my-app")
+	assert.Contains(t, ui.Outputs[2], "may be missing")
+}
+
+func createLogMessage(message string, sourceType logmessage.LogMessage_SourceType, sourceId string, messageType logmessage.LogMessage_MessageType, timestamp time.Time) (msg logmessage.LogMessage) {
+	msg = logmessage.LogMessage{
+		Message:     []byte(message),
+		AppId:       proto.String("my-app"),
+		MessageType: &messageType,
+		SourceType:  &sourceType,
+		Timestamp:   proto.Int64(timestamp.UnixNano()),
+	}
+	if sourceId != "" {
+		msg.SourceId = proto.String(sourceId)
+	}
+	return
+}
+
+func mixedRecentLogs(now time.Time) []logmessage.LogMessage {
+	return []logmessage.LogMessage{
+		createLogMessage("app 0 out", logmessage.LogMessage_WARDEN_CONTAINER, "0", logmessage.LogMessage_OUT, now.Add(-3*time.Hour)),
+		createLogMessage("app 1 err", logmessage.LogMessage_WARDEN_CONTAINER, "1", logmessage.LogMessage_ERR, now.Add(-2*time.Hour)),
+		createLogMessage("GET /index.html", logmessage.LogMessage_ROUTER, "", logmessage.LogMessage_OUT, now.Add(-1*time.Hour)),
+		createLogMessage("Updated app", logmessage.LogMessage_CLOUD_CONTROLLER, "", logmessage.LogMessage_OUT, now.Add(-1*time.Minute)),
+	}
+}
+
+func callRecentLogsWithFlags(flags []string) (ui *testhelpers.FakeUI) {
+	reqFactory, logsRepo := getLogsDependencies()
+	reqFactory.Application = cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	logsRepo.RecentLogs = mixedRecentLogs(time.Now())
+
+	args := append([]string{"--recent"}, flags...)
+	return callLogs(append(args, "my-app"), reqFactory, logsRepo)
+}
+
+func TestLogsFiltersBySource(t *testing.T) {
+	ui := callRecentLogsWithFlags([]string{"--source", "Router,cf"})
+
+	assert.Equal(t, len(ui.Outputs), 3)
+	assert.Contains(t, ui.Outputs[1], "GET /index.html")
+	assert.Contains(t, ui.Outputs[2], "Updated app")
+}
+
+func TestLogsFiltersByInstanceStreamAndRegex(t *testing.T) {
+	ui := callRecentLogsWithFlags([]string{"--instance", "1"})
+	assert.Equal(t, len(ui.Outputs), 2)
+	assert.Contains(t, ui.Outputs[1], "app 1 err")
+
+	ui = callRecentLogsWithFlags([]string{"--stream", "stdout", "--source", "app"})
+	assert.Equal(t, len(ui.Outputs), 2)
+	assert.Contains(t, ui.Outputs[1], "app 0 out")
+
+	ui = callRecentLogsWithFlags([]string{"--grep", "^app [0-9]"})
+	assert.Equal(t, len(ui.Outputs), 3)
+	assert.Contains(t, ui.Outputs[1], "app 0 out")
+	assert.Contains(t, ui.Outputs[2], "app 1 err")
+}
+
+func TestLogsRecentWithTimeWindow(t *testing.T) {
+	ui := callRecentLogsWithFlags([]string{"--since", "150m", "--until", "30m"})
+
+	assert.Equal(t, len(ui.Outputs), 3)
+	assert.Contains(t, ui.Outputs[1], "app 1 err")
+	assert.Contains(t, ui.Outputs[2], "GET /index.html")
+}
+
+func TestLogsPrintsOneJSONObjectPerLine(t *testing.T) {
+	ui := callRecentLogsWithFlags([]string{"--json", "--source", "app"})
+
+	assert.Equal(t, len(ui.Outputs), 2)
+
+	entry := map[string]interface{}{}
+	err := json.Unmarshal([]byte(ui.Outputs[1]), &entry)
+	assert.NoError(t, err)
+	assert.Equal(t, entry["app"], "my-app")
+	assert.Equal(t, entry["source"], "App")
+	assert.Equal(t, entry["instance"], "1")
+	assert.Equal(t, entry["stream"], "stderr")
+	assert.Equal(t, entry["message"], "app 1 err")
+	assert.NotEqual(t, entry["timestamp"], "")
+}
+
+func TestLogsFailsWithInvalidFilters(t *testing.T) {
+	reqFactory, logsRepo := getLogsDependencies()
+
+	ui := callLogs([]string{"--since", "10m", "my-app"}, reqFactory, logsRepo)
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "--recent")
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+
+	ui = callLogs([]string{"--grep", "(", "my-app"}, reqFactory, logsRepo)
+	assert.Contains(t, ui.Outputs[1], "Invalid regular expression")
+
+	ui = callLogs([]string{"--source", "foo", "my-app"}, reqFactory, logsRepo)
+	assert.Contains(t, ui.Outputs[1], "Invalid source foo")
+
+	ui = callLogs([]string{"--recent", "--since", "yesterday", "my-app"}, reqFactory, logsRepo)
+	assert.Contains(t, ui.Outputs[1], "Invalid time yesterday")
+}
+
+func createAppLogMessage(message, appGuid string, timestamp int64) (msg logmessage.LogMessage) {
+	msg = createLogMessage(message, logmessage.LogMessage_WARDEN_CONTAINER, "0", logmessage.LogMessage_OUT, time.Unix(0, timestamp))
+	msg.AppId = proto.String(appGuid)
+	return
+}
+
+func TestLogsRequirementsForSeveralApps(t *testing.T) {
+	reqFactory, logsRepo := getLogsDependencies()
+
+	ui := callLogs([]string{"--space", "my-app"}, reqFactory, logsRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	reqFactory.TargetedSpaceSuccess = false
+	callLogs([]string{"app1", "app2"}, reqFactory, logsRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+	assert.Equal(t, reqFactory.ApplicationName, "")
+
+	reqFactory.TargetedSpaceSuccess = true
+	callLogs([]string{"--space"}, reqFactory, logsRepo)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+}
+
+func TestLogsTailsSeveralApps(t *testing.T) {
+	reqFactory, logsRepo := getLogsDependencies()
+	reqFactory.TargetedSpaceSuccess = true
+	appRepo := &testhelpers.FakeApplicationRepository{FindByNameApps: map[string]cf.Application{
+		"app1": cf.Application{Name: "app1", Guid: "app1-guid"},
+		"app2": cf.Application{Name: "app2", Guid: "app2-guid"},
+	}}
+	logsRepo.TailLogMessages = []logmessage.LogMessage{
+		createAppLogMessage("Hello from app1", "app1-guid", 1000),
+		createAppLogMessage("Hello from app2", "app2-guid", 2000),
+	}
+
+	ui := callLogsForApps([]string{"app1", "app2"}, reqFactory, logsRepo, appRepo, &testhelpers.FakeSpaceRepository{})
+
+	assert.Equal(t, logsRepo.AppsLogged, []cf.Application{
+		cf.Application{Name: "app1", Guid: "app1-guid"},
+		cf.Application{Name: "app2", Guid: "app2-guid"},
+	})
+	assert.Equal(t, len(ui.Outputs), 4)
+	assert.Contains(t, ui.Outputs[0], "Connected to")
+	assert.Contains(t, ui.Outputs[0], "app1")
+	assert.Contains(t, ui.Outputs[1], "app2")
+	assert.Contains(t, ui.Outputs[2], terminal.AppNameColor("app1", 0))
+	assert.Contains(t, ui.Outputs[2], "Hello from app1")
+	assert.Contains(t, ui.Outputs[3], terminal.AppNameColor("app2", 1))
+	assert.Contains(t, ui.Outputs[3], "Hello from app2")
+}
+
+func TestLogsFailsWhenOneOfSeveralAppsIsNotFound(t *testing.T) {
+	reqFactory, logsRepo := getLogsDependencies()
+	reqFactory.TargetedSpaceSuccess = true
+	appRepo := &testhelpers.FakeApplicationRepository{FindByNameApps: map[string]cf.Application{
+		"app1": cf.Application{Name: "app1", Guid: "app1-guid"},
+	}}
+
+	ui := callLogsForApps([]string{"app1", "app2"}, reqFactory, logsRepo, appRepo, &testhelpers.FakeSpaceRepository{})
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "app2")
+	assert.Equal(t, len(logsRepo.AppsLogged), 0)
+}
+
+func TestLogsRecentForEveryAppInTheSpace(t *testing.T) {
+	reqFactory, logsRepo := getLogsDependencies()
+	reqFactory.TargetedSpaceSuccess = true
+	spaceRepo := &testhelpers.FakeSpaceRepository{SummarySpace: cf.Space{
+		Name: "my-space",
+		Applications: []cf.Application{
+			cf.Application{Name: "app1", Guid: "app1-guid"},
+			cf.Application{Name: "app2", Guid: "app2-guid"},
+		},
+	}}
+	logsRepo.RecentLogsByAppGuid = map[string][]logmessage.LogMessage{
+		"app1-guid": {createAppLogMessage("app1 first", "app1-guid", 1000), createAppLogMessage("app1 third", "app1-guid", 3000)},
+		"app2-guid": {createAppLogMessage("app2 second", "app2-guid", 2000)},
+	}
+
+	ui := callLogsForApps([]string{"--space", "--recent"}, reqFactory, logsRepo, &testhelpers.FakeApplicationRepository{}, spaceRepo)
+
+	assert.Equal(t, len(logsRepo.AppsLogged), 2)
+	assert.Equal(t, len(ui.Outputs), 4)
+	assert.Contains(t, ui.Outputs[0], "dumping recent logs for")
+	assert.Contains(t, ui.Outputs[1], "app1 first")
+	assert.Contains(t, ui.Outputs[2], "app2 second")
+	assert.Contains(t, ui.Outputs[3], "app1 third")
+}
+
+func TestLogsForAnEmptySpace(t *testing.T) {
+	reqFactory, logsRepo := getLogsDependencies()
+	reqFactory.TargetedSpaceSuccess = true
+	spaceRepo := &testhelpers.FakeSpaceRepository{SummarySpace: cf.Space{Name: "my-space"}}
+
+	ui := callLogsForApps([]string{"--space"}, reqFactory, logsRepo, &testhelpers.FakeApplicationRepository{}, spaceRepo)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "There are no apps in space my-space")
+}
+
 func getLogsDependencies() (reqFactory *testhelpers.FakeReqFactory, logsRepo *testhelpers.FakeLogsRepository) {
 	logsRepo = &testhelpers.FakeLogsRepository{}
 	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true}
@@ -113,9 +330,13 @@ func getLogsDependencies() (reqFactory *testhelpers.FakeReqFactory, logsRepo *te
 }
 
 func callLogs(args []string, reqFactory *testhelpers.FakeReqFactory, logsRepo *testhelpers.FakeLogsRepository) (ui *testhelpers.FakeUI) {
+	return callLogsForApps(args, reqFactory, logsRepo, &testhelpers.FakeApplicationRepository{}, &testhelpers.FakeSpaceRepository{})
+}
+
+func callLogsForApps(args []string, reqFactory *testhelpers.FakeReqFactory, logsRepo *testhelpers.FakeLogsRepository, appRepo *testhelpers.FakeApplicationRepository, spaceRepo *testhelpers.FakeSpaceRepository) (ui *testhelpers.FakeUI) {
 	ui = new(testhelpers.FakeUI)
 	ctxt := testhelpers.NewContext("logs", args)
-	cmd := NewLogs(ui, logsRepo)
+	cmd := NewLogs(ui, logsRepo, appRepo, spaceRepo)
 	testhelpers.RunCommand(cmd, ctxt, reqFactory)
 	return
 }
@@ -3,43 +3,55 @@ package application
 import (
 	"cf"
 	"cf/api"
+	"cf/configuration"
+	"cf/formatters"
+	"cf/manifest"
 	"cf/net"
 	"cf/requirements"
 	"cf/terminal"
+	"errors"
 	"fmt"
 	"github.com/codegangsta/cli"
 	"os"
+	"path/filepath"
 	"strconv"
-	"strings"
 )
 
 type Push struct {
 	ui          terminal.UI
+	config      *configuration.Configuration
 	starter     ApplicationStarter
 	stopper     ApplicationStopper
 	appRepo     api.ApplicationRepository
 	domainRepo  api.DomainRepository
 	routeRepo   api.RouteRepository
 	stackRepo   api.StackRepository
+	serviceRepo api.ServiceRepository
 	appBitsRepo api.ApplicationBitsRepository
 }
 
-func NewPush(ui terminal.UI, starter ApplicationStarter, stopper ApplicationStopper,
+func NewPush(ui terminal.UI, config *configuration.Configuration, starter ApplicationStarter, stopper ApplicationStopper,
 	aR api.ApplicationRepository, dR api.DomainRepository, rR api.RouteRepository, sR api.StackRepository,
-	appBitsRepo api.ApplicationBitsRepository) (cmd Push) {
+	serviceRepo api.ServiceRepository, appBitsRepo api.ApplicationBitsRepository) (cmd Push) {
 
 	cmd.ui = ui
+	cmd.config = config
 	cmd.starter = starter
 	cmd.stopper = stopper
 	cmd.appRepo = aR
 	cmd.domainRepo = dR
 	cmd.routeRepo = rR
 	cmd.stackRepo = sR
+	cmd.serviceRepo = serviceRepo
 	cmd.appBitsRepo = appBitsRepo
 	return
 }
 
 func (cmd Push) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if c.Bool("dry-run-files") {
+		return
+	}
+
 	reqs = []requirements.Requirement{
 		reqFactory.NewLoginRequirement(),
 		reqFactory.NewTargetedSpaceRequirement(),
@@ -48,99 +60,514 @@ func (cmd Push) GetRequirements(reqFactory requirements.Factory, c *cli.Context)
 }
 
 func (cmd Push) Run(c *cli.Context) {
-	var err error
+	if len(c.Args()) > 1 {
+		cmd.ui.FailWithUsage(c, "push")
+		return
+	}
+
+	if c.Bool("blue-green") && c.Bool("no-start") {
+		cmd.ui.Failed("Incorrect Usage. --blue-green cannot be used with --no-start.")
+		return
+	}
 
-	if len(c.Args()) != 1 {
+	appsParams, err := cmd.findAppsToPush(c)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	if len(appsParams) == 0 {
 		cmd.ui.FailWithUsage(c, "push")
 		return
 	}
 
-	appName := c.Args()[0]
+	for _, appParams := range appsParams {
+		if appParams.Memory == "" {
+			continue
+		}
+
+		_, err = getMemoryLimit(appParams.Memory)
+		if err != nil {
+			cmd.ui.Failed(err.Error())
+			return
+		}
+	}
+
+	if c.Bool("dry-run-files") {
+		cmd.listAppFiles(appsParams)
+		return
+	}
+
+	for _, appParams := range appsParams {
+		apiResponse := cmd.pushApp(appParams, c)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+	}
+}
+
+func (cmd Push) findAppsToPush(c *cli.Context) (appsParams []manifest.Application, err error) {
+	flagParams := appParamsFromContext(c)
+
+	appManifest, found, err := cmd.loadManifest(c)
+	if err != nil {
+		return
+	}
+
+	if !found {
+		if flagParams.Name != "" {
+			appsParams = append(appsParams, flagParams)
+		}
+		return
+	}
+
+	cmd.ui.Say("Using manifest file %s", terminal.EntityNameColor(appManifest.Path))
+
+	switch {
+	case flagParams.Name != "" && len(appManifest.Applications) == 1:
+		appsParams = append(appsParams, appManifest.Applications[0].Merge(flagParams))
+	case flagParams.Name != "":
+		appParams, appFound := appManifest.FindByName(flagParams.Name)
+		if !appFound {
+			err = errors.New(fmt.Sprintf("Could not find app %s in manifest %s", flagParams.Name, appManifest.Path))
+			return
+		}
+		appsParams = append(appsParams, appParams.Merge(flagParams))
+	case len(appManifest.Applications) > 1 && hasAppFlags(flagParams):
+		err = errors.New("Incorrect Usage. Command line flags (except -f) cannot be applied when pushing multiple apps from a manifest file.")
+		return
+	default:
+		for _, appParams := range appManifest.Applications {
+			appsParams = append(appsParams, appParams.Merge(flagParams))
+		}
+	}
+	return
+}
+
+func (cmd Push) loadManifest(c *cli.Context) (appManifest manifest.Manifest, found bool, err error) {
+	path := c.String("f")
+	if path == "" {
+		path, err = defaultManifestDir(c.String("p"))
+		if err != nil {
+			return
+		}
+
+		_, statErr := manifest.ManifestPath(path)
+		if statErr != nil {
+			return
+		}
+	}
+
+	appManifest, err = manifest.Load(path)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Error reading manifest file:\n%s", err.Error()))
+		return
+	}
+
+	found = true
+	return
+}
+
+func defaultManifestDir(appPath string) (dir string, err error) {
+	if appPath != "" {
+		fileInfo, statErr := os.Stat(appPath)
+		if statErr == nil && fileInfo.IsDir() {
+			dir = appPath
+			return
+		}
+	}
+
+	return os.Getwd()
+}
 
-	app, apiResponse := cmd.appRepo.FindByName(appName)
+func appParamsFromContext(c *cli.Context) (appParams manifest.Application) {
+	if len(c.Args()) > 0 {
+		appParams.Name = c.Args()[0]
+	}
+
+	appParams.Instances = c.Int("i")
+	appParams.Memory = c.String("m")
+	appParams.BuildpackUrl = c.String("b")
+	appParams.Command = c.String("c")
+	appParams.StackName = c.String("s")
+	appParams.Path = c.String("p")
+
+	if c.String("n") != "" {
+		appParams.Hosts = []string{c.String("n")}
+	}
+	if c.String("d") != "" {
+		appParams.Domains = []string{c.String("d")}
+	}
+	return
+}
+
+func hasAppFlags(appParams manifest.Application) bool {
+	return appParams.Instances > 0 || appParams.Memory != "" || appParams.BuildpackUrl != "" ||
+		appParams.Command != "" || appParams.StackName != "" || appParams.Path != "" ||
+		len(appParams.Hosts) > 0 || len(appParams.Domains) > 0
+}
+
+func (cmd Push) pushApp(appParams manifest.Application, c *cli.Context) (apiResponse net.ApiResponse) {
+	app, apiResponse := cmd.appRepo.FindByName(appParams.Name)
 	if apiResponse.IsError() {
 		cmd.ui.Failed(apiResponse.Message)
 		return
 	}
 
 	if apiResponse.IsNotFound() {
-		app, apiResponse = cmd.createApp(appName, c)
+		app, apiResponse = cmd.createApp(defaultAppParams.Merge(appParams))
+	} else if c.Bool("blue-green") {
+		apiResponse = cmd.blueGreenPush(app, appParams, c.Bool("keep-old"))
+		return
+	} else {
+		app, apiResponse = cmd.updateApp(app, appParams)
+	}
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
 
-		if apiResponse.IsNotSuccessful() {
-			cmd.ui.Failed(apiResponse.Message)
-			return
-		}
+	apiResponse = cmd.bindServices(app, appParams.Services)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	apiResponse = cmd.uploadApp(app, appParams.Path)
+	if apiResponse.IsNotSuccessful() {
+		return
 	}
 
+	updatedApp, _ := cmd.stopper.ApplicationStop(app)
+	if !c.Bool("no-start") {
+		cmd.starter.ApplicationStart(updatedApp)
+	}
+	return
+}
+
+func (cmd Push) uploadApp(app cf.Application, dir string) (apiResponse net.ApiResponse) {
 	cmd.ui.Say("Uploading %s...", terminal.EntityNameColor(app.Name))
 
-	dir := c.String("p")
 	if dir == "" {
+		var err error
 		dir, err = os.Getwd()
 		if err != nil {
-			cmd.ui.Failed(err.Error())
+			apiResponse = net.NewApiStatusWithError("Error finding app directory", err)
+			cmd.ui.Failed(apiResponse.Message)
 			return
 		}
 	}
 
-	apiResponse = cmd.appBitsRepo.UploadApp(app, dir)
+	apiResponse = cmd.appBitsRepo.UploadApp(app, dir, progressByPercent(cmd.ui))
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
 
 	cmd.ui.Ok()
-	updatedApp, _ := cmd.stopper.ApplicationStop(app)
-	if !c.Bool("no-start") {
-		if c.String("b") != "" {
-			updatedApp.BuildpackUrl = c.String("b")
+	return
+}
+
+type appFilesListing struct {
+	Name  string       `json:"name"`
+	Path  string       `json:"path"`
+	Files []cf.AppFile `json:"files"`
+}
+
+// listAppFiles shows the files that would be pushed for every app, after
+// .cfignore is applied, without talking to the cloud controller. Files the
+// cloud controller already has are still skipped on an actual push.
+func (cmd Push) listAppFiles(appsParams []manifest.Application) {
+	listings := []appFilesListing{}
+
+	for _, appParams := range appsParams {
+		dir := appParams.Path
+		if dir == "" {
+			var err error
+			dir, err = os.Getwd()
+			if err != nil {
+				cmd.ui.Failed("Error finding app directory\n%s", err.Error())
+				return
+			}
 		}
-		cmd.starter.ApplicationStart(updatedApp)
+
+		cmd.ui.Say("Listing files to push for %s from %s...", terminal.EntityNameColor(appParams.Name), terminal.EntityNameColor(dir))
+
+		fileInfo, err := os.Stat(dir)
+		if err != nil {
+			cmd.ui.Failed("Error reading app directory\n%s", err.Error())
+			return
+		}
+		if !fileInfo.IsDir() {
+			cmd.ui.Failed("%s is not a directory. Archives are pushed with all of their files.", dir)
+			return
+		}
+
+		appFiles, err := cf.AppFilesInDir(dir)
+		if err != nil {
+			cmd.ui.Failed("Error listing app files\n%s", err.Error())
+			return
+		}
+		cmd.ui.Ok()
+
+		listings = append(listings, appFilesListing{Name: appParams.Name, Path: dir, Files: appFiles})
+		if cmd.ui.IsStructuredOutput() {
+			continue
+		}
+
+		table := [][]string{
+			[]string{"path", "size"},
+		}
+
+		var totalSize int64
+		for _, file := range appFiles {
+			table = append(table, []string{filepath.ToSlash(file.Path), formatters.ByteSize(uint64(file.Size))})
+			totalSize += file.Size
+		}
+
+		cmd.ui.DisplayTable(table, nil)
+		cmd.ui.Say("%d files, %s", len(appFiles), formatters.ByteSize(uint64(totalSize)))
+	}
+
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(listings)
 	}
 }
 
-func (cmd Push) createApp(appName string, c *cli.Context) (app cf.Application, apiResponse net.ApiResponse) {
-	domainName := c.String("d")
+var defaultAppParams = manifest.Application{Instances: 1, Memory: "128"}
+
+func (cmd Push) createApp(appParams manifest.Application) (app cf.Application, apiResponse net.ApiResponse) {
+	app, apiResponse = cmd.createAppWithoutRoutes(appParams, cf.Stack{})
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	if len(appParams.Hosts) == 0 {
+		appParams.Hosts = []string{app.Name}
+	}
+
+	apiResponse = cmd.bindRoutes(app, appParams)
+	return
+}
+
+// createAppWithoutRoutes creates the app and sets its env variables. The app
+// runs on stack unless appParams names a different one.
+func (cmd Push) createAppWithoutRoutes(appParams manifest.Application, stack cf.Stack) (app cf.Application, apiResponse net.ApiResponse) {
+	// The memory limit was checked before anything was pushed
+	memory, _ := getMemoryLimit(appParams.Memory)
+
 	newApp := cf.Application{
-		Name:         appName,
-		Instances:    c.Int("i"),
-		Memory:       getMemoryLimit(c.String("m")),
-		BuildpackUrl: c.String("b"),
-		Command:      c.String("c"),
+		Name:         appParams.Name,
+		Instances:    appParams.Instances,
+		Memory:       memory,
+		BuildpackUrl: appParams.BuildpackUrl,
+		Command:      appParams.Command,
+		Stack:        stack,
+	}
+
+	if appParams.StackName != "" {
+		newApp.Stack, apiResponse = cmd.findStack(appParams.StackName)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
 	}
 
-	stackName := c.String("s")
-	if stackName != "" {
+	cmd.ui.Say("Creating %s...", terminal.EntityNameColor(appParams.Name))
+	app, apiResponse = cmd.appRepo.Create(newApp)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+	cmd.ui.Ok()
+
+	if len(appParams.EnvironmentVars) > 0 {
+		cmd.ui.Say("Setting env variables for %s...", terminal.EntityNameColor(app.Name))
+		apiResponse = cmd.appRepo.SetEnv(app, appParams.EnvironmentVars)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Failed(apiResponse.Message)
+			return
+		}
+		app.EnvironmentVars = appParams.EnvironmentVars
+		cmd.ui.Ok()
+	}
+	return
+}
+
+// updateApp changes the attributes and env variables of an existing app that
+// differ from the ones requested and maps any new routes. Attributes that
+// were not requested are left as they are.
+func (cmd Push) updateApp(app cf.Application, appParams manifest.Application) (updatedApp cf.Application, apiResponse net.ApiResponse) {
+	updatedApp = app
+	changes := cf.Application{Guid: app.Guid, Name: app.Name}
+	changeDescriptions := []string{}
+
+	if appParams.Instances > 0 && appParams.Instances != app.Instances {
+		changes.Instances = appParams.Instances
+		changeDescriptions = append(changeDescriptions,
+			fmt.Sprintf("instances: %d -> %d", app.Instances, appParams.Instances))
+	}
+
+	if appParams.Memory != "" {
+		// The memory limit was checked before anything was pushed
+		memory, _ := getMemoryLimit(appParams.Memory)
+		if memory != app.Memory {
+			changes.Memory = memory
+			changeDescriptions = append(changeDescriptions,
+				fmt.Sprintf("memory: %s -> %s", formatters.ByteSize(app.Memory*MEGABYTE), formatters.ByteSize(memory*MEGABYTE)))
+		}
+	}
+
+	if appParams.Command != "" && appParams.Command != app.Command {
+		changes.Command = appParams.Command
+		changeDescriptions = append(changeDescriptions,
+			fmt.Sprintf("command: %s -> %s", describeValue(app.Command), appParams.Command))
+	}
+
+	if appParams.BuildpackUrl != "" && appParams.BuildpackUrl != app.BuildpackUrl {
+		changes.BuildpackUrl = appParams.BuildpackUrl
+		changeDescriptions = append(changeDescriptions,
+			fmt.Sprintf("buildpack: %s -> %s", describeValue(app.BuildpackUrl), appParams.BuildpackUrl))
+	}
+
+	if appParams.StackName != "" {
 		var stack cf.Stack
-		stack, apiResponse = cmd.stackRepo.FindByName(stackName)
+		stack, apiResponse = cmd.findStack(appParams.StackName)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+
+		if stack.Guid != app.Stack.Guid {
+			changes.Stack = stack
+			changeDescriptions = append(changeDescriptions, fmt.Sprintf("stack: %s", stack.Name))
+		}
+	}
 
+	if len(changeDescriptions) > 0 {
+		cmd.ui.Say("Updating %s...", terminal.EntityNameColor(app.Name))
+		for _, description := range changeDescriptions {
+			cmd.ui.Say("  %s", description)
+		}
+
+		_, apiResponse = cmd.appRepo.Update(changes)
 		if apiResponse.IsNotSuccessful() {
 			cmd.ui.Failed(apiResponse.Message)
 			return
 		}
-		newApp.Stack = stack
-		cmd.ui.Say("Using stack %s...", terminal.EntityNameColor(stack.Name))
+		cmd.ui.Ok()
+
+		if changes.Instances > 0 {
+			updatedApp.Instances = changes.Instances
+		}
+		if changes.Memory > 0 {
+			updatedApp.Memory = changes.Memory
+		}
+		if changes.Command != "" {
+			updatedApp.Command = changes.Command
+		}
+		if changes.BuildpackUrl != "" {
+			updatedApp.BuildpackUrl = changes.BuildpackUrl
+		}
+		if changes.Stack.Guid != "" {
+			updatedApp.Stack = changes.Stack
+		}
 	}
 
-	cmd.ui.Say("Creating %s...", terminal.EntityNameColor(appName))
-	app, apiResponse = cmd.appRepo.Create(newApp)
+	updatedApp, apiResponse = cmd.updateEnv(updatedApp, appParams.EnvironmentVars)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	if len(appParams.Hosts) == 0 && len(appParams.Domains) == 0 {
+		return
+	}
+
+	if len(appParams.Hosts) == 0 {
+		appParams.Hosts = []string{app.Name}
+	}
+
+	apiResponse = cmd.bindRoutes(updatedApp, appParams)
+	return
+}
+
+// updateEnv adds the env variables of the manifest to an existing app, or
+// changes their values. Variables the manifest does not mention, like the
+// ones set with set-env, are kept.
+func (cmd Push) updateEnv(app cf.Application, envVars map[string]string) (updatedApp cf.Application, apiResponse net.ApiResponse) {
+	updatedApp = app
+
+	newEnvVars := map[string]string{}
+	for name, value := range app.EnvironmentVars {
+		newEnvVars[name] = value
+	}
+	for name, value := range envVars {
+		newEnvVars[name] = value
+	}
+
+	changes := diffEnvVars(app.EnvironmentVars, newEnvVars)
+	if changes.IsEmpty() {
+		return
+	}
+
+	cmd.ui.Say("Updating env variables for %s...", terminal.EntityNameColor(app.Name))
+	showEnvVarChanges(cmd.ui, changes, app.EnvironmentVars, newEnvVars)
+
+	apiResponse = cmd.appRepo.SetEnv(app, newEnvVars)
 	if apiResponse.IsNotSuccessful() {
 		cmd.ui.Failed(apiResponse.Message)
 		return
 	}
 	cmd.ui.Ok()
 
-	domain, apiResponse := cmd.domainRepo.FindByNameInCurrentSpace(domainName)
+	updatedApp.EnvironmentVars = newEnvVars
+	return
+}
 
+func (cmd Push) findStack(stackName string) (stack cf.Stack, apiResponse net.ApiResponse) {
+	stack, apiResponse = cmd.stackRepo.FindByName(stackName)
 	if apiResponse.IsNotSuccessful() {
 		cmd.ui.Failed(apiResponse.Message)
 		return
 	}
+	cmd.ui.Say("Using stack %s...", terminal.EntityNameColor(stack.Name))
+	return
+}
 
-	hostName := c.String("n")
-	if hostName == "" {
-		hostName = app.Name
+func (cmd Push) bindRoutes(app cf.Application, appParams manifest.Application) (apiResponse net.ApiResponse) {
+	domainNames := appParams.Domains
+	if len(domainNames) == 0 {
+		domainNames = []string{""}
 	}
 
-	route, apiResponse := cmd.routeRepo.FindByHost(hostName)
+	for _, domainName := range domainNames {
+		var domain cf.Domain
+		domain, apiResponse = cmd.domainRepo.FindByNameInCurrentSpace(domainName)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Failed(apiResponse.Message)
+			return
+		}
+
+		for _, hostName := range appParams.Hosts {
+			url := fmt.Sprintf("%s.%s", hostName, domain.Name)
+			if contains(app.Urls, url) {
+				continue
+			}
 
-	if apiResponse.IsNotSuccessful() {
+			_, apiResponse = cmd.bindRoute(app, hostName, domain)
+			if apiResponse.IsNotSuccessful() {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (cmd Push) bindRoute(app cf.Application, hostName string, domain cf.Domain) (route cf.Route, apiResponse net.ApiResponse) {
+	route, apiResponse = cmd.routeRepo.FindByHostAndDomain(hostName, domain.Name)
+
+	if apiResponse.IsError() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+
+	if apiResponse.IsNotFound() {
 		newRoute := cf.Route{Host: hostName}
 
 		createdUrl := fmt.Sprintf("%s.%s", newRoute.Host, domain.Name)
@@ -168,24 +595,54 @@ func (cmd Push) createApp(appName string, c *cli.Context) (app cf.Application, a
 	return
 }
 
-func getMemoryLimit(arg string) (memory uint64) {
-	var err error
+func (cmd Push) bindServices(app cf.Application, serviceNames []string) (apiResponse net.ApiResponse) {
+	for _, serviceName := range serviceNames {
+		var instance cf.ServiceInstance
+		instance, apiResponse = cmd.serviceRepo.FindInstanceByName(serviceName)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.Failed(apiResponse.Message)
+			return
+		}
 
-	switch {
-	case strings.HasSuffix(arg, "M"):
-		trimmedArg := arg[:len(arg)-1]
-		memory, err = strconv.ParseUint(trimmedArg, 10, 0)
-	case strings.HasSuffix(arg, "G"):
-		trimmedArg := arg[:len(arg)-1]
-		memory, err = strconv.ParseUint(trimmedArg, 10, 0)
-		memory = memory * 1024
-	default:
-		memory, err = strconv.ParseUint(arg, 10, 0)
+		cmd.ui.Say("Binding service %s to %s...", terminal.EntityNameColor(instance.Name), terminal.EntityNameColor(app.Name))
+		apiResponse = cmd.serviceRepo.BindService(instance, app)
+		if apiResponse.IsNotSuccessful() && apiResponse.ErrorCode != api.APP_ALREADY_BOUND_TO_SERVICE {
+			cmd.ui.Failed(apiResponse.Message)
+			return
+		}
+
+		apiResponse = net.ApiResponse{}
+		cmd.ui.Ok()
+	}
+	return
+}
+
+func describeValue(value string) string {
+	if value == "" {
+		return "(default)"
+	}
+	return value
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
+}
 
+// getMemoryLimit parses a memory limit such as 512M or 2G into megabytes. A
+// number without a unit is in megabytes.
+func getMemoryLimit(arg string) (memory uint64, err error) {
+	memory, err = strconv.ParseUint(arg, 10, 0)
 	if err != nil {
-		memory = 128
+		memory, err = formatters.ToMegabytes(arg)
 	}
 
+	if err != nil || memory == 0 {
+		err = errors.New(fmt.Sprintf("Invalid memory limit %s: expected a number with a unit, like 512M or 2G", arg))
+	}
 	return
 }
@@ -4,16 +4,19 @@ import (
 	"cf"
 	"cf/api"
 	. "cf/commands/application"
+	"cf/configuration"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"path/filepath"
+	"strings"
 	"testhelpers"
 	"testing"
 )
 
 func TestPushingRequirements(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 	fakeUI := new(testhelpers.FakeUI)
-	cmd := NewPush(fakeUI, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	cmd := NewPush(fakeUI, &configuration.Configuration{}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 	ctxt := testhelpers.NewContext("push", []string{})
 
 	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true}
@@ -32,18 +35,18 @@ func TestPushingRequirements(t *testing.T) {
 }
 
 func TestPushingAppWhenItDoesNotExist(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 
 	domains := []cf.Domain{
 		cf.Domain{Name: "foo.cf-app.com", Guid: "foo-domain-guid"},
 	}
 
 	domainRepo.FindByNameDomain = domains[0]
-	routeRepo.FindByHostErr = true
+	routeRepo.FindByHostAndDomainNotFound = true
 	appRepo.FindByNameNotFound = true
 	stopper.StoppedApp = cf.Application{Name: "my-stopped-app"}
 
-	fakeUI := callPush([]string{"my-new-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	fakeUI := callPush([]string{"my-new-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 
 	assert.Contains(t, fakeUI.Outputs[0], "my-new-app")
 	assert.Equal(t, appRepo.CreatedApp.Name, "my-new-app")
@@ -53,7 +56,7 @@ func TestPushingAppWhenItDoesNotExist(t *testing.T) {
 	assert.Contains(t, fakeUI.Outputs[1], "OK")
 
 	assert.Contains(t, fakeUI.Outputs[2], "my-new-app.foo.cf-app.com")
-	assert.Equal(t, routeRepo.FindByHostHost, "my-new-app")
+	assert.Equal(t, routeRepo.FindByHostAndDomainHost, "my-new-app")
 	assert.Equal(t, routeRepo.CreatedRoute.Host, "my-new-app")
 	assert.Equal(t, routeRepo.CreatedRouteDomain.Guid, "foo-domain-guid")
 	assert.Contains(t, fakeUI.Outputs[3], "OK")
@@ -76,7 +79,7 @@ func TestPushingAppWhenItDoesNotExist(t *testing.T) {
 }
 
 func TestPushingAppWhenItDoesNotExistButRouteExists(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 
 	domains := []cf.Domain{
 		cf.Domain{Name: "foo.cf-app.com", Guid: "foo-domain-guid"},
@@ -84,15 +87,15 @@ func TestPushingAppWhenItDoesNotExistButRouteExists(t *testing.T) {
 	route := cf.Route{Host: "my-new-app"}
 
 	domainRepo.FindByNameDomain = domains[0]
-	routeRepo.FindByHostRoute = route
+	routeRepo.FindByHostAndDomainRoute = route
 	appRepo.FindByNameNotFound = true
 
-	fakeUI := callPush([]string{"my-new-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	fakeUI := callPush([]string{"my-new-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 
 	assert.Empty(t, routeRepo.CreatedRoute.Host)
 	assert.Empty(t, routeRepo.CreatedRouteDomain.Guid)
 	assert.Contains(t, fakeUI.Outputs[2], "my-new-app.foo.cf-app.com")
-	assert.Equal(t, routeRepo.FindByHostHost, "my-new-app")
+	assert.Equal(t, routeRepo.FindByHostAndDomainHost, "my-new-app")
 
 	assert.Contains(t, fakeUI.Outputs[3], "my-new-app.foo.cf-app.com")
 	assert.Equal(t, routeRepo.BoundApp.Name, "my-new-app")
@@ -101,13 +104,13 @@ func TestPushingAppWhenItDoesNotExistButRouteExists(t *testing.T) {
 }
 
 func TestPushingAppWithCustomFlags(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 
 	domain := cf.Domain{Name: "bar.cf-app.com", Guid: "bar-domain-guid"}
 	stack := cf.Stack{Name: "customLinux", Guid: "custom-linux-guid"}
 
 	domainRepo.FindByNameDomain = domain
-	routeRepo.FindByHostErr = true
+	routeRepo.FindByHostAndDomainNotFound = true
 	stackRepo.FindByNameStack = stack
 	appRepo.FindByNameNotFound = true
 
@@ -122,7 +125,7 @@ func TestPushingAppWithCustomFlags(t *testing.T) {
 		"-s", "customLinux",
 		"--no-start",
 		"my-new-app",
-	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 
 	assert.Contains(t, fakeUI.Outputs[0], "customLinux")
 	assert.Equal(t, stackRepo.FindByNameName, "customLinux")
@@ -157,7 +160,7 @@ func TestPushingAppWithCustomFlags(t *testing.T) {
 }
 
 func TestPushingAppWithMemoryInMegaBytes(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 
 	domain := cf.Domain{Name: "bar.cf-app.com", Guid: "bar-domain-guid"}
 	domainRepo.FindByNameDomain = domain
@@ -166,13 +169,13 @@ func TestPushingAppWithMemoryInMegaBytes(t *testing.T) {
 	callPush([]string{
 		"-m", "256M",
 		"my-new-app",
-	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 
 	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(256))
 }
 
 func TestPushingAppWithMemoryWithoutUnit(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 
 	domain := cf.Domain{Name: "bar.cf-app.com", Guid: "bar-domain-guid"}
 	domainRepo.FindByNameDomain = domain
@@ -181,33 +184,68 @@ func TestPushingAppWithMemoryWithoutUnit(t *testing.T) {
 	callPush([]string{
 		"-m", "512",
 		"my-new-app",
-	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 
 	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(512))
 }
 
 func TestPushingAppWithInvalidMemory(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domain := cf.Domain{Name: "bar.cf-app.com", Guid: "bar-domain-guid"}
+	domainRepo.FindByNameDomain = domain
+	appRepo.FindByNameNotFound = true
+
+	for _, memory := range []string{"abcM", "1GB", "512MB", "0M"} {
+		fakeUI := callPush([]string{
+			"-m", memory,
+			"my-new-app",
+		}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+		assert.Contains(t, fakeUI.Outputs[0], "FAILED")
+		assert.Contains(t, fakeUI.Outputs[1], "Invalid memory limit "+memory)
+		assert.Equal(t, appRepo.CreatedApp.Name, "")
+	}
+}
+
+func TestPushingAppWithLowercaseMemoryUnit(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 
 	domain := cf.Domain{Name: "bar.cf-app.com", Guid: "bar-domain-guid"}
 	domainRepo.FindByNameDomain = domain
 	appRepo.FindByNameNotFound = true
 
 	callPush([]string{
-		"-m", "abcM",
+		"-m", "1g",
 		"my-new-app",
-	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 
-	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(128))
+	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(1024))
+}
+
+func TestPushingExistingAppWithInvalidMemoryLeavesItAlone(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	existingApp := cf.Application{Name: "existing-app", Guid: "existing-app-guid", Memory: 1024}
+	appRepo.FindByNameApp = existingApp
+
+	fakeUI := callPush([]string{
+		"-m", "1GB",
+		"existing-app",
+	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Contains(t, fakeUI.Outputs[0], "FAILED")
+	assert.Equal(t, appRepo.UpdatedApp.Guid, "")
+	assert.Equal(t, appBitsRepo.UploadedApp.Guid, "")
 }
 
 func TestPushingAppWhenItAlreadyExists(t *testing.T) {
-	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo := getPushDependencies()
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
 
 	existingApp := cf.Application{Name: "existing-app", Guid: "existing-app-guid"}
 	appRepo.FindByNameApp = existingApp
 
-	fakeUI := callPush([]string{"existing-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	fakeUI := callPush([]string{"existing-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 
 	assert.Equal(t, stopper.AppToStop.Name, "existing-app")
 	assert.Contains(t, fakeUI.Outputs[0], "existing-app")
@@ -215,12 +253,259 @@ func TestPushingAppWhenItAlreadyExists(t *testing.T) {
 	assert.Contains(t, fakeUI.Outputs[1], "OK")
 }
 
+func TestPushingAppWhenItAlreadyExistsUpdatesChangedAttributes(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domainRepo.FindByNameDomain = cf.Domain{Name: "example.com", Guid: "example-domain-guid"}
+	stackRepo.FindByNameStack = cf.Stack{Name: "customLinux", Guid: "custom-linux-guid"}
+	routeRepo.FindByHostAndDomainNotFound = true
+	appRepo.FindByNameApp = cf.Application{
+		Name:      "existing-app",
+		Guid:      "existing-app-guid",
+		Instances: 1,
+		Memory:    128,
+		Command:   "old-command",
+		Stack:     cf.Stack{Guid: "lucid-guid"},
+		Urls:      []string{"existing-app.example.com"},
+	}
+
+	fakeUI := callPush([]string{
+		"-m", "256M",
+		"-i", "3",
+		"-c", "new-command",
+		"-b", "https://github.com/heroku/heroku-buildpack-play.git",
+		"-s", "customLinux",
+		"-n", "new-host",
+		"existing-app",
+	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, appRepo.CreatedApp.Name, "")
+	assert.Equal(t, appRepo.UpdatedApp.Guid, "existing-app-guid")
+	assert.Equal(t, appRepo.UpdatedApp.Memory, uint64(256))
+	assert.Equal(t, appRepo.UpdatedApp.Instances, 3)
+	assert.Equal(t, appRepo.UpdatedApp.Command, "new-command")
+	assert.Equal(t, appRepo.UpdatedApp.BuildpackUrl, "https://github.com/heroku/heroku-buildpack-play.git")
+	assert.Equal(t, appRepo.UpdatedApp.Stack.Guid, "custom-linux-guid")
+
+	output := strings.Join(fakeUI.Outputs, "\n")
+	assert.Contains(t, output, "instances: 1 -> 3")
+	assert.Contains(t, output, "memory: 128M -> 256M")
+	assert.Contains(t, output, "command: old-command -> new-command")
+	assert.Contains(t, output, "buildpack: (default) -> https://github.com/heroku/heroku-buildpack-play.git")
+	assert.Contains(t, output, "stack: customLinux")
+
+	assert.Equal(t, routeRepo.CreatedRoute.Host, "new-host")
+	assert.Equal(t, routeRepo.BoundRoute.Host, "new-host")
+	assert.Equal(t, routeRepo.BoundApp.Guid, "existing-app-guid")
+	assert.Equal(t, appBitsRepo.UploadedApp.Guid, "existing-app-guid")
+}
+
+func TestPushingAppWhenItAlreadyExistsWithoutChanges(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domainRepo.FindByNameDomain = cf.Domain{Name: "example.com", Guid: "example-domain-guid"}
+	appRepo.FindByNameApp = cf.Application{
+		Name:      "existing-app",
+		Guid:      "existing-app-guid",
+		Instances: 2,
+		Memory:    256,
+		Urls:      []string{"existing-app.example.com"},
+	}
+
+	callPush([]string{"-m", "256M", "-i", "2", "-d", "example.com", "existing-app"},
+		starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, appRepo.UpdatedApp.Guid, "")
+	assert.Equal(t, routeRepo.FindByHostAndDomainHost, "")
+	assert.Equal(t, routeRepo.BoundRoute.Host, "")
+	assert.Equal(t, appBitsRepo.UploadedApp.Guid, "existing-app-guid")
+}
+
+func TestPushingAppFromManifest(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domainRepo.FindByNameDomain = cf.Domain{Name: "manifest-example.com", Guid: "manifest-domain-guid"}
+	stackRepo.FindByNameStack = cf.Stack{Name: "customLinux", Guid: "custom-linux-guid"}
+	routeRepo.FindByHostAndDomainNotFound = true
+	serviceRepo.FindInstanceByNameServiceInstance = cf.ServiceInstance{Name: "manifest-db", Guid: "manifest-db-guid"}
+	appRepo.FindByNameNotFound = true
+
+	manifestDir := manifestFixturePath(t, "single-app")
+	fakeUI := callPush([]string{"-f", manifestDir}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Contains(t, fakeUI.Outputs[0], filepath.Join(manifestDir, "manifest.yml"))
+
+	assert.Equal(t, appRepo.FindByNameName, "manifest-app")
+	assert.Equal(t, appRepo.CreatedApp.Name, "manifest-app")
+	assert.Equal(t, appRepo.CreatedApp.Instances, 2)
+	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(256))
+	assert.Equal(t, appRepo.CreatedApp.Command, "bundle exec rackup")
+	assert.Equal(t, appRepo.CreatedApp.Stack.Guid, "custom-linux-guid")
+
+	assert.Equal(t, appRepo.SetEnvApp.Name, "manifest-app")
+	assert.Equal(t, appRepo.SetEnvVars, map[string]string{"PAYMENT_GATEWAY": "https://pay.example.com"})
+
+	assert.Equal(t, domainRepo.FindByNameName, "manifest-example.com")
+	assert.Equal(t, routeRepo.CreatedRoute.Host, "manifest-host")
+	assert.Equal(t, routeRepo.BoundRoute.Host, "manifest-host")
+	assert.Equal(t, routeRepo.BoundApp.Name, "manifest-app")
+
+	assert.Equal(t, serviceRepo.FindInstanceByNameName, "manifest-db")
+	assert.Equal(t, serviceRepo.BindServiceServiceInstance.Guid, "manifest-db-guid")
+	assert.Equal(t, serviceRepo.BindServiceApplication.Name, "manifest-app")
+
+	assert.Equal(t, appBitsRepo.UploadedApp.Name, "manifest-app")
+	assert.Equal(t, appBitsRepo.UploadedDir, manifestDir)
+}
+
+func TestPushingExistingAppFromManifestUpdatesItsEnv(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domainRepo.FindByNameDomain = cf.Domain{Name: "manifest-example.com", Guid: "manifest-domain-guid"}
+	stackRepo.FindByNameStack = cf.Stack{Name: "customLinux", Guid: "custom-linux-guid"}
+	serviceRepo.FindInstanceByNameServiceInstance = cf.ServiceInstance{Name: "manifest-db", Guid: "manifest-db-guid"}
+	appRepo.FindByNameApp = cf.Application{
+		Name: "manifest-app",
+		Guid: "manifest-app-guid",
+		EnvironmentVars: map[string]string{
+			"PAYMENT_GATEWAY":  "https://old-pay.example.com",
+			"SET_WITH_SET_ENV": "kept",
+		},
+	}
+
+	fakeUI := callPush([]string{"-f", manifestFixturePath(t, "single-app")}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, appRepo.CreatedApp.Name, "")
+	assert.Equal(t, appRepo.SetEnvApp.Guid, "manifest-app-guid")
+	assert.Equal(t, appRepo.SetEnvVars, map[string]string{
+		"PAYMENT_GATEWAY":  "https://pay.example.com",
+		"SET_WITH_SET_ENV": "kept",
+	})
+
+	output := strings.Join(fakeUI.Outputs, "\n")
+	assert.Contains(t, output, "Updating env variables for")
+	assert.Contains(t, output, "PAYMENT_GATEWAY: https://old-pay.example.com -> https://pay.example.com")
+}
+
+func TestPushingExistingAppFromManifestWithTheSameEnv(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domainRepo.FindByNameDomain = cf.Domain{Name: "manifest-example.com", Guid: "manifest-domain-guid"}
+	stackRepo.FindByNameStack = cf.Stack{Name: "customLinux", Guid: "custom-linux-guid"}
+	serviceRepo.FindInstanceByNameServiceInstance = cf.ServiceInstance{Name: "manifest-db", Guid: "manifest-db-guid"}
+	appRepo.FindByNameApp = cf.Application{
+		Name:            "manifest-app",
+		Guid:            "manifest-app-guid",
+		EnvironmentVars: map[string]string{"PAYMENT_GATEWAY": "https://pay.example.com"},
+	}
+
+	fakeUI := callPush([]string{"-f", manifestFixturePath(t, "single-app")}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, appRepo.SetEnvApp.Guid, "")
+	assert.NotContains(t, strings.Join(fakeUI.Outputs, "\n"), "env variables")
+	assert.Equal(t, appBitsRepo.UploadedApp.Guid, "manifest-app-guid")
+}
+
+func TestPushingAppFromManifestWithFlagOverrides(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domainRepo.FindByNameDomain = cf.Domain{Name: "flag-example.com", Guid: "flag-domain-guid"}
+	routeRepo.FindByHostAndDomainNotFound = true
+	appRepo.FindByNameNotFound = true
+
+	callPush([]string{
+		"-f", manifestFixturePath(t, "single-app"),
+		"-m", "1G",
+		"-i", "5",
+		"-n", "flag-host",
+		"-d", "flag-example.com",
+		"-p", "/path/from/flag",
+		"flag-app",
+	}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, appRepo.CreatedApp.Name, "flag-app")
+	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(1024))
+	assert.Equal(t, appRepo.CreatedApp.Instances, 5)
+	assert.Equal(t, appRepo.CreatedApp.Command, "bundle exec rackup")
+
+	assert.Equal(t, domainRepo.FindByNameName, "flag-example.com")
+	assert.Equal(t, routeRepo.CreatedRoute.Host, "flag-host")
+	assert.Equal(t, appBitsRepo.UploadedDir, "/path/from/flag")
+}
+
+func TestPushingMultipleAppsFromManifest(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	domainRepo.FindByNameDomain = cf.Domain{Name: "example.com", Guid: "example-domain-guid"}
+	routeRepo.FindByHostAndDomainNotFound = true
+	appRepo.FindByNameNotFound = true
+
+	manifestDir := manifestFixturePath(t, "multiple-apps")
+	fakeUI := callPush([]string{"-f", manifestDir}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	output := strings.Join(fakeUI.Outputs, "\n")
+	assert.Contains(t, output, "app1-host.example.com")
+	assert.Contains(t, output, "app2-host.example.com")
+	assert.Contains(t, output, "app2-alias.example.com")
+	assert.Equal(t, serviceRepo.FindInstanceByNameName, "app2-cache")
+
+	assert.Equal(t, appRepo.CreatedApp.Name, "app2")
+	assert.Equal(t, appRepo.CreatedApp.Memory, uint64(1024))
+	assert.Equal(t, appRepo.CreatedApp.Instances, 1)
+	assert.Equal(t, appBitsRepo.UploadedApp.Name, "app2")
+}
+
+func TestPushingSingleAppFromManifestWithMultipleApps(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+	appRepo.FindByNameNotFound = true
+
+	fakeUI := callPush([]string{"-f", manifestFixturePath(t, "multiple-apps"), "app1"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, appRepo.CreatedApp.Name, "app1")
+	assert.Equal(t, appRepo.CreatedApp.Instances, 2)
+	assert.NotContains(t, strings.Join(fakeUI.Outputs, "\n"), "app2")
+
+	appRepo.CreatedApp = cf.Application{}
+	fakeUI = callPush([]string{"-f", manifestFixturePath(t, "multiple-apps"), "app3"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Contains(t, fakeUI.Outputs[1], "FAILED")
+	assert.Contains(t, fakeUI.Outputs[2], "app3")
+	assert.Equal(t, appRepo.CreatedApp.Name, "")
+}
+
+func TestPushingMultipleAppsFromManifestRejectsFlags(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+	appRepo.FindByNameNotFound = true
+
+	fakeUI := callPush([]string{"-f", manifestFixturePath(t, "multiple-apps"), "-i", "3"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Contains(t, fakeUI.Outputs[1], "FAILED")
+	assert.Contains(t, fakeUI.Outputs[2], "multiple apps")
+	assert.Equal(t, appRepo.CreatedApp.Name, "")
+}
+
+func TestPushingWithoutAppNameOrManifest(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	fakeUI := callPush([]string{}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.True(t, fakeUI.FailedWithUsage)
+	assert.Equal(t, appRepo.FindByNameName, "")
+}
+
+func manifestFixturePath(t *testing.T, name string) string {
+	dir, err := os.Getwd()
+	assert.NoError(t, err)
+	return filepath.Join(dir, "../../../fixtures/manifests", name)
+}
+
 func getPushDependencies() (starter *testhelpers.FakeAppStarter,
 	stopper *testhelpers.FakeAppStopper,
 	appRepo *testhelpers.FakeApplicationRepository,
 	domainRepo *testhelpers.FakeDomainRepository,
 	routeRepo *testhelpers.FakeRouteRepository,
 	stackRepo *testhelpers.FakeStackRepository,
+	serviceRepo *testhelpers.FakeServiceRepo,
 	appBitsRepo *testhelpers.FakeApplicationBitsRepository) {
 
 	starter = &testhelpers.FakeAppStarter{}
@@ -229,6 +514,7 @@ func getPushDependencies() (starter *testhelpers.FakeAppStarter,
 	domainRepo = &testhelpers.FakeDomainRepository{}
 	routeRepo = &testhelpers.FakeRouteRepository{}
 	stackRepo = &testhelpers.FakeStackRepository{}
+	serviceRepo = &testhelpers.FakeServiceRepo{}
 	appBitsRepo = &testhelpers.FakeApplicationBitsRepository{}
 
 	return
@@ -241,13 +527,70 @@ func callPush(args []string,
 	domainRepo api.DomainRepository,
 	routeRepo api.RouteRepository,
 	stackRepo api.StackRepository,
+	serviceRepo api.ServiceRepository,
 	appBitsRepo *testhelpers.FakeApplicationBitsRepository) (fakeUI *testhelpers.FakeUI) {
 
 	fakeUI = new(testhelpers.FakeUI)
 	ctxt := testhelpers.NewContext("push", args)
-	cmd := NewPush(fakeUI, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, appBitsRepo)
+	cmd := NewPush(fakeUI, &configuration.Configuration{ApplicationStartTimeout: 2}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
 	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true}
 	testhelpers.RunCommand(cmd, ctxt, reqFactory)
 
 	return
 }
+
+func TestPushingShowsUploadProgress(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+	appRepo.FindByNameApp = cf.Application{Name: "existing-app", Guid: "existing-app-guid"}
+	appBitsRepo.UploadProgress = []int64{10, 50, 100}
+	appBitsRepo.UploadTotal = 100
+
+	fakeUI := callPush([]string{"existing-app"}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+
+	assert.Equal(t, fakeUI.ProgressCurrent, int64(100))
+	assert.Equal(t, fakeUI.ProgressTotal, int64(100))
+}
+
+func TestPushingWithDryRunFilesListsFilesWithoutPushing(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	dir, err := os.Getwd()
+	assert.NoError(t, err)
+	appDir := filepath.Join(dir, "../../../fixtures/example-app")
+
+	fakeUI := new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("push", []string{"--dry-run-files", "-p", appDir, "my-app"})
+	cmd := NewPush(fakeUI, &configuration.Configuration{}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: false, TargetedSpaceSuccess: false}
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+	assert.Contains(t, fakeUI.Outputs[0], "manifest.yml")
+	assert.Contains(t, fakeUI.Outputs[1], "my-app")
+	assert.Contains(t, fakeUI.Outputs[1], appDir)
+	assert.Contains(t, fakeUI.Outputs[2], "OK")
+
+	output := strings.Join(fakeUI.Outputs, "\n")
+	assert.Contains(t, output, "Gemfile.lock")
+	assert.Contains(t, output, "manifest.yml")
+	assert.Contains(t, output, "5 files")
+
+	assert.Equal(t, appRepo.FindByNameName, "")
+	assert.Equal(t, appBitsRepo.UploadedDir, "")
+}
+
+func TestPushingWithDryRunFilesAndStructuredOutput(t *testing.T) {
+	starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo := getPushDependencies()
+
+	dir, err := os.Getwd()
+	assert.NoError(t, err)
+	appDir := filepath.Join(dir, "../../../fixtures/example-app")
+
+	fakeUI := &testhelpers.FakeUI{StructuredOutput: true}
+	ctxt := testhelpers.NewContext("push", []string{"--dry-run-files", "-p", appDir, "my-app"})
+	cmd := NewPush(fakeUI, &configuration.Configuration{}, starter, stopper, appRepo, domainRepo, routeRepo, stackRepo, serviceRepo, appBitsRepo)
+	testhelpers.RunCommand(cmd, ctxt, &testhelpers.FakeReqFactory{})
+
+	assert.NotNil(t, fakeUI.DisplayedData)
+	assert.NotContains(t, strings.Join(fakeUI.Outputs, "\n"), "5 files")
+}
@@ -0,0 +1,76 @@
+package application
+
+import (
+	"cf"
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"fmt"
+	"github.com/codegangsta/cli"
+	"strconv"
+)
+
+type RestartAppInstance struct {
+	ui      terminal.UI
+	appRepo api.ApplicationRepository
+	appReq  requirements.ApplicationRequirement
+	index   int
+}
+
+func NewRestartAppInstance(ui terminal.UI, appRepo api.ApplicationRepository) (cmd *RestartAppInstance) {
+	cmd = new(RestartAppInstance)
+	cmd.ui = ui
+	cmd.appRepo = appRepo
+	return
+}
+
+func (cmd *RestartAppInstance) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 2 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "restart-app-instance")
+		return
+	}
+
+	cmd.index, err = strconv.Atoi(c.Args()[1])
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Invalid instance index %s: expected a number", c.Args()[1]))
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	cmd.appReq = reqFactory.NewApplicationRequirement(c.Args()[0])
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+		reqFactory.NewTargetedSpaceRequirement(),
+		cmd.appReq,
+	}
+	return
+}
+
+// Run stops a single instance, leaving the health manager to start a new one
+// in its place, so that the other instances keep serving requests.
+func (cmd *RestartAppInstance) Run(c *cli.Context) {
+	app := cmd.appReq.GetApplication()
+
+	err := checkInstanceIndex(app, cmd.index)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	cmd.ui.Say("Restarting instance %s of app %s...",
+		terminal.EntityNameColor(fmt.Sprintf("#%d", cmd.index)),
+		terminal.EntityNameColor(app.Name),
+	)
+
+	apiResponse := cmd.appRepo.KillInstance(app, cmd.index)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("TIP: Use '%s' to follow the new instance", terminal.CommandColor(fmt.Sprintf("%s app %s --watch", cf.Name, app.Name)))
+}
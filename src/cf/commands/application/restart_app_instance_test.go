@@ -0,0 +1,89 @@
+package application_test
+
+import (
+	"cf"
+	. "cf/commands/application"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestRestartAppInstanceFailsWithUsage(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callRestartAppInstance([]string{}, reqFactory, appRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callRestartAppInstance([]string{"my-app"}, reqFactory, appRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callRestartAppInstance([]string{"my-app", "first"}, reqFactory, appRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+	assert.Contains(t, ui.Outputs[1], "Invalid instance index first")
+}
+
+func TestRestartAppInstanceRequirements(t *testing.T) {
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: false, TargetedSpaceSuccess: true}
+	callRestartAppInstance([]string{"my-app", "0"}, reqFactory, appRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: false}
+	callRestartAppInstance([]string{"my-app", "0"}, reqFactory, appRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true}
+	callRestartAppInstance([]string{"my-app", "0"}, reqFactory, appRepo)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+	assert.Equal(t, reqFactory.ApplicationName, "my-app")
+}
+
+func TestRestartAppInstance(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", Instances: 3}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callRestartAppInstance([]string{"my-app", "2"}, reqFactory, appRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Restarting instance")
+	assert.Contains(t, ui.Outputs[0], "#2")
+	assert.Contains(t, ui.Outputs[0], "my-app")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, appRepo.KillInstanceApp.Guid, "my-app-guid")
+	assert.Equal(t, appRepo.KillInstanceIndex, 2)
+}
+
+func TestRestartAppInstanceWithAnIndexOutOfRange(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", Instances: 3}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callRestartAppInstance([]string{"my-app", "3"}, reqFactory, appRepo)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "Invalid instance index 3")
+	assert.Contains(t, ui.Outputs[1], "0 to 2")
+	assert.Equal(t, appRepo.KillInstanceApp.Guid, "")
+}
+
+func TestRestartAppInstanceWhenKillingFails(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", Instances: 3}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	appRepo := &testhelpers.FakeApplicationRepository{KillInstanceErr: true}
+
+	ui := callRestartAppInstance([]string{"my-app", "1"}, reqFactory, appRepo)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "Error killing instance")
+}
+
+func callRestartAppInstance(args []string, reqFactory *testhelpers.FakeReqFactory, appRepo *testhelpers.FakeApplicationRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("restart-app-instance", args)
+	cmd := NewRestartAppInstance(ui, appRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
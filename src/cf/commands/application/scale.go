@@ -3,9 +3,11 @@ package application
 import (
 	"cf"
 	"cf/api"
+	"cf/formatters"
 	"cf/requirements"
 	"cf/terminal"
 	"errors"
+	"fmt"
 	"github.com/codegangsta/cli"
 )
 
@@ -42,44 +44,123 @@ func (cmd *Scale) GetRequirements(reqFactory requirements.Factory, c *cli.Contex
 	return
 }
 
+type appScale struct {
+	Instances int    `json:"instances"`
+	Memory    uint64 `json:"memory"`     // in megabytes
+	DiskQuota uint64 `json:"disk_quota"` // in megabytes
+}
+
 func (cmd *Scale) Run(c *cli.Context) {
 	currentApp := cmd.appReq.GetApplication()
+
+	if c.String("d") == "" && c.String("m") == "" && c.Int("i") == -1 {
+		cmd.showScale(currentApp)
+		return
+	}
+
+	changedApp, needsRestart, err := scaleChanges(c, currentApp)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	if changedApp.Instances == 0 && changedApp.Memory == 0 && changedApp.DiskQuota == 0 {
+		cmd.ui.Say("App %s is already scaled that way", terminal.EntityNameColor(currentApp.Name))
+		return
+	}
+
+	// Instances are added or removed live, but the memory and disk of the
+	// running instances can only change by restarting them.
+	needsRestart = needsRestart && currentApp.State == "started"
+
+	if needsRestart && !c.Bool("f") {
+		response := cmd.ui.Confirm(
+			"Changing the memory or disk of %s restarts it, making it unavailable until it has started again. Continue?%s",
+			terminal.EntityNameColor(currentApp.Name),
+			terminal.PromptColor(">"),
+		)
+		if !response {
+			return
+		}
+	}
+
 	cmd.ui.Say("Scaling app %s...", terminal.EntityNameColor(currentApp.Name))
 
-	changedApp := cf.Application{
-		Guid: currentApp.Guid,
+	apiResponse := cmd.appRepo.Scale(changedApp)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Failed(apiResponse.Message)
+		return
+	}
+
+	cmd.ui.Ok()
+
+	if needsRestart {
+		cmd.restarter.ApplicationRestart(currentApp)
+	}
+}
+
+func (cmd *Scale) showScale(app cf.Application) {
+	cmd.ui.Say("Showing current scale of app %s...", terminal.EntityNameColor(app.Name))
+	cmd.ui.Ok()
+
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(appScale{Instances: app.Instances, Memory: app.Memory, DiskQuota: app.DiskQuota})
+		return
 	}
 
-	diskQuota, err := extractMegaBytes(c.String("d"))
+	cmd.ui.Say("\n%s %d", terminal.HeaderColor("instances:"), app.Instances)
+	cmd.ui.Say("%s %s", terminal.HeaderColor("memory:"), formatters.ByteSize(app.Memory*MEGABYTE))
+	cmd.ui.Say("%s %s", terminal.HeaderColor("disk:"), formatters.ByteSize(app.DiskQuota*MEGABYTE))
+}
+
+// scaleChanges builds the update to app from the flags, leaving out the
+// values that would not change. It tells whether the changes need the app
+// to restart.
+func scaleChanges(c *cli.Context, app cf.Application) (changedApp cf.Application, needsRestart bool, err error) {
+	changedApp = cf.Application{Guid: app.Guid}
+
+	diskQuota, err := extractMegaBytes(c.String("d"), "disk quota")
 	if err != nil {
-		cmd.ui.Say("Invalid value for disk quota")
-		cmd.ui.FailWithUsage(c, "scale")
 		return
 	}
-	changedApp.DiskQuota = diskQuota
+	if diskQuota != 0 && diskQuota != app.DiskQuota {
+		changedApp.DiskQuota = diskQuota
+		needsRestart = true
+	}
 
-	memory, err := extractMegaBytes(c.String("m"))
+	memory, err := extractMegaBytes(c.String("m"), "memory limit")
 	if err != nil {
-		cmd.ui.Say("Invalid value for memory")
-		cmd.ui.FailWithUsage(c, "scale")
 		return
 	}
-	changedApp.Memory = memory
-	changedApp.Instances = c.Int("i")
+	if memory != 0 && memory != app.Memory {
+		changedApp.Memory = memory
+		needsRestart = true
+	}
 
-	cmd.appRepo.Scale(changedApp)
-	cmd.restarter.ApplicationRestart(currentApp)
+	instances := c.Int("i")
+	if instances == 0 || instances < -1 {
+		err = errors.New(fmt.Sprintf("Invalid instance count %d: it must be at least 1", instances))
+		return
+	}
+	if instances != -1 && instances != app.Instances {
+		changedApp.Instances = instances
+	}
+	return
 }
 
-func extractMegaBytes(arg string) (megaBytes uint64, err error) {
-	if arg != "" {
-		var byteSize uint64
-		byteSize, err = bytesFromString(arg)
-		if err != nil {
-			return
-		}
-		megaBytes = byteSize / MEGABYTE
+func extractMegaBytes(arg, name string) (megaBytes uint64, err error) {
+	if arg == "" {
+		return
 	}
 
+	megaBytes, err = formatters.ToMegabytes(arg)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Invalid %s %s: expected a number with a unit, like 512M or 2G", name, arg))
+		return
+	}
+
+	if megaBytes == 0 {
+		err = errors.New(fmt.Sprintf("Invalid %s %s: it must be at least 1M", name, arg))
+	}
 	return
 }
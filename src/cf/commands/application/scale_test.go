@@ -40,11 +40,11 @@ func TestScaleFailsWithUsage(t *testing.T) {
 }
 
 func TestScaleAll(t *testing.T) {
-	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", State: "started"}
 	reqFactory, restarter, appRepo := getScaleDependencies()
 	reqFactory.Application = app
 
-	ui := callScale([]string{"-d", "2G", "-i", "5", "-m", "512M", "my-app"}, reqFactory, restarter, appRepo)
+	ui := callScale([]string{"-d", "2G", "-i", "5", "-m", "512M", "-f", "my-app"}, reqFactory, restarter, appRepo)
 
 	assert.Contains(t, ui.Outputs[0], "Scaling")
 	assert.Contains(t, ui.Outputs[0], "my-app")
@@ -97,6 +97,106 @@ func TestScaleOnlyMemory(t *testing.T) {
 	assert.Equal(t, appRepo.ScaledApp.Instances, 0)
 }
 
+func TestScaleWithoutFlagsShowsTheCurrentScale(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", Instances: 3, Memory: 256, DiskQuota: 1024}
+	reqFactory, restarter, appRepo := getScaleDependencies()
+	reqFactory.Application = app
+
+	ui := callScale([]string{"my-app"}, reqFactory, restarter, appRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Showing current scale of app")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "instances")
+	assert.Contains(t, ui.Outputs[2], "3")
+	assert.Contains(t, ui.Outputs[3], "memory")
+	assert.Contains(t, ui.Outputs[3], "256M")
+	assert.Contains(t, ui.Outputs[4], "disk")
+	assert.Contains(t, ui.Outputs[4], "1G")
+
+	assert.Equal(t, appRepo.ScaledApp.Guid, "")
+	assert.Equal(t, restarter.AppToRestart.Guid, "")
+}
+
+func TestScaleInstancesOfAStartedAppDoesNotRestartIt(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", State: "started", Instances: 1, Memory: 256}
+	reqFactory, restarter, appRepo := getScaleDependencies()
+	reqFactory.Application = app
+
+	ui := callScale([]string{"-i", "3", "-m", "256M", "my-app"}, reqFactory, restarter, appRepo)
+
+	assert.Equal(t, len(ui.Prompts), 0)
+	assert.Equal(t, appRepo.ScaledApp.Instances, 3)
+	assert.Equal(t, appRepo.ScaledApp.Memory, uint64(0))
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Equal(t, restarter.AppToRestart.Guid, "")
+}
+
+func TestScaleMemoryOfAStartedAppAsksBeforeRestarting(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", State: "started", Memory: 256}
+	reqFactory, restarter, appRepo := getScaleDependencies()
+	reqFactory.Application = app
+
+	ui := callScaleWithInputs([]string{"-m", "1G", "my-app"}, []string{"y"}, reqFactory, restarter, appRepo)
+
+	assert.Contains(t, ui.Prompts[0], "restarts it")
+	assert.Equal(t, appRepo.ScaledApp.Memory, uint64(1024))
+	assert.Equal(t, restarter.AppToRestart.Guid, "my-app-guid")
+
+	reqFactory, restarter, appRepo = getScaleDependencies()
+	reqFactory.Application = app
+
+	ui = callScaleWithInputs([]string{"-m", "1G", "my-app"}, []string{"n"}, reqFactory, restarter, appRepo)
+
+	assert.Equal(t, len(ui.Prompts), 1)
+	assert.Equal(t, appRepo.ScaledApp.Guid, "")
+	assert.Equal(t, restarter.AppToRestart.Guid, "")
+}
+
+func TestScaleToTheCurrentValuesDoesNothing(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", State: "started", Memory: 256, DiskQuota: 1024}
+	reqFactory, restarter, appRepo := getScaleDependencies()
+	reqFactory.Application = app
+
+	ui := callScale([]string{"-m", "256M", "-d", "1G", "my-app"}, reqFactory, restarter, appRepo)
+
+	assert.Contains(t, ui.Outputs[0], "already scaled")
+	assert.Equal(t, appRepo.ScaledApp.Guid, "")
+	assert.Equal(t, restarter.AppToRestart.Guid, "")
+}
+
+func TestScaleToTheCurrentInstanceCountDoesNothing(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", State: "started", Instances: 3}
+	reqFactory, restarter, appRepo := getScaleDependencies()
+	reqFactory.Application = app
+
+	ui := callScale([]string{"-i", "3", "my-app"}, reqFactory, restarter, appRepo)
+
+	assert.Contains(t, ui.Outputs[0], "already scaled")
+	assert.Equal(t, appRepo.ScaledApp.Guid, "")
+}
+
+func TestScaleFailsWithInvalidValues(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+
+	for _, args := range [][]string{
+		[]string{"-m", "lots", "my-app"},
+		[]string{"-m", "512", "my-app"},
+		[]string{"-d", "100K", "my-app"},
+		[]string{"-i", "0", "my-app"},
+		[]string{"-i", "-3", "my-app"},
+	} {
+		reqFactory, restarter, appRepo := getScaleDependencies()
+		reqFactory.Application = app
+
+		ui := callScale(args, reqFactory, restarter, appRepo)
+
+		assert.Contains(t, ui.Outputs[0], "FAILED")
+		assert.Contains(t, ui.Outputs[1], "Invalid")
+		assert.Contains(t, ui.Outputs[1], args[1])
+		assert.Equal(t, appRepo.ScaledApp.Guid, "")
+	}
+}
+
 func getScaleDependencies() (reqFactory *testhelpers.FakeReqFactory, restarter *testhelpers.FakeAppRestarter, appRepo *testhelpers.FakeApplicationRepository) {
 	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true}
 	restarter = &testhelpers.FakeAppRestarter{}
@@ -105,7 +205,11 @@ func getScaleDependencies() (reqFactory *testhelpers.FakeReqFactory, restarter *
 }
 
 func callScale(args []string, reqFactory *testhelpers.FakeReqFactory, restarter *testhelpers.FakeAppRestarter, appRepo api.ApplicationRepository) (ui *testhelpers.FakeUI) {
-	ui = new(testhelpers.FakeUI)
+	return callScaleWithInputs(args, []string{}, reqFactory, restarter, appRepo)
+}
+
+func callScaleWithInputs(args []string, inputs []string, reqFactory *testhelpers.FakeReqFactory, restarter *testhelpers.FakeAppRestarter, appRepo api.ApplicationRepository) (ui *testhelpers.FakeUI) {
+	ui = &testhelpers.FakeUI{Inputs: inputs}
 	ctxt := testhelpers.NewContext("scale", args)
 	cmd := NewScale(ui, restarter, appRepo)
 	testhelpers.RunCommand(cmd, ctxt, reqFactory)
@@ -6,7 +6,10 @@ import (
 	"cf/requirements"
 	"cf/terminal"
 	"errors"
+	"fmt"
 	"github.com/codegangsta/cli"
+	"io"
+	"os"
 )
 
 type SetEnv struct {
@@ -23,7 +26,10 @@ func NewSetEnv(ui terminal.UI, appRepo api.ApplicationRepository) (se *SetEnv) {
 }
 
 func (cmd *SetEnv) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
-	if len(c.Args()) < 3 {
+	bulk := c.String("from-file") != "" || c.String("from-json") != ""
+	bothFiles := c.String("from-file") != "" && c.String("from-json") != ""
+
+	if (bulk && len(c.Args()) < 1) || (!bulk && len(c.Args()) < 3) || bothFiles {
 		err = errors.New("Incorrect Usage")
 		cmd.ui.FailWithUsage(c, "set-env")
 		return
@@ -39,6 +45,15 @@ func (cmd *SetEnv) GetRequirements(reqFactory requirements.Factory, c *cli.Conte
 }
 
 func (se *SetEnv) Run(c *cli.Context) {
+	if c.String("from-file") != "" {
+		se.setFromFile(c.String("from-file"), parseDotEnv, c.Bool("replace"))
+		return
+	}
+	if c.String("from-json") != "" {
+		se.setFromFile(c.String("from-json"), parseEnvJson, c.Bool("replace"))
+		return
+	}
+
 	varName := c.Args()[1]
 	varValue := c.Args()[2]
 	app := se.appReq.GetApplication()
@@ -71,3 +86,62 @@ func (se *SetEnv) Run(c *cli.Context) {
 	se.ui.Ok()
 	se.ui.Say("TIP: Use '%s push' to ensure your env variable changes take effect", cf.Name)
 }
+
+// setFromFile applies every variable in the file with a single update, so
+// that the app never sees only some of them. With replace, variables missing
+// from the file are removed.
+func (se *SetEnv) setFromFile(fileName string, parse func(io.Reader) (map[string]string, error), replace bool) {
+	app := se.appReq.GetApplication()
+
+	se.ui.Say("Updating env variables for app %s from %s...", terminal.EntityNameColor(app.Name), terminal.EntityNameColor(fileName))
+
+	fileVars, err := readEnvFile(fileName, parse)
+	if err != nil {
+		se.ui.Failed("%s", err.Error())
+		return
+	}
+
+	envVars := map[string]string{}
+	if !replace {
+		for name, value := range app.EnvironmentVars {
+			envVars[name] = value
+		}
+	}
+	for name, value := range fileVars {
+		envVars[name] = value
+	}
+
+	changes := diffEnvVars(app.EnvironmentVars, envVars)
+	if changes.IsEmpty() {
+		se.ui.Ok()
+		se.ui.Say("No env variables changed")
+		return
+	}
+
+	showEnvVarChanges(se.ui, changes, app.EnvironmentVars, envVars)
+
+	apiResponse := se.appRepo.SetEnv(app, envVars)
+	if apiResponse.IsNotSuccessful() {
+		se.ui.Failed("%s", apiResponse.Message)
+		return
+	}
+
+	se.ui.Ok()
+	se.ui.Say("%d added, %d changed, %d removed", len(changes.Added), len(changes.Changed), len(changes.Removed))
+	se.ui.Say("TIP: Use '%s push' to ensure your env variable changes take effect", cf.Name)
+}
+
+func readEnvFile(fileName string, parse func(io.Reader) (map[string]string, error)) (envVars map[string]string, err error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Error reading %s: %s", fileName, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	envVars, err = parse(file)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Error parsing %s: %s", fileName, err.Error()))
+	}
+	return
+}
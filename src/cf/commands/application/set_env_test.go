@@ -5,6 +5,8 @@ import (
 	"cf/api"
 	. "cf/commands/application"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
 	"testhelpers"
 	"testing"
 )
@@ -104,6 +106,117 @@ func TestSetEnvFailsWithUsage(t *testing.T) {
 	assert.True(t, ui.FailedWithUsage)
 }
 
+func TestSetEnvFromFile(t *testing.T) {
+	envFile := createEnvFile(t, "RAILS_ENV=production\nDB_PASSWORD=new-secret\nNEW_VAR=\"hello world\"\n")
+	defer os.Remove(envFile)
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", EnvironmentVars: map[string]string{
+		"RAILS_ENV":   "production",
+		"DB_PASSWORD": "old-secret",
+		"KEEP_ME":     "kept",
+	}}
+	reqFactory := &testhelpers.FakeReqFactory{Application: app, LoginSuccess: true, TargetedSpaceSuccess: true}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callSetEnv([]string{"--from-file", envFile, "my-app"}, reqFactory, appRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Updating env variables for app")
+	assert.Contains(t, ui.Outputs[0], "my-app")
+	assert.Contains(t, ui.Outputs[1], "NEW_VAR")
+	assert.Contains(t, ui.Outputs[1], "hello world")
+	assert.Contains(t, ui.Outputs[2], "DB_PASSWORD")
+	assert.NotContains(t, ui.Outputs[2], "secret")
+	assert.Contains(t, ui.Outputs[3], "OK")
+	assert.Contains(t, ui.Outputs[4], "1 added, 1 changed, 0 removed")
+
+	assert.Equal(t, appRepo.SetEnvVars, map[string]string{
+		"RAILS_ENV":   "production",
+		"DB_PASSWORD": "new-secret",
+		"NEW_VAR":     "hello world",
+		"KEEP_ME":     "kept",
+	})
+	assert.Equal(t, reqFactory.Application.EnvironmentVars["DB_PASSWORD"], "old-secret")
+}
+
+func TestSetEnvFromJsonReplacingAllVariables(t *testing.T) {
+	envFile := createEnvFile(t, `{"RAILS_ENV": "staging", "PORT": 8080}`)
+	defer os.Remove(envFile)
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", EnvironmentVars: map[string]string{
+		"RAILS_ENV": "production",
+		"OLD_VAR":   "old",
+	}}
+	reqFactory := &testhelpers.FakeReqFactory{Application: app, LoginSuccess: true, TargetedSpaceSuccess: true}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callSetEnv([]string{"--from-json", envFile, "--replace", "my-app"}, reqFactory, appRepo)
+
+	assert.Contains(t, ui.Outputs[1], "PORT")
+	assert.Contains(t, ui.Outputs[2], "RAILS_ENV")
+	assert.Contains(t, ui.Outputs[2], "production")
+	assert.Contains(t, ui.Outputs[2], "staging")
+	assert.Contains(t, ui.Outputs[3], "OLD_VAR")
+	assert.Contains(t, ui.Outputs[4], "OK")
+	assert.Contains(t, ui.Outputs[5], "1 added, 1 changed, 1 removed")
+
+	assert.Equal(t, appRepo.SetEnvVars, map[string]string{"RAILS_ENV": "staging", "PORT": "8080"})
+}
+
+func TestSetEnvFromFileWithoutChanges(t *testing.T) {
+	envFile := createEnvFile(t, "RAILS_ENV=production\n")
+	defer os.Remove(envFile)
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", EnvironmentVars: map[string]string{"RAILS_ENV": "production"}}
+	reqFactory := &testhelpers.FakeReqFactory{Application: app, LoginSuccess: true, TargetedSpaceSuccess: true}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callSetEnv([]string{"--from-file", envFile, "my-app"}, reqFactory, appRepo)
+
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "No env variables changed")
+	assert.Equal(t, appRepo.SetEnvApp, cf.Application{})
+}
+
+func TestSetEnvFromAnInvalidFile(t *testing.T) {
+	envFile := createEnvFile(t, "RAILS_ENV=production\nthis is not a variable\n")
+	defer os.Remove(envFile)
+
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	reqFactory := &testhelpers.FakeReqFactory{Application: app, LoginSuccess: true, TargetedSpaceSuccess: true}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callSetEnv([]string{"--from-file", envFile, "my-app"}, reqFactory, appRepo)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "line 2")
+	assert.Equal(t, appRepo.SetEnvApp, cf.Application{})
+}
+
+func TestSetEnvFromFileFailsWithUsage(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid"}
+	reqFactory := &testhelpers.FakeReqFactory{Application: app, LoginSuccess: true, TargetedSpaceSuccess: true}
+	appRepo := &testhelpers.FakeApplicationRepository{}
+
+	ui := callSetEnv([]string{"--from-file", ".env", "my-app"}, reqFactory, appRepo)
+	assert.False(t, ui.FailedWithUsage)
+
+	ui = callSetEnv([]string{"--from-file", ".env"}, reqFactory, appRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callSetEnv([]string{"--from-file", ".env", "--from-json", "env.json", "my-app"}, reqFactory, appRepo)
+	assert.True(t, ui.FailedWithUsage)
+}
+
+func createEnvFile(t *testing.T, contents string) string {
+	file, err := ioutil.TempFile("", "env-file")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.WriteString(contents)
+	assert.NoError(t, err)
+	return file.Name()
+}
+
 func callSetEnv(args []string, reqFactory *testhelpers.FakeReqFactory, appRepo api.ApplicationRepository) (ui *testhelpers.FakeUI) {
 	ui = new(testhelpers.FakeUI)
 	ctxt := testhelpers.NewContext("set-env", args)
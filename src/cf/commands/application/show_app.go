@@ -1,25 +1,35 @@
 package application
 
 import (
+	"cf"
 	"cf/api"
+	"cf/formatters"
 	"cf/requirements"
 	"cf/terminal"
 	"errors"
 	"fmt"
 	"github.com/codegangsta/cli"
+	"sort"
 	"strings"
+	"time"
 )
 
+// appWatchInterval is how often app --watch refreshes the app.
+const appWatchInterval = 3 * time.Second
+
 type ShowApp struct {
 	ui             terminal.UI
 	appSummaryRepo api.AppSummaryRepository
+	appRepo        api.ApplicationRepository
 	appReq         requirements.ApplicationRequirement
+	instanceIndex  int
 }
 
-func NewShowApp(ui terminal.UI, appSummaryRepo api.AppSummaryRepository) (cmd *ShowApp) {
+func NewShowApp(ui terminal.UI, appSummaryRepo api.AppSummaryRepository, appRepo api.ApplicationRepository) (cmd *ShowApp) {
 	cmd = new(ShowApp)
 	cmd.ui = ui
 	cmd.appSummaryRepo = appSummaryRepo
+	cmd.appRepo = appRepo
 	return
 }
 
@@ -42,21 +52,95 @@ func (cmd *ShowApp) GetRequirements(reqFactory requirements.Factory, c *cli.Cont
 
 func (cmd *ShowApp) Run(c *cli.Context) {
 	app := cmd.appReq.GetApplication()
+
+	cmd.instanceIndex = c.Int("i")
+	if cmd.instanceIndex != -1 {
+		err := checkInstanceIndex(app, cmd.instanceIndex)
+		if err != nil {
+			cmd.ui.Failed(err.Error())
+			return
+		}
+	}
+
 	cmd.ui.Say("Showing health and status for app %s...", terminal.EntityNameColor(app.Name))
 
 	summary, apiResponse := cmd.appSummaryRepo.GetSummary(app)
 	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return
 	}
 
 	cmd.ui.Ok()
-	cmd.ui.Say("\n%s %s", terminal.HeaderColor("health:"), coloredState(summary.App.Health()))
-	cmd.ui.Say("%s %s x %d instances", terminal.HeaderColor("usage:"), byteSize(summary.App.Memory*MEGABYTE), summary.App.Instances)
-	cmd.ui.Say("%s %s\n", terminal.HeaderColor("urls:"), strings.Join(summary.App.Urls, ", "))
+
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(summary)
+		return
+	}
+
+	if !cmd.displaySummary(summary) {
+		return
+	}
+
+	if c.Bool("watch") {
+		cmd.watch(app)
+	}
+}
+
+// watch shows the app again every appWatchInterval, until the user
+// interrupts it or the app can no longer be found.
+func (cmd *ShowApp) watch(app cf.Application) {
+	for {
+		cmd.ui.Wait(appWatchInterval)
+
+		refreshedApp, apiResponse := cmd.appRepo.FindByName(app.Name)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+			return
+		}
+
+		summary, apiResponse := cmd.appSummaryRepo.GetSummary(refreshedApp)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+			return
+		}
+
+		cmd.ui.Say("\n%s %s", terminal.HeaderColor("refreshed:"), time.Now().Format("2006-01-02 03:04:05 PM"))
+		if !cmd.displaySummary(summary) {
+			return
+		}
+	}
+}
+
+// displaySummary shows summary, and the crashes of the selected instance if
+// there is one. It tells whether that worked.
+func (cmd *ShowApp) displaySummary(summary cf.AppSummary) (ok bool) {
+	app := summary.App
+
+	cmd.ui.Say("\n%s %s", terminal.HeaderColor("health:"), coloredState(app.Health()))
+	cmd.ui.Say("%s %s x %d instances", terminal.HeaderColor("usage:"), formatters.ByteSize(app.Memory*MEGABYTE), app.Instances)
+
+	details := [][]string{
+		[]string{"urls:", strings.Join(app.Urls, ", ")},
+		[]string{"disk:", diskQuotaDetails(app)},
+		[]string{"buildpack:", buildpackDetails(app)},
+		[]string{"command:", commandDetails(app)},
+		[]string{"stack:", app.Stack.Name},
+		[]string{"package:", strings.ToLower(app.PackageState)},
+		[]string{"staging:", app.StagingState()},
+		[]string{"env:", envVarNames(app)},
+	}
+
+	lines := []string{}
+	for index, detail := range details {
+		// The urls are shown even when there are none
+		if detail[1] != "" || index == 0 {
+			lines = append(lines, fmt.Sprintf("%s %s", terminal.HeaderColor(detail[0]), detail[1]))
+		}
+	}
+	cmd.ui.Say("%s\n", strings.Join(lines, "\n"))
 
 	table := [][]string{
-		[]string{"", "status", "since", "cpu", "memory", "disk"},
+		[]string{"", "status", "since", "uptime", "cpu", "memory", "disk", "details"},
 	}
 
 	for index, instance := range summary.Instances {
@@ -64,13 +148,52 @@ func (cmd *ShowApp) Run(c *cli.Context) {
 			fmt.Sprintf("#%d", index),
 			string(instance.State),
 			instance.Since.Format("2006-01-02 03:04:05 PM"),
+			formatUptime(instance),
 			fmt.Sprintf("%.1f%%", instance.CpuUsage),
-			fmt.Sprintf("%s of %s", byteSize(instance.MemUsage), byteSize(instance.MemQuota)),
-			fmt.Sprintf("%s of %s", byteSize(instance.DiskUsage), byteSize(instance.DiskQuota)),
+			fmt.Sprintf("%s of %s", formatters.ByteSize(instance.MemUsage), formatters.ByteSize(instance.MemQuota)),
+			fmt.Sprintf("%s of %s", formatters.ByteSize(instance.DiskUsage), formatters.ByteSize(instance.DiskQuota)),
+			instanceDetails(instance),
 		})
 	}
 
 	cmd.ui.DisplayTable(table, cmd.coloringFunc)
+
+	if cmd.instanceIndex == -1 {
+		return true
+	}
+	return cmd.displayCrashes(app)
+}
+
+func (cmd *ShowApp) displayCrashes(app cf.Application) (ok bool) {
+	crashes, apiResponse := cmd.appRepo.GetCrashes(app)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	table := [][]string{
+		[]string{"crashed at", "exit status", "description"},
+	}
+
+	for _, crash := range crashes {
+		if crash.Index != cmd.instanceIndex {
+			continue
+		}
+		table = append(table, []string{
+			crash.Since.Format("2006-01-02 03:04:05 PM"),
+			fmt.Sprintf("%d", crash.ExitStatus),
+			crash.ExitDescription,
+		})
+	}
+
+	if len(table) == 1 {
+		cmd.ui.Say("\nInstance #%d has not crashed", cmd.instanceIndex)
+		return true
+	}
+
+	cmd.ui.Say("\n%s", terminal.HeaderColor(fmt.Sprintf("crashes of instance #%d:", cmd.instanceIndex)))
+	cmd.ui.DisplayTable(table, nil)
+	return true
 }
 
 func (cmd *ShowApp) coloringFunc(value string, row int, col int) string {
@@ -80,3 +203,54 @@ func (cmd *ShowApp) coloringFunc(value string, row int, col int) string {
 
 	return terminal.DefaultColoringFunc(value, row, col)
 }
+
+func diskQuotaDetails(app cf.Application) string {
+	if app.DiskQuota == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s per instance", formatters.ByteSize(app.DiskQuota*MEGABYTE))
+}
+
+func buildpackDetails(app cf.Application) string {
+	switch {
+	case app.BuildpackUrl == "":
+		return app.DetectedBuildpack
+	case app.DetectedBuildpack == "":
+		return app.BuildpackUrl
+	}
+	return fmt.Sprintf("%s (detected %s)", app.BuildpackUrl, app.DetectedBuildpack)
+}
+
+// commandDetails tells which command starts the app, and whether the
+// buildpack detected it.
+func commandDetails(app cf.Application) string {
+	if app.Command != "" || app.DetectedStartCommand == "" {
+		return app.Command
+	}
+	return fmt.Sprintf("%s (detected)", app.DetectedStartCommand)
+}
+
+// envVarNames lists the names of the environment variables only, since
+// their values may be secrets.
+func envVarNames(app cf.Application) string {
+	names := []string{}
+	for name := range app.EnvironmentVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func formatUptime(instance cf.ApplicationInstance) string {
+	if instance.State != cf.InstanceRunning || instance.Uptime == 0 {
+		return ""
+	}
+	return (instance.Uptime / time.Second * time.Second).String()
+}
+
+func instanceDetails(instance cf.ApplicationInstance) string {
+	if instance.State != cf.InstanceDown && instance.State != cf.InstanceFlapping {
+		return ""
+	}
+	return instance.Details
+}
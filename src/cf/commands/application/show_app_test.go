@@ -103,10 +103,129 @@ func TestDisplayingAppSummary(t *testing.T) {
 	assert.Contains(t, ui.Outputs[7], "0 of 0")
 }
 
+func TestDisplayingAppDetails(t *testing.T) {
+	app := cf.Application{
+		Name:                 "my-app",
+		State:                "started",
+		Instances:            2,
+		RunningInstances:     1,
+		Memory:               256,
+		DiskQuota:            1024,
+		BuildpackUrl:         "https://example.com/buildpack.git",
+		DetectedBuildpack:    "Ruby/Rack",
+		DetectedStartCommand: "bundle exec rackup config.ru -p $PORT",
+		Stack:                cf.Stack{Name: "lucid64"},
+		PackageState:         "FAILED",
+		StagingFailedReason:  "NoAppDetectedError",
+		EnvironmentVars:      map[string]string{"SECRET": "s3cr3t", "RAILS_ENV": "production"},
+	}
+
+	instances := []cf.ApplicationInstance{
+		cf.ApplicationInstance{
+			Index:   0,
+			State:   cf.InstanceRunning,
+			Uptime:  90*time.Minute + 1500*time.Millisecond,
+			Details: "ignored while running",
+		},
+		cf.ApplicationInstance{
+			Index:   1,
+			State:   cf.InstanceFlapping,
+			Details: "insufficient resources",
+		},
+	}
+
+	appSummaryRepo := &testhelpers.FakeAppSummaryRepo{GetSummarySummary: cf.AppSummary{App: app, Instances: instances}}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+	ui := callApp([]string{"my-app"}, reqFactory, appSummaryRepo)
+
+	output := ui.DumpOutputs()
+	assert.Contains(t, output, "1G per instance")
+	assert.Contains(t, output, "https://example.com/buildpack.git (detected Ruby/Rack)")
+	assert.Contains(t, output, "bundle exec rackup config.ru -p $PORT (detected)")
+	assert.Contains(t, output, "lucid64")
+	assert.Contains(t, output, "package:")
+	assert.Contains(t, output, "failed (NoAppDetectedError)")
+	assert.Contains(t, output, "RAILS_ENV, SECRET")
+	assert.NotContains(t, output, "s3cr3t")
+
+	lastRow := len(ui.Outputs) - 1
+	assert.Contains(t, ui.Outputs[lastRow-2], "uptime")
+	assert.Contains(t, ui.Outputs[lastRow-2], "details")
+	assert.Contains(t, ui.Outputs[lastRow-1], "1h30m1s")
+	assert.NotContains(t, ui.Outputs[lastRow-1], "ignored while running")
+	assert.Contains(t, ui.Outputs[lastRow], "flapping")
+	assert.Contains(t, ui.Outputs[lastRow], "insufficient resources")
+}
+
+func TestAppWatchRefreshesUntilTheAppIsGone(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", State: "started", Instances: 1}
+
+	starting := cf.AppSummary{App: app, Instances: []cf.ApplicationInstance{
+		cf.ApplicationInstance{State: cf.InstanceStarting},
+	}}
+	running := cf.AppSummary{App: app, Instances: []cf.ApplicationInstance{
+		cf.ApplicationInstance{State: cf.InstanceRunning},
+	}}
+
+	appSummaryRepo := &testhelpers.FakeAppSummaryRepo{GetSummaryResponses: []cf.AppSummary{starting, running}}
+	appRepo := &testhelpers.FakeApplicationRepository{FindByNameApp: app}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+
+	ui := callAppWithAppRepo([]string{"--watch", "my-app"}, reqFactory, appSummaryRepo, appRepo)
+
+	assert.Equal(t, appRepo.FindByNameName, "my-app")
+
+	output := ui.DumpOutputs()
+	assert.Contains(t, output, "starting")
+	assert.Contains(t, output, "refreshed")
+	assert.Contains(t, output, "running")
+	assert.Contains(t, ui.Outputs[len(ui.Outputs)-2], "FAILED")
+	assert.Contains(t, ui.Outputs[len(ui.Outputs)-1], "my-app not found")
+}
+
+func TestAppShowsTheCrashesOfAnInstance(t *testing.T) {
+	app := cf.Application{Name: "my-app", Guid: "my-app-guid", State: "started", Instances: 2}
+	summary := cf.AppSummary{App: app, Instances: []cf.ApplicationInstance{
+		cf.ApplicationInstance{State: cf.InstanceRunning},
+		cf.ApplicationInstance{State: cf.InstanceFlapping},
+	}}
+
+	crashed, err := time.Parse("Mon Jan 2 15:04:05 -0700 MST 2006", "Mon Jan 2 15:04:05 -0700 MST 2012")
+	assert.NoError(t, err)
+
+	appSummaryRepo := &testhelpers.FakeAppSummaryRepo{GetSummarySummary: summary}
+	appRepo := &testhelpers.FakeApplicationRepository{GetCrashesCrashes: []cf.InstanceCrash{
+		cf.InstanceCrash{Index: 0, Since: crashed, ExitDescription: "crash of another instance"},
+		cf.InstanceCrash{Index: 1, Since: crashed, ExitStatus: 137, ExitDescription: "out of memory"},
+	}}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedSpaceSuccess: true, Application: app}
+
+	ui := callAppWithAppRepo([]string{"-i", "1", "my-app"}, reqFactory, appSummaryRepo, appRepo)
+
+	lastRow := len(ui.Outputs) - 1
+	assert.Contains(t, ui.Outputs[lastRow-2], "crashes of instance #1")
+	assert.Contains(t, ui.Outputs[lastRow-1], "exit status")
+	assert.Contains(t, ui.Outputs[lastRow], "2012-01-02 03:04:05 PM")
+	assert.Contains(t, ui.Outputs[lastRow], "137")
+	assert.Contains(t, ui.Outputs[lastRow], "out of memory")
+	assert.NotContains(t, ui.DumpOutputs(), "crash of another instance")
+
+	ui = callAppWithAppRepo([]string{"-i", "0", "my-app"}, reqFactory, appSummaryRepo, &testhelpers.FakeApplicationRepository{})
+	assert.Contains(t, ui.Outputs[len(ui.Outputs)-1], "Instance #0 has not crashed")
+
+	ui = callAppWithAppRepo([]string{"-i", "2", "my-app"}, reqFactory, appSummaryRepo, appRepo)
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "Invalid instance index 2")
+}
+
 func callApp(args []string, reqFactory *testhelpers.FakeReqFactory, appSummaryRepo *testhelpers.FakeAppSummaryRepo) (ui *testhelpers.FakeUI) {
+	return callAppWithAppRepo(args, reqFactory, appSummaryRepo, &testhelpers.FakeApplicationRepository{})
+}
+
+func callAppWithAppRepo(args []string, reqFactory *testhelpers.FakeReqFactory, appSummaryRepo *testhelpers.FakeAppSummaryRepo, appRepo *testhelpers.FakeApplicationRepository) (ui *testhelpers.FakeUI) {
 	ui = &testhelpers.FakeUI{}
 	ctxt := testhelpers.NewContext("app", args)
-	cmd := NewShowApp(ui, appSummaryRepo)
+	cmd := NewShowApp(ui, appSummaryRepo, appRepo)
 	testhelpers.RunCommand(cmd, ctxt, reqFactory)
 
 	return
@@ -8,15 +8,22 @@ import (
 	"cf/terminal"
 	"errors"
 	"fmt"
+	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"github.com/codegangsta/cli"
 	"strings"
+	"sync"
 	"time"
 )
 
+// stagingLogTimeout is how long start waits for the staging log to connect,
+// and to close once staging is over, before carrying on without it.
+const stagingLogTimeout = 5 * time.Second
+
 type Start struct {
 	ui        terminal.UI
 	config    *configuration.Configuration
 	appRepo   api.ApplicationRepository
+	logsRepo  api.LogsRepository
 	startTime time.Time
 	appReq    requirements.ApplicationRequirement
 }
@@ -25,11 +32,12 @@ type ApplicationStarter interface {
 	ApplicationStart(cf.Application) (startedApp cf.Application, err error)
 }
 
-func NewStart(ui terminal.UI, config *configuration.Configuration, appRepo api.ApplicationRepository) (cmd *Start) {
+func NewStart(ui terminal.UI, config *configuration.Configuration, appRepo api.ApplicationRepository, logsRepo api.LogsRepository) (cmd *Start) {
 	cmd = new(Start)
 	cmd.ui = ui
 	cmd.config = config
 	cmd.appRepo = appRepo
+	cmd.logsRepo = logsRepo
 
 	return
 }
@@ -59,8 +67,11 @@ func (cmd *Start) ApplicationStart(app cf.Application) (updatedApp cf.Applicatio
 
 	cmd.ui.Say("Starting %s...", terminal.EntityNameColor(app.Name))
 
+	staging := cmd.tailStagingLog(app)
+
 	updatedApp, apiResponse := cmd.appRepo.Start(app)
 	if apiResponse.IsNotSuccessful() {
+		staging.stop()
 		cmd.ui.Failed(apiResponse.Message)
 		return
 	}
@@ -69,16 +80,30 @@ func (cmd *Start) ApplicationStart(app cf.Application) (updatedApp cf.Applicatio
 
 	instances, apiResponse := cmd.appRepo.GetInstances(app)
 
-	for apiResponse.IsNotSuccessful() {
-		if apiResponse.ErrorCode != api.APP_NOT_STAGED {
-			cmd.ui.Say("")
+	for apiResponse.IsNotSuccessful() && apiResponse.ErrorCode == api.APP_NOT_STAGED {
+		cmd.ui.Wait(1 * time.Second)
+		instances, apiResponse = cmd.appRepo.GetInstances(app)
+		if !staging.show(cmd.ui) && !staging.connected {
+			cmd.ui.LoadingIndication()
+		}
+	}
+
+	staging.stop()
+	staging.show(cmd.ui)
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.Say("")
+		if apiResponse.ErrorCode != api.STAGING_FAILED {
 			cmd.ui.Failed(apiResponse.Message)
 			return
 		}
 
-		cmd.ui.Wait(1 * time.Second)
-		instances, apiResponse = cmd.appRepo.GetInstances(app)
-		cmd.ui.LoadingIndication()
+		message := fmt.Sprintf("Staging of %s failed: %s", app.Name, apiResponse.Message)
+		if !staging.connected {
+			message += fmt.Sprintf("\nTIP: Use '%s' for more information", terminal.CommandColor(cf.Name+" logs "+app.Name+" --recent"))
+		}
+		cmd.ui.Failed("%s", message)
+		return
 	}
 
 	cmd.ui.Say("")
@@ -93,6 +118,80 @@ func (cmd *Start) ApplicationStart(app cf.Application) (updatedApp cf.Applicatio
 	return
 }
 
+// tailStagingLog starts collecting the staging messages of app in the
+// background, and waits for the connection so that none are missed.
+func (cmd *Start) tailStagingLog(app cf.Application) (staging *stagingLog) {
+	staging = &stagingLog{
+		stopChan: make(chan bool),
+		doneChan: make(chan bool),
+	}
+	connectChan := make(chan bool, 1)
+
+	onConnect := func() {
+		select {
+		case connectChan <- true:
+		default:
+		}
+	}
+
+	onMessage := func(msg logmessage.LogMessage) {
+		if msg.GetSourceType() != logSourceTypesByFlag["staging"] {
+			return
+		}
+		staging.mutex.Lock()
+		staging.lines = append(staging.lines, string(msg.GetMessage()))
+		staging.mutex.Unlock()
+	}
+
+	go func() {
+		cmd.logsRepo.TailLogsFor(app, staging.stopChan, onConnect, onMessage, 1, api.LOGGREGATOR_REDIRECTOR_PORT)
+		close(staging.doneChan)
+	}()
+
+	select {
+	case <-connectChan:
+		staging.connected = true
+	case <-staging.doneChan:
+	case <-time.After(stagingLogTimeout):
+	}
+	return
+}
+
+// stagingLog holds the staging messages received in the background until the
+// command shows them.
+type stagingLog struct {
+	connected bool
+	stopChan  chan bool
+	doneChan  chan bool
+
+	mutex sync.Mutex
+	lines []string
+}
+
+// show says the messages received since it was last called, and tells
+// whether there were any.
+func (staging *stagingLog) show(ui terminal.UI) bool {
+	staging.mutex.Lock()
+	lines := staging.lines
+	staging.lines = nil
+	staging.mutex.Unlock()
+
+	for _, line := range lines {
+		ui.Say("%s", line)
+	}
+	return len(lines) > 0
+}
+
+// stop ends the tail, waiting for the messages received before it ended.
+func (staging *stagingLog) stop() {
+	close(staging.stopChan)
+
+	select {
+	case <-staging.doneChan:
+	case <-time.After(stagingLogTimeout):
+	}
+}
+
 func (cmd Start) displayInstancesStatus(app cf.Application, instances []cf.ApplicationInstance) (notFinished bool) {
 	totalCount := len(instances)
 	runningCount, startingCount, flappingCount, downCount := 0, 0, 0, 0
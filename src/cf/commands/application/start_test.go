@@ -5,9 +5,12 @@ import (
 	"cf/api"
 	. "cf/commands/application"
 	"cf/configuration"
+	"errors"
+	"github.com/cloudfoundry/loggregatorlib/logmessage"
 	"github.com/stretchr/testify/assert"
 	"testhelpers"
 	"testing"
+	"time"
 )
 
 var defaultAppForStart = cf.Application{
@@ -215,11 +218,93 @@ func TestStartApplicationIsAlreadyStarted(t *testing.T) {
 	assert.Equal(t, appRepo.StartAppToStart.Guid, "")
 }
 
+func TestStartApplicationShowsTheStagingLog(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{
+		[]cf.ApplicationInstance{},
+		[]cf.ApplicationInstance{
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+			cf.ApplicationInstance{State: cf.InstanceRunning},
+		},
+	}
+	errorCodes := []string{api.APP_NOT_STAGED, ""}
+
+	now := time.Now()
+	logsRepo := &testhelpers.FakeLogsRepository{
+		TailLogMessages: []logmessage.LogMessage{
+			createLogMessage("-----> Downloaded app package", logmessage.LogMessage_DEA, "", logmessage.LogMessage_OUT, now),
+			createLogMessage("app output", logmessage.LogMessage_WARDEN_CONTAINER, "0", logmessage.LogMessage_OUT, now),
+			createLogMessage("-----> Uploading droplet", logmessage.LogMessage_DEA, "", logmessage.LogMessage_OUT, now),
+		},
+	}
+
+	ui, _ := startAppWithLogs(instances, errorCodes, logsRepo)
+
+	assert.Equal(t, logsRepo.AppLogged.Guid, "my-app-guid")
+	assert.True(t, logsRepo.TailStopped)
+
+	assert.Contains(t, ui.Outputs[0], "my-app")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Equal(t, ui.Outputs[2], "-----> Downloaded app package")
+	assert.Equal(t, ui.Outputs[3], "-----> Uploading droplet")
+	assert.Contains(t, ui.Outputs[5], "Started: app my-app available at http://my-app.example.com")
+}
+
+func TestStartApplicationWhenStagingFailsShowsTheStagingLog(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{[]cf.ApplicationInstance{}, []cf.ApplicationInstance{}}
+	errorCodes := []string{api.APP_NOT_STAGED, api.STAGING_FAILED}
+
+	logsRepo := &testhelpers.FakeLogsRepository{
+		TailLogMessages: []logmessage.LogMessage{
+			createLogMessage("-----> Compiling Ruby", logmessage.LogMessage_DEA, "", logmessage.LogMessage_OUT, time.Now()),
+			createLogMessage("Gem::InstallError: pg requires libpq", logmessage.LogMessage_DEA, "", logmessage.LogMessage_ERR, time.Now()),
+		},
+	}
+
+	ui, _ := startAppWithLogs(instances, errorCodes, logsRepo)
+
+	assert.True(t, logsRepo.TailStopped)
+	assert.Equal(t, ui.Outputs[2], "-----> Compiling Ruby")
+	assert.Equal(t, ui.Outputs[3], "Gem::InstallError: pg requires libpq")
+	assert.Contains(t, ui.Outputs[5], "FAILED")
+	assert.Contains(t, ui.Outputs[6], "Staging of my-app failed: Error staging app")
+	assert.NotContains(t, ui.Outputs[6], "TIP")
+}
+
+func TestStartApplicationWhenStagingFailsWithoutTheStagingLog(t *testing.T) {
+	instances := [][]cf.ApplicationInstance{[]cf.ApplicationInstance{}}
+	errorCodes := []string{api.STAGING_FAILED}
+	logsRepo := &testhelpers.FakeLogsRepository{TailErr: errors.New("connection refused")}
+
+	ui, appRepo := startAppWithLogs(instances, errorCodes, logsRepo)
+
+	assert.Equal(t, appRepo.StartAppToStart.Guid, "my-app-guid")
+	assert.Contains(t, ui.Outputs[3], "FAILED")
+	assert.Contains(t, ui.Outputs[4], "Staging of my-app failed")
+	assert.Contains(t, ui.Outputs[4], "logs my-app --recent")
+}
+
+func startAppWithLogs(instances [][]cf.ApplicationInstance, errorCodes []string, logsRepo *testhelpers.FakeLogsRepository) (ui *testhelpers.FakeUI, appRepo *testhelpers.FakeApplicationRepository) {
+	config := &configuration.Configuration{ApplicationStartTimeout: 2}
+	appRepo = &testhelpers.FakeApplicationRepository{
+		FindByNameApp:          defaultAppForStart,
+		GetInstancesResponses:  instances,
+		GetInstancesErrorCodes: errorCodes,
+	}
+	reqFactory := &testhelpers.FakeReqFactory{Application: defaultAppForStart}
+
+	ui = callStartWithLogs([]string{"my-app"}, config, reqFactory, appRepo, logsRepo)
+	return
+}
+
 func callStart(args []string, config *configuration.Configuration, reqFactory *testhelpers.FakeReqFactory, appRepo api.ApplicationRepository) (ui *testhelpers.FakeUI) {
+	return callStartWithLogs(args, config, reqFactory, appRepo, &testhelpers.FakeLogsRepository{})
+}
+
+func callStartWithLogs(args []string, config *configuration.Configuration, reqFactory *testhelpers.FakeReqFactory, appRepo api.ApplicationRepository, logsRepo api.LogsRepository) (ui *testhelpers.FakeUI) {
 	ui = new(testhelpers.FakeUI)
 	ctxt := testhelpers.NewContext("start", args)
 
-	cmd := NewStart(ui, config, appRepo)
+	cmd := NewStart(ui, config, appRepo, logsRepo)
 	testhelpers.RunCommand(cmd, ctxt, reqFactory)
 	return
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"cf"
 	"cf/api"
 	"cf/commands/application"
 	"cf/requirements"
@@ -43,27 +44,53 @@ func (cmd *ListDomains) Run(c *cli.Context) {
 
 	cmd.ui.Say("Getting domains in org %s...", org.Name)
 
-	domains, apiResponse := cmd.domainRepo.FindAllByOrg(org)
-	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+	if cmd.ui.IsStructuredOutput() {
+		cmd.displayDomainData(org)
 		return
 	}
 
-	table := [][]string{
-		[]string{"name", "shared", "spaces"},
-	}
-	for _, domain := range domains {
-		var isShared string
-		if domain.Shared {
-			isShared = "true"
+	firstPage := true
+	table := terminal.NewPagedTable(cmd.ui, []string{"name", "shared", "spaces"})
+	apiResponse := cmd.domainRepo.ListDomainsForOrg(org, func(domains []cf.Domain) bool {
+		if firstPage {
+			cmd.ui.Ok()
+			firstPage = false
+		}
+
+		rows := [][]string{}
+		for _, domain := range domains {
+			var isShared string
+			if domain.Shared {
+				isShared = "true"
+			}
+			rows = append(rows, []string{
+				domain.Name,
+				isShared,
+				strings.Join(application.MapStr(domain.Spaces), ", "),
+			})
 		}
-		table = append(table, []string{
-			domain.Name,
-			isShared,
-			strings.Join(application.MapStr(domain.Spaces), ", "),
-		})
+
+		table.Print(rows)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+	}
+}
+
+func (cmd *ListDomains) displayDomainData(org cf.Organization) {
+	domains := []cf.Domain{}
+	apiResponse := cmd.domainRepo.ListDomainsForOrg(org, func(page []cf.Domain) bool {
+		domains = append(domains, page...)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
 	}
 
 	cmd.ui.Ok()
-	cmd.ui.DisplayTable(table, nil)
+	cmd.ui.DisplayData(domains)
 }
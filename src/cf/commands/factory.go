@@ -5,9 +5,11 @@ import (
 	"cf/commands/application"
 	"cf/commands/domain"
 	"cf/commands/organization"
+	"cf/commands/quota"
 	"cf/commands/route"
 	"cf/commands/service"
 	"cf/commands/space"
+	"cf/commands/user"
 	"cf/configuration"
 	"cf/terminal"
 	"errors"
@@ -23,32 +25,43 @@ type ConcreteFactory struct {
 
 func NewFactory(ui terminal.UI, config *configuration.Configuration, configRepo configuration.ConfigurationRepository, repoLocator api.RepositoryLocator) (factory ConcreteFactory) {
 	factory.cmdsByName = make(map[string]Command)
+	profileRepo := configuration.NewProfileDiskRepository()
 
 	factory.cmdsByName["api"] = NewApi(ui, config, repoLocator.GetEndpointRepository())
-	factory.cmdsByName["app"] = application.NewShowApp(ui, repoLocator.GetAppSummaryRepository())
+	factory.cmdsByName["app"] = application.NewShowApp(ui, repoLocator.GetAppSummaryRepository(), repoLocator.GetApplicationRepository())
 	factory.cmdsByName["apps"] = application.NewListApps(ui, repoLocator.GetSpaceRepository())
 	factory.cmdsByName["bind-service"] = service.NewBindService(ui, repoLocator.GetServiceRepository())
+	factory.cmdsByName["create-profile"] = NewCreateProfile(ui, profileRepo)
+	factory.cmdsByName["create-quota"] = quota.NewCreateQuota(ui, repoLocator.GetQuotaRepository())
 	factory.cmdsByName["create-org"] = organization.NewCreateOrg(ui, repoLocator.GetOrganizationRepository())
 	factory.cmdsByName["create-service"] = service.NewCreateService(ui, repoLocator.GetServiceRepository())
 	factory.cmdsByName["create-space"] = space.NewCreateSpace(ui, repoLocator.GetSpaceRepository())
+	factory.cmdsByName["create-user"] = user.NewCreateUser(ui, repoLocator.GetUserRepository())
 	factory.cmdsByName["create-user-provided-service"] = service.NewCreateUserProvidedService(ui, repoLocator.GetServiceRepository())
 	factory.cmdsByName["delete"] = application.NewDeleteApp(ui, repoLocator.GetApplicationRepository())
 	factory.cmdsByName["delete-domain"] = domain.NewDeleteDomain(ui, repoLocator.GetDomainRepository())
 	factory.cmdsByName["delete-org"] = organization.NewDeleteOrg(ui, repoLocator.GetOrganizationRepository(), configRepo)
+	factory.cmdsByName["delete-profile"] = NewDeleteProfile(ui, profileRepo)
+	factory.cmdsByName["delete-quota"] = quota.NewDeleteQuota(ui, repoLocator.GetQuotaRepository())
 	factory.cmdsByName["delete-service"] = service.NewDeleteService(ui, repoLocator.GetServiceRepository())
 	factory.cmdsByName["delete-space"] = space.NewDeleteSpace(ui, repoLocator.GetSpaceRepository(), configRepo)
+	factory.cmdsByName["delete-user"] = user.NewDeleteUser(ui, repoLocator.GetUserRepository())
 	factory.cmdsByName["domains"] = domain.NewListDomains(ui, repoLocator.GetDomainRepository())
-	factory.cmdsByName["env"] = application.NewEnv(ui)
+	factory.cmdsByName["env"] = application.NewEnv(ui, repoLocator.GetApplicationRepository())
 	factory.cmdsByName["files"] = application.NewFiles(ui, repoLocator.GetAppFilesRepository())
 	factory.cmdsByName["login"] = NewLogin(ui, configRepo, repoLocator.GetAuthenticationRepository())
 	factory.cmdsByName["logout"] = NewLogout(ui, configRepo)
-	factory.cmdsByName["logs"] = application.NewLogs(ui, repoLocator.GetLogsRepository())
+	factory.cmdsByName["logs"] = application.NewLogs(ui, repoLocator.GetLogsRepository(), repoLocator.GetApplicationRepository(), repoLocator.GetSpaceRepository())
 	factory.cmdsByName["marketplace"] = service.NewMarketplaceServices(ui, repoLocator.GetServiceRepository())
 	factory.cmdsByName["map-domain"] = domain.NewDomainMapper(ui, repoLocator.GetDomainRepository(), true)
 	factory.cmdsByName["map-route"] = route.NewRouteMapper(ui, repoLocator.GetRouteRepository(), true)
-	factory.cmdsByName["org"] = organization.NewShowOrg(ui)
+	factory.cmdsByName["org"] = organization.NewShowOrg(ui, repoLocator.GetQuotaRepository())
+	factory.cmdsByName["org-users"] = user.NewOrgUsers(ui, repoLocator.GetUserRepository())
 	factory.cmdsByName["orgs"] = organization.NewListOrgs(ui, repoLocator.GetOrganizationRepository())
 	factory.cmdsByName["password"] = NewPassword(ui, repoLocator.GetPasswordRepository(), configRepo)
+	factory.cmdsByName["profiles"] = NewListProfiles(ui, profileRepo)
+	factory.cmdsByName["quota"] = quota.NewShowQuota(ui, repoLocator.GetQuotaRepository())
+	factory.cmdsByName["quotas"] = quota.NewListQuotas(ui, repoLocator.GetQuotaRepository())
 	factory.cmdsByName["rename"] = application.NewRenameApp(ui, repoLocator.GetApplicationRepository())
 	factory.cmdsByName["rename-org"] = organization.NewRenameOrg(ui, repoLocator.GetOrganizationRepository())
 	factory.cmdsByName["rename-service"] = service.NewRenameService(ui, repoLocator.GetServiceRepository())
@@ -57,26 +70,35 @@ func NewFactory(ui terminal.UI, config *configuration.Configuration, configRepo
 	factory.cmdsByName["reserve-route"] = route.NewReserveRoute(ui, repoLocator.GetRouteRepository())
 	factory.cmdsByName["routes"] = route.NewListRoutes(ui, config, repoLocator.GetRouteRepository())
 	factory.cmdsByName["set-env"] = application.NewSetEnv(ui, repoLocator.GetApplicationRepository())
+	factory.cmdsByName["set-org-role"] = user.NewOrgRoleSetter(ui, repoLocator.GetUserRepository(), true)
 	factory.cmdsByName["set-quota"] = organization.NewSetQuota(ui, repoLocator.GetOrganizationRepository())
+	factory.cmdsByName["set-space-role"] = user.NewSpaceRoleSetter(ui, repoLocator.GetSpaceRepository(), repoLocator.GetUserRepository(), true)
 	factory.cmdsByName["space"] = space.NewShowSpace(ui, config)
 	factory.cmdsByName["service"] = service.NewShowService(ui)
 	factory.cmdsByName["services"] = service.NewListServices(ui, repoLocator.GetSpaceRepository())
+	factory.cmdsByName["space-users"] = user.NewSpaceUsers(ui, repoLocator.GetSpaceRepository(), repoLocator.GetUserRepository())
 	factory.cmdsByName["spaces"] = space.NewListSpaces(ui, config, repoLocator.GetSpaceRepository())
 	factory.cmdsByName["stacks"] = NewStacks(ui, repoLocator.GetStackRepository())
+	factory.cmdsByName["switch-profile"] = NewSwitchProfile(ui, profileRepo)
 	factory.cmdsByName["target"] = NewTarget(ui, configRepo, repoLocator.GetOrganizationRepository(), repoLocator.GetSpaceRepository())
 	factory.cmdsByName["unbind-service"] = service.NewUnbindService(ui, repoLocator.GetServiceRepository())
 	factory.cmdsByName["unmap-domain"] = domain.NewDomainMapper(ui, repoLocator.GetDomainRepository(), false)
 	factory.cmdsByName["unmap-route"] = route.NewRouteMapper(ui, repoLocator.GetRouteRepository(), false)
 	factory.cmdsByName["unset-env"] = application.NewUnsetEnv(ui, repoLocator.GetApplicationRepository())
+	factory.cmdsByName["unset-org-role"] = user.NewOrgRoleSetter(ui, repoLocator.GetUserRepository(), false)
+	factory.cmdsByName["unset-space-role"] = user.NewSpaceRoleSetter(ui, repoLocator.GetSpaceRepository(), repoLocator.GetUserRepository(), false)
+	factory.cmdsByName["usage"] = organization.NewUsage(ui, config, repoLocator.GetOrganizationRepository(), repoLocator.GetSpaceRepository())
+	factory.cmdsByName["update-quota"] = quota.NewUpdateQuota(ui, repoLocator.GetQuotaRepository())
 
-	start := application.NewStart(ui, config, repoLocator.GetApplicationRepository())
+	start := application.NewStart(ui, config, repoLocator.GetApplicationRepository(), repoLocator.GetLogsRepository())
 	stop := application.NewStop(ui, repoLocator.GetApplicationRepository())
 	restart := application.NewRestart(ui, start, stop)
 
 	factory.cmdsByName["start"] = start
 	factory.cmdsByName["stop"] = stop
 	factory.cmdsByName["restart"] = restart
-	factory.cmdsByName["push"] = application.NewPush(ui, start, stop, repoLocator.GetApplicationRepository(), repoLocator.GetDomainRepository(), repoLocator.GetRouteRepository(), repoLocator.GetStackRepository(), repoLocator.GetApplicationBitsRepository())
+	factory.cmdsByName["restart-app-instance"] = application.NewRestartAppInstance(ui, repoLocator.GetApplicationRepository())
+	factory.cmdsByName["push"] = application.NewPush(ui, config, start, stop, repoLocator.GetApplicationRepository(), repoLocator.GetDomainRepository(), repoLocator.GetRouteRepository(), repoLocator.GetStackRepository(), repoLocator.GetServiceRepository(), repoLocator.GetApplicationBitsRepository())
 	factory.cmdsByName["scale"] = application.NewScale(ui, restart, repoLocator.GetApplicationRepository())
 
 	return
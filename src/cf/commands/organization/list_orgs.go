@@ -1,6 +1,7 @@
 package organization
 
 import (
+	"cf"
 	"cf/api"
 	"cf/requirements"
 	"cf/terminal"
@@ -28,15 +29,48 @@ func (cmd ListOrgs) GetRequirements(reqFactory requirements.Factory, c *cli.Cont
 func (cmd ListOrgs) Run(c *cli.Context) {
 	cmd.ui.Say("Getting orgs...")
 
-	orgs, apiResponse := cmd.orgRepo.FindAll()
+	if cmd.ui.IsStructuredOutput() {
+		cmd.displayOrgData()
+		return
+	}
+
+	noOrgs := true
+	apiResponse := cmd.orgRepo.ListOrgs(func(orgs []cf.Organization) bool {
+		for _, org := range orgs {
+			if noOrgs {
+				cmd.ui.Ok()
+				noOrgs = false
+			}
+			cmd.ui.Say(org.Name)
+		}
+		return true
+	})
+
 	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return
 	}
 
-	cmd.ui.Ok()
+	if noOrgs {
+		cmd.ui.Ok()
+		cmd.ui.Say("No orgs found")
+	}
+}
+
+// displayOrgData has to wait for every page since a document can only be
+// written once it is complete.
+func (cmd ListOrgs) displayOrgData() {
+	orgs := []cf.Organization{}
+	apiResponse := cmd.orgRepo.ListOrgs(func(page []cf.Organization) bool {
+		orgs = append(orgs, page...)
+		return true
+	})
 
-	for _, org := range orgs {
-		cmd.ui.Say(org.Name)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
 	}
+
+	cmd.ui.Ok()
+	cmd.ui.DisplayData(orgs)
 }
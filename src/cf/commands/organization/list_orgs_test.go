@@ -39,6 +39,32 @@ func TestListOrgs(t *testing.T) {
 	assert.Contains(t, ui.Outputs[3], "Organization-2")
 }
 
+func TestListOrgsWhenThereAreNone(t *testing.T) {
+	orgRepo := &testhelpers.FakeOrgRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callListOrgs(reqFactory, orgRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Getting orgs")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "No orgs found")
+}
+
+func TestListOrgsWithStructuredOutput(t *testing.T) {
+	orgs := []cf.Organization{
+		cf.Organization{Name: "Organization-1"},
+		cf.Organization{Name: "Organization-2"},
+	}
+	orgRepo := &testhelpers.FakeOrgRepository{Organizations: orgs}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := &testhelpers.FakeUI{StructuredOutput: true}
+	ctxt := testhelpers.NewContext("orgs", []string{})
+	testhelpers.RunCommand(NewListOrgs(ui, orgRepo), ctxt, reqFactory)
+
+	assert.Equal(t, ui.DisplayedData, orgs)
+}
+
 func callListOrgs(reqFactory *testhelpers.FakeReqFactory, orgRepo *testhelpers.FakeOrgRepository) (fakeUI *testhelpers.FakeUI) {
 	fakeUI = &testhelpers.FakeUI{}
 	ctxt := testhelpers.NewContext("orgs", []string{})
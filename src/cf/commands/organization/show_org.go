@@ -1,21 +1,28 @@
 package organization
 
 import (
+	"cf"
+	"cf/api"
+	"cf/formatters"
+	"cf/net"
 	"cf/requirements"
 	"cf/terminal"
 	"errors"
+	"fmt"
 	"github.com/codegangsta/cli"
 	"strings"
 )
 
 type ShowOrg struct {
-	ui     terminal.UI
-	orgReq requirements.OrganizationRequirement
+	ui        terminal.UI
+	quotaRepo api.QuotaRepository
+	orgReq    requirements.OrganizationRequirement
 }
 
-func NewShowOrg(ui terminal.UI) (cmd *ShowOrg) {
+func NewShowOrg(ui terminal.UI, quotaRepo api.QuotaRepository) (cmd *ShowOrg) {
 	cmd = new(ShowOrg)
 	cmd.ui = ui
+	cmd.quotaRepo = quotaRepo
 	return
 }
 
@@ -38,6 +45,17 @@ func (cmd *ShowOrg) GetRequirements(reqFactory requirements.Factory, c *cli.Cont
 func (cmd *ShowOrg) Run(c *cli.Context) {
 	org := cmd.orgReq.GetOrganization()
 	cmd.ui.Say("Getting info for org %s...", org.Name)
+
+	var usage cf.QuotaUsage
+	if org.Quota.Guid != "" {
+		var apiResponse net.ApiResponse
+		usage, apiResponse = cmd.quotaRepo.FindUsage(org)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+			return
+		}
+	}
+
 	cmd.ui.Ok()
 	cmd.ui.Say("%s:", terminal.EntityNameColor(org.Name))
 
@@ -53,4 +71,16 @@ func (cmd *ShowOrg) Run(c *cli.Context) {
 
 	cmd.ui.Say("  domains: %s", terminal.EntityNameColor(strings.Join(domains, ", ")))
 	cmd.ui.Say("  spaces: %s", terminal.EntityNameColor(strings.Join(spaces, ", ")))
+
+	if org.Quota.Guid == "" {
+		return
+	}
+
+	quota := org.Quota
+	cmd.ui.Say("  quota: %s", terminal.EntityNameColor(quota.Name))
+	cmd.ui.Say("    memory: %s", terminal.EntityNameColor(fmt.Sprintf("%s of %s",
+		formatters.ByteSize(usage.Memory*formatters.MEGABYTE),
+		formatters.ByteSize(quota.MemoryLimit*formatters.MEGABYTE))))
+	cmd.ui.Say("    services: %s", terminal.EntityNameColor(fmt.Sprintf("%d of %d", usage.Services, quota.ServicesLimit)))
+	cmd.ui.Say("    routes: %s", terminal.EntityNameColor(fmt.Sprintf("%d of %d", usage.Routes, quota.RoutesLimit)))
 }
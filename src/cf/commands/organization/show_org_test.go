@@ -63,11 +63,50 @@ func TestRunWhenOrganizationExists(t *testing.T) {
 	assert.Contains(t, ui.Outputs[4], "development, staging")
 }
 
+func TestShowOrgDisplaysQuotaUsage(t *testing.T) {
+	org := cf.Organization{
+		Name:  "my-org",
+		Guid:  "my-org-guid",
+		Quota: cf.Quota{Name: "paid", Guid: "quota-guid", MemoryLimit: 10240, ServicesLimit: 100, RoutesLimit: 1000},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{Organization: org, LoginSuccess: true}
+	quotaRepo := &testhelpers.FakeQuotaRepository{
+		FindUsageUsage: cf.QuotaUsage{Memory: 512, Services: 2, Routes: 3},
+	}
+
+	ui := new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("org", []string{"my-org"})
+	testhelpers.RunCommand(NewShowOrg(ui, quotaRepo), ctxt, reqFactory)
+
+	assert.Equal(t, quotaRepo.FindUsageOrganization, org)
+	assert.Contains(t, ui.Outputs[5], "quota:")
+	assert.Contains(t, ui.Outputs[5], "paid")
+	assert.Contains(t, ui.Outputs[6], "memory:")
+	assert.Contains(t, ui.Outputs[6], "512M of 10G")
+	assert.Contains(t, ui.Outputs[7], "services:")
+	assert.Contains(t, ui.Outputs[7], "2 of 100")
+	assert.Contains(t, ui.Outputs[8], "routes:")
+	assert.Contains(t, ui.Outputs[8], "3 of 1000")
+}
+
+func TestShowOrgWhenQuotaUsageCannotBeFound(t *testing.T) {
+	org := cf.Organization{Name: "my-org", Guid: "my-org-guid", Quota: cf.Quota{Name: "paid", Guid: "quota-guid"}}
+	reqFactory := &testhelpers.FakeReqFactory{Organization: org, LoginSuccess: true}
+	quotaRepo := &testhelpers.FakeQuotaRepository{FindUsageErr: true}
+
+	ui := new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("org", []string{"my-org"})
+	testhelpers.RunCommand(NewShowOrg(ui, quotaRepo), ctxt, reqFactory)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "Error finding quota usage")
+}
+
 func callShowOrg(args []string, reqFactory *testhelpers.FakeReqFactory) (ui *testhelpers.FakeUI) {
 	ui = new(testhelpers.FakeUI)
 	ctxt := testhelpers.NewContext("org", args)
 
-	cmd := NewShowOrg(ui)
+	cmd := NewShowOrg(ui, &testhelpers.FakeQuotaRepository{})
 	testhelpers.RunCommand(cmd, ctxt, reqFactory)
 	return
 }
@@ -0,0 +1,246 @@
+package organization
+
+import (
+	"bytes"
+	"cf"
+	"cf/api"
+	"cf/configuration"
+	"cf/formatters"
+	"cf/net"
+	"cf/requirements"
+	"cf/terminal"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/codegangsta/cli"
+	"strings"
+)
+
+type Usage struct {
+	ui        terminal.UI
+	config    *configuration.Configuration
+	orgRepo   api.OrganizationRepository
+	spaceRepo api.SpaceRepository
+}
+
+// usageRow is the usage of a single space, or of a whole org when Space is
+// empty. Memory and disk are in megabytes and only count started apps, the
+// same way the cloud controller counts them against the quota.
+type usageRow struct {
+	Org           string `json:"org"`
+	Space         string `json:"space,omitempty"`
+	Apps          int    `json:"apps"`
+	Instances     int    `json:"instances"`
+	Memory        uint64 `json:"memory"`
+	Disk          uint64 `json:"disk"`
+	Services      int    `json:"services"`
+	MemoryLimit   uint64 `json:"memory_limit"`
+	ServicesLimit int    `json:"services_limit"`
+}
+
+func NewUsage(ui terminal.UI, config *configuration.Configuration, orgRepo api.OrganizationRepository, spaceRepo api.SpaceRepository) (cmd *Usage) {
+	cmd = new(Usage)
+	cmd.ui = ui
+	cmd.config = config
+	cmd.orgRepo = orgRepo
+	cmd.spaceRepo = spaceRepo
+	return
+}
+
+func (cmd *Usage) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 0 || (c.String("org") != "" && c.Bool("all-orgs")) {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "usage")
+		return
+	}
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+
+	if c.String("org") == "" && !c.Bool("all-orgs") {
+		reqs = append(reqs, reqFactory.NewTargetedOrgRequirement())
+	}
+	return
+}
+
+func (cmd *Usage) Run(c *cli.Context) {
+	allOrgs := c.Bool("all-orgs")
+	showCSV := c.Bool("csv") && !cmd.ui.IsStructuredOutput()
+
+	orgNames := []string{}
+	switch {
+	case allOrgs:
+		orgs, apiResponse := cmd.orgRepo.FindAll()
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+			return
+		}
+		for _, org := range orgs {
+			orgNames = append(orgNames, org.Name)
+		}
+	case c.String("org") != "":
+		orgNames = append(orgNames, c.String("org"))
+	default:
+		orgNames = append(orgNames, cmd.config.Organization.Name)
+	}
+
+	if !showCSV {
+		if allOrgs {
+			cmd.ui.Say("Getting usage for all orgs...")
+		} else {
+			cmd.ui.Say("Getting usage for org %s...", terminal.EntityNameColor(orgNames[0]))
+		}
+	}
+
+	rows := []usageRow{}
+	for _, name := range orgNames {
+		org, apiResponse := cmd.orgRepo.FindByName(name)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+			return
+		}
+
+		orgRows, apiResponse := cmd.orgUsage(org)
+		if apiResponse.IsNotSuccessful() {
+			cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+			return
+		}
+
+		if allOrgs {
+			orgRows = orgRows[len(orgRows)-1:]
+		}
+		rows = append(rows, orgRows...)
+	}
+
+	if showCSV {
+		cmd.displayCSV(rows)
+		return
+	}
+
+	cmd.ui.Ok()
+
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(rows)
+		return
+	}
+
+	cmd.displayTable(rows)
+}
+
+// orgUsage returns a row for each space in org followed by the org total.
+func (cmd *Usage) orgUsage(org cf.Organization) (rows []usageRow, apiResponse net.ApiResponse) {
+	total := usageRow{
+		Org:           org.Name,
+		MemoryLimit:   org.Quota.MemoryLimit,
+		ServicesLimit: org.Quota.ServicesLimit,
+	}
+
+	spaces, apiResponse := cmd.spaceRepo.FindAllInOrg(org)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	for _, space := range spaces {
+		var summary cf.Space
+		summary, apiResponse = cmd.spaceRepo.GetSummaryForSpace(space)
+		if apiResponse.IsNotSuccessful() {
+			return
+		}
+
+		row := spaceUsage(summary)
+		row.Org = org.Name
+		row.Space = space.Name
+		row.MemoryLimit = total.MemoryLimit
+		row.ServicesLimit = total.ServicesLimit
+		rows = append(rows, row)
+
+		total.Apps += row.Apps
+		total.Instances += row.Instances
+		total.Memory += row.Memory
+		total.Disk += row.Disk
+		total.Services += row.Services
+	}
+
+	rows = append(rows, total)
+	return
+}
+
+func spaceUsage(summary cf.Space) (row usageRow) {
+	row.Apps = len(summary.Applications)
+	row.Services = len(summary.ServiceInstances)
+
+	for _, app := range summary.Applications {
+		if app.State != "started" {
+			continue
+		}
+		instances := uint64(app.Instances)
+		row.Instances += app.Instances
+		row.Memory += app.Memory * instances
+		row.Disk += app.DiskQuota * instances
+	}
+	return
+}
+
+func (cmd *Usage) displayTable(rows []usageRow) {
+	table := [][]string{
+		[]string{"org", "space", "apps", "instances", "memory", "disk", "services", "memory quota", "services quota"},
+	}
+
+	for _, row := range rows {
+		space := row.Space
+		if space == "" {
+			space = "(total)"
+		}
+
+		table = append(table, []string{
+			row.Org,
+			space,
+			fmt.Sprintf("%d", row.Apps),
+			fmt.Sprintf("%d", row.Instances),
+			formatters.ByteSize(row.Memory * formatters.MEGABYTE),
+			formatters.ByteSize(row.Disk * formatters.MEGABYTE),
+			fmt.Sprintf("%d", row.Services),
+			quotaPercentage(int64(row.Memory), int64(row.MemoryLimit), formatters.ByteSize(row.MemoryLimit*formatters.MEGABYTE)),
+			quotaPercentage(int64(row.Services), int64(row.ServicesLimit), fmt.Sprintf("%d", row.ServicesLimit)),
+		})
+	}
+
+	cmd.ui.DisplayTable(table, nil)
+}
+
+func quotaPercentage(used, limit int64, formattedLimit string) string {
+	if limit <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%% of %s", used*100/limit, formattedLimit)
+}
+
+func (cmd *Usage) displayCSV(rows []usageRow) {
+	records := [][]string{
+		[]string{"org", "space", "apps", "instances", "memory_mb", "disk_mb", "services", "memory_limit_mb", "services_limit"},
+	}
+
+	for _, row := range rows {
+		records = append(records, []string{
+			row.Org,
+			row.Space,
+			fmt.Sprintf("%d", row.Apps),
+			fmt.Sprintf("%d", row.Instances),
+			fmt.Sprintf("%d", row.Memory),
+			fmt.Sprintf("%d", row.Disk),
+			fmt.Sprintf("%d", row.Services),
+			fmt.Sprintf("%d", row.MemoryLimit),
+			fmt.Sprintf("%d", row.ServicesLimit),
+		})
+	}
+
+	buffer := new(bytes.Buffer)
+	writer := csv.NewWriter(buffer)
+	for _, record := range records {
+		writer.Write(record)
+		writer.Flush()
+		cmd.ui.Say("%s", strings.TrimSuffix(buffer.String(), "\n"))
+		buffer.Reset()
+	}
+}
@@ -0,0 +1,157 @@
+package organization_test
+
+import (
+	"cf"
+	. "cf/commands/organization"
+	"cf/configuration"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestUsageRequirements(t *testing.T) {
+	orgRepo, spaceRepo := usageRepos()
+
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedOrgSuccess: true}
+	callUsage([]string{}, reqFactory, orgRepo, spaceRepo)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: false, TargetedOrgSuccess: true}
+	callUsage([]string{}, reqFactory, orgRepo, spaceRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedOrgSuccess: false}
+	callUsage([]string{}, reqFactory, orgRepo, spaceRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedOrgSuccess: false}
+	callUsage([]string{"--org", "my-org"}, reqFactory, orgRepo, spaceRepo)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+}
+
+func TestUsageFailsWithUsage(t *testing.T) {
+	orgRepo, spaceRepo := usageRepos()
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedOrgSuccess: true}
+
+	ui := callUsage([]string{"my-org"}, reqFactory, orgRepo, spaceRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callUsage([]string{"--org", "my-org", "--all-orgs"}, reqFactory, orgRepo, spaceRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callUsage([]string{}, reqFactory, orgRepo, spaceRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestUsageForTargetedOrg(t *testing.T) {
+	orgRepo, spaceRepo := usageRepos()
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedOrgSuccess: true}
+
+	ui := callUsage([]string{}, reqFactory, orgRepo, spaceRepo)
+
+	assert.Equal(t, orgRepo.FindByNameName, "my-org")
+
+	assert.Contains(t, ui.Outputs[0], "Getting usage for org")
+	assert.Contains(t, ui.Outputs[0], "my-org")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Contains(t, ui.Outputs[2], "memory quota")
+
+	assert.Contains(t, ui.Outputs[3], "development")
+	assert.Contains(t, ui.Outputs[3], "768M")
+	assert.Contains(t, ui.Outputs[3], "1.5G")
+	assert.Contains(t, ui.Outputs[3], "37% of 2G")
+	assert.Contains(t, ui.Outputs[3], "50% of 2")
+
+	assert.Contains(t, ui.Outputs[4], "production")
+	assert.Contains(t, ui.Outputs[4], "1G")
+	assert.Contains(t, ui.Outputs[4], "50% of 2G")
+
+	assert.Contains(t, ui.Outputs[5], "(total)")
+	assert.Contains(t, ui.Outputs[5], "1.8G")
+	assert.Contains(t, ui.Outputs[5], "87% of 2G")
+	assert.Contains(t, ui.Outputs[5], "50% of 2")
+}
+
+func TestUsageAsCSV(t *testing.T) {
+	orgRepo, spaceRepo := usageRepos()
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, TargetedOrgSuccess: true}
+
+	ui := callUsage([]string{"--csv"}, reqFactory, orgRepo, spaceRepo)
+
+	assert.Equal(t, ui.Outputs, []string{
+		"org,space,apps,instances,memory_mb,disk_mb,services,memory_limit_mb,services_limit",
+		"my-org,development,3,3,768,1536,1,2048,2",
+		"my-org,production,1,2,1024,2048,0,2048,2",
+		"my-org,,4,5,1792,3584,1,2048,2",
+	})
+}
+
+func TestUsageForAllOrgs(t *testing.T) {
+	orgRepo, spaceRepo := usageRepos()
+	orgRepo.Organizations = []cf.Organization{cf.Organization{Name: "my-org"}}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callUsage([]string{"--all-orgs", "--csv"}, reqFactory, orgRepo, spaceRepo)
+
+	assert.Equal(t, ui.Outputs, []string{
+		"org,space,apps,instances,memory_mb,disk_mb,services,memory_limit_mb,services_limit",
+		"my-org,,4,5,1792,3584,1,2048,2",
+	})
+}
+
+func TestUsageWithStructuredOutput(t *testing.T) {
+	orgRepo, spaceRepo := usageRepos()
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := &testhelpers.FakeUI{StructuredOutput: true}
+	ctxt := testhelpers.NewContext("usage", []string{"--org", "my-org"})
+	cmd := NewUsage(ui, &configuration.Configuration{}, orgRepo, spaceRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+
+	assert.NotNil(t, ui.DisplayedData)
+}
+
+func usageRepos() (orgRepo *testhelpers.FakeOrgRepository, spaceRepo *testhelpers.FakeSpaceRepository) {
+	orgRepo = &testhelpers.FakeOrgRepository{
+		FindByNameOrganization: cf.Organization{
+			Name:  "my-org",
+			Guid:  "my-org-guid",
+			Quota: cf.Quota{Name: "small", MemoryLimit: 2048, ServicesLimit: 2},
+		},
+	}
+
+	spaceRepo = &testhelpers.FakeSpaceRepository{
+		SpacesByOrgGuid: map[string][]cf.Space{
+			"my-org-guid": []cf.Space{
+				cf.Space{Name: "development", Guid: "development-guid"},
+				cf.Space{Name: "production", Guid: "production-guid"},
+			},
+		},
+		SummariesByGuid: map[string]cf.Space{
+			"development-guid": cf.Space{
+				Applications: []cf.Application{
+					cf.Application{State: "started", Instances: 1, Memory: 256, DiskQuota: 512},
+					cf.Application{State: "started", Instances: 2, Memory: 256, DiskQuota: 512},
+					cf.Application{State: "stopped", Instances: 4, Memory: 1024, DiskQuota: 1024},
+				},
+				ServiceInstances: []cf.ServiceInstance{cf.ServiceInstance{Name: "my-db"}},
+			},
+			"production-guid": cf.Space{
+				Applications: []cf.Application{
+					cf.Application{State: "started", Instances: 2, Memory: 512, DiskQuota: 1024},
+				},
+			},
+		},
+	}
+	return
+}
+
+func callUsage(args []string, reqFactory *testhelpers.FakeReqFactory, orgRepo *testhelpers.FakeOrgRepository, spaceRepo *testhelpers.FakeSpaceRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("usage", args)
+	config := &configuration.Configuration{Organization: cf.Organization{Name: "my-org"}}
+	cmd := NewUsage(ui, config, orgRepo, spaceRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"cf"
+	"cf/configuration"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type ListProfiles struct {
+	ui          terminal.UI
+	profileRepo configuration.ProfileRepository
+}
+
+func NewListProfiles(ui terminal.UI, profileRepo configuration.ProfileRepository) (cmd ListProfiles) {
+	cmd.ui = ui
+	cmd.profileRepo = profileRepo
+	return
+}
+
+func (cmd ListProfiles) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	return
+}
+
+func (cmd ListProfiles) Run(c *cli.Context) {
+	cmd.ui.Say("Getting profiles...")
+
+	names, err := cmd.profileRepo.List()
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("")
+
+	active := cmd.profileRepo.Active()
+	table := [][]string{
+		[]string{"name", "active"},
+	}
+	for _, name := range names {
+		marker := ""
+		if name == active {
+			marker = "*"
+		}
+		table = append(table, []string{name, marker})
+	}
+
+	cmd.ui.DisplayTable(table, nil)
+}
+
+type CreateProfile struct {
+	ui          terminal.UI
+	profileRepo configuration.ProfileRepository
+}
+
+func NewCreateProfile(ui terminal.UI, profileRepo configuration.ProfileRepository) (cmd CreateProfile) {
+	cmd.ui = ui
+	cmd.profileRepo = profileRepo
+	return
+}
+
+func (cmd CreateProfile) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "create-profile")
+	}
+	return
+}
+
+func (cmd CreateProfile) Run(c *cli.Context) {
+	name := c.Args()[0]
+
+	cmd.ui.Say("Creating profile %s...", terminal.EntityNameColor(name))
+	err := cmd.profileRepo.Create(name)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("\nTIP: Use '%s' to make it the active profile", terminal.CommandColor(cf.Name+" switch-profile "+name))
+}
+
+type SwitchProfile struct {
+	ui          terminal.UI
+	profileRepo configuration.ProfileRepository
+}
+
+func NewSwitchProfile(ui terminal.UI, profileRepo configuration.ProfileRepository) (cmd SwitchProfile) {
+	cmd.ui = ui
+	cmd.profileRepo = profileRepo
+	return
+}
+
+func (cmd SwitchProfile) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "switch-profile")
+	}
+	return
+}
+
+func (cmd SwitchProfile) Run(c *cli.Context) {
+	name := c.Args()[0]
+
+	cmd.ui.Say("Switching to profile %s...", terminal.EntityNameColor(name))
+	err := cmd.profileRepo.Activate(name)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	cmd.ui.Ok()
+}
+
+type DeleteProfile struct {
+	ui          terminal.UI
+	profileRepo configuration.ProfileRepository
+}
+
+func NewDeleteProfile(ui terminal.UI, profileRepo configuration.ProfileRepository) (cmd DeleteProfile) {
+	cmd.ui = ui
+	cmd.profileRepo = profileRepo
+	return
+}
+
+func (cmd DeleteProfile) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "delete-profile")
+	}
+	return
+}
+
+func (cmd DeleteProfile) Run(c *cli.Context) {
+	name := c.Args()[0]
+
+	if !c.Bool("f") {
+		response := cmd.ui.Confirm(
+			"Really delete profile %s and the target and login saved in it?%s",
+			terminal.EntityNameColor(name),
+			terminal.PromptColor(">"),
+		)
+
+		if !response {
+			return
+		}
+	}
+
+	cmd.ui.Say("Deleting profile %s...", terminal.EntityNameColor(name))
+	err := cmd.profileRepo.Delete(name)
+	if err != nil {
+		cmd.ui.Failed(err.Error())
+		return
+	}
+
+	cmd.ui.Ok()
+}
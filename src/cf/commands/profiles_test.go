@@ -0,0 +1,120 @@
+package commands_test
+
+import (
+	. "cf/commands"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestListProfiles(t *testing.T) {
+	profileRepo := &testhelpers.FakeProfileRepository{
+		Profiles:      []string{"default", "production", "staging"},
+		ActiveProfile: "staging",
+	}
+
+	ui := callProfileCommand("profiles", []string{}, profileRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Getting profiles")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[4], "default")
+	assert.NotContains(t, ui.Outputs[4], "*")
+	assert.Contains(t, ui.Outputs[5], "production")
+	assert.NotContains(t, ui.Outputs[5], "*")
+	assert.Contains(t, ui.Outputs[6], "staging")
+	assert.Contains(t, ui.Outputs[6], "*")
+}
+
+func TestCreateProfile(t *testing.T) {
+	profileRepo := &testhelpers.FakeProfileRepository{}
+
+	ui := callProfileCommand("create-profile", []string{}, profileRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callProfileCommand("create-profile", []string{"staging"}, profileRepo)
+	assert.False(t, ui.FailedWithUsage)
+	assert.Contains(t, ui.Outputs[0], "Creating profile")
+	assert.Contains(t, ui.Outputs[0], "staging")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "switch-profile staging")
+	assert.Equal(t, profileRepo.CreatedName, "staging")
+}
+
+func TestCreateProfileWhenItFails(t *testing.T) {
+	profileRepo := &testhelpers.FakeProfileRepository{CreateErr: errors.New("Profile staging already exists")}
+
+	ui := callProfileCommand("create-profile", []string{"staging"}, profileRepo)
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "already exists")
+}
+
+func TestSwitchProfile(t *testing.T) {
+	profileRepo := &testhelpers.FakeProfileRepository{}
+
+	ui := callProfileCommand("switch-profile", []string{}, profileRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callProfileCommand("switch-profile", []string{"staging"}, profileRepo)
+	assert.Contains(t, ui.Outputs[0], "Switching to profile")
+	assert.Contains(t, ui.Outputs[0], "staging")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Equal(t, profileRepo.ActivatedName, "staging")
+}
+
+func TestSwitchProfileWhenItDoesNotExist(t *testing.T) {
+	profileRepo := &testhelpers.FakeProfileRepository{ActivateErr: errors.New("Profile staging does not exist")}
+
+	ui := callProfileCommand("switch-profile", []string{"staging"}, profileRepo)
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "does not exist")
+}
+
+func TestDeleteProfileConfirms(t *testing.T) {
+	profileRepo := &testhelpers.FakeProfileRepository{}
+
+	ui := callProfileCommand("delete-profile", []string{}, profileRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = &testhelpers.FakeUI{Inputs: []string{"n"}}
+	runProfileCommand(ui, "delete-profile", []string{"staging"}, profileRepo)
+	assert.Contains(t, ui.Prompts[0], "Really delete profile")
+	assert.Equal(t, profileRepo.DeletedName, "")
+
+	ui = &testhelpers.FakeUI{Inputs: []string{"y"}}
+	runProfileCommand(ui, "delete-profile", []string{"staging"}, profileRepo)
+	assert.Contains(t, ui.Outputs[0], "Deleting profile")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Equal(t, profileRepo.DeletedName, "staging")
+}
+
+func TestDeleteProfileWithForce(t *testing.T) {
+	profileRepo := &testhelpers.FakeProfileRepository{}
+
+	ui := callProfileCommand("delete-profile", []string{"-f", "staging"}, profileRepo)
+	assert.Equal(t, len(ui.Prompts), 0)
+	assert.Equal(t, profileRepo.DeletedName, "staging")
+}
+
+func callProfileCommand(cmdName string, args []string, profileRepo *testhelpers.FakeProfileRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	runProfileCommand(ui, cmdName, args, profileRepo)
+	return
+}
+
+func runProfileCommand(ui *testhelpers.FakeUI, cmdName string, args []string, profileRepo *testhelpers.FakeProfileRepository) {
+	var cmd Command
+	switch cmdName {
+	case "profiles":
+		cmd = NewListProfiles(ui, profileRepo)
+	case "create-profile":
+		cmd = NewCreateProfile(ui, profileRepo)
+	case "switch-profile":
+		cmd = NewSwitchProfile(ui, profileRepo)
+	case "delete-profile":
+		cmd = NewDeleteProfile(ui, profileRepo)
+	}
+
+	ctxt := testhelpers.NewContext(cmdName, args)
+	testhelpers.RunCommand(cmd, ctxt, &testhelpers.FakeReqFactory{})
+}
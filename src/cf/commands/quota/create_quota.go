@@ -0,0 +1,57 @@
+package quota
+
+import (
+	"cf"
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type CreateQuota struct {
+	ui        terminal.UI
+	quotaRepo api.QuotaRepository
+}
+
+func NewCreateQuota(ui terminal.UI, quotaRepo api.QuotaRepository) (cmd CreateQuota) {
+	cmd.ui = ui
+	cmd.quotaRepo = quotaRepo
+	return
+}
+
+func (cmd CreateQuota) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "create-quota")
+		return
+	}
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+	return
+}
+
+func (cmd CreateQuota) Run(c *cli.Context) {
+	quota := cf.Quota{Name: c.Args()[0]}
+	if !applyQuotaFlags(cmd.ui, c, &quota) {
+		return
+	}
+
+	cmd.ui.Say("Creating quota %s...", terminal.EntityNameColor(quota.Name))
+
+	apiResponse := cmd.quotaRepo.Create(quota)
+	if apiResponse.IsNotSuccessful() {
+		if apiResponse.ErrorCode == api.QUOTA_EXISTS {
+			cmd.ui.Ok()
+			cmd.ui.Warn("Quota %s already exists", quota.Name)
+			return
+		}
+
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+}
@@ -0,0 +1,70 @@
+package quota_test
+
+import (
+	"cf"
+	. "cf/commands/quota"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestCreateQuotaFailsWithUsage(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callCreateQuota([]string{}, reqFactory, quotaRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callCreateQuota([]string{"my-quota"}, reqFactory, quotaRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestCreateQuota(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	args := []string{"-m", "2G", "-s", "5", "-r", "10", "--allow-paid-service-plans", "my-quota"}
+	ui := callCreateQuota(args, reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Creating quota")
+	assert.Contains(t, ui.Outputs[0], "my-quota")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, quotaRepo.CreateQuota, cf.Quota{
+		Name:                    "my-quota",
+		MemoryLimit:             2048,
+		ServicesLimit:           5,
+		RoutesLimit:             10,
+		NonBasicServicesAllowed: true,
+	})
+}
+
+func TestCreateQuotaWithInvalidMemory(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callCreateQuota([]string{"-m", "lots", "my-quota"}, reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "Invalid memory limit")
+	assert.Equal(t, quotaRepo.CreateQuota, cf.Quota{})
+}
+
+func TestCreateQuotaWhenItAlreadyExists(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{CreateExists: true}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callCreateQuota([]string{"my-quota"}, reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "my-quota")
+	assert.Contains(t, ui.Outputs[2], "already exists")
+}
+
+func callCreateQuota(args []string, reqFactory *testhelpers.FakeReqFactory, quotaRepo *testhelpers.FakeQuotaRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("create-quota", args)
+	cmd := NewCreateQuota(ui, quotaRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
@@ -0,0 +1,71 @@
+package quota
+
+import (
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type DeleteQuota struct {
+	ui        terminal.UI
+	quotaRepo api.QuotaRepository
+}
+
+func NewDeleteQuota(ui terminal.UI, quotaRepo api.QuotaRepository) (cmd DeleteQuota) {
+	cmd.ui = ui
+	cmd.quotaRepo = quotaRepo
+	return
+}
+
+func (cmd DeleteQuota) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "delete-quota")
+		return
+	}
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+	return
+}
+
+func (cmd DeleteQuota) Run(c *cli.Context) {
+	name := c.Args()[0]
+
+	if !c.Bool("f") {
+		response := cmd.ui.Confirm(
+			"Really delete quota %s?%s",
+			terminal.EntityNameColor(name),
+			terminal.PromptColor(">"),
+		)
+
+		if !response {
+			return
+		}
+	}
+
+	cmd.ui.Say("Deleting quota %s...", terminal.EntityNameColor(name))
+
+	quota, apiResponse := cmd.quotaRepo.FindByName(name)
+	if apiResponse.IsError() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	if apiResponse.IsNotFound() {
+		cmd.ui.Ok()
+		cmd.ui.Warn("Quota %s does not exist.", name)
+		return
+	}
+
+	apiResponse = cmd.quotaRepo.Delete(quota)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+}
@@ -0,0 +1,69 @@
+package quota_test
+
+import (
+	"cf"
+	. "cf/commands/quota"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestDeleteQuotaWithConfirmation(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{
+		FindByNameQuota: cf.Quota{Name: "my-quota", Guid: "my-quota-guid"},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteQuota([]string{"my-quota"}, []string{"y"}, reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Prompts[0], "Really delete")
+	assert.Contains(t, ui.Prompts[0], "my-quota")
+
+	assert.Contains(t, ui.Outputs[0], "Deleting quota")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Equal(t, quotaRepo.DeletedQuota.Guid, "my-quota-guid")
+}
+
+func TestDeleteQuotaWhenNotConfirmed(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{
+		FindByNameQuota: cf.Quota{Name: "my-quota", Guid: "my-quota-guid"},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteQuota([]string{"my-quota"}, []string{"n"}, reqFactory, quotaRepo)
+
+	assert.Equal(t, len(ui.Outputs), 0)
+	assert.Equal(t, quotaRepo.DeletedQuota, cf.Quota{})
+}
+
+func TestDeleteQuotaWithForceOption(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{
+		FindByNameQuota: cf.Quota{Name: "my-quota", Guid: "my-quota-guid"},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteQuota([]string{"-f", "my-quota"}, []string{}, reqFactory, quotaRepo)
+
+	assert.Equal(t, len(ui.Prompts), 0)
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Equal(t, quotaRepo.DeletedQuota.Guid, "my-quota-guid")
+}
+
+func TestDeleteQuotaWhenItDoesNotExist(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{FindByNameNotFound: true}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteQuota([]string{"-f", "my-quota"}, []string{}, reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "does not exist")
+	assert.Equal(t, quotaRepo.DeletedQuota, cf.Quota{})
+}
+
+func callDeleteQuota(args []string, inputs []string, reqFactory *testhelpers.FakeReqFactory, quotaRepo *testhelpers.FakeQuotaRepository) (ui *testhelpers.FakeUI) {
+	ui = &testhelpers.FakeUI{Inputs: inputs}
+	ctxt := testhelpers.NewContext("delete-quota", args)
+	cmd := NewDeleteQuota(ui, quotaRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
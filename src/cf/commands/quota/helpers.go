@@ -0,0 +1,57 @@
+package quota
+
+import (
+	"cf"
+	"cf/formatters"
+	"cf/terminal"
+	"github.com/codegangsta/cli"
+	"strconv"
+)
+
+func memoryLimit(quota cf.Quota) string {
+	return formatters.ByteSize(quota.MemoryLimit * formatters.MEGABYTE)
+}
+
+func paidServicePlans(quota cf.Quota) string {
+	if quota.NonBasicServicesAllowed {
+		return "allowed"
+	}
+	return "disallowed"
+}
+
+// applyQuotaFlags copies the limits given on the command line into quota,
+// leaving anything the user did not mention as it was.
+func applyQuotaFlags(ui terminal.UI, c *cli.Context, quota *cf.Quota) (ok bool) {
+	if c.String("m") != "" {
+		memory, err := formatters.ToMegabytes(c.String("m"))
+		if err != nil {
+			ui.Failed("Invalid memory limit: %s\n%s", c.String("m"), err)
+			return
+		}
+		quota.MemoryLimit = memory
+	}
+
+	if c.String("s") != "" {
+		services, err := strconv.Atoi(c.String("s"))
+		if err != nil {
+			ui.Failed("Invalid services limit: %s", c.String("s"))
+			return
+		}
+		quota.ServicesLimit = services
+	}
+
+	if c.String("r") != "" {
+		routes, err := strconv.Atoi(c.String("r"))
+		if err != nil {
+			ui.Failed("Invalid routes limit: %s", c.String("r"))
+			return
+		}
+		quota.RoutesLimit = routes
+	}
+
+	if c.Bool("allow-paid-service-plans") {
+		quota.NonBasicServicesAllowed = true
+	}
+
+	return true
+}
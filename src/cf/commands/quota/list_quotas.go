@@ -0,0 +1,81 @@
+package quota
+
+import (
+	"cf"
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"fmt"
+	"github.com/codegangsta/cli"
+)
+
+type ListQuotas struct {
+	ui        terminal.UI
+	quotaRepo api.QuotaRepository
+}
+
+func NewListQuotas(ui terminal.UI, quotaRepo api.QuotaRepository) (cmd *ListQuotas) {
+	cmd = new(ListQuotas)
+	cmd.ui = ui
+	cmd.quotaRepo = quotaRepo
+	return
+}
+
+func (cmd *ListQuotas) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+	return
+}
+
+func (cmd *ListQuotas) Run(c *cli.Context) {
+	cmd.ui.Say("Getting quotas...")
+
+	if cmd.ui.IsStructuredOutput() {
+		cmd.displayQuotaData()
+		return
+	}
+
+	firstPage := true
+	table := terminal.NewPagedTable(cmd.ui, []string{"name", "memory limit", "services", "routes", "paid service plans"})
+	apiResponse := cmd.quotaRepo.ListQuotas(func(quotas []cf.Quota) bool {
+		if firstPage {
+			cmd.ui.Ok()
+			firstPage = false
+		}
+
+		rows := [][]string{}
+		for _, quota := range quotas {
+			rows = append(rows, []string{
+				quota.Name,
+				memoryLimit(quota),
+				fmt.Sprintf("%d", quota.ServicesLimit),
+				fmt.Sprintf("%d", quota.RoutesLimit),
+				paidServicePlans(quota),
+			})
+		}
+
+		table.Print(rows)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+	}
+}
+
+func (cmd *ListQuotas) displayQuotaData() {
+	quotas := []cf.Quota{}
+	apiResponse := cmd.quotaRepo.ListQuotas(func(page []cf.Quota) bool {
+		quotas = append(quotas, page...)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.DisplayData(quotas)
+}
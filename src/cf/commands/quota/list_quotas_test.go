@@ -0,0 +1,70 @@
+package quota_test
+
+import (
+	"cf"
+	. "cf/commands/quota"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestListQuotasRequirements(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{}
+
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	callListQuotas(reqFactory, quotaRepo)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: false}
+	callListQuotas(reqFactory, quotaRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+}
+
+func TestListQuotas(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{
+		Quotas: []cf.Quota{
+			cf.Quota{Name: "quota-1", MemoryLimit: 1024, ServicesLimit: 5, RoutesLimit: 10},
+			cf.Quota{Name: "quota-2", MemoryLimit: 512, ServicesLimit: 2, RoutesLimit: 4, NonBasicServicesAllowed: true},
+		},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callListQuotas(reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Getting quotas")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Contains(t, ui.Outputs[2], "name")
+	assert.Contains(t, ui.Outputs[2], "memory limit")
+	assert.Contains(t, ui.Outputs[2], "paid service plans")
+
+	assert.Contains(t, ui.Outputs[3], "quota-1")
+	assert.Contains(t, ui.Outputs[3], "1G")
+	assert.Contains(t, ui.Outputs[3], "5")
+	assert.Contains(t, ui.Outputs[3], "10")
+	assert.Contains(t, ui.Outputs[3], "disallowed")
+
+	assert.Contains(t, ui.Outputs[4], "quota-2")
+	assert.Contains(t, ui.Outputs[4], "512M")
+	assert.Contains(t, ui.Outputs[4], "allowed")
+}
+
+func TestListQuotasWithStructuredOutput(t *testing.T) {
+	quotas := []cf.Quota{cf.Quota{Name: "quota-1", MemoryLimit: 1024}}
+	quotaRepo := &testhelpers.FakeQuotaRepository{Quotas: quotas}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := &testhelpers.FakeUI{StructuredOutput: true}
+	ctxt := testhelpers.NewContext("quotas", []string{})
+	testhelpers.RunCommand(NewListQuotas(ui, quotaRepo), ctxt, reqFactory)
+
+	assert.Equal(t, ui.DisplayedData, quotas)
+}
+
+func callListQuotas(reqFactory *testhelpers.FakeReqFactory, quotaRepo *testhelpers.FakeQuotaRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("quotas", []string{})
+	cmd := NewListQuotas(ui, quotaRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
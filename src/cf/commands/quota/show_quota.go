@@ -0,0 +1,60 @@
+package quota
+
+import (
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"fmt"
+	"github.com/codegangsta/cli"
+)
+
+type ShowQuota struct {
+	ui        terminal.UI
+	quotaRepo api.QuotaRepository
+}
+
+func NewShowQuota(ui terminal.UI, quotaRepo api.QuotaRepository) (cmd *ShowQuota) {
+	cmd = new(ShowQuota)
+	cmd.ui = ui
+	cmd.quotaRepo = quotaRepo
+	return
+}
+
+func (cmd *ShowQuota) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "quota")
+		return
+	}
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+	return
+}
+
+func (cmd *ShowQuota) Run(c *cli.Context) {
+	name := c.Args()[0]
+
+	cmd.ui.Say("Getting info for quota %s...", terminal.EntityNameColor(name))
+
+	quota, apiResponse := cmd.quotaRepo.FindByName(name)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(quota)
+		return
+	}
+
+	cmd.ui.Say("%s:", terminal.EntityNameColor(quota.Name))
+	cmd.ui.Say("  memory limit: %s", terminal.EntityNameColor(memoryLimit(quota)))
+	cmd.ui.Say("  services: %s", terminal.EntityNameColor(fmt.Sprintf("%d", quota.ServicesLimit)))
+	cmd.ui.Say("  routes: %s", terminal.EntityNameColor(fmt.Sprintf("%d", quota.RoutesLimit)))
+	cmd.ui.Say("  paid service plans: %s", terminal.EntityNameColor(paidServicePlans(quota)))
+}
@@ -0,0 +1,63 @@
+package quota_test
+
+import (
+	"cf"
+	. "cf/commands/quota"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestShowQuotaFailsWithUsage(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callShowQuota([]string{}, reqFactory, quotaRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callShowQuota([]string{"my-quota"}, reqFactory, quotaRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestShowQuota(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{
+		FindByNameQuota: cf.Quota{Name: "my-quota", MemoryLimit: 2048, ServicesLimit: 5, RoutesLimit: 10, NonBasicServicesAllowed: true},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callShowQuota([]string{"my-quota"}, reqFactory, quotaRepo)
+
+	assert.Equal(t, quotaRepo.FindByNameName, "my-quota")
+
+	assert.Contains(t, ui.Outputs[0], "Getting info for quota")
+	assert.Contains(t, ui.Outputs[0], "my-quota")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "my-quota")
+	assert.Contains(t, ui.Outputs[3], "memory limit:")
+	assert.Contains(t, ui.Outputs[3], "2G")
+	assert.Contains(t, ui.Outputs[4], "services:")
+	assert.Contains(t, ui.Outputs[4], "5")
+	assert.Contains(t, ui.Outputs[5], "routes:")
+	assert.Contains(t, ui.Outputs[5], "10")
+	assert.Contains(t, ui.Outputs[6], "paid service plans:")
+	assert.Contains(t, ui.Outputs[6], "allowed")
+}
+
+func TestShowQuotaWhenNotFound(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{FindByNameNotFound: true}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callShowQuota([]string{"my-quota"}, reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "my-quota")
+	assert.Contains(t, ui.Outputs[2], "not found")
+}
+
+func callShowQuota(args []string, reqFactory *testhelpers.FakeReqFactory, quotaRepo *testhelpers.FakeQuotaRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("quota", args)
+	cmd := NewShowQuota(ui, quotaRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
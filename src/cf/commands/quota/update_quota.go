@@ -0,0 +1,71 @@
+package quota
+
+import (
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type UpdateQuota struct {
+	ui        terminal.UI
+	quotaRepo api.QuotaRepository
+}
+
+func NewUpdateQuota(ui terminal.UI, quotaRepo api.QuotaRepository) (cmd UpdateQuota) {
+	cmd.ui = ui
+	cmd.quotaRepo = quotaRepo
+	return
+}
+
+func (cmd UpdateQuota) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "update-quota")
+		return
+	}
+
+	if c.Bool("allow-paid-service-plans") && c.Bool("disallow-paid-service-plans") {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "update-quota")
+		return
+	}
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+	return
+}
+
+func (cmd UpdateQuota) Run(c *cli.Context) {
+	name := c.Args()[0]
+
+	cmd.ui.Say("Updating quota %s...", terminal.EntityNameColor(name))
+
+	quota, apiResponse := cmd.quotaRepo.FindByName(name)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	if !applyQuotaFlags(cmd.ui, c, &quota) {
+		return
+	}
+
+	if c.Bool("disallow-paid-service-plans") {
+		quota.NonBasicServicesAllowed = false
+	}
+
+	if c.String("n") != "" {
+		quota.Name = c.String("n")
+	}
+
+	apiResponse = cmd.quotaRepo.Update(quota)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+}
@@ -0,0 +1,72 @@
+package quota_test
+
+import (
+	"cf"
+	. "cf/commands/quota"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestUpdateQuotaFailsWithUsage(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callUpdateQuota([]string{}, reqFactory, quotaRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callUpdateQuota([]string{"--allow-paid-service-plans", "--disallow-paid-service-plans", "my-quota"}, reqFactory, quotaRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callUpdateQuota([]string{"my-quota"}, reqFactory, quotaRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestUpdateQuotaOnlyChangesGivenLimits(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{
+		FindByNameQuota: cf.Quota{
+			Name:                    "my-quota",
+			Guid:                    "my-quota-guid",
+			MemoryLimit:             1024,
+			ServicesLimit:           5,
+			RoutesLimit:             10,
+			NonBasicServicesAllowed: true,
+		},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callUpdateQuota([]string{"-n", "new-quota", "-s", "20", "--disallow-paid-service-plans", "my-quota"}, reqFactory, quotaRepo)
+
+	assert.Equal(t, quotaRepo.FindByNameName, "my-quota")
+
+	assert.Contains(t, ui.Outputs[0], "Updating quota")
+	assert.Contains(t, ui.Outputs[0], "my-quota")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, quotaRepo.UpdateQuota, cf.Quota{
+		Name:          "new-quota",
+		Guid:          "my-quota-guid",
+		MemoryLimit:   1024,
+		ServicesLimit: 20,
+		RoutesLimit:   10,
+	})
+}
+
+func TestUpdateQuotaWhenNotFound(t *testing.T) {
+	quotaRepo := &testhelpers.FakeQuotaRepository{FindByNameNotFound: true}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callUpdateQuota([]string{"-m", "1G", "my-quota"}, reqFactory, quotaRepo)
+
+	assert.Contains(t, ui.Outputs[1], "FAILED")
+	assert.Contains(t, ui.Outputs[2], "not found")
+	assert.Equal(t, quotaRepo.UpdateQuota, cf.Quota{})
+}
+
+func callUpdateQuota(args []string, reqFactory *testhelpers.FakeReqFactory, quotaRepo *testhelpers.FakeQuotaRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("update-quota", args)
+	cmd := NewUpdateQuota(ui, quotaRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
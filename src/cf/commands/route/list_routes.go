@@ -1,6 +1,7 @@
 package route
 
 import (
+	"cf"
 	"cf/api"
 	"cf/configuration"
 	"cf/requirements"
@@ -30,32 +31,61 @@ func (cmd ListRoutes) GetRequirements(reqFactory requirements.Factory, c *cli.Co
 func (cmd ListRoutes) Run(c *cli.Context) {
 	cmd.ui.Say("Getting routes in space %s...", terminal.EntityNameColor(cmd.config.Space.Name))
 
-	routes, apiResponse := cmd.routeRepo.FindAll()
-
-	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+	if cmd.ui.IsStructuredOutput() {
+		cmd.displayRouteData()
 		return
 	}
 
-	cmd.ui.Ok()
-	cmd.ui.Say("")
+	noRoutes := true
+	table := terminal.NewPagedTable(cmd.ui, []string{"host", "domain", "apps"})
+	apiResponse := cmd.routeRepo.ListRoutes(func(routes []cf.Route) bool {
+		if len(routes) == 0 {
+			return true
+		}
 
-	if len(routes) == 0 {
-		cmd.ui.Say("No routes found")
+		if noRoutes {
+			cmd.ui.Ok()
+			cmd.ui.Say("")
+			noRoutes = false
+		}
+
+		rows := [][]string{}
+		for _, route := range routes {
+			rows = append(rows, []string{
+				route.Host,
+				route.Domain.Name,
+				strings.Join(route.AppNames, ", "),
+			})
+		}
+
+		table.Print(rows)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return
 	}
 
-	table := [][]string{
-		{"host", "domain", "apps"},
+	if noRoutes {
+		cmd.ui.Ok()
+		cmd.ui.Say("")
+		cmd.ui.Say("No routes found")
 	}
+}
+
+func (cmd ListRoutes) displayRouteData() {
+	routes := []cf.Route{}
+	apiResponse := cmd.routeRepo.ListRoutes(func(page []cf.Route) bool {
+		routes = append(routes, page...)
+		return true
+	})
 
-	for _, route := range routes {
-		table = append(table, []string{
-			route.Host,
-			route.Domain.Name,
-			strings.Join(route.AppNames, ", "),
-		})
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
 	}
 
-	cmd.ui.DisplayTable(table, nil)
+	cmd.ui.Ok()
+	cmd.ui.DisplayData(routes)
 }
@@ -48,6 +48,29 @@ func TestListingRoutes(t *testing.T) {
 	assert.Contains(t, ui.Outputs[5], "my-app, my-app2")
 }
 
+func TestListingRoutesShowsEachPageAsItArrives(t *testing.T) {
+	routeRepo := &testhelpers.FakeRouteRepository{
+		ListRoutesPages: [][]cf.Route{
+			[]cf.Route{cf.Route{Host: "hostname-1", Domain: cf.Domain{Name: "example.com"}}},
+			[]cf.Route{},
+			[]cf.Route{cf.Route{Host: "hostname-2", Domain: cf.Domain{Name: "cfapps.com"}}},
+		},
+	}
+	config := &configuration.Configuration{
+		Space: cf.Space{Name: "my-space"},
+	}
+	ui := &testhelpers.FakeUI{}
+
+	cmd := NewListRoutes(ui, config, routeRepo)
+	cmd.Run(testhelpers.NewContext("routes", []string{}))
+
+	assert.Equal(t, len(ui.Outputs), 6)
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[3], "domain")
+	assert.Contains(t, ui.Outputs[4], "hostname-1")
+	assert.Contains(t, ui.Outputs[5], "hostname-2")
+}
+
 func TestListingRoutesWhenNoneExist(t *testing.T) {
 	routes := []cf.Route{}
 	routeRepo := &testhelpers.FakeRouteRepository{FindAllRoutes: routes}
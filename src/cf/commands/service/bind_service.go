@@ -46,14 +46,14 @@ func (cmd *BindService) Run(c *cli.Context) {
 	cmd.ui.Say("Binding service %s to %s...", terminal.EntityNameColor(instance.Name), terminal.EntityNameColor(app.Name))
 
 	apiResponse := cmd.serviceRepo.BindService(instance, app)
-	if apiResponse.IsNotSuccessful() && apiResponse.ErrorCode != "90003" {
+	if apiResponse.IsNotSuccessful() && apiResponse.ErrorCode != api.APP_ALREADY_BOUND_TO_SERVICE {
 		cmd.ui.Failed(apiResponse.Message)
 		return
 	}
 
 	cmd.ui.Ok()
 
-	if apiResponse.IsNotSuccessful() && apiResponse.ErrorCode == "90003" {
+	if apiResponse.IsNotSuccessful() && apiResponse.ErrorCode == api.APP_ALREADY_BOUND_TO_SERVICE {
 		cmd.ui.Warn("App %s is already bound to %s.", app.Name, instance.Name)
 	}
 }
@@ -29,12 +29,17 @@ func (cmd ListServices) Run(c *cli.Context) {
 	space, apiResponse := cmd.spaceRepo.GetSummary()
 
 	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return
 	}
 
 	cmd.ui.Ok()
 
+	if cmd.ui.IsStructuredOutput() {
+		cmd.ui.DisplayData(space.ServiceInstances)
+		return
+	}
+
 	table := [][]string{
 		[]string{"name", "service", "plan", "bound apps"},
 	}
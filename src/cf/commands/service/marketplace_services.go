@@ -1,6 +1,7 @@
 package service
 
 import (
+	"cf"
 	"cf/api"
 	"cf/requirements"
 	"cf/terminal"
@@ -26,32 +27,54 @@ func (cmd MarketplaceServices) GetRequirements(reqFactory requirements.Factory,
 func (cmd MarketplaceServices) Run(c *cli.Context) {
 	cmd.ui.Say("Getting services from marketplace...")
 
-	serviceOfferings, apiResponse := cmd.serviceRepo.GetServiceOfferings()
-
-	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+	if cmd.ui.IsStructuredOutput() {
+		cmd.displayServiceOfferingData()
 		return
 	}
 
-	cmd.ui.Ok()
+	firstPage := true
+	table := terminal.NewPagedTable(cmd.ui, []string{"service", "plans", "description"})
+	apiResponse := cmd.serviceRepo.ListServiceOfferings(func(serviceOfferings []cf.ServiceOffering) bool {
+		if firstPage {
+			cmd.ui.Ok()
+			firstPage = false
+		}
 
-	table := [][]string{
-		[]string{"service", "plans", "description"},
-	}
+		rows := [][]string{}
+		for _, offering := range serviceOfferings {
+			var planNames []string
+			for _, plan := range offering.Plans {
+				planNames = append(planNames, plan.Name)
+			}
 
-	for _, offering := range serviceOfferings {
-		var planNames []string
-		for _, plan := range offering.Plans {
-			planNames = append(planNames, plan.Name)
+			rows = append(rows, []string{
+				offering.Label,
+				strings.Join(planNames, ", "),
+				offering.Description,
+			})
 		}
 
-		table = append(table, []string{
-			offering.Label,
-			strings.Join(planNames, ", "),
-			offering.Description,
-		})
+		table.Print(rows)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 	}
+}
 
-	cmd.ui.DisplayTable(table, nil)
-	return
+func (cmd MarketplaceServices) displayServiceOfferingData() {
+	serviceOfferings := []cf.ServiceOffering{}
+	apiResponse := cmd.serviceRepo.ListServiceOfferings(func(page []cf.ServiceOffering) bool {
+		serviceOfferings = append(serviceOfferings, page...)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.DisplayData(serviceOfferings)
 }
@@ -1,6 +1,7 @@
 package space
 
 import (
+	"cf"
 	"cf/api"
 	"cf/configuration"
 	"cf/requirements"
@@ -32,15 +33,48 @@ func (cmd ListSpaces) GetRequirements(reqFactory requirements.Factory, c *cli.Co
 func (cmd ListSpaces) Run(c *cli.Context) {
 	cmd.ui.Say("Getting spaces in %s...", terminal.EntityNameColor(cmd.config.Organization.Name))
 
-	spaces, apiResponse := cmd.spaceRepo.FindAll()
+	if cmd.ui.IsStructuredOutput() {
+		cmd.displaySpaceData()
+		return
+	}
+
+	noSpaces := true
+	apiResponse := cmd.spaceRepo.ListSpaces(func(spaces []cf.Space) bool {
+		for _, space := range spaces {
+			if noSpaces {
+				cmd.ui.Ok()
+				noSpaces = false
+			}
+			cmd.ui.Say(space.Name)
+		}
+		return true
+	})
+
 	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return
 	}
 
-	cmd.ui.Ok()
+	if noSpaces {
+		cmd.ui.Ok()
+	}
+}
+
+func (cmd ListSpaces) displaySpaceData() {
+	spaces := []cf.Space{}
+	apiResponse := cmd.spaceRepo.ListSpaces(func(page []cf.Space) bool {
+		for _, space := range page {
+			space.Organization = cmd.config.Organization
+			spaces = append(spaces, space)
+		}
+		return true
+	})
 
-	for _, space := range spaces {
-		cmd.ui.Say(space.Name)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
 	}
+
+	cmd.ui.Ok()
+	cmd.ui.DisplayData(spaces)
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"cf"
 	"cf/api"
 	"cf/requirements"
 	"cf/terminal"
@@ -26,24 +27,48 @@ func (cmd *Stacks) GetRequirements(reqFactory requirements.Factory, c *cli.Conte
 func (cmd *Stacks) Run(c *cli.Context) {
 	cmd.ui.Say("Getting stacks...")
 
-	stacks, apiResponse := cmd.stacksRepo.FindAll()
-	if apiResponse.IsNotSuccessful() {
-		cmd.ui.Failed(apiResponse.Message)
+	if cmd.ui.IsStructuredOutput() {
+		cmd.displayStackData()
 		return
 	}
 
-	cmd.ui.Ok()
+	noStacks := true
+	table := terminal.NewPagedTable(cmd.ui, []string{"name", "description"})
+	apiResponse := cmd.stacksRepo.ListStacks(func(stacks []cf.Stack) bool {
+		if noStacks {
+			cmd.ui.Ok()
+			noStacks = false
+		}
+
+		rows := [][]string{}
+		for _, stack := range stacks {
+			rows = append(rows, []string{
+				stack.Name,
+				stack.Description,
+			})
+		}
 
-	table := [][]string{
-		[]string{"name", "description"},
+		table.Print(rows)
+		return true
+	})
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 	}
+}
+
+func (cmd *Stacks) displayStackData() {
+	stacks := []cf.Stack{}
+	apiResponse := cmd.stacksRepo.ListStacks(func(page []cf.Stack) bool {
+		stacks = append(stacks, page...)
+		return true
+	})
 
-	for _, stack := range stacks {
-		table = append(table, []string{
-			stack.Name,
-			stack.Description,
-		})
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
 	}
 
-	cmd.ui.DisplayTable(table, nil)
+	cmd.ui.Ok()
+	cmd.ui.DisplayData(stacks)
 }
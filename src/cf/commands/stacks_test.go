@@ -28,6 +28,19 @@ func TestStacks(t *testing.T) {
 	assert.Contains(t, ui.Outputs[4], "Stack 2 Description")
 }
 
+func TestStacksWithStructuredOutput(t *testing.T) {
+	stacks := []cf.Stack{
+		cf.Stack{Name: "Stack-1", Description: "Stack 1 Description"},
+	}
+	stackRepo := &testhelpers.FakeStackRepository{FindAllStacks: stacks}
+
+	ui := &testhelpers.FakeUI{StructuredOutput: true}
+	ctxt := testhelpers.NewContext("stacks", []string{})
+	testhelpers.RunCommand(NewStacks(ui, stackRepo), ctxt, nil)
+
+	assert.Equal(t, ui.DisplayedData, stacks)
+}
+
 func callStacks(stackRepo *testhelpers.FakeStackRepository) (ui *testhelpers.FakeUI) {
 	ui = &testhelpers.FakeUI{}
 
@@ -42,8 +42,11 @@ func (cmd Target) GetRequirements(reqFactory requirements.Factory, c *cli.Contex
 		return
 	}
 
-	reqs = []requirements.Requirement{
-		reqFactory.NewLoginRequirement(),
+	// Showing the target works logged out, so that it can say so
+	if c.String("o") != "" || c.String("s") != "" {
+		reqs = []requirements.Requirement{
+			reqFactory.NewLoginRequirement(),
+		}
 	}
 	return
 }
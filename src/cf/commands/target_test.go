@@ -22,13 +22,33 @@ func TestTargetRequirements(t *testing.T) {
 	orgRepo, spaceRepo, configRepo, reqFactory := getTargetDependencies()
 	reqFactory.LoginSuccess = false
 
-	callTarget([]string{}, reqFactory, configRepo, orgRepo, spaceRepo)
+	callTarget([]string{"-o", "my-org"}, reqFactory, configRepo, orgRepo, spaceRepo)
 	assert.False(t, testhelpers.CommandDidPassRequirements)
 
-	reqFactory.LoginSuccess = true
+	callTarget([]string{"-s", "my-space"}, reqFactory, configRepo, orgRepo, spaceRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
 
 	callTarget([]string{}, reqFactory, configRepo, orgRepo, spaceRepo)
 	assert.True(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory.LoginSuccess = true
+
+	callTarget([]string{"-o", "my-org"}, reqFactory, configRepo, orgRepo, spaceRepo)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+}
+
+func TestTargetWithoutArgumentAndLoggedOut(t *testing.T) {
+	orgRepo, spaceRepo, configRepo, reqFactory := getTargetDependencies()
+	reqFactory.LoginSuccess = false
+
+	configRepo.Delete()
+	config, _ := configRepo.Get()
+	config.Target = "https://api.run.pivotal.io"
+
+	ui := callTarget([]string{}, reqFactory, configRepo, orgRepo, spaceRepo)
+
+	assert.Contains(t, ui.Outputs[0], "https://api.run.pivotal.io")
+	assert.Contains(t, ui.Outputs[1], "Logged out")
 }
 
 func TestTargetWithoutArgumentAndLoggedIn(t *testing.T) {
@@ -0,0 +1,58 @@
+package user
+
+import (
+	"cf"
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type CreateUser struct {
+	ui       terminal.UI
+	userRepo api.UserRepository
+}
+
+func NewCreateUser(ui terminal.UI, userRepo api.UserRepository) (cmd CreateUser) {
+	cmd.ui = ui
+	cmd.userRepo = userRepo
+	return
+}
+
+func (cmd CreateUser) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 2 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "create-user")
+		return
+	}
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+	return
+}
+
+func (cmd CreateUser) Run(c *cli.Context) {
+	username := c.Args()[0]
+	password := c.Args()[1]
+
+	cmd.ui.Say("Creating user %s...", terminal.EntityNameColor(username))
+
+	apiResponse := cmd.userRepo.Create(username, password)
+	if apiResponse.IsNotSuccessful() {
+		if apiResponse.ErrorCode == api.USER_EXISTS {
+			cmd.ui.Ok()
+			cmd.ui.Warn("User %s already exists", username)
+			return
+		}
+
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+	cmd.ui.Say("\nTIP: Assign roles with '%s' and '%s'",
+		terminal.CommandColor(cf.Name+" set-org-role"),
+		terminal.CommandColor(cf.Name+" set-space-role"))
+}
@@ -0,0 +1,69 @@
+package user_test
+
+import (
+	. "cf/commands/user"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestCreateUserRequirements(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	callCreateUser([]string{"my-user", "my-password"}, reqFactory, userRepo)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: false}
+	callCreateUser([]string{"my-user", "my-password"}, reqFactory, userRepo)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+}
+
+func TestCreateUserFailsWithUsage(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callCreateUser([]string{}, reqFactory, userRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callCreateUser([]string{"my-user"}, reqFactory, userRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callCreateUser([]string{"my-user", "my-password"}, reqFactory, userRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestCreateUser(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callCreateUser([]string{"my-user", "my-password"}, reqFactory, userRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Creating user")
+	assert.Contains(t, ui.Outputs[0], "my-user")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "TIP")
+
+	assert.Equal(t, userRepo.CreateUserUsername, "my-user")
+	assert.Equal(t, userRepo.CreateUserPassword, "my-password")
+}
+
+func TestCreateUserWhenItAlreadyExists(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{CreateUserExists: true}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callCreateUser([]string{"my-user", "my-password"}, reqFactory, userRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Creating user")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "my-user")
+	assert.Contains(t, ui.Outputs[2], "already exists")
+}
+
+func callCreateUser(args []string, reqFactory *testhelpers.FakeReqFactory, userRepo *testhelpers.FakeUserRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("create-user", args)
+	cmd := NewCreateUser(ui, userRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type DeleteUser struct {
+	ui       terminal.UI
+	userRepo api.UserRepository
+}
+
+func NewDeleteUser(ui terminal.UI, userRepo api.UserRepository) (cmd DeleteUser) {
+	cmd.ui = ui
+	cmd.userRepo = userRepo
+	return
+}
+
+func (cmd DeleteUser) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "delete-user")
+		return
+	}
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+	}
+	return
+}
+
+func (cmd DeleteUser) Run(c *cli.Context) {
+	username := c.Args()[0]
+
+	if !c.Bool("f") {
+		response := cmd.ui.Confirm(
+			"Really delete user %s?%s",
+			terminal.EntityNameColor(username),
+			terminal.PromptColor(">"),
+		)
+
+		if !response {
+			return
+		}
+	}
+
+	cmd.ui.Say("Deleting user %s...", terminal.EntityNameColor(username))
+
+	user, apiResponse := cmd.userRepo.FindByUsername(username)
+	if apiResponse.IsError() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	if apiResponse.IsNotFound() {
+		cmd.ui.Ok()
+		cmd.ui.Warn("User %s does not exist.", username)
+		return
+	}
+
+	apiResponse = cmd.userRepo.Delete(user)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+}
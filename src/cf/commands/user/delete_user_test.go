@@ -0,0 +1,86 @@
+package user_test
+
+import (
+	"cf"
+	. "cf/commands/user"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestDeleteUserFailsWithUsage(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteUser([]string{}, []string{}, reqFactory, userRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callDeleteUser([]string{"my-user"}, []string{"y"}, reqFactory, userRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestDeleteUserWithConfirmation(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{
+		FindByUsernameUser: cf.User{Username: "my-user", Guid: "my-user-guid"},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteUser([]string{"my-user"}, []string{"y"}, reqFactory, userRepo)
+
+	assert.Contains(t, ui.Prompts[0], "Really delete")
+	assert.Contains(t, ui.Prompts[0], "my-user")
+
+	assert.Contains(t, ui.Outputs[0], "Deleting user")
+	assert.Contains(t, ui.Outputs[0], "my-user")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, userRepo.FindByUsernameUsername, "my-user")
+	assert.Equal(t, userRepo.DeletedUser.Guid, "my-user-guid")
+}
+
+func TestDeleteUserWhenNotConfirmed(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{
+		FindByUsernameUser: cf.User{Username: "my-user", Guid: "my-user-guid"},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteUser([]string{"my-user"}, []string{"n"}, reqFactory, userRepo)
+
+	assert.Equal(t, len(ui.Outputs), 0)
+	assert.Equal(t, userRepo.DeletedUser, cf.User{})
+}
+
+func TestDeleteUserWithForceOption(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{
+		FindByUsernameUser: cf.User{Username: "my-user", Guid: "my-user-guid"},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteUser([]string{"-f", "my-user"}, []string{}, reqFactory, userRepo)
+
+	assert.Equal(t, len(ui.Prompts), 0)
+	assert.Contains(t, ui.Outputs[0], "Deleting user")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Equal(t, userRepo.DeletedUser.Guid, "my-user-guid")
+}
+
+func TestDeleteUserWhenUserDoesNotExist(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{FindByUsernameNotFound: true}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+
+	ui := callDeleteUser([]string{"-f", "my-user"}, []string{}, reqFactory, userRepo)
+
+	assert.Contains(t, ui.Outputs[0], "Deleting user")
+	assert.Contains(t, ui.Outputs[1], "OK")
+	assert.Contains(t, ui.Outputs[2], "my-user")
+	assert.Contains(t, ui.Outputs[2], "does not exist")
+	assert.Equal(t, userRepo.DeletedUser, cf.User{})
+}
+
+func callDeleteUser(args []string, inputs []string, reqFactory *testhelpers.FakeReqFactory, userRepo *testhelpers.FakeUserRepository) (ui *testhelpers.FakeUI) {
+	ui = &testhelpers.FakeUI{Inputs: inputs}
+	ctxt := testhelpers.NewContext("delete-user", args)
+	cmd := NewDeleteUser(ui, userRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"cf"
+	"cf/api"
+	"cf/net"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type OrgRoleSetter struct {
+	ui       terminal.UI
+	userRepo api.UserRepository
+	userReq  requirements.UserRequirement
+	orgReq   requirements.OrganizationRequirement
+	set      bool
+}
+
+func NewOrgRoleSetter(ui terminal.UI, userRepo api.UserRepository, set bool) (cmd *OrgRoleSetter) {
+	cmd = &OrgRoleSetter{
+		ui:       ui,
+		userRepo: userRepo,
+		set:      set,
+	}
+	return
+}
+
+func (cmd *OrgRoleSetter) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 3 {
+		err = errors.New("Incorrect Usage")
+		if cmd.set {
+			cmd.ui.FailWithUsage(c, "set-org-role")
+		} else {
+			cmd.ui.FailWithUsage(c, "unset-org-role")
+		}
+		return
+	}
+
+	cmd.userReq = reqFactory.NewUserRequirement(c.Args()[0])
+	cmd.orgReq = reqFactory.NewOrganizationRequirement(c.Args()[1])
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+		cmd.userReq,
+		cmd.orgReq,
+	}
+	return
+}
+
+func (cmd *OrgRoleSetter) Run(c *cli.Context) {
+	user := cmd.userReq.GetUser()
+	org := cmd.orgReq.GetOrganization()
+
+	role, found := findRole(c.Args()[2], cf.OrgRoles)
+	if !found {
+		cmd.ui.Failed(invalidRoleMessage(c.Args()[2], cf.OrgRoles))
+		return
+	}
+
+	var apiResponse net.ApiResponse
+	if cmd.set {
+		cmd.ui.Say("Assigning role %s to user %s in org %s...",
+			terminal.EntityNameColor(role),
+			terminal.EntityNameColor(user.Username),
+			terminal.EntityNameColor(org.Name))
+		apiResponse = cmd.userRepo.SetOrgRole(user, org, role)
+	} else {
+		cmd.ui.Say("Removing role %s from user %s in org %s...",
+			terminal.EntityNameColor(role),
+			terminal.EntityNameColor(user.Username),
+			terminal.EntityNameColor(org.Name))
+		apiResponse = cmd.userRepo.UnsetOrgRole(user, org, role)
+	}
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+}
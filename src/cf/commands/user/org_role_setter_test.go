@@ -0,0 +1,99 @@
+package user_test
+
+import (
+	"cf"
+	. "cf/commands/user"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestSetOrgRoleFailsWithUsage(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callOrgRoleSetter("set-org-role", []string{"my-user", "my-org"}, reqFactory, userRepo, true)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callOrgRoleSetter("set-org-role", []string{"my-user", "my-org", "OrgManager"}, reqFactory, userRepo, true)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestSetOrgRoleRequirements(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{}
+	args := []string{"my-user", "my-org", "OrgManager"}
+
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: false}
+	callOrgRoleSetter("set-org-role", args, reqFactory, userRepo, true)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true, UserNotFound: true}
+	callOrgRoleSetter("set-org-role", args, reqFactory, userRepo, true)
+	assert.False(t, testhelpers.CommandDidPassRequirements)
+
+	reqFactory = &testhelpers.FakeReqFactory{LoginSuccess: true}
+	callOrgRoleSetter("set-org-role", args, reqFactory, userRepo, true)
+	assert.True(t, testhelpers.CommandDidPassRequirements)
+	assert.Equal(t, reqFactory.Username, "my-user")
+	assert.Equal(t, reqFactory.OrganizationName, "my-org")
+}
+
+func TestSetOrgRole(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{
+		LoginSuccess: true,
+		User:         cf.User{Username: "my-user", Guid: "my-user-guid"},
+		Organization: cf.Organization{Name: "my-org", Guid: "my-org-guid"},
+	}
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callOrgRoleSetter("set-org-role", []string{"my-user", "my-org", "orgmanager"}, reqFactory, userRepo, true)
+
+	assert.Contains(t, ui.Outputs[0], "Assigning role")
+	assert.Contains(t, ui.Outputs[0], "OrgManager")
+	assert.Contains(t, ui.Outputs[0], "my-user")
+	assert.Contains(t, ui.Outputs[0], "my-org")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, userRepo.SetOrgRoleUser.Guid, "my-user-guid")
+	assert.Equal(t, userRepo.SetOrgRoleOrganization.Guid, "my-org-guid")
+	assert.Equal(t, userRepo.SetOrgRoleRole, cf.ORG_MANAGER)
+}
+
+func TestUnsetOrgRole(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{
+		LoginSuccess: true,
+		User:         cf.User{Username: "my-user", Guid: "my-user-guid"},
+		Organization: cf.Organization{Name: "my-org", Guid: "my-org-guid"},
+	}
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callOrgRoleSetter("unset-org-role", []string{"my-user", "my-org", "BillingManager"}, reqFactory, userRepo, false)
+
+	assert.Contains(t, ui.Outputs[0], "Removing role")
+	assert.Contains(t, ui.Outputs[0], "BillingManager")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, userRepo.UnsetOrgRoleUser.Guid, "my-user-guid")
+	assert.Equal(t, userRepo.UnsetOrgRoleOrganization.Guid, "my-org-guid")
+	assert.Equal(t, userRepo.UnsetOrgRoleRole, cf.BILLING_MANAGER)
+}
+
+func TestSetOrgRoleWithInvalidRole(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callOrgRoleSetter("set-org-role", []string{"my-user", "my-org", "SpaceDeveloper"}, reqFactory, userRepo, true)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "Invalid role SpaceDeveloper")
+	assert.Contains(t, ui.Outputs[1], "OrgManager")
+	assert.Equal(t, userRepo.SetOrgRoleRole, "")
+}
+
+func callOrgRoleSetter(cmdName string, args []string, reqFactory *testhelpers.FakeReqFactory, userRepo *testhelpers.FakeUserRepository, set bool) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext(cmdName, args)
+	cmd := NewOrgRoleSetter(ui, userRepo, set)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
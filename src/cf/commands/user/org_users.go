@@ -0,0 +1,54 @@
+package user
+
+import (
+	"cf"
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type OrgUsers struct {
+	ui       terminal.UI
+	userRepo api.UserRepository
+	orgReq   requirements.OrganizationRequirement
+}
+
+func NewOrgUsers(ui terminal.UI, userRepo api.UserRepository) (cmd *OrgUsers) {
+	cmd = new(OrgUsers)
+	cmd.ui = ui
+	cmd.userRepo = userRepo
+	return
+}
+
+func (cmd *OrgUsers) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 1 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "org-users")
+		return
+	}
+
+	cmd.orgReq = reqFactory.NewOrganizationRequirement(c.Args()[0])
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+		cmd.orgReq,
+	}
+	return
+}
+
+func (cmd *OrgUsers) Run(c *cli.Context) {
+	org := cmd.orgReq.GetOrganization()
+
+	cmd.ui.Say("Getting users in org %s...", terminal.EntityNameColor(org.Name))
+
+	usersByRole, apiResponse := cmd.userRepo.FindAllInOrgByRole(org)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+	displayUsersByRole(cmd.ui, cf.OrgRoles, usersByRole)
+}
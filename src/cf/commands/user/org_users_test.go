@@ -0,0 +1,70 @@
+package user_test
+
+import (
+	"cf"
+	. "cf/commands/user"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestOrgUsersFailsWithUsage(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callOrgUsers([]string{}, reqFactory, userRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callOrgUsers([]string{"my-org"}, reqFactory, userRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestOrgUsers(t *testing.T) {
+	org := cf.Organization{Name: "my-org", Guid: "my-org-guid"}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true, Organization: org}
+	userRepo := &testhelpers.FakeUserRepository{
+		FindAllInOrgByRoleUsersByRole: map[string][]cf.User{
+			cf.ORG_MANAGER: []cf.User{{Username: "user1"}, {Username: "user2"}},
+			cf.ORG_AUDITOR: []cf.User{{Username: "user3"}},
+		},
+	}
+
+	ui := callOrgUsers([]string{"my-org"}, reqFactory, userRepo)
+
+	assert.Equal(t, reqFactory.OrganizationName, "my-org")
+	assert.Equal(t, userRepo.FindAllInOrgByRoleOrganization, org)
+
+	assert.Contains(t, ui.Outputs[0], "Getting users in org")
+	assert.Contains(t, ui.Outputs[0], "my-org")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Contains(t, ui.Outputs[3], "OrgManager")
+	assert.Contains(t, ui.Outputs[4], "user1")
+	assert.Contains(t, ui.Outputs[5], "user2")
+	assert.Contains(t, ui.Outputs[7], "BillingManager")
+	assert.Contains(t, ui.Outputs[8], "No users found")
+	assert.Contains(t, ui.Outputs[10], "OrgAuditor")
+	assert.Contains(t, ui.Outputs[11], "user3")
+}
+
+func TestOrgUsersWithStructuredOutput(t *testing.T) {
+	usersByRole := map[string][]cf.User{
+		cf.ORG_MANAGER: []cf.User{{Username: "user1", Guid: "user-1-guid"}},
+	}
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	userRepo := &testhelpers.FakeUserRepository{FindAllInOrgByRoleUsersByRole: usersByRole}
+
+	ui := &testhelpers.FakeUI{StructuredOutput: true}
+	ctxt := testhelpers.NewContext("org-users", []string{"my-org"})
+	testhelpers.RunCommand(NewOrgUsers(ui, userRepo), ctxt, reqFactory)
+
+	assert.Equal(t, ui.DisplayedData, usersByRole)
+}
+
+func callOrgUsers(args []string, reqFactory *testhelpers.FakeReqFactory, userRepo *testhelpers.FakeUserRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("org-users", args)
+	cmd := NewOrgUsers(ui, userRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
@@ -0,0 +1,61 @@
+package user
+
+import (
+	"cf"
+	"cf/api"
+	"cf/net"
+	"cf/terminal"
+	"fmt"
+	"strings"
+)
+
+// findRole matches the role the user typed against the known roles without
+// caring about case, so orgmanager works as well as OrgManager.
+func findRole(name string, roles []string) (role string, found bool) {
+	for _, role = range roles {
+		if strings.EqualFold(role, name) {
+			found = true
+			return
+		}
+	}
+	role = ""
+	return
+}
+
+func invalidRoleMessage(name string, roles []string) string {
+	return fmt.Sprintf("Invalid role %s. Use one of: %s", name, strings.Join(roles, ", "))
+}
+
+// findSpaceInOrg asks the cloud controller for the space, since the spaces
+// inlined in the org are cut off after the first page.
+func findSpaceInOrg(spaceRepo api.SpaceRepository, org cf.Organization, name string) (space cf.Space, apiResponse net.ApiResponse) {
+	space, apiResponse = spaceRepo.FindByNameInOrg(name, org)
+	space.Organization = org
+	return
+}
+
+func displayUsersByRole(ui terminal.UI, roles []string, usersByRole map[string][]cf.User) {
+	if ui.IsStructuredOutput() {
+		ui.DisplayData(usersByRole)
+		return
+	}
+
+	for _, role := range roles {
+		ui.Say("")
+		ui.Say("%s", terminal.HeaderColor(role))
+
+		users := usersByRole[role]
+		if len(users) == 0 {
+			ui.Say("  No users found")
+			continue
+		}
+
+		for _, user := range users {
+			name := user.Username
+			if name == "" {
+				name = user.Guid
+			}
+			ui.Say("  %s", name)
+		}
+	}
+}
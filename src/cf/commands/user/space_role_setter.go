@@ -0,0 +1,96 @@
+package user
+
+import (
+	"cf"
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type SpaceRoleSetter struct {
+	ui        terminal.UI
+	spaceRepo api.SpaceRepository
+	userRepo  api.UserRepository
+	userReq   requirements.UserRequirement
+	orgReq    requirements.OrganizationRequirement
+	set       bool
+}
+
+func NewSpaceRoleSetter(ui terminal.UI, spaceRepo api.SpaceRepository, userRepo api.UserRepository, set bool) (cmd *SpaceRoleSetter) {
+	cmd = &SpaceRoleSetter{
+		ui:        ui,
+		spaceRepo: spaceRepo,
+		userRepo:  userRepo,
+		set:       set,
+	}
+	return
+}
+
+func (cmd *SpaceRoleSetter) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 4 {
+		err = errors.New("Incorrect Usage")
+		if cmd.set {
+			cmd.ui.FailWithUsage(c, "set-space-role")
+		} else {
+			cmd.ui.FailWithUsage(c, "unset-space-role")
+		}
+		return
+	}
+
+	cmd.userReq = reqFactory.NewUserRequirement(c.Args()[0])
+	cmd.orgReq = reqFactory.NewOrganizationRequirement(c.Args()[1])
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+		cmd.userReq,
+		cmd.orgReq,
+	}
+	return
+}
+
+func (cmd *SpaceRoleSetter) Run(c *cli.Context) {
+	user := cmd.userReq.GetUser()
+	org := cmd.orgReq.GetOrganization()
+	spaceName := c.Args()[2]
+
+	role, found := findRole(c.Args()[3], cf.SpaceRoles)
+	if !found {
+		cmd.ui.Failed(invalidRoleMessage(c.Args()[3], cf.SpaceRoles))
+		return
+	}
+
+	space, apiResponse := findSpaceInOrg(cmd.spaceRepo, org, spaceName)
+	if apiResponse.IsNotFound() {
+		cmd.ui.Failed("Space %s not found in org %s", spaceName, org.Name)
+		return
+	}
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	if cmd.set {
+		cmd.ui.Say("Assigning role %s to user %s in org %s / space %s...",
+			terminal.EntityNameColor(role),
+			terminal.EntityNameColor(user.Username),
+			terminal.EntityNameColor(org.Name),
+			terminal.EntityNameColor(space.Name))
+		apiResponse = cmd.userRepo.SetSpaceRole(user, space, role)
+	} else {
+		cmd.ui.Say("Removing role %s from user %s in org %s / space %s...",
+			terminal.EntityNameColor(role),
+			terminal.EntityNameColor(user.Username),
+			terminal.EntityNameColor(org.Name),
+			terminal.EntityNameColor(space.Name))
+		apiResponse = cmd.userRepo.UnsetSpaceRole(user, space, role)
+	}
+
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+}
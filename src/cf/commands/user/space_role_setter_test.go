@@ -0,0 +1,102 @@
+package user_test
+
+import (
+	"cf"
+	. "cf/commands/user"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestSetSpaceRoleFailsWithUsage(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callSpaceRoleSetter("set-space-role", []string{"my-user", "my-org", "my-space"}, reqFactory, userRepo, true)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callSpaceRoleSetter("set-space-role", []string{"my-user", "my-org", "my-space", "SpaceDeveloper"}, reqFactory, userRepo, true)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestSetSpaceRole(t *testing.T) {
+	reqFactory := spaceRoleReqFactory()
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callSpaceRoleSetter("set-space-role", []string{"my-user", "my-org", "my-space", "spacedeveloper"}, reqFactory, userRepo, true)
+
+	assert.Equal(t, reqFactory.Username, "my-user")
+	assert.Equal(t, reqFactory.OrganizationName, "my-org")
+
+	assert.Contains(t, ui.Outputs[0], "Assigning role")
+	assert.Contains(t, ui.Outputs[0], "SpaceDeveloper")
+	assert.Contains(t, ui.Outputs[0], "my-space")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, userRepo.SetSpaceRoleUser.Guid, "my-user-guid")
+	assert.Equal(t, userRepo.SetSpaceRoleSpace.Guid, "my-space-guid")
+	assert.Equal(t, userRepo.SetSpaceRoleSpace.Organization.Guid, "my-org-guid")
+	assert.Equal(t, userRepo.SetSpaceRoleRole, cf.SPACE_DEVELOPER)
+}
+
+func TestUnsetSpaceRole(t *testing.T) {
+	reqFactory := spaceRoleReqFactory()
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callSpaceRoleSetter("unset-space-role", []string{"my-user", "my-org", "my-space", "SpaceAuditor"}, reqFactory, userRepo, false)
+
+	assert.Contains(t, ui.Outputs[0], "Removing role")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Equal(t, userRepo.UnsetSpaceRoleSpace.Guid, "my-space-guid")
+	assert.Equal(t, userRepo.UnsetSpaceRoleRole, cf.SPACE_AUDITOR)
+}
+
+func TestSetSpaceRoleWhenSpaceIsNotInOrg(t *testing.T) {
+	reqFactory := spaceRoleReqFactory()
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callSpaceRoleSetter("set-space-role", []string{"my-user", "my-org", "other-space", "SpaceManager"}, reqFactory, userRepo, true)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "other-space")
+	assert.Contains(t, ui.Outputs[1], "not found")
+	assert.Equal(t, userRepo.SetSpaceRoleRole, "")
+}
+
+func TestSetSpaceRoleWithInvalidRole(t *testing.T) {
+	reqFactory := spaceRoleReqFactory()
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callSpaceRoleSetter("set-space-role", []string{"my-user", "my-org", "my-space", "OrgManager"}, reqFactory, userRepo, true)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "Invalid role OrgManager")
+	assert.Equal(t, userRepo.SetSpaceRoleRole, "")
+}
+
+func spaceRoleReqFactory() *testhelpers.FakeReqFactory {
+	return &testhelpers.FakeReqFactory{
+		LoginSuccess: true,
+		User:         cf.User{Username: "my-user", Guid: "my-user-guid"},
+		Organization: cf.Organization{Name: "my-org", Guid: "my-org-guid"},
+	}
+}
+
+// spaceRoleSpaceRepo only knows my-space, so that the commands cannot find
+// spaces through the org itself.
+func spaceRoleSpaceRepo() *testhelpers.FakeSpaceRepository {
+	return &testhelpers.FakeSpaceRepository{
+		SpacesByOrgGuid: map[string][]cf.Space{
+			"my-org-guid": []cf.Space{{Name: "my-space", Guid: "my-space-guid"}},
+		},
+	}
+}
+
+func callSpaceRoleSetter(cmdName string, args []string, reqFactory *testhelpers.FakeReqFactory, userRepo *testhelpers.FakeUserRepository, set bool) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext(cmdName, args)
+	cmd := NewSpaceRoleSetter(ui, spaceRoleSpaceRepo(), userRepo, set)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
@@ -0,0 +1,69 @@
+package user
+
+import (
+	"cf"
+	"cf/api"
+	"cf/requirements"
+	"cf/terminal"
+	"errors"
+	"github.com/codegangsta/cli"
+)
+
+type SpaceUsers struct {
+	ui        terminal.UI
+	spaceRepo api.SpaceRepository
+	userRepo  api.UserRepository
+	orgReq    requirements.OrganizationRequirement
+}
+
+func NewSpaceUsers(ui terminal.UI, spaceRepo api.SpaceRepository, userRepo api.UserRepository) (cmd *SpaceUsers) {
+	cmd = new(SpaceUsers)
+	cmd.ui = ui
+	cmd.spaceRepo = spaceRepo
+	cmd.userRepo = userRepo
+	return
+}
+
+func (cmd *SpaceUsers) GetRequirements(reqFactory requirements.Factory, c *cli.Context) (reqs []requirements.Requirement, err error) {
+	if len(c.Args()) != 2 {
+		err = errors.New("Incorrect Usage")
+		cmd.ui.FailWithUsage(c, "space-users")
+		return
+	}
+
+	cmd.orgReq = reqFactory.NewOrganizationRequirement(c.Args()[0])
+
+	reqs = []requirements.Requirement{
+		reqFactory.NewLoginRequirement(),
+		cmd.orgReq,
+	}
+	return
+}
+
+func (cmd *SpaceUsers) Run(c *cli.Context) {
+	org := cmd.orgReq.GetOrganization()
+	spaceName := c.Args()[1]
+
+	space, apiResponse := findSpaceInOrg(cmd.spaceRepo, org, spaceName)
+	if apiResponse.IsNotFound() {
+		cmd.ui.Failed("Space %s not found in org %s", spaceName, org.Name)
+		return
+	}
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Say("Getting users in org %s / space %s...",
+		terminal.EntityNameColor(org.Name),
+		terminal.EntityNameColor(space.Name))
+
+	usersByRole, apiResponse := cmd.userRepo.FindAllInSpaceByRole(space)
+	if apiResponse.IsNotSuccessful() {
+		cmd.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
+		return
+	}
+
+	cmd.ui.Ok()
+	displayUsersByRole(cmd.ui, cf.SpaceRoles, usersByRole)
+}
@@ -0,0 +1,62 @@
+package user_test
+
+import (
+	"cf"
+	. "cf/commands/user"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestSpaceUsersFailsWithUsage(t *testing.T) {
+	reqFactory := &testhelpers.FakeReqFactory{LoginSuccess: true}
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callSpaceUsers([]string{"my-org"}, reqFactory, userRepo)
+	assert.True(t, ui.FailedWithUsage)
+
+	ui = callSpaceUsers([]string{"my-org", "my-space"}, reqFactory, userRepo)
+	assert.False(t, ui.FailedWithUsage)
+}
+
+func TestSpaceUsers(t *testing.T) {
+	reqFactory := spaceRoleReqFactory()
+	userRepo := &testhelpers.FakeUserRepository{
+		FindAllInSpaceByRoleUsersByRole: map[string][]cf.User{
+			cf.SPACE_DEVELOPER: []cf.User{{Username: "user1"}},
+		},
+	}
+
+	ui := callSpaceUsers([]string{"my-org", "my-space"}, reqFactory, userRepo)
+
+	assert.Equal(t, reqFactory.OrganizationName, "my-org")
+	assert.Equal(t, userRepo.FindAllInSpaceByRoleSpace.Guid, "my-space-guid")
+
+	assert.Contains(t, ui.Outputs[0], "Getting users in org")
+	assert.Contains(t, ui.Outputs[0], "my-space")
+	assert.Contains(t, ui.Outputs[1], "OK")
+
+	assert.Contains(t, ui.Outputs[3], "SpaceManager")
+	assert.Contains(t, ui.Outputs[4], "No users found")
+	assert.Contains(t, ui.Outputs[6], "SpaceDeveloper")
+	assert.Contains(t, ui.Outputs[7], "user1")
+}
+
+func TestSpaceUsersWhenSpaceIsNotInOrg(t *testing.T) {
+	reqFactory := spaceRoleReqFactory()
+	userRepo := &testhelpers.FakeUserRepository{}
+
+	ui := callSpaceUsers([]string{"my-org", "other-space"}, reqFactory, userRepo)
+
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "other-space")
+	assert.Equal(t, userRepo.FindAllInSpaceByRoleSpace, cf.Space{})
+}
+
+func callSpaceUsers(args []string, reqFactory *testhelpers.FakeReqFactory, userRepo *testhelpers.FakeUserRepository) (ui *testhelpers.FakeUI) {
+	ui = new(testhelpers.FakeUI)
+	ctxt := testhelpers.NewContext("space-users", args)
+	cmd := NewSpaceUsers(ui, spaceRoleSpaceRepo(), userRepo)
+	testhelpers.RunCommand(cmd, ctxt, reqFactory)
+	return
+}
@@ -15,6 +15,8 @@ type Configuration struct {
 	Organization            cf.Organization
 	Space                   cf.Space
 	ApplicationStartTimeout time.Duration // will be used as seconds
+	SSLDisabled             bool
+	CACertFile              string
 }
 
 func (c Configuration) UserEmail() (email string) {
@@ -0,0 +1,27 @@
+// +build darwin freebsd linux netbsd openbsd
+
+package configuration
+
+import (
+	"os"
+	"syscall"
+)
+
+func lockFile(path string) (unlock func(), err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, filePermissions)
+	if err != nil {
+		return
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		file.Close()
+		return
+	}
+
+	unlock = func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}
+	return
+}
@@ -0,0 +1,51 @@
+// +build windows
+
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+)
+
+// Windows has no flock, so the lock is the existence of the lock file. A
+// lock older than lockTimeout was left behind by a process that died and is
+// taken over.
+func lockFile(path string) (unlock func(), err error) {
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		var file *os.File
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePermissions)
+		if err == nil {
+			file.Close()
+			unlock = func() {
+				os.Remove(path)
+			}
+			return
+		}
+
+		if !os.IsExist(err) {
+			return
+		}
+
+		info, statErr := os.Stat(path)
+		if statErr == nil && time.Since(info.ModTime()) > lockTimeout {
+			os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			err = errors.New(fmt.Sprintf("Timed out waiting for lock %s", path))
+			return
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,227 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultProfileName = "default"
+	ProfileEnvVar      = "CF_PROFILE"
+)
+
+var profileOverride string
+
+// UseProfile makes every configuration read and write in this process go to
+// the named profile without changing the active one.
+func UseProfile(name string) {
+	profileOverride = name
+	singleton = nil
+}
+
+// CurrentProfileName is the profile used by this process: the one passed to
+// UseProfile, then CF_PROFILE, then the active profile.
+func CurrentProfileName() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+
+	if name := os.Getenv(ProfileEnvVar); name != "" {
+		return name
+	}
+
+	return activeProfileName()
+}
+
+type ProfileRepository interface {
+	List() (names []string, err error)
+	Active() (name string)
+	Create(name string) (err error)
+	Activate(name string) (err error)
+	Delete(name string) (err error)
+}
+
+type ProfileDiskRepository struct {
+}
+
+func NewProfileDiskRepository() (repo ProfileDiskRepository) {
+	return ProfileDiskRepository{}
+}
+
+func (repo ProfileDiskRepository) List() (names []string, err error) {
+	names = []string{DefaultProfileName}
+
+	files, err := ioutil.ReadDir(profilesDir())
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".json")
+		if file.IsDir() || name == file.Name() || name == DefaultProfileName {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names[1:])
+	return
+}
+
+func (repo ProfileDiskRepository) Active() (name string) {
+	return activeProfileName()
+}
+
+func (repo ProfileDiskRepository) Create(name string) (err error) {
+	err = validateProfileName(name)
+	if err != nil {
+		return
+	}
+
+	if ProfileExists(name) {
+		err = errors.New(fmt.Sprintf("Profile %s already exists", name))
+		return
+	}
+
+	file, err := profileConfigFile(name)
+	if err != nil {
+		return
+	}
+
+	return writeConfiguration(file, defaultConfig())
+}
+
+func (repo ProfileDiskRepository) Activate(name string) (err error) {
+	if !ProfileExists(name) {
+		err = errors.New(fmt.Sprintf("Profile %s does not exist", name))
+		return
+	}
+
+	file, err := activeProfileFile()
+	if err != nil {
+		return
+	}
+
+	if name == DefaultProfileName {
+		err = os.Remove(file)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	return writeFileAtomically(file, []byte(name))
+}
+
+func (repo ProfileDiskRepository) Delete(name string) (err error) {
+	if name == DefaultProfileName {
+		err = errors.New("The default profile cannot be deleted")
+		return
+	}
+
+	if name == activeProfileName() {
+		err = errors.New(fmt.Sprintf("Profile %s is active. Switch to another profile before deleting it.", name))
+		return
+	}
+
+	if !ProfileExists(name) {
+		err = errors.New(fmt.Sprintf("Profile %s does not exist", name))
+		return
+	}
+
+	file, err := profileConfigFile(name)
+	if err != nil {
+		return
+	}
+
+	return os.Remove(file)
+}
+
+func validateProfileName(name string) (err error) {
+	if !regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`).MatchString(name) {
+		err = errors.New("Profile names can only contain letters, numbers, underscores and hyphens")
+	}
+	return
+}
+
+func ProfileExists(name string) bool {
+	if name == DefaultProfileName {
+		return true
+	}
+
+	if validateProfileName(name) != nil {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(profilesDir(), name+".json"))
+	return err == nil
+}
+
+func activeProfileName() string {
+	file, err := activeProfileFile()
+	if err != nil {
+		return DefaultProfileName
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return DefaultProfileName
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return DefaultProfileName
+	}
+	return name
+}
+
+func activeProfileFile() (file string, err error) {
+	err = os.MkdirAll(configDir(), dirPermissions)
+	if err != nil {
+		return
+	}
+
+	file = filepath.Join(configDir(), "active_profile")
+	return
+}
+
+func profilesDir() string {
+	return filepath.Join(configDir(), "profiles")
+}
+
+// profileConfigFile keeps the default profile in config.json so
+// configurations written before profiles existed keep working.
+func profileConfigFile(name string) (file string, err error) {
+	if name == DefaultProfileName {
+		err = os.MkdirAll(configDir(), dirPermissions)
+		if err != nil {
+			return
+		}
+
+		file = filepath.Join(configDir(), "config.json")
+		return
+	}
+
+	err = validateProfileName(name)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(profilesDir(), dirPermissions)
+	if err != nil {
+		return
+	}
+
+	file = filepath.Join(profilesDir(), name+".json")
+	return
+}
@@ -0,0 +1,118 @@
+package configuration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreatingListingAndDeletingProfiles(t *testing.T) {
+	defer useTemporaryHome(t)()
+	repo := NewProfileDiskRepository()
+
+	names, err := repo.List()
+	assert.NoError(t, err)
+	assert.Equal(t, names, []string{"default"})
+
+	assert.NoError(t, repo.Create("staging"))
+	assert.NoError(t, repo.Create("production"))
+	assert.Error(t, repo.Create("staging"))
+	assert.Error(t, repo.Create("default"))
+	assert.Error(t, repo.Create("../outside"))
+
+	names, err = repo.List()
+	assert.NoError(t, err)
+	assert.Equal(t, names, []string{"default", "production", "staging"})
+
+	assert.NoError(t, repo.Delete("staging"))
+	assert.Error(t, repo.Delete("staging"))
+	assert.Error(t, repo.Delete("default"))
+
+	names, err = repo.List()
+	assert.NoError(t, err)
+	assert.Equal(t, names, []string{"default", "production"})
+}
+
+func TestActivatingProfiles(t *testing.T) {
+	defer useTemporaryHome(t)()
+	repo := NewProfileDiskRepository()
+
+	assert.Equal(t, repo.Active(), "default")
+	assert.Error(t, repo.Activate("staging"))
+
+	assert.NoError(t, repo.Create("staging"))
+	assert.NoError(t, repo.Activate("staging"))
+	assert.Equal(t, repo.Active(), "staging")
+	assert.Equal(t, CurrentProfileName(), "staging")
+	assert.Error(t, repo.Delete("staging"))
+
+	assert.NoError(t, repo.Activate("default"))
+	assert.Equal(t, repo.Active(), "default")
+}
+
+func TestEachProfileKeepsItsOwnConfiguration(t *testing.T) {
+	defer useTemporaryHome(t)()
+	repo := NewConfigurationDiskRepository()
+	profileRepo := NewProfileDiskRepository()
+
+	config, err := repo.Get()
+	assert.NoError(t, err)
+	config.Target = "https://api.production.example.com"
+	config.AccessToken = "production-token"
+	assert.NoError(t, repo.Save())
+
+	assert.NoError(t, profileRepo.Create("staging"))
+	UseProfile("staging")
+
+	config, err = repo.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, config.Target, "https://api.run.pivotal.io")
+	assert.Equal(t, config.AccessToken, "")
+	config.Target = "https://api.staging.example.com"
+	assert.NoError(t, repo.Save())
+
+	UseProfile("")
+	config, err = repo.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, config.Target, "https://api.production.example.com")
+	assert.Equal(t, config.AccessToken, "production-token")
+	assert.Equal(t, profileRepo.Active(), "default")
+
+	os.Setenv(ProfileEnvVar, "staging")
+	defer os.Setenv(ProfileEnvVar, "")
+	singleton = nil
+
+	config, err = repo.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, config.Target, "https://api.staging.example.com")
+}
+
+func TestLoadingAProfileThatDoesNotExist(t *testing.T) {
+	defer useTemporaryHome(t)()
+
+	UseProfile("missing")
+	defer UseProfile("")
+
+	_, err := NewConfigurationDiskRepository().Get()
+	assert.Error(t, err)
+
+	_, err = os.Stat(filepath.Join(profilesDir(), "missing.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func useTemporaryHome(t *testing.T) (restore func()) {
+	home, err := ioutil.TempDir("", "cf-home")
+	assert.NoError(t, err)
+
+	oldHome := os.Getenv(CF_HOME_ENV)
+	os.Setenv(CF_HOME_ENV, home)
+	singleton = nil
+
+	return func() {
+		os.Setenv(CF_HOME_ENV, oldHome)
+		os.RemoveAll(home)
+		singleton = nil
+	}
+}
@@ -3,6 +3,8 @@ package configuration
 import (
 	"cf"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -10,7 +12,8 @@ import (
 )
 
 const (
-	filePermissions = 0644
+	CF_HOME_ENV     = "CF_HOME"
+	filePermissions = 0600
 	dirPermissions  = 0700
 )
 
@@ -75,17 +78,29 @@ func (repo ConfigurationDiskRepository) ClearSession() (err error) {
 
 // Keep this one public for configtest/configuration.go
 func ConfigFile() (file string, err error) {
+	name := CurrentProfileName()
+	if !ProfileExists(name) {
+		err = errors.New(fmt.Sprintf("Profile %s does not exist", name))
+		return
+	}
 
-	configDir := filepath.Join(userHomeDir(), ".cf")
+	return profileConfigFile(name)
+}
 
-	err = os.MkdirAll(configDir, dirPermissions)
+// ResourceCacheDir is where push keeps the fingerprints of the files of each
+// app between pushes.
+func ResourceCacheDir() string {
+	return filepath.Join(configDir(), "resource_cache")
+}
 
-	if err != nil {
-		return
+// configDir is $CF_HOME/.cf when CF_HOME is set so that, for example, CI
+// jobs can keep their configuration apart from the user's.
+func configDir() string {
+	home := os.Getenv(CF_HOME_ENV)
+	if home == "" {
+		home = userHomeDir()
 	}
-
-	file = filepath.Join(configDir, "config.json")
-	return
+	return filepath.Join(home, ".cf")
 }
 
 // See: http://stackoverflow.com/questions/7922270/obtain-users-home-directory
@@ -113,14 +128,12 @@ func defaultConfig() (c *Configuration) {
 }
 
 func load() (c *Configuration, parseError error) {
-	file, readError := ConfigFile()
-	c = new(Configuration)
-
-	if readError != nil {
-		c := defaultConfig()
-		return c, saveConfiguration(c)
+	file, parseError := ConfigFile()
+	if parseError != nil {
+		return
 	}
 
+	c = new(Configuration)
 	data, readError := ioutil.ReadFile(file)
 
 	if readError != nil {
@@ -134,17 +147,59 @@ func load() (c *Configuration, parseError error) {
 }
 
 func saveConfiguration(config *Configuration) (err error) {
+	file, err := ConfigFile()
+	if err != nil {
+		return
+	}
+
+	return writeConfiguration(file, config)
+}
+
+func writeConfiguration(file string, config *Configuration) (err error) {
 	bytes, err := json.Marshal(config)
 	if err != nil {
 		return
 	}
 
-	file, err := ConfigFile()
+	return writeFileAtomically(file, bytes)
+}
 
+// writeFileAtomically replaces file with data by writing a temporary file
+// next to it and renaming it into place, so readers never see a partial
+// file. The lock keeps concurrent cf processes from interleaving writes.
+func writeFileAtomically(file string, data []byte) (err error) {
+	unlock, err := lockFile(file + ".lock")
 	if err != nil {
 		return
 	}
-	err = ioutil.WriteFile(file, bytes, filePermissions)
+	defer unlock()
 
-	return
+	tempFile, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(tempFile.Name())
+		}
+	}()
+
+	_, err = tempFile.Write(data)
+	if err == nil {
+		err = tempFile.Chmod(filePermissions)
+	}
+	if err == nil {
+		err = tempFile.Sync()
+	}
+
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	return os.Rename(tempFile.Name(), file)
 }
@@ -2,7 +2,11 @@ package configuration
 
 import (
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -35,6 +39,61 @@ func TestSavingAndLoading(t *testing.T) {
 	assert.Equal(t, savedConfig, configToSave)
 }
 
+func TestConfigFileIsInCFHome(t *testing.T) {
+	defer useTemporaryHome(t)()
+
+	file, err := ConfigFile()
+	assert.NoError(t, err)
+	assert.Equal(t, file, filepath.Join(os.Getenv(CF_HOME_ENV), ".cf", "config.json"))
+}
+
+func TestSavedConfigurationIsOnlyReadableByTheUser(t *testing.T) {
+	defer useTemporaryHome(t)()
+	repo := NewConfigurationDiskRepository()
+
+	file, err := ConfigFile()
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(file, []byte("{}"), 0644)
+	assert.NoError(t, err)
+
+	_, err = repo.Get()
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Save())
+
+	info, err := os.Stat(file)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+}
+
+func TestConcurrentSavesLeaveAValidConfiguration(t *testing.T) {
+	defer useTemporaryHome(t)()
+
+	file, err := ConfigFile()
+	assert.NoError(t, err)
+
+	done := make(chan error)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			config := defaultConfig()
+			config.AccessToken = strings.Repeat(strconv.Itoa(i), 10000)
+			done <- writeConfiguration(file, config)
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, <-done)
+	}
+
+	config, err := NewConfigurationDiskRepository().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, len(config.AccessToken), 10000)
+
+	files, err := ioutil.ReadDir(filepath.Dir(file))
+	assert.NoError(t, err)
+	for _, f := range files {
+		assert.NotContains(t, f.Name(), ".tmp")
+	}
+}
+
 func (repo ConfigurationDiskRepository) loadDefaultConfig(t *testing.T) (config *Configuration) {
 	file, err := ConfigFile()
 	assert.NoError(t, err)
@@ -1,7 +1,10 @@
 package cf
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -15,19 +18,20 @@ const (
 )
 
 type Organization struct {
-	Name    string
-	Guid    string
-	Spaces  []Space
-	Domains []Domain
+	Name    string   `json:"name"`
+	Guid    string   `json:"guid"`
+	Spaces  []Space  `json:"spaces,omitempty"`
+	Domains []Domain `json:"domains,omitempty"`
+	Quota   Quota    `json:"quota"`
 }
 
 type Space struct {
-	Name             string
-	Guid             string
-	Applications     []Application
-	ServiceInstances []ServiceInstance
-	Organization     Organization
-	Domains          []Domain
+	Name             string            `json:"name"`
+	Guid             string            `json:"guid"`
+	Applications     []Application     `json:"applications,omitempty"`
+	ServiceInstances []ServiceInstance `json:"service_instances,omitempty"`
+	Organization     Organization      `json:"organization"`
+	Domains          []Domain          `json:"domains,omitempty"`
 }
 
 func (space Space) String() string {
@@ -35,18 +39,26 @@ func (space Space) String() string {
 }
 
 type Application struct {
-	Name             string
-	Guid             string
-	State            string
-	Instances        int
-	RunningInstances int
-	Memory           uint64 // in Megabytes
-	DiskQuota        uint64 // in Megabytes
-	Urls             []string
-	BuildpackUrl     string
-	Stack            Stack
-	EnvironmentVars  map[string]string
-	Command          string
+	Name             string            `json:"name"`
+	Guid             string            `json:"guid"`
+	State            string            `json:"state"`
+	Instances        int               `json:"instances"`
+	RunningInstances int               `json:"running_instances"`
+	Memory           uint64            `json:"memory"`     // in Megabytes
+	DiskQuota        uint64            `json:"disk_quota"` // in Megabytes
+	Urls             []string          `json:"urls"`
+	Routes           []Route           `json:"-"`
+	ServiceNames     []string          `json:"-"`
+	BuildpackUrl     string            `json:"buildpack"`
+	Stack            Stack             `json:"stack"`
+	EnvironmentVars  map[string]string `json:"-"` // values may be secrets
+	Command          string            `json:"command"`
+
+	DetectedBuildpack    string `json:"detected_buildpack"`
+	DetectedStartCommand string `json:"detected_start_command"`
+	PackageState         string `json:"package_state"`
+	StagingTaskId        string `json:"staging_task_id"`
+	StagingFailedReason  string `json:"staging_failed_reason"`
 }
 
 func (app Application) Health() string {
@@ -65,29 +77,49 @@ func (app Application) Health() string {
 	return "N/A"
 }
 
+// StagingState describes how staging the current package of the app went.
+func (app Application) StagingState() string {
+	switch strings.ToUpper(app.PackageState) {
+	case "STAGED":
+		return "staged"
+	case "FAILED":
+		if app.StagingFailedReason != "" {
+			return "failed (" + app.StagingFailedReason + ")"
+		}
+		return "failed"
+	case "PENDING":
+		if app.StagingTaskId != "" {
+			return "staging"
+		}
+		return "not staged"
+	}
+	return ""
+}
+
 type AppSummary struct {
-	App       Application
-	Instances []ApplicationInstance
+	App       Application           `json:"app"`
+	Instances []ApplicationInstance `json:"instances"`
 }
 
 type AppFile struct {
-	Path string
-	Sha1 string
-	Size int64
+	Path string      `json:"path"`
+	Sha1 string      `json:"sha1"`
+	Size int64       `json:"size"`
+	Mode os.FileMode `json:"mode"`
 }
 
 type Domain struct {
-	Name   string
-	Guid   string
-	Shared bool
-	Spaces []Space
+	Name   string  `json:"name"`
+	Guid   string  `json:"guid"`
+	Shared bool    `json:"shared"`
+	Spaces []Space `json:"spaces,omitempty"`
 }
 
 type Route struct {
-	Host     string
-	Guid     string
-	Domain   Domain
-	AppNames []string
+	Host     string   `json:"host"`
+	Guid     string   `json:"guid"`
+	Domain   Domain   `json:"domain"`
+	AppNames []string `json:"apps"`
 }
 
 func (r Route) URL() string {
@@ -98,53 +130,122 @@ func (r Route) URL() string {
 }
 
 type Stack struct {
-	Name        string
-	Guid        string
-	Description string
+	Name        string `json:"name"`
+	Guid        string `json:"guid"`
+	Description string `json:"description"`
 }
 
 type ApplicationInstance struct {
-	State     InstanceState
-	Since     time.Time
-	CpuUsage  float64 // percentage
-	DiskQuota uint64  // in bytes
-	DiskUsage uint64
-	MemQuota  uint64
-	MemUsage  uint64
+	Index     int           `json:"index"`
+	State     InstanceState `json:"state"`
+	Since     time.Time     `json:"since"`
+	Uptime    time.Duration `json:"-"`          // written as seconds by MarshalJSON
+	CpuUsage  float64       `json:"cpu"`        // percentage
+	DiskQuota uint64        `json:"disk_quota"` // in bytes
+	DiskUsage uint64        `json:"disk_usage"`
+	MemQuota  uint64        `json:"mem_quota"`
+	MemUsage  uint64        `json:"mem_usage"`
+
+	// Details tells why an instance is down or flapping, when known
+	Details string `json:"details,omitempty"`
+}
+
+// MarshalJSON writes Uptime as whole seconds, like the cloud controller
+// reports it, instead of the nanoseconds of a time.Duration.
+func (instance ApplicationInstance) MarshalJSON() ([]byte, error) {
+	type instanceFields ApplicationInstance
+	return json.Marshal(struct {
+		instanceFields
+		Uptime int64 `json:"uptime"`
+	}{instanceFields(instance), int64(instance.Uptime / time.Second)})
+}
+
+// InstanceCrash is a crash of an app instance, as recorded by the health
+// manager.
+type InstanceCrash struct {
+	Index           int       `json:"index"`
+	Since           time.Time `json:"since"`
+	ExitStatus      int       `json:"exit_status"`
+	ExitDescription string    `json:"exit_description"`
+}
+
+// AppEnvironment is the environment an app runs with, split up by where the
+// variables come from.
+type AppEnvironment struct {
+	System      map[string]interface{} `json:"system_env_json"`
+	Application map[string]interface{} `json:"application_env_json"`
+	User        map[string]string      `json:"environment_json"`
+	Running     map[string]interface{} `json:"running_env_json"`
+	Staging     map[string]interface{} `json:"staging_env_json"`
+}
+
+// InstanceFile is an entry of a directory listing of an app instance. Size
+// is the human readable size the DEA reports, and is "-" for directories.
+type InstanceFile struct {
+	Name  string
+	Size  string
+	IsDir bool
 }
 
 type ServicePlan struct {
-	Name            string
-	Guid            string
-	ServiceOffering ServiceOffering
+	Name            string          `json:"name"`
+	Guid            string          `json:"guid"`
+	ServiceOffering ServiceOffering `json:"service_offering"`
 }
 
 type ServiceOffering struct {
-	Guid             string
-	Label            string
-	Provider         string
-	Version          string
-	Description      string
-	DocumentationUrl string
-	Plans            []ServicePlan
+	Guid             string        `json:"guid"`
+	Label            string        `json:"label"`
+	Provider         string        `json:"provider"`
+	Version          string        `json:"version"`
+	Description      string        `json:"description"`
+	DocumentationUrl string        `json:"documentation_url"`
+	Plans            []ServicePlan `json:"plans,omitempty"`
 }
 
 type ServiceInstance struct {
-	Name             string
-	Guid             string
-	ServiceBindings  []ServiceBinding
-	ServicePlan      ServicePlan
-	ApplicationNames []string
-	ServiceOffering  ServiceOffering
+	Name             string           `json:"name"`
+	Guid             string           `json:"guid"`
+	ServiceBindings  []ServiceBinding `json:"service_bindings"`
+	ServicePlan      ServicePlan      `json:"service_plan"`
+	ApplicationNames []string         `json:"apps"`
+	ServiceOffering  ServiceOffering  `json:"service_offering"`
 }
 
 type ServiceBinding struct {
-	Url     string
-	Guid    string
-	AppGuid string
+	Url     string `json:"url"`
+	Guid    string `json:"guid"`
+	AppGuid string `json:"app_guid"`
+}
+
+const (
+	ORG_MANAGER     = "OrgManager"
+	BILLING_MANAGER = "BillingManager"
+	ORG_AUDITOR     = "OrgAuditor"
+	SPACE_MANAGER   = "SpaceManager"
+	SPACE_DEVELOPER = "SpaceDeveloper"
+	SPACE_AUDITOR   = "SpaceAuditor"
+)
+
+var OrgRoles = []string{ORG_MANAGER, BILLING_MANAGER, ORG_AUDITOR}
+var SpaceRoles = []string{SPACE_MANAGER, SPACE_DEVELOPER, SPACE_AUDITOR}
+
+type User struct {
+	Username string `json:"username"`
+	Guid     string `json:"guid"`
 }
 
 type Quota struct {
-	Name string
-	Guid string
+	Name                    string `json:"name"`
+	Guid                    string `json:"guid"`
+	MemoryLimit             uint64 `json:"memory_limit"` // in Megabytes
+	ServicesLimit           int    `json:"total_services"`
+	RoutesLimit             int    `json:"total_routes"`
+	NonBasicServicesAllowed bool   `json:"non_basic_services_allowed"`
+}
+
+type QuotaUsage struct {
+	Memory   uint64 `json:"memory"` // in Megabytes
+	Services int    `json:"services"`
+	Routes   int    `json:"routes"`
 }
@@ -1,8 +1,10 @@
 package cf
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestApplicationHealth(t *testing.T) {
@@ -38,3 +40,21 @@ func TestRouteURLWithoutHost(t *testing.T) {
 
 	assert.Equal(t, route.URL(), "example.com")
 }
+
+func TestApplicationJSONLeavesOutTheEnvVariables(t *testing.T) {
+	app := Application{Name: "my-app", EnvironmentVars: map[string]string{"PASSWORD": "s3cr3t"}}
+
+	bytes, err := json.Marshal(AppSummary{App: app})
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), "my-app")
+	assert.NotContains(t, string(bytes), "s3cr3t")
+}
+
+func TestApplicationInstanceJSONWritesTheUptimeInSeconds(t *testing.T) {
+	instance := ApplicationInstance{State: InstanceRunning, Uptime: 90*time.Minute + 1500*time.Millisecond}
+
+	bytes, err := json.Marshal(instance)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), `"uptime":5401`)
+	assert.Contains(t, string(bytes), `"state":"running"`)
+}
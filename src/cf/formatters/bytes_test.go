@@ -0,0 +1,34 @@
+package formatters_test
+
+import (
+	. "cf/formatters"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestByteSize(t *testing.T) {
+	assert.Equal(t, ByteSize(0), "0")
+	assert.Equal(t, ByteSize(512*KILOBYTE), "512K")
+	assert.Equal(t, ByteSize(100*MEGABYTE), "100M")
+	assert.Equal(t, ByteSize(1536*MEGABYTE), "1.5G")
+	assert.Equal(t, ByteSize(2*TERABYTE), "2T")
+}
+
+func TestToMegabytes(t *testing.T) {
+	megabytes, err := ToMegabytes("512M")
+	assert.NoError(t, err)
+	assert.Equal(t, megabytes, uint64(512))
+
+	megabytes, err = ToMegabytes("10g")
+	assert.NoError(t, err)
+	assert.Equal(t, megabytes, uint64(10240))
+
+	_, err = ToMegabytes("512")
+	assert.Error(t, err)
+
+	_, err = ToMegabytes("M")
+	assert.Error(t, err)
+
+	_, err = ToMegabytes("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,382 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const DefaultFileName = "manifest.yml"
+
+type Manifest struct {
+	Path         string
+	Applications []Application
+}
+
+type Application struct {
+	Name            string
+	Instances       int
+	Memory          string
+	BuildpackUrl    string
+	Command         string
+	StackName       string
+	Hosts           []string
+	Domains         []string
+	Path            string
+	EnvironmentVars map[string]string
+	Services        []string
+}
+
+// Merge returns a copy of app with every property set in overrides applied on
+// top of it. Environment variables are merged key by key and services are
+// combined, all other properties are replaced.
+func (app Application) Merge(overrides Application) (merged Application) {
+	merged = app
+
+	if overrides.Name != "" {
+		merged.Name = overrides.Name
+	}
+	if overrides.Instances > 0 {
+		merged.Instances = overrides.Instances
+	}
+	if overrides.Memory != "" {
+		merged.Memory = overrides.Memory
+	}
+	if overrides.BuildpackUrl != "" {
+		merged.BuildpackUrl = overrides.BuildpackUrl
+	}
+	if overrides.Command != "" {
+		merged.Command = overrides.Command
+	}
+	if overrides.StackName != "" {
+		merged.StackName = overrides.StackName
+	}
+	if len(overrides.Hosts) > 0 {
+		merged.Hosts = overrides.Hosts
+	}
+	if len(overrides.Domains) > 0 {
+		merged.Domains = overrides.Domains
+	}
+	if overrides.Path != "" {
+		merged.Path = overrides.Path
+	}
+
+	if len(app.EnvironmentVars) > 0 || len(overrides.EnvironmentVars) > 0 {
+		merged.EnvironmentVars = map[string]string{}
+		for name, value := range app.EnvironmentVars {
+			merged.EnvironmentVars[name] = value
+		}
+		for name, value := range overrides.EnvironmentVars {
+			merged.EnvironmentVars[name] = value
+		}
+	}
+
+	merged.Services = nil
+	for _, services := range [][]string{app.Services, overrides.Services} {
+		for _, service := range services {
+			if !contains(merged.Services, service) {
+				merged.Services = append(merged.Services, service)
+			}
+		}
+	}
+
+	return
+}
+
+func (manifest Manifest) FindByName(name string) (app Application, found bool) {
+	for _, app = range manifest.Applications {
+		if app.Name == name {
+			found = true
+			return
+		}
+	}
+	app = Application{}
+	return
+}
+
+// ManifestPath resolves the manifest file for a path which may either be the
+// manifest itself or a directory containing a manifest.yml.
+func ManifestPath(path string) (manifestPath string, err error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	if fileInfo.IsDir() {
+		manifestPath = filepath.Join(path, DefaultFileName)
+		_, err = os.Stat(manifestPath)
+		return
+	}
+
+	manifestPath = path
+	return
+}
+
+func Load(path string) (manifest Manifest, err error) {
+	manifestPath, err := ManifestPath(path)
+	if err != nil {
+		return
+	}
+
+	manifestPath, err = filepath.Abs(manifestPath)
+	if err != nil {
+		return
+	}
+
+	globals, apps, err := loadWithInheritance(manifestPath, []string{})
+	if err != nil {
+		return
+	}
+
+	manifest.Path = manifestPath
+
+	if globals.Path == "" {
+		globals.Path = filepath.Dir(manifestPath)
+	}
+
+	if len(apps) == 0 {
+		if globals.Name == "" {
+			err = errors.New(fmt.Sprintf("Manifest %s does not define any applications", manifestPath))
+			return
+		}
+		manifest.Applications = []Application{globals}
+		return
+	}
+
+	for _, app := range apps {
+		app = globals.Merge(app)
+		if app.Name == "" {
+			err = errors.New(fmt.Sprintf("Every application in manifest %s must have a name", manifestPath))
+			return
+		}
+		manifest.Applications = append(manifest.Applications, app)
+	}
+
+	return
+}
+
+func Parse(data string, dir string) (globals Application, apps []Application, parent string, err error) {
+	document, err := parseYAML(data)
+	if err != nil {
+		return
+	}
+
+	root, ok := document.(map[string]interface{})
+	if !ok {
+		err = errors.New("Expected the manifest to be a map of properties")
+		return
+	}
+
+	for key, value := range root {
+		switch key {
+		case "applications":
+			list, isList := value.([]interface{})
+			if !isList {
+				err = errors.New("Expected applications to be a list")
+				return
+			}
+
+			for _, item := range list {
+				appMap, isMap := item.(map[string]interface{})
+				if !isMap {
+					err = errors.New("Expected each application to be a map of properties")
+					return
+				}
+
+				var app Application
+				app, err = applicationFromMap(appMap, dir)
+				if err != nil {
+					return
+				}
+				apps = append(apps, app)
+			}
+		case "inherit":
+			parent, ok = value.(string)
+			if !ok {
+				err = errors.New("Expected inherit to be the path of a manifest")
+				return
+			}
+			if !filepath.IsAbs(parent) {
+				parent = filepath.Join(dir, parent)
+			}
+		}
+	}
+
+	delete(root, "applications")
+	delete(root, "inherit")
+	globals, err = applicationFromMap(root, dir)
+	return
+}
+
+func loadWithInheritance(path string, visited []string) (globals Application, apps []Application, err error) {
+	if contains(visited, path) {
+		err = errors.New(fmt.Sprintf("Manifest %s inherits from itself", path))
+		return
+	}
+	visited = append(visited, path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	globals, apps, parent, err := Parse(string(data), filepath.Dir(path))
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Error parsing manifest %s: %s", path, err.Error()))
+		return
+	}
+
+	if parent == "" {
+		return
+	}
+
+	parentGlobals, parentApps, err := loadWithInheritance(parent, visited)
+	if err != nil {
+		return
+	}
+
+	globals = parentGlobals.Merge(globals)
+
+	if len(apps) == 0 {
+		apps = parentApps
+		return
+	}
+
+	for index, app := range apps {
+		for _, parentApp := range parentApps {
+			if parentApp.Name == app.Name {
+				apps[index] = parentApp.Merge(app)
+			}
+		}
+	}
+	return
+}
+
+func applicationFromMap(properties map[string]interface{}, dir string) (app Application, err error) {
+	keys := []string{}
+	for key, _ := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := properties[key]
+		if value == nil {
+			continue
+		}
+
+		switch key {
+		case "name":
+			app.Name, err = stringValue(key, value)
+		case "instances":
+			var instances string
+			instances, err = stringValue(key, value)
+			if err == nil {
+				app.Instances, err = strconv.Atoi(instances)
+				if err != nil || app.Instances < 1 {
+					err = errors.New(fmt.Sprintf("Invalid value for instances: %s", instances))
+				}
+			}
+		case "memory":
+			app.Memory, err = stringValue(key, value)
+		case "buildpack":
+			app.BuildpackUrl, err = stringValue(key, value)
+		case "command":
+			app.Command, err = stringValue(key, value)
+		case "stack":
+			app.StackName, err = stringValue(key, value)
+		case "host":
+			var host string
+			host, err = stringValue(key, value)
+			app.Hosts = append(app.Hosts, host)
+		case "hosts":
+			var hosts []string
+			hosts, err = stringSliceValue(key, value)
+			app.Hosts = append(app.Hosts, hosts...)
+		case "domain":
+			var domain string
+			domain, err = stringValue(key, value)
+			app.Domains = append(app.Domains, domain)
+		case "domains":
+			var domains []string
+			domains, err = stringSliceValue(key, value)
+			app.Domains = append(app.Domains, domains...)
+		case "path":
+			app.Path, err = stringValue(key, value)
+			if err == nil && !filepath.IsAbs(app.Path) {
+				app.Path = filepath.Join(dir, app.Path)
+			}
+		case "env":
+			app.EnvironmentVars, err = stringMapValue(key, value)
+		case "services":
+			app.Services, err = stringSliceValue(key, value)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func stringValue(key string, value interface{}) (result string, err error) {
+	result, ok := value.(string)
+	if !ok {
+		err = errors.New(fmt.Sprintf("Expected %s to be a string", key))
+	}
+	return
+}
+
+func stringSliceValue(key string, value interface{}) (result []string, err error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		err = errors.New(fmt.Sprintf("Expected %s to be a list of strings", key))
+		return
+	}
+
+	for _, item := range list {
+		var itemString string
+		itemString, err = stringValue(key, item)
+		if err != nil {
+			return
+		}
+		result = append(result, itemString)
+	}
+	return
+}
+
+func stringMapValue(key string, value interface{}) (result map[string]string, err error) {
+	properties, ok := value.(map[string]interface{})
+	if !ok {
+		err = errors.New(fmt.Sprintf("Expected %s to be a map of strings", key))
+		return
+	}
+
+	result = map[string]string{}
+	for name, item := range properties {
+		if item == nil {
+			result[name] = ""
+			continue
+		}
+
+		result[name], err = stringValue(fmt.Sprintf("%s %s", key, name), item)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
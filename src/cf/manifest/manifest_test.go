@@ -0,0 +1,199 @@
+package manifest_test
+
+import (
+	. "cf/manifest"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsingGlobalsAndApplications(t *testing.T) {
+	globals, apps, parent, err := Parse(`
+---
+memory: 256M # a comment
+env:
+  GREETING: "hello # not a comment"
+applications:
+- name: app1
+  instances: 2
+  hosts: [one, two]
+  services:
+  - db
+  - 'cache'
+- name: app2
+  domain: example.com
+  path: sub/dir
+`, "/base/dir")
+
+	assert.NoError(t, err)
+	assert.Equal(t, parent, "")
+
+	assert.Equal(t, globals.Memory, "256M")
+	assert.Equal(t, globals.EnvironmentVars, map[string]string{"GREETING": "hello # not a comment"})
+
+	assert.Equal(t, len(apps), 2)
+	assert.Equal(t, apps[0].Name, "app1")
+	assert.Equal(t, apps[0].Instances, 2)
+	assert.Equal(t, apps[0].Hosts, []string{"one", "two"})
+	assert.Equal(t, apps[0].Services, []string{"db", "cache"})
+
+	assert.Equal(t, apps[1].Name, "app2")
+	assert.Equal(t, apps[1].Domains, []string{"example.com"})
+	assert.Equal(t, apps[1].Path, filepath.Join("/base/dir", "sub/dir"))
+}
+
+func TestParsingInvalidManifests(t *testing.T) {
+	_, _, _, err := Parse("applications: not-a-list", "")
+	assert.Error(t, err)
+
+	_, _, _, err = Parse("applications:\n- name: app\n  instances: lots", "")
+	assert.Error(t, err)
+
+	_, _, _, err = Parse("name: app\n\tmemory: 128M", "")
+	assert.Error(t, err)
+
+	_, _, _, err = Parse("name: app\nname: other-app", "")
+	assert.Error(t, err)
+}
+
+func TestParsingQuotedAndFlowValues(t *testing.T) {
+	globals, _, _, err := Parse(`
+command: it's running # the apostrophe does not start a string
+services: ["db, primary", 'it''s', cache,]
+env:
+  ESCAPED: "tab\there\nnew line \"quoted\" \\ # not a comment" # a comment
+  SINGLE: 'back\slash'
+  EMPTY:
+  URL: http://example.com/#fragment
+`, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, globals.Command, "it's running")
+	assert.Equal(t, globals.Services, []string{"db, primary", "it's", "cache"})
+	assert.Equal(t, globals.EnvironmentVars, map[string]string{
+		"ESCAPED": "tab\there\nnew line \"quoted\" \\ # not a comment",
+		"SINGLE":  `back\slash`,
+		"EMPTY":   "",
+		"URL":     "http://example.com/#fragment",
+	})
+}
+
+func TestParsingUnsupportedYAMLFails(t *testing.T) {
+	for manifest, message := range map[string]string{
+		"command: |\n  echo hello":               "'|' are not supported",
+		"command: >-\n  echo hello":              "'>' are not supported",
+		"command:\n  echo hello":                 "line 2: values on the line after their key",
+		"env: {GREETING: hello}":                 "'{' are not supported",
+		"services: [db, [cache]]":                "nested flow collections",
+		"services: [db, {name: cache}]":          "nested flow collections",
+		"services: [db, cache":                   "unterminated flow sequence",
+		"services: [db,, cache]":                 "empty item",
+		"services: [db] cache":                   "unexpected text after flow sequence",
+		"services:\n- - db":                      "lists of lists",
+		"memory: &memory 256M":                   "'&' are not supported",
+		"memory: *memory":                        "'*' are not supported",
+		"memory: !!str 256M":                     "'!' are not supported",
+		"command: echo a: b":                     "cannot contain ': '",
+		"command: @echo":                         "'@' are not supported",
+		"command: \"echo":                        "unterminated quoted string",
+		"command: \"echo\" hello":                "unexpected text after quoted string",
+		"command: \"echo \\u00e9\"":              `unknown escape sequence \u`,
+		"name: app1\n---\nname: app2":            "line 2: only a single document",
+		"name: app1\n...\nname: app2":            "line 2: only a single document",
+		"%YAML 1.2\nname: app":                   "line 1: expected a key/value pair",
+		"applications:\n- name: app\n  env: { }": "'{' are not supported",
+	} {
+		_, _, _, err := Parse(manifest, "")
+		if assert.Error(t, err, manifest) {
+			assert.Contains(t, err.Error(), message, manifest)
+		}
+	}
+}
+
+func TestMergeOverridesPropertiesThatAreSet(t *testing.T) {
+	base := Application{
+		Name:            "app",
+		Instances:       2,
+		Memory:          "256M",
+		Command:         "rackup",
+		Hosts:           []string{"base-host"},
+		EnvironmentVars: map[string]string{"A": "base", "B": "base"},
+		Services:        []string{"db"},
+	}
+
+	overrides := Application{
+		Memory:          "1G",
+		Hosts:           []string{"override-host"},
+		EnvironmentVars: map[string]string{"B": "override"},
+		Services:        []string{"db", "cache"},
+	}
+
+	merged := base.Merge(overrides)
+
+	assert.Equal(t, merged.Name, "app")
+	assert.Equal(t, merged.Instances, 2)
+	assert.Equal(t, merged.Memory, "1G")
+	assert.Equal(t, merged.Command, "rackup")
+	assert.Equal(t, merged.Hosts, []string{"override-host"})
+	assert.Equal(t, merged.EnvironmentVars, map[string]string{"A": "base", "B": "override"})
+	assert.Equal(t, merged.Services, []string{"db", "cache"})
+
+	assert.Equal(t, base.EnvironmentVars, map[string]string{"A": "base", "B": "base"})
+}
+
+func TestLoadingManifestWithMultipleApps(t *testing.T) {
+	dir := fixturePath(t, "multiple-apps")
+	manifest, err := Load(dir)
+	assert.NoError(t, err)
+
+	assert.Equal(t, manifest.Path, filepath.Join(dir, "manifest.yml"))
+	assert.Equal(t, len(manifest.Applications), 2)
+
+	app1 := manifest.Applications[0]
+	assert.Equal(t, app1.Name, "app1")
+	assert.Equal(t, app1.Memory, "256M")
+	assert.Equal(t, app1.Instances, 2)
+	assert.Equal(t, app1.Hosts, []string{"app1-host"})
+	assert.Equal(t, app1.Domains, []string{"example.com"})
+	assert.Equal(t, app1.Path, filepath.Join(dir, "app1"))
+	assert.Equal(t, app1.EnvironmentVars, map[string]string{"RAILS_ENV": "production", "SHARED": "from-app1"})
+	assert.Equal(t, app1.Services, []string{"shared-db"})
+
+	app2, found := manifest.FindByName("app2")
+	assert.True(t, found)
+	assert.Equal(t, app2.Memory, "1G")
+	assert.Equal(t, app2.Hosts, []string{"app2-host", "app2-alias"})
+	assert.Equal(t, app2.Path, dir)
+	assert.Equal(t, app2.Command, "bundle exec rake db:migrate && bundle exec rackup")
+	assert.Equal(t, app2.EnvironmentVars, map[string]string{"RAILS_ENV": "production", "SHARED": "from-globals"})
+	assert.Equal(t, app2.Services, []string{"shared-db", "app2-cache"})
+
+	_, found = manifest.FindByName("app3")
+	assert.False(t, found)
+}
+
+func TestLoadingManifestWithInheritance(t *testing.T) {
+	manifest, err := Load(filepath.Join(fixturePath(t, "inherited"), "manifest.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, len(manifest.Applications), 1)
+
+	app := manifest.Applications[0]
+	assert.Equal(t, app.Name, "inherited-app")
+	assert.Equal(t, app.Memory, "128M")
+	assert.Equal(t, app.Instances, 3)
+	assert.Equal(t, app.StackName, "lucid64")
+	assert.Equal(t, app.BuildpackUrl, "https://github.com/cloudfoundry/heroku-buildpack-ruby.git")
+	assert.Equal(t, app.EnvironmentVars, map[string]string{"FROM_BASE": "base", "OVERRIDDEN": "child"})
+}
+
+func TestLoadingManifestThatDoesNotExist(t *testing.T) {
+	_, err := Load(fixturePath(t, "does-not-exist"))
+	assert.Error(t, err)
+}
+
+func fixturePath(t *testing.T, name string) string {
+	dir, err := os.Getwd()
+	assert.NoError(t, err)
+	return filepath.Join(dir, "../../fixtures/manifests", name)
+}
@@ -0,0 +1,324 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseYAML reads the small part of YAML that manifests are written in: a
+// mapping of properties, where applications is a list of mappings, env is a
+// mapping, and hosts, domains and services are lists of strings, written as
+// block lists or as [a, b]. Scalars are returned as strings, plain, single or
+// double quoted, on a single line.
+//
+// Anything else, like block scalars (| and >), flow mappings, anchors, tags
+// or several documents, is reported as an error rather than read as something
+// it is not.
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+func parseYAML(data string) (value interface{}, err error) {
+	lines, err := tokenizeYAML(data)
+	if err != nil {
+		return
+	}
+
+	if len(lines) == 0 {
+		value = map[string]interface{}{}
+		return
+	}
+
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err == nil && next < len(lines) {
+		err = yamlError(lines[next], "unexpected indentation")
+	}
+	return
+}
+
+// tokenizeYAML drops blank lines, comment lines and the --- a manifest may
+// start with. Comments after a value are left to parseYAMLScalar, which knows
+// whether a # is inside quotes.
+func tokenizeYAML(data string) (lines []yamlLine, err error) {
+	for index, rawLine := range strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n") {
+		text := strings.TrimLeft(rawLine, " ")
+		if strings.HasPrefix(text, "\t") {
+			err = fmt.Errorf("line %d: tabs are not allowed for indentation", index+1)
+			return
+		}
+
+		text = strings.TrimRight(text, " \t")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		line := yamlLine{number: index + 1, indent: len(rawLine) - len(strings.TrimLeft(rawLine, " ")), text: text}
+		if text == "---" || text == "..." {
+			if text == "..." || len(lines) > 0 {
+				err = yamlError(line, "only a single document is supported")
+				return
+			}
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+	return
+}
+
+func parseYAMLBlock(lines []yamlLine, start, indent int) (value interface{}, next int, err error) {
+	if isSequenceItem(lines[start].text) {
+		return parseYAMLSequence(lines, start, indent)
+	}
+	return parseYAMLMapping(lines, start, indent)
+}
+
+func parseYAMLSequence(lines []yamlLine, start, indent int) (value interface{}, next int, err error) {
+	items := []interface{}{}
+	next = start
+
+	for next < len(lines) && lines[next].indent == indent && isSequenceItem(lines[next].text) {
+		line := lines[next]
+		content := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+
+		var item interface{}
+		if isMappingEntry(content) {
+			// a mapping, like an application, that starts on the line of the dash
+			lines[next] = yamlLine{
+				number: line.number,
+				indent: line.indent + len(line.text) - len(content),
+				text:   content,
+			}
+			item, next, err = parseYAMLMapping(lines, next, lines[next].indent)
+		} else {
+			item, err = parseYAMLScalar(line, content)
+			next++
+		}
+
+		if err != nil {
+			return
+		}
+		items = append(items, item)
+	}
+
+	value = items
+	return
+}
+
+func parseYAMLMapping(lines []yamlLine, start, indent int) (value interface{}, next int, err error) {
+	mapping := map[string]interface{}{}
+	next = start
+
+	for next < len(lines) && lines[next].indent == indent {
+		line := lines[next]
+		if isSequenceItem(line.text) {
+			break
+		}
+
+		if !isMappingEntry(line.text) {
+			err = yamlError(line, "expected a key/value pair")
+			return
+		}
+
+		key, rest := splitMappingEntry(line.text)
+		if key == "" {
+			err = yamlError(line, "expected a key/value pair")
+			return
+		}
+
+		if _, found := mapping[key]; found {
+			err = yamlError(line, fmt.Sprintf("duplicate key '%s'", key))
+			return
+		}
+
+		var item interface{}
+		next++
+
+		if rest != "" {
+			item, err = parseYAMLScalar(line, rest)
+		} else if next < len(lines) && lines[next].indent > indent {
+			nested := lines[next]
+			if !isSequenceItem(nested.text) && !isMappingEntry(nested.text) {
+				err = yamlError(nested, "values on the line after their key are not supported")
+				return
+			}
+			item, next, err = parseYAMLBlock(lines, next, nested.indent)
+		} else if next < len(lines) && lines[next].indent == indent && isSequenceItem(lines[next].text) {
+			// lists are allowed at the same indentation as their key
+			item, next, err = parseYAMLSequence(lines, next, indent)
+		}
+
+		if err != nil {
+			return
+		}
+		mapping[key] = item
+	}
+
+	value = mapping
+	return
+}
+
+func parseYAMLScalar(line yamlLine, text string) (value interface{}, err error) {
+	switch {
+	case isYAMLComment(text):
+		value = nil
+	case isSequenceItem(text):
+		err = yamlError(line, "lists of lists are not supported")
+	case strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'"):
+		var rest string
+		value, rest, err = parseYAMLQuoted(line, text)
+		if err == nil && !isYAMLComment(rest) {
+			err = yamlError(line, "unexpected text after quoted string")
+		}
+	case strings.HasPrefix(text, "["):
+		value, err = parseYAMLFlowSequence(line, text)
+	case strings.ContainsAny(text[:1], "|>{&*!%@`"):
+		err = yamlError(line, fmt.Sprintf("values starting with '%c' are not supported unless they are quoted", text[0]))
+	default:
+		text = stripYAMLComment(text)
+		if strings.Contains(text, ": ") || strings.HasSuffix(text, ":") {
+			err = yamlError(line, "a value cannot contain ': ' unless it is quoted")
+		} else if text != "~" && text != "null" {
+			value = text
+		}
+	}
+	return
+}
+
+// parseYAMLQuoted reads the quoted string text starts with, and returns what
+// follows it. Double quoted strings only understand the escapes \" \\ \n and
+// \t.
+func parseYAMLQuoted(line yamlLine, text string) (value string, rest string, err error) {
+	quote := text[0]
+	unquoted := []byte{}
+
+	for i := 1; i < len(text); i++ {
+		char := text[i]
+		switch {
+		case char == '\'' && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			unquoted = append(unquoted, '\'')
+			i++
+		case char == quote:
+			value = string(unquoted)
+			rest = text[i+1:]
+			return
+		case char == '\\' && quote == '"' && i+1 < len(text):
+			i++
+			unescaped, found := yamlEscapes[text[i]]
+			if !found {
+				err = yamlError(line, fmt.Sprintf(`unknown escape sequence \%c`, text[i]))
+				return
+			}
+			unquoted = append(unquoted, unescaped)
+		default:
+			unquoted = append(unquoted, char)
+		}
+	}
+
+	err = yamlError(line, "unterminated quoted string")
+	return
+}
+
+var yamlEscapes = map[byte]byte{'"': '"', '\\': '\\', 'n': '\n', 't': '\t'}
+
+// parseYAMLFlowSequence reads a list like [a, "b, c"] that fits on its line.
+func parseYAMLFlowSequence(line yamlLine, text string) (value interface{}, err error) {
+	items := []interface{}{}
+	rest := strings.TrimLeft(text[1:], " ")
+
+	for !strings.HasPrefix(rest, "]") {
+		var item string
+		if strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'") {
+			item, rest, err = parseYAMLQuoted(line, rest)
+			if err != nil {
+				return
+			}
+		} else {
+			end := strings.IndexAny(rest, ",]")
+			if end < 0 {
+				err = yamlError(line, "unterminated flow sequence")
+				return
+			}
+
+			item = strings.TrimSpace(rest[:end])
+			if item == "" {
+				err = yamlError(line, "empty item in flow sequence")
+				return
+			}
+			if strings.ContainsAny(item, "[{") {
+				err = yamlError(line, "nested flow collections are not supported")
+				return
+			}
+			rest = rest[end:]
+		}
+		items = append(items, item)
+
+		rest = strings.TrimLeft(rest, " ")
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimLeft(rest[1:], " ")
+		} else if !strings.HasPrefix(rest, "]") {
+			err = yamlError(line, "expected ',' or ']' in flow sequence")
+			return
+		}
+	}
+
+	if !isYAMLComment(rest[1:]) {
+		err = yamlError(line, "unexpected text after flow sequence")
+		return
+	}
+
+	value = items
+	return
+}
+
+// stripYAMLComment removes a comment from a plain value. A # only starts a
+// comment after a space, so that URLs keep their fragments.
+func stripYAMLComment(text string) string {
+	if strings.HasPrefix(text, "#") {
+		return ""
+	}
+
+	if index := strings.Index(text, " #"); index >= 0 {
+		text = text[:index]
+	}
+	return strings.TrimRight(text, " ")
+}
+
+func isYAMLComment(text string) bool {
+	return stripYAMLComment(strings.TrimLeft(text, " ")) == ""
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isMappingEntry(text string) bool {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") || strings.HasPrefix(text, "[") {
+		return false
+	}
+	return strings.HasSuffix(text, ":") || strings.Contains(text, ": ")
+}
+
+// splitMappingEntry returns the key of an entry and its value, which is empty
+// when the value is a block on the following lines.
+func splitMappingEntry(text string) (key, rest string) {
+	separator := strings.Index(text, ": ")
+	if separator < 0 {
+		key = strings.TrimSuffix(text, ":")
+		return
+	}
+
+	key = text[:separator]
+	rest = strings.TrimSpace(text[separator+2:])
+	if isYAMLComment(rest) {
+		rest = ""
+	}
+	return
+}
+
+func yamlError(line yamlLine, message string) error {
+	return fmt.Errorf("line %d: %s", line.number, message)
+}
@@ -20,6 +20,7 @@ func TestCloudControllerGatewayErrorHandling(t *testing.T) {
 
 	ts := httptest.NewTLSServer(http.HandlerFunc(failingCloudControllerRequest))
 	defer ts.Close()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	request, apiResponse := gateway.NewRequest("GET", ts.URL, "TOKEN", nil)
 	assert.False(t, apiResponse.IsNotSuccessful())
@@ -42,6 +43,7 @@ func TestCloudControllerGatewayInvalidTokenHandling(t *testing.T) {
 
 	ts := httptest.NewTLSServer(http.HandlerFunc(invalidTokenCloudControllerRequest))
 	defer ts.Close()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	request, apiResponse := gateway.NewRequest("GET", ts.URL, "TOKEN", nil)
 	assert.False(t, apiResponse.IsNotSuccessful())
@@ -3,12 +3,17 @@ package net
 import (
 	"bytes"
 	"cf"
+	"cf/configuration"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"runtime"
+	"syscall"
+	"time"
 )
 
 const INVALID_TOKEN_CODE = "GATEWAY INVALID TOKEN CODE"
@@ -26,11 +31,14 @@ type tokenRefresher interface {
 
 type Request struct {
 	*http.Request
+	getBody func() (io.ReadCloser, error)
 }
 
 type Gateway struct {
 	authenticator tokenRefresher
 	errHandler    errorHandler
+	config        *configuration.Configuration
+	trustedCerts  []tls.Certificate
 }
 
 func newGateway(errHandler errorHandler) (gateway Gateway) {
@@ -42,6 +50,22 @@ func (gateway *Gateway) SetTokenRefresher(auth tokenRefresher) {
 	gateway.authenticator = auth
 }
 
+// SetConfiguration gives the gateway access to the SSL settings of the
+// current target.
+func (gateway *Gateway) SetConfiguration(config *configuration.Configuration) {
+	gateway.config = config
+}
+
+// SetTrustedCerts adds certificates that are trusted in addition to the
+// system and configured CA certificates.
+func (gateway *Gateway) SetTrustedCerts(certs []tls.Certificate) {
+	gateway.trustedCerts = certs
+}
+
+func (gateway Gateway) TLSConfig() (tlsConfig *tls.Config, err error) {
+	return newTLSConfig(gateway.config, gateway.trustedCerts)
+}
+
 func (gateway Gateway) NewRequest(method, path, accessToken string, body io.Reader) (req *Request, apiResponse ApiResponse) {
 	request, err := http.NewRequest(method, path, body)
 	if err != nil {
@@ -55,7 +79,22 @@ func (gateway Gateway) NewRequest(method, path, accessToken string, body io.Read
 
 	request.Header.Set("accept", "application/json")
 	request.Header.Set("User-Agent", "go-cli "+cf.Version+" / "+runtime.GOOS)
-	req = &Request{request}
+	req = &Request{Request: request}
+	return
+}
+
+// NewStreamingRequest builds a request whose body is produced by getBody
+// while it is being sent instead of being held in memory. Attempts that fail
+// with a transient network error are retried by rebuilding the body from the
+// start: getBody is called again for every attempt, so it has to produce the
+// same body each time, and nothing that was already sent is reused.
+func (gateway Gateway) NewStreamingRequest(method, path, accessToken string, getBody func() (io.ReadCloser, error)) (req *Request, apiResponse ApiResponse) {
+	req, apiResponse = gateway.NewRequest(method, path, accessToken, nil)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	req.getBody = getBody
 	return
 }
 
@@ -65,18 +104,33 @@ func (gateway Gateway) PerformRequest(request *Request) (apiResponse ApiResponse
 }
 
 func (gateway Gateway) PerformRequestForResponseBytes(request *Request) (bytes []byte, headers http.Header, apiResponse ApiResponse) {
-	rawResponse, apiResponse := gateway.doRequestHandlingAuth(request)
+	body, headers, apiResponse := gateway.PerformRequestForResponseBody(request)
 	if apiResponse.IsNotSuccessful() {
 		return
 	}
+	defer body.Close()
 
-	bytes, err := ioutil.ReadAll(rawResponse.Body)
+	bytes, err := ioutil.ReadAll(body)
 	if err != nil {
 		apiResponse = NewApiStatusWithError("Error reading response", err)
 	}
 	return
 }
 
+// PerformRequestForResponseBody returns the body of the response as it is
+// received, so that large responses need not be held in memory. The caller
+// has to close body.
+func (gateway Gateway) PerformRequestForResponseBody(request *Request) (body io.ReadCloser, headers http.Header, apiResponse ApiResponse) {
+	rawResponse, apiResponse := gateway.doRequestHandlingAuth(request)
+	if apiResponse.IsNotSuccessful() {
+		return
+	}
+
+	body = rawResponse.Body
+	headers = rawResponse.Header
+	return
+}
+
 func (gateway Gateway) PerformRequestForTextResponse(request *Request) (response string, headers http.Header, apiResponse ApiResponse) {
 	bytes, headers, apiResponse := gateway.PerformRequestForResponseBytes(request)
 	response = string(bytes)
@@ -98,14 +152,20 @@ func (gateway Gateway) PerformRequestForJSONResponse(request *Request, response
 
 func (gateway Gateway) doRequestHandlingAuth(request *Request) (response *http.Response, apiResponse ApiResponse) {
 	var bodyBytes []byte
-	if request.Body != nil {
+	if request.getBody == nil && request.Body != nil {
 		bodyBytes, _ = ioutil.ReadAll(request.Body)
 		request.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
 	}
 
-	response, err := doRequest(request.Request)
+	tlsConfig, err := gateway.TLSConfig()
+	if err != nil {
+		apiResponse = NewApiStatusWithError("Error loading trusted CA certificates", err)
+		return
+	}
+
+	response, err = gateway.send(request, tlsConfig)
 	if err != nil {
-		apiResponse = NewApiStatusWithError("Error performing request", err)
+		apiResponse = newApiStatusForRequestError(err, request.URL.Host)
 		return
 	}
 
@@ -141,9 +201,47 @@ func (gateway Gateway) doRequestHandlingAuth(request *Request) (response *http.R
 	}
 
 	// make the request again
-	response, err = doRequest(request.Request)
+	response, err = gateway.send(request, tlsConfig)
 	if err != nil {
-		apiResponse = NewApiStatusWithError("Error performing request", err)
+		apiResponse = newApiStatusForRequestError(err, request.URL.Host)
 	}
 	return
 }
+
+const streamingRequestAttempts = 3
+
+var streamingRetryDelay = 1 * time.Second
+
+func (gateway Gateway) send(request *Request, tlsConfig *tls.Config) (response *http.Response, err error) {
+	if request.getBody == nil {
+		return doRequest(request.Request, tlsConfig)
+	}
+
+	for attempt := 1; ; attempt++ {
+		request.Body, err = request.getBody()
+		if err != nil {
+			return
+		}
+
+		response, err = doRequest(request.Request, tlsConfig)
+		if err == nil || attempt == streamingRequestAttempts || !isTransientError(err) {
+			return
+		}
+
+		time.Sleep(time.Duration(attempt) * streamingRetryDelay)
+	}
+}
+
+func isTransientError(err error) bool {
+	var timeoutErr interface {
+		Timeout() bool
+	}
+	if errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
@@ -5,11 +5,14 @@ import (
 	"cf/api"
 	"cf/configuration"
 	. "cf/net"
+	"encoding/pem"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"runtime"
 	"strings"
 	"testhelpers"
@@ -76,6 +79,7 @@ func TestRefreshingTheTokenWithUAARequest(t *testing.T) {
 	configRepo, auth := createAuthenticationRepository(t, uaaServer, authServer)
 
 	gateway := NewUAAGateway()
+	gateway.SetTrustedCerts(uaaServer.TLS.Certificates)
 	gateway.SetTokenRefresher(auth)
 
 	testRefreshToken(t, configRepo, gateway)
@@ -93,6 +97,7 @@ func TestRefreshingTheTokenWithCloudControllerRequest(t *testing.T) {
 	configRepo, auth := createAuthenticationRepository(t, ccServer, authServer)
 
 	gateway := NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ccServer.TLS.Certificates)
 	gateway.SetTokenRefresher(auth)
 
 	testRefreshToken(t, configRepo, gateway)
@@ -110,6 +115,7 @@ func createAuthenticationRepository(t *testing.T, apiServer *httptest.Server, au
 	config.RefreshToken = "initial-refresh-token"
 
 	authGateway := NewUAAGateway()
+	authGateway.SetTrustedCerts(authServer.TLS.Certificates)
 	authenticator := api.NewUAAAuthenticationRepository(authGateway, configRepo)
 
 	return configRepo, authenticator
@@ -129,3 +135,165 @@ func testRefreshToken(t *testing.T, configRepo configuration.ConfigurationReposi
 	assert.Equal(t, savedConfig.AccessToken, "bearer new-access-token")
 	assert.Equal(t, savedConfig.RefreshToken, "new-refresh-token")
 }
+
+var successfulRequest = func(writer http.ResponseWriter, request *http.Request) {
+	fmt.Fprintln(writer, "{}")
+}
+
+func TestRequestsFailForUntrustedCertificates(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(successfulRequest))
+	defer ts.Close()
+
+	gateway := NewCloudControllerGateway()
+
+	request, apiResponse := gateway.NewRequest("GET", ts.URL, "TOKEN", nil)
+	assert.False(t, apiResponse.IsNotSuccessful())
+
+	apiResponse = gateway.PerformRequest(request)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Contains(t, apiResponse.Message, "Invalid SSL Cert")
+	assert.Contains(t, apiResponse.Message, "unknown authority")
+	assert.Contains(t, apiResponse.Message, "--skip-ssl-validation")
+}
+
+func TestRequestsFailWhenCertificateDoesNotMatchHostName(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(successfulRequest))
+	defer ts.Close()
+
+	gateway := NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+
+	url := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+	request, apiResponse := gateway.NewRequest("GET", url, "TOKEN", nil)
+	assert.False(t, apiResponse.IsNotSuccessful())
+
+	apiResponse = gateway.PerformRequest(request)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Contains(t, apiResponse.Message, "Invalid SSL Cert")
+	assert.Contains(t, apiResponse.Message, "not valid for this host name")
+}
+
+func TestRequestsSucceedWhenSSLValidationIsDisabled(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(successfulRequest))
+	defer ts.Close()
+
+	gateway := NewCloudControllerGateway()
+	gateway.SetConfiguration(&configuration.Configuration{SSLDisabled: true})
+
+	request, apiResponse := gateway.NewRequest("GET", ts.URL, "TOKEN", nil)
+	assert.False(t, apiResponse.IsNotSuccessful())
+
+	apiResponse = gateway.PerformRequest(request)
+	assert.False(t, apiResponse.IsNotSuccessful())
+}
+
+func TestRequestsTrustTheConfiguredCACertFile(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(successfulRequest))
+	defer ts.Close()
+
+	caCertFile, err := ioutil.TempFile("", "ca-cert")
+	assert.NoError(t, err)
+	defer os.Remove(caCertFile.Name())
+
+	pem.Encode(caCertFile, &pem.Block{Type: "CERTIFICATE", Bytes: ts.TLS.Certificates[0].Certificate[0]})
+	caCertFile.Close()
+
+	gateway := NewCloudControllerGateway()
+	gateway.SetConfiguration(&configuration.Configuration{CACertFile: caCertFile.Name()})
+
+	request, apiResponse := gateway.NewRequest("GET", ts.URL, "TOKEN", nil)
+	assert.False(t, apiResponse.IsNotSuccessful())
+
+	apiResponse = gateway.PerformRequest(request)
+	assert.False(t, apiResponse.IsNotSuccessful())
+}
+
+func TestRequestsTrustTheCACertFileFromTheEnvironment(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(successfulRequest))
+	defer ts.Close()
+
+	caCertFile, err := ioutil.TempFile("", "ca-cert")
+	assert.NoError(t, err)
+	defer os.Remove(caCertFile.Name())
+
+	pem.Encode(caCertFile, &pem.Block{Type: "CERTIFICATE", Bytes: ts.TLS.Certificates[0].Certificate[0]})
+	caCertFile.Close()
+
+	os.Setenv(CA_CERT_FILE_ENV, caCertFile.Name())
+	defer os.Setenv(CA_CERT_FILE_ENV, "")
+
+	gateway := NewCloudControllerGateway()
+
+	request, apiResponse := gateway.NewRequest("GET", ts.URL, "TOKEN", nil)
+	assert.False(t, apiResponse.IsNotSuccessful())
+
+	apiResponse = gateway.PerformRequest(request)
+	assert.False(t, apiResponse.IsNotSuccessful())
+}
+
+func TestRequestsFailWhenTheCACertFileIsInvalid(t *testing.T) {
+	gateway := NewCloudControllerGateway()
+	gateway.SetConfiguration(&configuration.Configuration{CACertFile: "/does/not/exist.pem"})
+
+	request, apiResponse := gateway.NewRequest("GET", "https://127.0.0.1", "TOKEN", nil)
+	assert.False(t, apiResponse.IsNotSuccessful())
+
+	apiResponse = gateway.PerformRequest(request)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Contains(t, apiResponse.Message, "Error loading trusted CA certificates")
+}
+
+func TestStreamingRequestsAreRetriedAfterTransientNetworkErrors(t *testing.T) {
+	attempts := 0
+	receivedBody := ""
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts++
+		if attempts == 1 {
+			conn, _, err := writer.(http.Hijacker).Hijack()
+			assert.NoError(t, err)
+			conn.Close()
+			return
+		}
+
+		bodyBytes, _ := ioutil.ReadAll(request.Body)
+		receivedBody = string(bodyBytes)
+		writer.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	gateway := NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+
+	bodiesCreated := 0
+	request, apiResponse := gateway.NewStreamingRequest("PUT", ts.URL, "TOKEN", func() (io.ReadCloser, error) {
+		bodiesCreated++
+		return ioutil.NopCloser(strings.NewReader("streamed body")), nil
+	})
+	assert.False(t, apiResponse.IsNotSuccessful())
+
+	apiResponse = gateway.PerformRequest(request)
+	assert.False(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, attempts, 2)
+	assert.Equal(t, bodiesCreated, 2)
+	assert.Equal(t, receivedBody, "streamed body")
+}
+
+func TestStreamingRequestsAreNotRetriedAfterErrorResponses(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	gateway := NewCloudControllerGateway()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
+
+	bodiesCreated := 0
+	request, _ := gateway.NewStreamingRequest("PUT", ts.URL, "TOKEN", func() (io.ReadCloser, error) {
+		bodiesCreated++
+		return ioutil.NopCloser(strings.NewReader("streamed body")), nil
+	})
+
+	apiResponse := gateway.PerformRequest(request)
+	assert.True(t, apiResponse.IsNotSuccessful())
+	assert.Equal(t, bodiesCreated, 1)
+}
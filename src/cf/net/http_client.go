@@ -16,9 +16,9 @@ const (
 	PRIVATE_DATA_PLACEHOLDER = "[PRIVATE DATA HIDDEN]"
 )
 
-func newHttpClient() *http.Client {
+func newHttpClient(tlsConfig *tls.Config) *http.Client {
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		TLSClientConfig: tlsConfig,
 		Proxy:           http.ProxyFromEnvironment,
 	}
 	return &http.Client{
@@ -55,8 +55,8 @@ func Sanitize(input string) (sanitized string) {
 	return
 }
 
-func doRequest(request *http.Request) (response *http.Response, err error) {
-	httpClient := newHttpClient()
+func doRequest(request *http.Request, tlsConfig *tls.Config) (response *http.Response, err error) {
+	httpClient := newHttpClient(tlsConfig)
 
 	if traceEnabled() {
 		dumpRequest(request)
@@ -0,0 +1,94 @@
+package net
+
+import (
+	"cf"
+	"cf/configuration"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+const CA_CERT_FILE_ENV = "CF_CA_CERT_FILE"
+
+// newTLSConfig verifies server certificates unless SSL validation was
+// disabled for the target. Certificates from the configured CA file, the
+// CF_CA_CERT_FILE environment variable and trustedCerts are trusted on top of
+// the system roots.
+func newTLSConfig(config *configuration.Configuration, trustedCerts []tls.Certificate) (tlsConfig *tls.Config, err error) {
+	tlsConfig = &tls.Config{}
+
+	if config != nil && config.SSLDisabled {
+		tlsConfig.InsecureSkipVerify = true
+		return
+	}
+
+	caCertFiles := []string{}
+	if config != nil && config.CACertFile != "" {
+		caCertFiles = append(caCertFiles, config.CACertFile)
+	}
+	if os.Getenv(CA_CERT_FILE_ENV) != "" {
+		caCertFiles = append(caCertFiles, os.Getenv(CA_CERT_FILE_ENV))
+	}
+
+	if len(caCertFiles) == 0 && len(trustedCerts) == 0 {
+		return
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+		err = nil
+	}
+
+	for _, caCertFile := range caCertFiles {
+		var pemBytes []byte
+		pemBytes, err = ioutil.ReadFile(caCertFile)
+		if err != nil {
+			return
+		}
+
+		if !rootCAs.AppendCertsFromPEM(pemBytes) {
+			err = errors.New(fmt.Sprintf("No PEM encoded certificates found in %s", caCertFile))
+			return
+		}
+	}
+
+	for _, trustedCert := range trustedCerts {
+		for _, certBytes := range trustedCert.Certificate {
+			var cert *x509.Certificate
+			cert, err = x509.ParseCertificate(certBytes)
+			if err != nil {
+				return
+			}
+			rootCAs.AddCert(cert)
+		}
+	}
+
+	tlsConfig.RootCAs = rootCAs
+	return
+}
+
+func newApiStatusForRequestError(err error, host string) (apiResponse ApiResponse) {
+	tip := fmt.Sprintf("TIP: Use '%s api --skip-ssl-validation' to continue with an insecure API endpoint", cf.Name)
+
+	var invalidErr x509.CertificateInvalidError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+
+	switch {
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		apiResponse = NewApiStatusWithMessage("Invalid SSL Cert for %s: the certificate has expired or is not yet valid\n%s", host, tip)
+	case errors.As(err, &authorityErr):
+		apiResponse = NewApiStatusWithMessage(
+			"Invalid SSL Cert for %s: the certificate is signed by an unknown authority\nTIP: Add the CA certificate to %s, or use '%s api --skip-ssl-validation' to continue with an insecure API endpoint",
+			host, CA_CERT_FILE_ENV, cf.Name)
+	case errors.As(err, &hostnameErr):
+		apiResponse = NewApiStatusWithMessage("Invalid SSL Cert for %s: the certificate is not valid for this host name\n%s", host, tip)
+	default:
+		apiResponse = NewApiStatusWithError("Error performing request", err)
+	}
+	return
+}
@@ -20,6 +20,7 @@ func TestUAAGatewayErrorHandling(t *testing.T) {
 
 	ts := httptest.NewTLSServer(http.HandlerFunc(failingUAARequest))
 	defer ts.Close()
+	gateway.SetTrustedCerts(ts.TLS.Certificates)
 
 	request, apiResponse := gateway.NewRequest("GET", ts.URL, "TOKEN", nil)
 	assert.False(t, apiResponse.IsNotSuccessful())
@@ -32,7 +32,7 @@ func (req *ApplicationApiRequirement) Execute() (success bool) {
 	req.application, apiResponse = req.appRepo.FindByName(req.name)
 
 	if apiResponse.IsNotSuccessful() {
-		req.ui.Failed(apiResponse.Message)
+		req.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return false
 	}
 
@@ -32,7 +32,7 @@ func (req *DomainApiRequirement) Execute() bool {
 	req.domain, apiResponse = req.domainRepo.FindByNameInCurrentSpace(req.name)
 
 	if apiResponse.IsNotSuccessful() {
-		req.ui.Failed(apiResponse.Message)
+		req.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return false
 	}
 
@@ -16,7 +16,7 @@ func NewLoginRequirement(ui terminal.UI, config *configuration.Configuration) Lo
 
 func (req LoginRequirement) Execute() (success bool) {
 	if !req.config.IsLoggedIn() {
-		req.ui.Say(terminal.NotLoggedInText())
+		req.ui.ApiFailure(terminal.NotLoggedInText(), "", 0)
 		return false
 	}
 	return true
@@ -25,5 +25,6 @@ func TestLoginRequirement(t *testing.T) {
 	req = NewLoginRequirement(ui, config)
 	success = req.Execute()
 	assert.False(t, success)
-	assert.Contains(t, ui.Outputs[0], "Not logged in.")
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "Not logged in.")
 }
@@ -32,7 +32,7 @@ func (req *OrganizationApiRequirement) Execute() (success bool) {
 	req.org, apiResponse = req.orgRepo.FindByName(req.name)
 
 	if apiResponse.IsNotSuccessful() {
-		req.ui.Failed(apiResponse.Message)
+		req.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return false
 	}
 
@@ -34,7 +34,7 @@ func (req *RouteApiRequirement) Execute() bool {
 	req.route, apiResponse = req.routeRepo.FindByHostAndDomain(req.host, req.domain)
 
 	if apiResponse.IsNotSuccessful() {
-		req.ui.Failed(apiResponse.Message)
+		req.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return false
 	}
 
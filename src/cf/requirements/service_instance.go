@@ -32,7 +32,7 @@ func (req *ServiceInstanceApiRequirement) Execute() (success bool) {
 	req.serviceInstance, apiResponse = req.serviceRepo.FindInstanceByName(req.name)
 
 	if apiResponse.IsNotSuccessful() {
-		req.ui.Failed(apiResponse.Message)
+		req.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return false
 	}
 
@@ -32,7 +32,7 @@ func (req *SpaceApiRequirement) Execute() (success bool) {
 	req.space, apiResponse = req.spaceRepo.FindByName(req.name)
 
 	if apiResponse.IsNotSuccessful() {
-		req.ui.Failed(apiResponse.Message)
+		req.ui.ApiFailure(apiResponse.Message, apiResponse.ErrorCode, apiResponse.StatusCode)
 		return false
 	}
 
@@ -0,0 +1,32 @@
+package requirements_test
+
+import (
+	"cf"
+	. "cf/requirements"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestUserReqExecute(t *testing.T) {
+	user := cf.User{Username: "my-user", Guid: "my-user-guid"}
+	userRepo := &testhelpers.FakeUserRepository{FindByUsernameUser: user}
+	ui := new(testhelpers.FakeUI)
+
+	userReq := NewUserRequirement("my-user", ui, userRepo)
+	success := userReq.Execute()
+
+	assert.True(t, success)
+	assert.Equal(t, userRepo.FindByUsernameUsername, "my-user")
+	assert.Equal(t, userReq.GetUser(), user)
+}
+
+func TestUserReqWhenUserDoesNotExist(t *testing.T) {
+	userRepo := &testhelpers.FakeUserRepository{FindByUsernameNotFound: true}
+	ui := new(testhelpers.FakeUI)
+
+	userReq := NewUserRequirement("my-user", ui, userRepo)
+	success := userReq.Execute()
+
+	assert.False(t, success)
+}
@@ -18,7 +18,7 @@ func (req ValidAccessTokenRequirement) Execute() (success bool) {
 	_, apiResponse := req.appRepo.FindByName("checking_for_valid_access_token")
 
 	if apiResponse.IsNotSuccessful() && apiResponse.StatusCode == 401 {
-		req.ui.Say(terminal.NotLoggedInText())
+		req.ui.ApiFailure(terminal.NotLoggedInText(), apiResponse.ErrorCode, apiResponse.StatusCode)
 		return false
 	}
 
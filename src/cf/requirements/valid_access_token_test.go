@@ -16,7 +16,8 @@ func TestValidAccessRequirement(t *testing.T) {
 	req := NewValidAccessTokenRequirement(ui, appRepo)
 	success := req.Execute()
 	assert.False(t, success)
-	assert.Contains(t, ui.Outputs[0], "Not logged in.")
+	assert.Contains(t, ui.Outputs[0], "FAILED")
+	assert.Contains(t, ui.Outputs[1], "Not logged in.")
 
 	appRepo.FindByNameAuthErr = false
 
@@ -0,0 +1,195 @@
+package cf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// racyFingerprintWindow is how recently a file may have been modified before
+// it was fingerprinted for its fingerprint to still be remembered. A file
+// changed again within the resolution of the file system clock keeps its
+// modification time, so a fingerprint taken in that window cannot be trusted
+// later on.
+const racyFingerprintWindow = 2 * time.Second
+
+// ResourceCache remembers between pushes of an app the SHA1 of each of its
+// files, and which of those SHA1s the cloud controller already has, so that
+// files that did not change are neither read again nor offered to
+// resource_match.
+//
+// A SHA1 is only reused when the path, size and modification time of the
+// file are the same as when it was computed. Matches are only trusted for the
+// app directory and target they were confirmed for.
+type ResourceCache struct {
+	file    string
+	dir     string
+	target  string
+	files   map[string]cachedFingerprint
+	matched map[string]bool
+
+	mutex sync.Mutex
+	fresh map[string]cachedFingerprint
+}
+
+type resourceCacheFile struct {
+	Dir     string                       `json:"dir"`
+	Target  string                       `json:"target"`
+	Files   map[string]cachedFingerprint `json:"files"`
+	Matched []string                     `json:"matched"`
+}
+
+type cachedFingerprint struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Sha1    string `json:"sha1"`
+}
+
+// LoadResourceCache reads the cache kept in file for the app in appDir. A
+// cache that is missing or cannot be read is not an error, the push just
+// starts over with an empty one.
+func LoadResourceCache(file, appDir, target string) (cache *ResourceCache) {
+	cache = &ResourceCache{
+		file:    file,
+		dir:     appDir,
+		target:  target,
+		files:   map[string]cachedFingerprint{},
+		matched: map[string]bool{},
+	}
+
+	if absDir, err := filepath.Abs(appDir); err == nil {
+		cache.dir = absDir
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	saved := resourceCacheFile{}
+	err = json.Unmarshal(data, &saved)
+	if err != nil || saved.Dir != cache.dir {
+		return
+	}
+
+	if saved.Files != nil {
+		cache.files = saved.Files
+	}
+
+	if saved.Target == target {
+		for _, sha1 := range saved.Matched {
+			cache.matched[sha1] = true
+		}
+	}
+	return
+}
+
+// IsMatched tells whether the cloud controller confirmed it has a file with
+// the given SHA1 on an earlier push.
+func (cache *ResourceCache) IsMatched(sha1 string) bool {
+	return cache.matched[sha1]
+}
+
+// SetMatched replaces the SHA1s the cloud controller is known to have.
+func (cache *ResourceCache) SetMatched(sha1s []string) {
+	cache.matched = map[string]bool{}
+	for _, sha1 := range sha1s {
+		cache.matched[sha1] = true
+	}
+}
+
+// ForgetMatched drops every match, for when the cloud controller no longer
+// has a file it confirmed before.
+func (cache *ResourceCache) ForgetMatched() {
+	cache.matched = map[string]bool{}
+}
+
+// Save writes the cache to its file. It is written to a temporary file first
+// and renamed into place, so an interrupted push cannot leave a partial cache
+// behind.
+func (cache *ResourceCache) Save() (err error) {
+	saved := resourceCacheFile{
+		Dir:     cache.dir,
+		Target:  cache.target,
+		Files:   cache.files,
+		Matched: []string{},
+	}
+	for sha1 := range cache.matched {
+		saved.Matched = append(saved.Matched, sha1)
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(cache.file), 0700)
+	if err != nil {
+		return
+	}
+
+	tempFile, err := ioutil.TempFile(filepath.Dir(cache.file), filepath.Base(cache.file)+".tmp")
+	if err != nil {
+		return
+	}
+
+	_, err = tempFile.Write(data)
+	closeErr := tempFile.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), cache.file)
+	}
+	if err != nil {
+		os.Remove(tempFile.Name())
+	}
+	return
+}
+
+func (cache *ResourceCache) beginFingerprinting() {
+	cache.fresh = map[string]cachedFingerprint{}
+}
+
+// endFingerprinting keeps only the fingerprints of the files that were just
+// listed, so files that were deleted do not pile up in the cache.
+func (cache *ResourceCache) endFingerprinting() {
+	cache.files = cache.fresh
+	cache.fresh = nil
+}
+
+// fingerprint fills in the size and SHA1 of appFile, reusing the remembered
+// SHA1 when the file has not changed since it was computed.
+func (cache *ResourceCache) fingerprint(appFile *AppFile, fullPath string, startedAt time.Time) (err error) {
+	fileInfo, err := os.Stat(fullPath)
+	if err != nil {
+		return
+	}
+
+	key := filepath.ToSlash(appFile.Path)
+	modTime := fileInfo.ModTime().UnixNano()
+
+	cached, found := cache.files[key]
+	if found && cached.Size == fileInfo.Size() && cached.ModTime == modTime {
+		appFile.Size = cached.Size
+		appFile.Sha1 = cached.Sha1
+	} else {
+		err = fingerprint(appFile, fullPath)
+		if err != nil {
+			return
+		}
+		cached = cachedFingerprint{Size: appFile.Size, ModTime: modTime, Sha1: appFile.Sha1}
+	}
+
+	if appFile.Size != fileInfo.Size() || !fileInfo.ModTime().Before(startedAt.Add(-racyFingerprintWindow)) {
+		return
+	}
+
+	cache.mutex.Lock()
+	cache.fresh[key] = cached
+	cache.mutex.Unlock()
+	return
+}
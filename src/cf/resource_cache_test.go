@@ -0,0 +1,137 @@
+package cf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createAgedFile(t *testing.T, path, contents string, modTime time.Time) {
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func sha1sByPath(appFiles []AppFile) (sha1s map[string]string) {
+	sha1s = map[string]string{}
+	for _, file := range appFiles {
+		sha1s[filepath.ToSlash(file.Path)] = file.Sha1
+	}
+	return
+}
+
+func TestResourceCacheReusesFingerprintsOfUnchangedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resource-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	appDir := filepath.Join(dir, "app")
+	cacheFile := filepath.Join(dir, "cache", "my-app-guid.json")
+	assert.NoError(t, os.MkdirAll(appDir, 0755))
+
+	aWhileAgo := time.Now().Add(-time.Hour)
+	createAgedFile(t, filepath.Join(appDir, "same.txt"), "same", aWhileAgo)
+	createAgedFile(t, filepath.Join(appDir, "touched.txt"), "touched", aWhileAgo)
+
+	cache := LoadResourceCache(cacheFile, appDir, "https://api.example.com")
+	appFiles, err := AppFilesInDirWithCache(appDir, cache)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Save())
+	before := sha1sByPath(appFiles)
+
+	// Same size and modification time: the remembered SHA1 is trusted
+	createAgedFile(t, filepath.Join(appDir, "same.txt"), "SAME", aWhileAgo)
+	// Same size, new modification time: the file is read again
+	createAgedFile(t, filepath.Join(appDir, "touched.txt"), "TOUCHED", aWhileAgo.Add(time.Minute))
+
+	cache = LoadResourceCache(cacheFile, appDir, "https://api.example.com")
+	appFiles, err = AppFilesInDirWithCache(appDir, cache)
+	assert.NoError(t, err)
+	after := sha1sByPath(appFiles)
+
+	assert.Equal(t, after["same.txt"], before["same.txt"])
+	assert.NotEqual(t, after["touched.txt"], before["touched.txt"])
+	assert.Equal(t, after["touched.txt"], "22cb73a2e000ad0ce996232a75df151dbd3bcdff")
+}
+
+func TestResourceCacheDoesNotRememberRecentlyModifiedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resource-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	appDir := filepath.Join(dir, "app")
+	cacheFile := filepath.Join(dir, "my-app-guid.json")
+	assert.NoError(t, os.MkdirAll(appDir, 0755))
+
+	createAgedFile(t, filepath.Join(appDir, "old.txt"), "old", time.Now().Add(-time.Hour))
+	createAgedFile(t, filepath.Join(appDir, "new.txt"), "new", time.Now())
+
+	cache := LoadResourceCache(cacheFile, appDir, "https://api.example.com")
+	_, err = AppFilesInDirWithCache(appDir, cache)
+	assert.NoError(t, err)
+
+	_, found := cache.files["old.txt"]
+	assert.True(t, found)
+	_, found = cache.files["new.txt"]
+	assert.False(t, found)
+}
+
+func TestResourceCacheForgetsDeletedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resource-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	aWhileAgo := time.Now().Add(-time.Hour)
+	createAgedFile(t, filepath.Join(dir, "kept.txt"), "kept", aWhileAgo)
+	createAgedFile(t, filepath.Join(dir, "deleted.txt"), "deleted", aWhileAgo)
+
+	cache := LoadResourceCache(filepath.Join(os.TempDir(), "unused.json"), dir, "")
+	_, err = AppFilesInDirWithCache(dir, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, len(cache.files), 2)
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "deleted.txt")))
+	_, err = AppFilesInDirWithCache(dir, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, len(cache.files), 1)
+}
+
+func TestLoadResourceCacheKeepsMatchesOnlyForTheSameTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resource-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cacheFile := filepath.Join(dir, "my-app-guid.json")
+	cache := LoadResourceCache(cacheFile, dir, "https://api.example.com")
+	cache.SetMatched([]string{"my-sha1"})
+	assert.NoError(t, cache.Save())
+
+	cache = LoadResourceCache(cacheFile, dir, "https://api.example.com")
+	assert.True(t, cache.IsMatched("my-sha1"))
+
+	cache = LoadResourceCache(cacheFile, dir, "https://api.other.example.com")
+	assert.False(t, cache.IsMatched("my-sha1"))
+}
+
+func TestLoadResourceCacheStartsOverForAnotherDirOrACorruptFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resource-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cacheFile := filepath.Join(dir, "my-app-guid.json")
+	cache := LoadResourceCache(cacheFile, dir, "https://api.example.com")
+	cache.files["file.txt"] = cachedFingerprint{Size: 1, ModTime: 1, Sha1: "my-sha1"}
+	cache.SetMatched([]string{"my-sha1"})
+	assert.NoError(t, cache.Save())
+
+	cache = LoadResourceCache(cacheFile, filepath.Join(dir, "other"), "https://api.example.com")
+	assert.Equal(t, len(cache.files), 0)
+	assert.False(t, cache.IsMatched("my-sha1"))
+
+	assert.NoError(t, ioutil.WriteFile(cacheFile, []byte(`{"dir": `), 0600))
+	cache = LoadResourceCache(cacheFile, dir, "https://api.example.com")
+	assert.Equal(t, len(cache.files), 0)
+	assert.False(t, cache.IsMatched("my-sha1"))
+}
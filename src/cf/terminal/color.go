@@ -74,3 +74,11 @@ func TableContentHeaderColor(message string) string {
 func WarningColor(message string) string {
 	return colorize(message, magenta, true)
 }
+
+var appNameColors = []Color{cyan, green, yellow, magenta, red, grey}
+
+// AppNameColor gives the index-th of several apps a color of its own, so the
+// output of each app stands out when they are shown together.
+func AppNameColor(message string, index int) string {
+	return colorize(message, appNameColors[index%len(appNameColors)], true)
+}
@@ -0,0 +1,102 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+type OutputFormat string
+
+const (
+	TextOutput OutputFormat = ""
+	JSONOutput              = "json"
+	YAMLOutput              = "yaml"
+)
+
+func ParseOutputFormat(name string) (format OutputFormat, err error) {
+	switch strings.ToLower(name) {
+	case "", "text":
+		format = TextOutput
+	case "json":
+		format = JSONOutput
+	case "yaml":
+		format = YAMLOutput
+	default:
+		err = errors.New(fmt.Sprintf("Unknown output format %s. Use json or yaml.", name))
+	}
+	return
+}
+
+type errorDocument struct {
+	Error errorDetails `json:"error"`
+}
+
+type errorDetails struct {
+	Description string `json:"description"`
+	Code        string `json:"code,omitempty"`
+	Status      int    `json:"status,omitempty"`
+}
+
+type targetDocument struct {
+	ApiEndpoint  string         `json:"api_endpoint"`
+	ApiVersion   string         `json:"api_version"`
+	LoggedIn     bool           `json:"logged_in"`
+	User         string         `json:"user"`
+	Organization targetedEntity `json:"organization"`
+	Space        targetedEntity `json:"space"`
+}
+
+type targetedEntity struct {
+	Name string `json:"name"`
+	Guid string `json:"guid"`
+}
+
+func formatData(format OutputFormat, data interface{}) (output string, err error) {
+	// Empty lists and maps should come out as [] and {}, not null.
+	value := reflect.ValueOf(data)
+	switch {
+	case value.Kind() == reflect.Slice && value.IsNil():
+		data = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	case value.Kind() == reflect.Map && value.IsNil():
+		data = reflect.MakeMap(value.Type()).Interface()
+	}
+
+	bytes, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return
+	}
+
+	if format != YAMLOutput {
+		output = string(bytes) + "\n"
+		return
+	}
+
+	return jsonToYAML(bytes)
+}
+
+var colorCodes = regexp.MustCompile("\033\\[[0-9;]*m")
+
+func decolorize(message string) string {
+	return colorCodes.ReplaceAllString(message, "")
+}
+
+func jsonToYAML(data []byte) (output string, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	writeYAML(buffer, value, "")
+	output = buffer.String()
+	return
+}
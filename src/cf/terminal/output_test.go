@@ -0,0 +1,118 @@
+package terminal_test
+
+import (
+	"cf"
+	"cf/configuration"
+	"cf/terminal"
+	"github.com/stretchr/testify/assert"
+	"testhelpers"
+	"testing"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	format, err := terminal.ParseOutputFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, format, terminal.TextOutput)
+
+	format, err = terminal.ParseOutputFormat("JSON")
+	assert.NoError(t, err)
+	assert.Equal(t, format, terminal.OutputFormat(terminal.JSONOutput))
+
+	format, err = terminal.ParseOutputFormat("yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, format, terminal.OutputFormat(terminal.YAMLOutput))
+
+	_, err = terminal.ParseOutputFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestStructuredOutputKeepsMessagesOffStdout(t *testing.T) {
+	ui := terminal.TerminalUI{OutputFormat: terminal.JSONOutput}
+
+	out := testhelpers.CaptureOutput(func() {
+		ui.Say("Getting apps...")
+		ui.Ok()
+	})
+
+	assert.Equal(t, out, "")
+}
+
+func TestDisplayDataAsJSON(t *testing.T) {
+	ui := terminal.TerminalUI{OutputFormat: terminal.JSONOutput}
+	apps := []cf.Application{
+		cf.Application{Name: "my-app", Guid: "my-app-guid", Urls: []string{"my-app.example.com"}},
+	}
+
+	out := testhelpers.CaptureOutput(func() {
+		ui.DisplayData(apps)
+	})
+
+	assert.Contains(t, out, `"name": "my-app"`)
+	assert.Contains(t, out, `"guid": "my-app-guid"`)
+	assert.Contains(t, out, `"urls": [
+      "my-app.example.com"
+    ]`)
+}
+
+func TestDisplayDataWithNoItems(t *testing.T) {
+	ui := terminal.TerminalUI{OutputFormat: terminal.JSONOutput}
+
+	out := testhelpers.CaptureOutput(func() {
+		var apps []cf.Application
+		ui.DisplayData(apps)
+	})
+
+	assert.Equal(t, out, "[]\n")
+}
+
+func TestDisplayDataAsYAML(t *testing.T) {
+	ui := terminal.TerminalUI{OutputFormat: terminal.YAMLOutput}
+	routes := []cf.Route{
+		cf.Route{
+			Host:     "my-host",
+			Guid:     "route-guid",
+			Domain:   cf.Domain{Name: "example.com", Guid: "domain-guid"},
+			AppNames: []string{"app1", "true"},
+		},
+		cf.Route{Host: "", Guid: "other-route-guid"},
+	}
+
+	out := testhelpers.CaptureOutput(func() {
+		ui.DisplayData(routes)
+	})
+
+	assert.Equal(t, out, `- apps:
+  - app1
+  - "true"
+  domain:
+    guid: domain-guid
+    name: example.com
+    shared: false
+  guid: route-guid
+  host: my-host
+- apps: null
+  domain:
+    guid: ""
+    name: ""
+    shared: false
+  guid: other-route-guid
+  host: ""
+`)
+}
+
+func TestShowConfigurationWithStructuredOutput(t *testing.T) {
+	ui := terminal.TerminalUI{OutputFormat: terminal.JSONOutput}
+	config := &configuration.Configuration{
+		Target:       "https://api.example.com",
+		ApiVersion:   "2",
+		Organization: cf.Organization{Name: "my-org", Guid: "my-org-guid"},
+	}
+
+	out := testhelpers.CaptureOutput(func() {
+		ui.ShowConfiguration(config)
+	})
+
+	assert.Contains(t, out, `"api_endpoint": "https://api.example.com"`)
+	assert.Contains(t, out, `"logged_in": false`)
+	assert.Contains(t, out, `"name": "my-org"`)
+}
@@ -0,0 +1,59 @@
+package terminal
+
+import "strings"
+
+// PagedTable displays a table a page at a time, as the pages of a list
+// arrive. The header is shown with the first page, and the later pages are
+// padded to the column widths of the first so that the columns line up. A
+// value wider than its column still pushes the rest of its own row along.
+type PagedTable struct {
+	ui     UI
+	header []string
+	widths []int
+}
+
+func NewPagedTable(ui UI, header []string) *PagedTable {
+	return &PagedTable{ui: ui, header: header}
+}
+
+// Print displays rows, along with the header if nothing was printed yet.
+func (table *PagedTable) Print(rows [][]string) {
+	if table.widths == nil {
+		table.widths = make([]int, len(table.header))
+		lines := append([][]string{table.header}, rows...)
+		for _, line := range lines {
+			for col, value := range line {
+				if table.widths[col] < len(value) {
+					table.widths[col] = len(value)
+				}
+			}
+		}
+
+		table.ui.DisplayTable(lines, DefaultColoringFunc)
+		return
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	padded := [][]string{}
+	for _, row := range rows {
+		line := []string{}
+		for col, value := range row {
+			if len(value) < table.widths[col] {
+				value += strings.Repeat(" ", table.widths[col]-len(value))
+			}
+			line = append(line, value)
+		}
+		padded = append(padded, line)
+	}
+
+	table.ui.DisplayTable(padded, continuedTableColoringFunc)
+}
+
+// continuedTableColoringFunc colors the rows of a table continuing one that
+// is already displayed, which have no header of their own.
+func continuedTableColoringFunc(value string, row int, col int) string {
+	return DefaultColoringFunc(value, row+1, col)
+}
@@ -0,0 +1,27 @@
+package terminal_test
+
+import (
+	"cf/terminal"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testhelpers"
+	"testing"
+)
+
+func TestPagedTableKeepsTheColumnWidthsOfTheFirstPage(t *testing.T) {
+	ui := new(terminal.TerminalUI)
+	out := testhelpers.CaptureOutput(func() {
+		table := terminal.NewPagedTable(ui, []string{"name", "description"})
+		table.Print([][]string{{"a-long-name", "first"}})
+		table.Print([][]string{})
+		table.Print([][]string{{"b", "second"}})
+	})
+
+	out = regexp.MustCompile("\033\\[[0-9;]*m").ReplaceAllString(out, "")
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+
+	assert.Equal(t, len(lines), 3)
+	assert.Equal(t, strings.Index(lines[0], "description"), strings.Index(lines[1], "first"))
+	assert.Equal(t, strings.Index(lines[0], "description"), strings.Index(lines[2], "second"))
+}
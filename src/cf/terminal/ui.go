@@ -3,6 +3,7 @@ package terminal
 import (
 	"cf"
 	"cf/configuration"
+	"cf/formatters"
 	"fmt"
 	"github.com/codegangsta/cli"
 	"io"
@@ -29,20 +30,35 @@ type UI interface {
 	ConfigFailure(err error)
 	ShowConfiguration(*configuration.Configuration)
 	LoadingIndication()
+	ShowProgress(current, total int64)
 	Wait(duration time.Duration)
 	DisplayTable(table [][]string, coloringFunc ColoringFunction)
+	ApiFailure(message string, errorCode string, statusCode int)
+	IsStructuredOutput() bool
+	DisplayData(data interface{})
 }
 
+// TerminalUI prints human readable text unless OutputFormat asks for json or
+// yaml. In that case only documents go to stdout and progress messages are
+// moved to stderr so scripts can parse the output.
 type TerminalUI struct {
+	OutputFormat OutputFormat
 }
 
 var Stdin io.Reader = os.Stdin
 
 func (c TerminalUI) Say(message string, args ...interface{}) {
-	fmt.Printf(message+"\n", args...)
+	fmt.Fprintf(c.messageWriter(), message+"\n", args...)
 	return
 }
 
+func (c TerminalUI) messageWriter() io.Writer {
+	if c.IsStructuredOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 func (c TerminalUI) Warn(message string, args ...interface{}) {
 	message = fmt.Sprintf(message, args...)
 	c.Say(WarningColor(message))
@@ -59,8 +75,8 @@ func (c TerminalUI) Confirm(message string, args ...interface{}) bool {
 }
 
 func (c TerminalUI) Ask(prompt string, args ...interface{}) (answer string) {
-	fmt.Println("")
-	fmt.Printf(prompt+" ", args...)
+	fmt.Fprintln(c.messageWriter(), "")
+	fmt.Fprintf(c.messageWriter(), prompt+" ", args...)
 	fmt.Fscanln(Stdin, &answer)
 	return
 }
@@ -70,9 +86,21 @@ func (c TerminalUI) Ok() {
 }
 
 func (c TerminalUI) Failed(message string, args ...interface{}) {
-	message = fmt.Sprintf(message, args...)
+	c.ApiFailure(fmt.Sprintf(message, args...), "", 0)
+}
+
+func (c TerminalUI) ApiFailure(message string, errorCode string, statusCode int) {
+	if c.IsStructuredOutput() {
+		c.DisplayData(errorDocument{errorDetails{
+			Description: decolorize(message),
+			Code:        errorCode,
+			Status:      statusCode,
+		}})
+		os.Exit(1)
+	}
+
 	c.Say(FailureColor("FAILED"))
-	c.Say(message)
+	c.Say("%s", message)
 	os.Exit(1)
 }
 
@@ -92,6 +120,21 @@ func (c TerminalUI) ConfigFailure(err error) {
 }
 
 func (ui TerminalUI) ShowConfiguration(config *configuration.Configuration) {
+	if ui.IsStructuredOutput() {
+		document := targetDocument{
+			ApiEndpoint:  config.Target,
+			ApiVersion:   config.ApiVersion,
+			LoggedIn:     config.IsLoggedIn(),
+			Organization: targetedEntity{config.Organization.Name, config.Organization.Guid},
+			Space:        targetedEntity{config.Space.Name, config.Space.Guid},
+		}
+		if document.LoggedIn {
+			document.User = config.UserEmail()
+		}
+		ui.DisplayData(document)
+		return
+	}
+
 	ui.Say("API endpoint: %s (API version: %s)",
 		EntityNameColor(config.Target),
 		EntityNameColor(config.ApiVersion))
@@ -112,7 +155,22 @@ func (ui TerminalUI) ShowConfiguration(config *configuration.Configuration) {
 }
 
 func (c TerminalUI) LoadingIndication() {
-	fmt.Print(".")
+	fmt.Fprint(c.messageWriter(), ".")
+}
+
+// ShowProgress redraws the current line with how far along current is in
+// total. The line is finished once current reaches total.
+func (c TerminalUI) ShowProgress(current, total int64) {
+	if total <= 0 {
+		return
+	}
+
+	fmt.Fprintf(c.messageWriter(), "\r  %s of %s (%d%%)   ",
+		formatters.ByteSize(uint64(current)), formatters.ByteSize(uint64(total)), current*100/total)
+
+	if current >= total {
+		fmt.Fprintln(c.messageWriter(), "")
+	}
 }
 
 func (c TerminalUI) Wait(duration time.Duration) {
@@ -139,12 +197,26 @@ func (ui TerminalUI) DisplayTable(table [][]string, coloringFunc ColoringFunctio
 		for col, value := range line {
 			padding := strings.Repeat(" ", maxSizes[col]-len(value))
 			value = coloringFunc(value, row, col)
-			fmt.Printf("%s%s   ", value, padding)
+			fmt.Fprintf(ui.messageWriter(), "%s%s   ", value, padding)
 		}
-		fmt.Print("\n")
+		fmt.Fprint(ui.messageWriter(), "\n")
 	}
 }
 
+func (ui TerminalUI) IsStructuredOutput() bool {
+	return ui.OutputFormat != TextOutput
+}
+
+func (ui TerminalUI) DisplayData(data interface{}) {
+	output, err := formatData(ui.OutputFormat, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting output: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Print(output)
+}
+
 func DefaultColoringFunc(value string, row int, col int) string {
 	switch {
 	case row == 0:
@@ -0,0 +1,101 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// writeYAML writes a value decoded from JSON as block style YAML. Map keys
+// are sorted so the same document always produces the same output.
+func writeYAML(out *bytes.Buffer, value interface{}, indent string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			out.WriteString(indent + "{}\n")
+			return
+		}
+
+		keys := []string{}
+		for key, _ := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := value[key]
+			switch {
+			case isYAMLBlock(child) && isYAMLList(child):
+				out.WriteString(indent + yamlScalar(key) + ":\n")
+				writeYAML(out, child, indent)
+			case isYAMLBlock(child):
+				out.WriteString(indent + yamlScalar(key) + ":\n")
+				writeYAML(out, child, indent+"  ")
+			default:
+				out.WriteString(indent + yamlScalar(key) + ": " + yamlScalar(child) + "\n")
+			}
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			out.WriteString(indent + "[]\n")
+			return
+		}
+
+		for _, item := range value {
+			if !isYAMLBlock(item) {
+				out.WriteString(indent + "- " + yamlScalar(item) + "\n")
+				continue
+			}
+
+			itemOut := new(bytes.Buffer)
+			writeYAML(itemOut, item, indent+"  ")
+			out.WriteString(indent + "- " + strings.TrimPrefix(itemOut.String(), indent+"  "))
+		}
+	default:
+		out.WriteString(indent + yamlScalar(value) + "\n")
+	}
+}
+
+func isYAMLBlock(value interface{}) bool {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return len(value) > 0
+	case []interface{}:
+		return len(value) > 0
+	}
+	return false
+}
+
+func isYAMLList(value interface{}) bool {
+	_, ok := value.([]interface{})
+	return ok
+}
+
+// Strings are only written plain when YAML cannot read them as anything else,
+// like a number, a boolean or null. All others are double quoted.
+var yamlPlainString = regexp.MustCompile(`^[a-zA-Z_/][a-zA-Z0-9_./@-]*$`)
+var yamlReservedWords = regexp.MustCompile(`(?i)^(true|false|yes|no|on|off|null|~|y|n)$`)
+
+func yamlScalar(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		return value.String()
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	case string:
+		if !yamlPlainString.MatchString(value) || yamlReservedWords.MatchString(value) {
+			return strconv.Quote(value)
+		}
+		return value
+	}
+	return ""
+}
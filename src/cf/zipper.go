@@ -2,146 +2,103 @@ package cf
 
 import (
 	"archive/zip"
-	"bytes"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 type Zipper interface {
-	Zip(dirToZip string) (zip *bytes.Buffer, err error)
+	Zip(dir string, appFiles []AppFile, target io.Writer, onProgress func(bytesZipped int64)) (err error)
 }
 
 type ApplicationZipper struct{}
 
-var doNotZipExtensions = []string{".zip", ".war", ".jar"}
-
-func (zipper ApplicationZipper) Zip(dirOrZipFile string) (zipBuffer *bytes.Buffer, err error) {
-	if shouldNotZip(filepath.Ext(dirOrZipFile)) {
-		return readZipFile(dirOrZipFile)
-	}
-
-	return createZipFile(dirOrZipFile)
-}
-
-func shouldNotZip(extension string) (result bool) {
-	for _, ext := range doNotZipExtensions {
-		if ext == extension {
-			return true
+// Zip writes a zip archive of appFiles, whose paths are relative to dir, to
+// target while reading them. Nothing is buffered, so target sees the archive
+// as it is being built. File modes are kept, directories are added as empty
+// directories and symlinks as symlinks. onProgress, if given, is called with
+// the number of file bytes zipped so far.
+func (zipper ApplicationZipper) Zip(dir string, appFiles []AppFile, target io.Writer, onProgress func(bytesZipped int64)) (err error) {
+	writer := zip.NewWriter(target)
+
+	counter := NewProgressCounter(onProgress)
+	for _, file := range appFiles {
+		err = zipFile(writer, dir, file, counter)
+		if err != nil {
+			return
 		}
 	}
-	return
-}
 
-func readZipFile(file string) (zipBuffer *bytes.Buffer, err error) {
-	var zipBytes []byte
-	zipBytes, err = ioutil.ReadFile(file)
-	zipBuffer = bytes.NewBuffer(zipBytes)
+	err = writer.Close()
 	return
 }
 
-func createZipFile(dir string) (zipBuffer *bytes.Buffer, err error) {
-	zipBuffer = new(bytes.Buffer)
+func zipFile(writer *zip.Writer, dir string, file AppFile, counter *ProgressCounter) (err error) {
+	fullPath := filepath.Join(dir, file.Path)
 
-	isEmpty, err := IsDirEmpty(dir)
-	if isEmpty {
-		return
+	header := &zip.FileHeader{
+		Name:   filepath.ToSlash(file.Path),
+		Method: zip.Deflate,
+	}
+	if file.Mode != 0 {
+		header.SetMode(file.Mode)
 	}
 
-	writer := zip.NewWriter(zipBuffer)
-	defer writer.Close()
-
-	err = walkAppFiles(dir, func(fileName string, fullPath string) {
-		zipFile, err := writer.Create(fileName)
-		if err != nil {
-			return
-		}
-
-		content, err := ioutil.ReadFile(fullPath)
+	switch {
+	case file.Mode.IsDir():
+		header.Name += "/"
+		header.Method = zip.Store
+		_, err = writer.CreateHeader(header)
+		return
+	case file.Mode&os.ModeSymlink != 0:
+		var linkTarget string
+		linkTarget, err = os.Readlink(fullPath)
 		if err != nil {
 			return
 		}
 
-		_, err = zipFile.Write(content)
+		header.Method = zip.Store
+		var dst io.Writer
+		dst, err = writer.CreateHeader(header)
 		if err != nil {
 			return
 		}
-
+		_, err = dst.Write([]byte(filepath.ToSlash(linkTarget)))
 		return
-	})
-
-	return
-}
-
-func fileShouldBeIgnored(exclusions []string, relativePath string) bool {
-	for _, exclusion := range exclusions {
-		if exclusion == relativePath {
-			return true
-		}
 	}
-	return false
-}
 
-func readCfIgnore(dir string) (exclusions []string) {
-	cfIgnore, err := os.Open(filepath.Join(dir, ".cfignore"))
+	src, err := os.Open(fullPath)
 	if err != nil {
 		return
 	}
+	defer src.Close()
 
-	ignores := strings.Split(readFile(cfIgnore), "\n")
-	ignores = append([]string{".cfignore"}, ignores...)
-
-	for _, pattern := range ignores {
-		pattern = filepath.Clean(pattern)
-
-		patternExclusions := exclusionsForPattern(dir, pattern)
-		exclusions = append(exclusions, patternExclusions...)
-	}
-
-	return
-}
-
-func exclusionsForPattern(dir string, pattern string) (exclusions []string) {
-	starting_dir := dir
-
-	findPatternMatches := func(dir string, f os.FileInfo, inErr error) (err error) {
-		err = inErr
-		if err != nil {
-			return
-		}
-
-		absolutePaths := []string{}
-		if f.IsDir() && f.Name() == pattern {
-			absolutePaths, _ = filepath.Glob(filepath.Join(dir, "*"))
-		} else {
-			absolutePaths, _ = filepath.Glob(filepath.Join(dir, pattern))
-		}
-
-		for _, p := range absolutePaths {
-			relpath, _ := filepath.Rel(starting_dir, p)
-
-			exclusions = append(exclusions, relpath)
-		}
-		return
-	}
-
-	err := filepath.Walk(dir, findPatternMatches)
+	dst, err := writer.CreateHeader(header)
 	if err != nil {
 		return
 	}
 
+	_, err = io.Copy(dst, io.TeeReader(src, counter))
 	return
 }
 
-func readFile(file *os.File) string {
-	buf := &bytes.Buffer{}
-	_, err := io.Copy(buf, file)
+// ProgressCounter is a writer that only counts what is written to it, for
+// reporting progress through an io.TeeReader. onProgress, if not nil, is
+// called with the number of bytes counted so far after every write.
+type ProgressCounter struct {
+	total      int64
+	onProgress func(int64)
+}
 
-	if err != nil {
-		return ""
-	}
+func NewProgressCounter(onProgress func(int64)) *ProgressCounter {
+	return &ProgressCounter{onProgress: onProgress}
+}
 
-	return string(buf.Bytes())
+func (counter *ProgressCounter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	counter.total += int64(n)
+	if counter.onProgress != nil {
+		counter.onProgress(counter.total)
+	}
+	return
 }
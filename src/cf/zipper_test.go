@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -13,9 +14,14 @@ import (
 func TestZipWithDirectory(t *testing.T) {
 	dir, err := os.Getwd()
 	assert.NoError(t, err)
+	dir = filepath.Join(dir, "../fixtures/zip/")
 
+	appFiles, err := AppFilesInDir(dir)
+	assert.NoError(t, err)
+
+	zipFile := new(bytes.Buffer)
 	zipper := ApplicationZipper{}
-	zipFile, err := zipper.Zip(filepath.Join(dir, "../fixtures/zip/"))
+	err = zipper.Zip(dir, appFiles, zipFile, nil)
 	assert.NoError(t, err)
 
 	byteReader := bytes.NewReader(zipFile.Bytes())
@@ -44,35 +50,122 @@ func TestZipWithDirectory(t *testing.T) {
 	assert.Equal(t, contents, "I am in a subdirectory.")
 }
 
-func TestZipWithZipFile(t *testing.T) {
+func TestZipOnlyIncludesTheGivenFiles(t *testing.T) {
 	dir, err := os.Getwd()
 	assert.NoError(t, err)
+	dir = filepath.Join(dir, "../fixtures/zip/")
 
+	zipFile := new(bytes.Buffer)
 	zipper := ApplicationZipper{}
-	zipFile, err := zipper.Zip(filepath.Join(dir, "../fixtures/application.zip"))
+	err = zipper.Zip(dir, []AppFile{AppFile{Path: "subDir/bar.txt"}}, zipFile, nil)
 	assert.NoError(t, err)
 
-	assert.Equal(t, string(zipFile.Bytes()), "This is an application zip file\n")
+	reader, err := zip.NewReader(bytes.NewReader(zipFile.Bytes()), int64(zipFile.Len()))
+	assert.NoError(t, err)
+	assert.Equal(t, len(reader.File), 1)
+	assert.Equal(t, reader.File[0].Name, "subDir/bar.txt")
 }
 
-func TestZipWithWarFile(t *testing.T) {
+func TestZipReportsProgress(t *testing.T) {
 	dir, err := os.Getwd()
 	assert.NoError(t, err)
+	dir = filepath.Join(dir, "../fixtures/zip/")
 
-	zipper := ApplicationZipper{}
-	zipFile, err := zipper.Zip(filepath.Join(dir, "../fixtures/application.war"))
+	appFiles, err := AppFilesInDir(dir)
 	assert.NoError(t, err)
 
-	assert.Equal(t, string(zipFile.Bytes()), "This is an application war file\n")
+	var totalSize int64
+	for _, file := range appFiles {
+		totalSize += file.Size
+	}
+
+	var zipped int64
+	zipper := ApplicationZipper{}
+	err = zipper.Zip(dir, appFiles, new(bytes.Buffer), func(bytesZipped int64) {
+		zipped = bytesZipped
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, zipped, totalSize)
 }
 
-func TestZipWithJarFile(t *testing.T) {
+func TestAppFilesInDir(t *testing.T) {
 	dir, err := os.Getwd()
 	assert.NoError(t, err)
 
+	appFiles, err := AppFilesInDir(filepath.Join(dir, "../fixtures/example-app"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(appFiles), 5)
+	assert.Equal(t, appFiles[0].Path, "Gemfile")
+	assert.Equal(t, appFiles[0].Sha1, "d9c3a51de5c89c11331d3b90b972789f1a14699a")
+	assert.Equal(t, appFiles[0].Size, int64(59))
+	assert.True(t, appFiles[0].Mode.IsRegular())
+
+	assert.Equal(t, appFiles[4].Path, "manifest.yml")
+	assert.Equal(t, appFiles[4].Sha1, "19b5b4225dc64da3213b1ffaa1e1920ee5faf36c")
+	assert.Equal(t, appFiles[4].Size, int64(111))
+}
+
+func TestZipKeepsModesSymlinksAndEmptyDirectories(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zipper")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "start.sh"), []byte("#!/bin/sh"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.yml"), []byte("config"), 0600))
+	assert.NoError(t, os.Symlink("config.yml", filepath.Join(dir, "current.yml")))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "tmp"), 0755))
+
+	appFiles, err := AppFilesInDir(dir)
+	assert.NoError(t, err)
+
+	zipFile := new(bytes.Buffer)
 	zipper := ApplicationZipper{}
-	zipFile, err := zipper.Zip(filepath.Join(dir, "../fixtures/application.jar"))
+	err = zipper.Zip(dir, appFiles, zipFile, nil)
+	assert.NoError(t, err)
+
+	reader, err := zip.NewReader(bytes.NewReader(zipFile.Bytes()), int64(zipFile.Len()))
+	assert.NoError(t, err)
+
+	entries := map[string]*zip.File{}
+	for _, file := range reader.File {
+		entries[file.Name] = file
+	}
+	assert.Equal(t, len(entries), 4)
+
+	assert.Equal(t, entries["start.sh"].Mode(), os.FileMode(0755))
+	assert.Equal(t, entries["config.yml"].Mode(), os.FileMode(0600))
+	assert.True(t, entries["tmp/"].Mode().IsDir())
+
+	link := entries["current.yml"]
+	assert.True(t, link.Mode()&os.ModeSymlink != 0)
+	linkReader, err := link.Open()
+	assert.NoError(t, err)
+	linkTarget, err := ioutil.ReadAll(linkReader)
+	assert.NoError(t, err)
+	assert.Equal(t, string(linkTarget), "config.yml")
+}
+
+func TestAppFilesInDirWithSymlinksOutsideTheApp(t *testing.T) {
+	outsideDir, err := ioutil.TempDir("", "outside")
+	assert.NoError(t, err)
+	defer os.RemoveAll(outsideDir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(outsideDir, "shared.txt"), []byte("shared"), 0644))
+
+	dir, err := ioutil.TempDir("", "app")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.Symlink(filepath.Join(outsideDir, "shared.txt"), filepath.Join(dir, "shared.txt")))
+	assert.NoError(t, os.Symlink(outsideDir, filepath.Join(dir, "shared-dir")))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "missing.txt"), filepath.Join(dir, "dangling.txt")))
+
+	appFiles, err := AppFilesInDir(dir)
 	assert.NoError(t, err)
 
-	assert.Equal(t, string(zipFile.Bytes()), "This is an application jar file\n")
+	assert.Equal(t, len(appFiles), 2)
+	assert.Equal(t, appFiles[0].Path, "dangling.txt")
+	assert.True(t, appFiles[0].Mode&os.ModeSymlink != 0)
+	assert.Equal(t, appFiles[1].Path, "shared.txt")
+	assert.True(t, appFiles[1].Mode.IsRegular())
+	assert.Equal(t, appFiles[1].Size, int64(len("shared")))
 }
@@ -12,12 +12,30 @@ import (
 	"cf/configuration"
 	"github.com/codegangsta/cli"
 	"cf/net"
+	"strings"
 )
 
 func main() {
 	termUI := new(terminal.TerminalUI)
 	assignTemplates()
 	configRepo := configuration.NewConfigurationDiskRepository()
+
+	args, output := outputFlagAfterCommand(os.Args)
+	if output == "" {
+		output = globalFlagFromArgs(args, "output")
+	}
+
+	outputFormat, err := terminal.ParseOutputFormat(output)
+	if err != nil {
+		termUI.Failed("%s", err.Error())
+		return
+	}
+	termUI.OutputFormat = outputFormat
+
+	profile := globalFlagFromArgs(args, "profile")
+	if profile != "" {
+		configuration.UseProfile(profile)
+	}
 	config := loadConfig(termUI, configRepo)
 
 	repoLocator := api.NewRepositoryLocator(config, configRepo, map[string]net.Gateway{
@@ -33,7 +51,7 @@ func main() {
 	if err != nil {
 		return
 	}
-	app.Run(os.Args)
+	app.Run(args)
 }
 
 func assignTemplates() {
@@ -55,6 +73,9 @@ GLOBAL OPTIONS:
 ENVIRONMENT VARIABLES:
    CF_TRACE=true - will output HTTP requests and responses during command
    HTTP_PROXY=http://proxy.example.com:8080 - set to your proxy
+   CF_CA_CERT_FILE=path/to/ca.pem - additional CA certificates to trust
+   CF_HOME=path/to/dir - keep configuration in path/to/dir/.cf instead of ~/.cf
+   CF_PROFILE=staging - use this profile instead of the active one
 `
 
 	cli.CommandHelpTemplate = `NAME:
@@ -73,14 +94,100 @@ OPTIONS:
 
 }
 
+// The UI and configuration are set up before the cli parses any flags, so
+// global flags have to be picked out of the arguments by hand. Every global
+// flag takes a value.
+func globalFlagFromArgs(args []string, flagName string) (value string) {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if strings.HasPrefix(name, flagName+"=") {
+			return strings.TrimPrefix(name, flagName+"=")
+		}
+		if strings.Contains(name, "=") {
+			continue
+		}
+
+		i++
+		if name == flagName && i < len(args) {
+			return args[i]
+		}
+	}
+	return
+}
+
+// outputFlagAfterCommand takes --output out of the arguments that follow the
+// command name, where the cli would refuse it as a flag the command does not
+// have, so that it can be given on either side of the command.
+func outputFlagAfterCommand(args []string) (rest []string, value string) {
+	command := commandIndex(args)
+	if command == len(args) {
+		return args, ""
+	}
+
+	rest = append(rest, args[:command+1]...)
+	for i := command + 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		switch {
+		case !strings.HasPrefix(arg, "-"):
+			rest = append(rest, arg)
+		case strings.HasPrefix(name, "output="):
+			value = strings.TrimPrefix(name, "output=")
+		case name == "output" && i+1 < len(args):
+			i++
+			value = args[i]
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return
+}
+
+// commandIndex finds the command name in args, after the global flags, or
+// returns len(args) when there is none.
+func commandIndex(args []string) int {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return i
+		}
+
+		if !strings.Contains(arg, "=") {
+			i++
+		}
+	}
+	return len(args)
+}
+
 func loadConfig(termUI terminal.UI, configRepo configuration.ConfigurationRepository) (config *configuration.Configuration) {
+	profile := configuration.CurrentProfileName()
+	if !configuration.ProfileExists(profile) {
+		termUI.Failed(
+			"Profile %s does not exist. Use '%s' to list profiles.",
+			terminal.EntityNameColor(profile),
+			terminal.CommandColor(fmt.Sprintf("%s profiles", cf.Name)),
+		)
+		os.Exit(1)
+		return
+	}
+
 	config, err := configRepo.Get()
 	if err != nil {
-		termUI.Failed(fmt.Sprintf(
+		termUI.Failed(
 			"Error loading config. Please reset target (%s) and log in (%s).",
 			terminal.CommandColor(fmt.Sprintf("%s target", cf.Name)),
 			terminal.CommandColor(fmt.Sprintf("%s login", cf.Name)),
-		))
+		)
 		configRepo.Delete()
 		os.Exit(1)
 		return
@@ -8,11 +8,22 @@ import (
 type FakeApplicationBitsRepository struct {
 	UploadedApp cf.Application
 	UploadedDir string
+	UploadProgress []int64
+	UploadTotal int64
+	UploadErr bool
 }
 
-func (repo *FakeApplicationBitsRepository) UploadApp(app cf.Application, dir string) (apiResponse net.ApiResponse) {
+func (repo *FakeApplicationBitsRepository) UploadApp(app cf.Application, dir string, onProgress func(uploaded, total int64)) (apiResponse net.ApiResponse) {
 	repo.UploadedDir = dir
 	repo.UploadedApp = app
 
+	for _, uploaded := range repo.UploadProgress {
+		onProgress(uploaded, repo.UploadTotal)
+	}
+
+	if repo.UploadErr {
+		apiResponse = net.NewApiStatusWithMessage("Error uploading app")
+	}
+
 	return
 }
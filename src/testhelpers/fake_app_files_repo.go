@@ -3,20 +3,56 @@ package testhelpers
 import (
 	"cf"
 	"cf/net"
+	"io"
 )
 
 type FakeAppFilesRepo struct{
 	Application cf.Application
+	Instance int
 	Path string
 	FileList string
+
+	Directories map[string][]cf.InstanceFile
+	FileContents map[string]string
+	DownloadedPaths []string
 }
 
 
-func (repo *FakeAppFilesRepo)ListFiles(app cf.Application, path string) (files string, apiResponse net.ApiResponse) {
+func (repo *FakeAppFilesRepo)ListFiles(app cf.Application, instance int, path string) (files string, apiResponse net.ApiResponse) {
 	repo.Application = app
+	repo.Instance = instance
 	repo.Path = path
 
 	files = repo.FileList
 
 	return
 }
+
+func (repo *FakeAppFilesRepo)ListDirectory(app cf.Application, instance int, path string) (entries []cf.InstanceFile, apiResponse net.ApiResponse) {
+	repo.Application = app
+	repo.Instance = instance
+
+	entries, found := repo.Directories[path]
+	if !found {
+		apiResponse = net.NewNotFoundApiStatus("Directory", path)
+	}
+	return
+}
+
+func (repo *FakeAppFilesRepo)DownloadFile(app cf.Application, instance int, path string, destination io.Writer, onProgress func(downloaded, total int64)) (apiResponse net.ApiResponse) {
+	repo.Application = app
+	repo.Instance = instance
+	repo.DownloadedPaths = append(repo.DownloadedPaths, path)
+
+	contents, found := repo.FileContents[path]
+	if !found {
+		apiResponse = net.NewNotFoundApiStatus("File", path)
+		return
+	}
+
+	destination.Write([]byte(contents))
+	if onProgress != nil {
+		onProgress(int64(len(contents)), int64(len(contents)))
+	}
+	return
+}
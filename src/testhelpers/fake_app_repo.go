@@ -20,6 +20,7 @@ type FakeApplicationRepository struct {
 	StopUpdatedApp cf.Application
 
 	DeletedApp cf.Application
+	DeletedApps []cf.Application
 
 	FindAllApps []cf.Application
 
@@ -28,6 +29,7 @@ type FakeApplicationRepository struct {
 	FindByNameErr       bool
 	FindByNameAuthErr   bool
 	FindByNameNotFound  bool
+	FindByNameApps      map[string]cf.Application
 
 	SetEnvApp   cf.Application
 	SetEnvVars  map[string]string
@@ -36,17 +38,39 @@ type FakeApplicationRepository struct {
 
 	CreatedApp  cf.Application
 
+	UpdatedApp    cf.Application
+	UpdateAppErr  bool
+
 	RenameApp     cf.Application
 	RenameNewName string
 
 	GetInstancesResponses  [][]cf.ApplicationInstance
 	GetInstancesErrorCodes []string
+
+	KillInstanceApp   cf.Application
+	KillInstanceIndex int
+	KillInstanceErr   bool
+
+	GetCrashesCrashes []cf.InstanceCrash
+	GetCrashesErr     bool
+
+	GetEnvApp cf.Application
+	GetEnvEnv cf.AppEnvironment
+	GetEnvErr bool
 }
 
 func (repo *FakeApplicationRepository) FindByName(name string) (app cf.Application, apiResponse net.ApiResponse) {
 	repo.FindByNameName = name
 	app = repo.FindByNameApp
 
+	if repo.FindByNameApps != nil {
+		var found bool
+		app, found = repo.FindByNameApps[name]
+		if !found {
+			apiResponse = net.NewNotFoundApiStatus("App", name)
+		}
+	}
+
 	if repo.FindByNameErr {
 		apiResponse = net.NewApiStatusWithMessage("Error finding app by name.")
 	}
@@ -81,8 +105,19 @@ func (repo *FakeApplicationRepository) Create(newApp cf.Application) (createdApp
 	return
 }
 
+func (repo *FakeApplicationRepository) Update(app cf.Application) (updatedApp cf.Application, apiResponse net.ApiResponse) {
+	repo.UpdatedApp = app
+	updatedApp = app
+
+	if repo.UpdateAppErr {
+		apiResponse = net.NewApiStatusWithMessage("Error updating app.")
+	}
+	return
+}
+
 func (repo *FakeApplicationRepository) Delete(app cf.Application) (apiResponse net.ApiResponse) {
 	repo.DeletedApp = app
+	repo.DeletedApps = append(repo.DeletedApps, app)
 	return
 }
 
@@ -130,3 +165,32 @@ func (repo *FakeApplicationRepository) GetInstances(app cf.Application) (instanc
 
 	return
 }
+
+func (repo *FakeApplicationRepository) KillInstance(app cf.Application, index int) (apiResponse net.ApiResponse) {
+	repo.KillInstanceApp = app
+	repo.KillInstanceIndex = index
+
+	if repo.KillInstanceErr {
+		apiResponse = net.NewApiStatusWithMessage("Error killing instance")
+	}
+	return
+}
+
+func (repo *FakeApplicationRepository) GetCrashes(app cf.Application) (crashes []cf.InstanceCrash, apiResponse net.ApiResponse) {
+	crashes = repo.GetCrashesCrashes
+
+	if repo.GetCrashesErr {
+		apiResponse = net.NewApiStatusWithMessage("Error getting crashes")
+	}
+	return
+}
+
+func (repo *FakeApplicationRepository) GetEnv(app cf.Application) (env cf.AppEnvironment, apiResponse net.ApiResponse) {
+	repo.GetEnvApp = app
+	env = repo.GetEnvEnv
+
+	if repo.GetEnvErr {
+		apiResponse = net.NewApiStatusWithMessage("Error getting env")
+	}
+	return
+}
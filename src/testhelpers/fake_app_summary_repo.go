@@ -8,6 +8,9 @@ import (
 type FakeAppSummaryRepo struct{
 	GetSummaryApp cf.Application
 	GetSummarySummary cf.AppSummary
+
+	// Returned in turn when set, then the app is not found
+	GetSummaryResponses []cf.AppSummary
 }
 
 
@@ -15,5 +18,14 @@ func (repo *FakeAppSummaryRepo)GetSummary(app cf.Application) (summary cf.AppSum
 	repo.GetSummaryApp= app
 	summary = repo.GetSummarySummary
 
+	if repo.GetSummaryResponses != nil {
+		if len(repo.GetSummaryResponses) == 0 {
+			apiResponse = net.NewNotFoundApiStatus("App", app.Name)
+			return
+		}
+		summary = repo.GetSummaryResponses[0]
+		repo.GetSummaryResponses = repo.GetSummaryResponses[1:]
+	}
+
 	return
 }
@@ -15,6 +15,9 @@ type FakeAuthenticationRepository struct {
 	AuthError bool
 	AccessToken string
 	RefreshToken string
+
+	RefreshTokenCalled bool
+	RefreshedAccessToken string
 }
 
 func (auth *FakeAuthenticationRepository) Authenticate(email string, password string) (apiResponse net.ApiResponse) {
@@ -37,5 +40,7 @@ func (auth *FakeAuthenticationRepository) Authenticate(email string, password st
 }
 
 func (auth *FakeAuthenticationRepository) RefreshAuthToken() (updatedToken string, apiResponse net.ApiResponse) {
+	auth.RefreshTokenCalled = true
+	updatedToken = auth.RefreshedAccessToken
 	return
 }
@@ -46,6 +46,12 @@ func (repo *FakeDomainRepository) FindAllByOrg(org cf.Organization)(domains []cf
 	return
 }
 
+func (repo *FakeDomainRepository) ListDomainsForOrg(org cf.Organization, cb func([]cf.Domain) bool) (apiResponse net.ApiResponse){
+	repo.FindAllByOrgOrg = org
+	cb(repo.FindAllByOrgDomains)
+	return
+}
+
 func (repo *FakeDomainRepository) FindByNameInCurrentSpace(name string) (domain cf.Domain, apiResponse net.ApiResponse){
 	repo.FindByNameName = name
 	domain = repo.FindByNameDomain
@@ -6,9 +6,18 @@ import (
 
 type FakeEndpointRepo struct {
 	UpdateEndpointEndpoint string
+	UpdateEndpointSSLDisabled bool
+	UpdateEndpointCACertFile string
+	UpdateEndpointErr bool
 }
 
-func (repo *FakeEndpointRepo) UpdateEndpoint(endpoint string) (apiResponse net.ApiResponse) {
+func (repo *FakeEndpointRepo) UpdateEndpoint(endpoint string, sslDisabled bool, caCertFile string) (apiResponse net.ApiResponse) {
 	repo.UpdateEndpointEndpoint = endpoint
+	repo.UpdateEndpointSSLDisabled = sslDisabled
+	repo.UpdateEndpointCACertFile = caCertFile
+
+	if repo.UpdateEndpointErr {
+		apiResponse = net.NewApiStatusWithMessage("Error updating endpoint")
+	}
 	return
 }
@@ -10,12 +10,25 @@ type FakeLogsRepository struct {
 	AppLogged cf.Application
 	RecentLogs []logmessage.LogMessage
 	TailLogMessages []logmessage.LogMessage
+	AppsLogged []cf.Application
+	RecentLogsByAppGuid map[string][]logmessage.LogMessage
+	TailDropErr error
+
+	TailStop <-chan bool
+	TailStopped bool
+	TailErr error
 }
 
 func (l *FakeLogsRepository) RecentLogsFor(app cf.Application, onConnect func(), onMessage func(logmessage.LogMessage), port string) (err error){
 	l.AppLogged = app
+	l.AppsLogged = append(l.AppsLogged, app)
 	onConnect()
-	for _, message := range l.RecentLogs{
+
+	recentLogs := l.RecentLogs
+	if l.RecentLogsByAppGuid != nil {
+		recentLogs = l.RecentLogsByAppGuid[app.Guid]
+	}
+	for _, message := range recentLogs{
 		onMessage(message)
 	}
 
@@ -23,12 +36,41 @@ func (l *FakeLogsRepository) RecentLogsFor(app cf.Application, onConnect func(),
 }
 
 
-func (l *FakeLogsRepository) TailLogsFor(app cf.Application, onConnect func(), onMessage func(logmessage.LogMessage),  printInterval time.Duration, port string) (err error){
+func (l *FakeLogsRepository) TailLogsFor(app cf.Application, stop <-chan bool, onConnect func(), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) (err error){
 	l.AppLogged = app
+	if l.TailErr != nil {
+		err = l.TailErr
+		return
+	}
+
 	onConnect()
 	for _, message := range l.TailLogMessages{
 		onMessage(message)
 	}
 
+	if stop != nil {
+		<-stop
+		l.TailStopped = true
+	}
+	return
+}
+
+func (l *FakeLogsRepository) TailLogsForApps(apps []cf.Application, stop <-chan bool, onConnect func(cf.Application), onDisconnect func(cf.Application, error), onMessage func(logmessage.LogMessage), printInterval time.Duration, port string) (err error){
+	l.AppsLogged = apps
+	l.TailStop = stop
+	if len(apps) > 0 {
+		l.AppLogged = apps[0]
+	}
+	for _, app := range apps{
+		onConnect(app)
+	}
+	if l.TailDropErr != nil {
+		onDisconnect(apps[0], l.TailDropErr)
+		onConnect(apps[0])
+	}
+	for _, message := range l.TailLogMessages{
+		onMessage(message)
+	}
+
 	return
 }
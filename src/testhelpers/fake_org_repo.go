@@ -36,6 +36,11 @@ func (repo FakeOrgRepository) FindAll() (orgs []cf.Organization, apiResponse net
 	return
 }
 
+func (repo FakeOrgRepository) ListOrgs(cb func([]cf.Organization) bool) (apiResponse net.ApiResponse) {
+	cb(repo.Organizations)
+	return
+}
+
 func (repo *FakeOrgRepository) FindByName(name string) (org cf.Organization, apiResponse net.ApiResponse) {
 	repo.FindByNameName = name
 	org = repo.FindByNameOrganization
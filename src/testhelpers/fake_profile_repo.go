@@ -0,0 +1,45 @@
+package testhelpers
+
+type FakeProfileRepository struct {
+	Profiles      []string
+	ActiveProfile string
+
+	CreatedName   string
+	ActivatedName string
+	DeletedName   string
+
+	CreateErr   error
+	ActivateErr error
+	DeleteErr   error
+}
+
+func (repo *FakeProfileRepository) List() (names []string, err error) {
+	names = repo.Profiles
+	return
+}
+
+func (repo *FakeProfileRepository) Active() (name string) {
+	name = repo.ActiveProfile
+	if name == "" {
+		name = "default"
+	}
+	return
+}
+
+func (repo *FakeProfileRepository) Create(name string) (err error) {
+	repo.CreatedName = name
+	err = repo.CreateErr
+	return
+}
+
+func (repo *FakeProfileRepository) Activate(name string) (err error) {
+	repo.ActivatedName = name
+	err = repo.ActivateErr
+	return
+}
+
+func (repo *FakeProfileRepository) Delete(name string) (err error) {
+	repo.DeletedName = name
+	err = repo.DeleteErr
+	return
+}
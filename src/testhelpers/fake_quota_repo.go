@@ -0,0 +1,75 @@
+package testhelpers
+
+import (
+	"cf"
+	"cf/api"
+	"cf/net"
+)
+
+type FakeQuotaRepository struct {
+	Quotas []cf.Quota
+
+	FindByNameName     string
+	FindByNameQuota    cf.Quota
+	FindByNameNotFound bool
+
+	CreateQuota  cf.Quota
+	CreateExists bool
+
+	UpdateQuota cf.Quota
+
+	DeletedQuota cf.Quota
+
+	FindUsageOrganization cf.Organization
+	FindUsageUsage        cf.QuotaUsage
+	FindUsageErr          bool
+}
+
+func (repo *FakeQuotaRepository) FindAll() (quotas []cf.Quota, apiResponse net.ApiResponse) {
+	quotas = repo.Quotas
+	return
+}
+
+func (repo *FakeQuotaRepository) ListQuotas(cb func([]cf.Quota) bool) (apiResponse net.ApiResponse) {
+	cb(repo.Quotas)
+	return
+}
+
+func (repo *FakeQuotaRepository) FindByName(name string) (quota cf.Quota, apiResponse net.ApiResponse) {
+	repo.FindByNameName = name
+	quota = repo.FindByNameQuota
+
+	if repo.FindByNameNotFound {
+		apiResponse = net.NewNotFoundApiStatus("Quota", name)
+	}
+	return
+}
+
+func (repo *FakeQuotaRepository) Create(quota cf.Quota) (apiResponse net.ApiResponse) {
+	if repo.CreateExists {
+		apiResponse = net.NewApiStatus("Quota already exists", api.QUOTA_EXISTS, 400)
+		return
+	}
+	repo.CreateQuota = quota
+	return
+}
+
+func (repo *FakeQuotaRepository) Update(quota cf.Quota) (apiResponse net.ApiResponse) {
+	repo.UpdateQuota = quota
+	return
+}
+
+func (repo *FakeQuotaRepository) Delete(quota cf.Quota) (apiResponse net.ApiResponse) {
+	repo.DeletedQuota = quota
+	return
+}
+
+func (repo *FakeQuotaRepository) FindUsage(org cf.Organization) (usage cf.QuotaUsage, apiResponse net.ApiResponse) {
+	repo.FindUsageOrganization = org
+	usage = repo.FindUsageUsage
+
+	if repo.FindUsageErr {
+		apiResponse = net.NewApiStatusWithMessage("Error finding quota usage")
+	}
+	return
+}
@@ -29,6 +29,10 @@ type FakeReqFactory struct {
 
 	DomainName string
 	Domain cf.Domain
+
+	Username string
+	User cf.User
+	UserNotFound bool
 }
 
 func (f *FakeReqFactory) NewApplicationRequirement(name string) requirements.ApplicationRequirement {
@@ -78,7 +82,10 @@ func (f *FakeReqFactory) NewDomainRequirement(name string) requirements.DomainRe
 	return FakeRequirement{ f, true }
 }
 
-
+func (f *FakeReqFactory) NewUserRequirement(username string) requirements.UserRequirement {
+	f.Username = username
+	return FakeRequirement{ f, !f.UserNotFound }
+}
 
 type FakeRequirement struct {
 	factory *FakeReqFactory
@@ -112,3 +119,7 @@ func (r FakeRequirement) GetRoute() cf.Route {
 func (r FakeRequirement) GetDomain() cf.Domain {
 	return r.factory.Domain
 }
+
+func (r FakeRequirement) GetUser() cf.User {
+	return r.factory.User
+}
@@ -25,14 +25,18 @@ type FakeRouteRepository struct {
 	CreateInSpaceSpace cf.Space
 	CreateInSpaceCreatedRoute cf.Route
 
-	BoundRoute cf.Route
-	BoundApp   cf.Application
+	BoundRoute  cf.Route
+	BoundApp    cf.Application
+	BoundRoutes []cf.Route
+	BindErrRouteGuid string
 
-	UnboundRoute cf.Route
-	UnboundApp   cf.Application
+	UnboundRoute  cf.Route
+	UnboundApp    cf.Application
+	UnboundRoutes []cf.Route
 
 	FindAllErr    bool
 	FindAllRoutes []cf.Route
+	ListRoutesPages [][]cf.Route
 }
 
 func (repo *FakeRouteRepository) FindAll() (routes []cf.Route, apiResponse net.ApiResponse) {
@@ -44,6 +48,25 @@ func (repo *FakeRouteRepository) FindAll() (routes []cf.Route, apiResponse net.A
 	return
 }
 
+func (repo *FakeRouteRepository) ListRoutes(cb func([]cf.Route) bool) (apiResponse net.ApiResponse) {
+	if repo.FindAllErr {
+		apiResponse = net.NewApiStatusWithMessage("Error finding all routes")
+		return
+	}
+
+	if repo.ListRoutesPages == nil {
+		cb(repo.FindAllRoutes)
+		return
+	}
+
+	for _, page := range repo.ListRoutesPages {
+		if !cb(page) {
+			break
+		}
+	}
+	return
+}
+
 func (repo *FakeRouteRepository) FindByHost(host string) (route cf.Route, apiResponse net.ApiResponse) {
 	repo.FindByHostHost = host
 
@@ -94,12 +117,17 @@ func (repo *FakeRouteRepository) CreateInSpace(newRoute cf.Route, domain cf.Doma
 func (repo *FakeRouteRepository) Bind(route cf.Route, app cf.Application) (apiResponse net.ApiResponse) {
 	repo.BoundRoute = route
 	repo.BoundApp = app
+	repo.BoundRoutes = append(repo.BoundRoutes, route)
+	if route.Guid != "" && route.Guid == repo.BindErrRouteGuid {
+		apiResponse = net.NewApiStatusWithMessage("Error binding route")
+	}
 	return
 }
 
 func (repo *FakeRouteRepository) Unbind(route cf.Route, app cf.Application) (apiResponse net.ApiResponse) {
 	repo.UnboundRoute = route
 	repo.UnboundApp = app
+	repo.UnboundRoutes = append(repo.UnboundRoutes, route)
 	return
 }
 
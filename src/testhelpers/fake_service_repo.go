@@ -40,6 +40,11 @@ func (repo *FakeServiceRepo) GetServiceOfferings() (offerings []cf.ServiceOfferi
 	return
 }
 
+func (repo *FakeServiceRepo) ListServiceOfferings(cb func([]cf.ServiceOffering) bool) (apiResponse net.ApiResponse) {
+	cb(repo.ServiceOfferings)
+	return
+}
+
 func (repo *FakeServiceRepo) CreateServiceInstance(name string, plan cf.ServicePlan) (identicalAlreadyExists bool, apiResponse net.ApiResponse) {
 	repo.CreateServiceInstanceName = name
 	repo.CreateServiceInstancePlan = plan
@@ -4,12 +4,14 @@ import (
 	"cf"
 	"cf/net"
 	"cf/api"
+	"strings"
 )
 
 type FakeSpaceRepository struct {
 	CurrentSpace cf.Space
 
 	Spaces []cf.Space
+	SpacesByOrgGuid map[string][]cf.Space
 
 	FindByNameName string
 	FindByNameSpace cf.Space
@@ -17,6 +19,8 @@ type FakeSpaceRepository struct {
 	FindByNameNotFound bool
 
 	SummarySpace cf.Space
+	SummaryErr net.ApiResponse
+	SummariesByGuid map[string]cf.Space
 
 	CreateSpaceName string
 	CreateSpaceExists bool
@@ -36,6 +40,16 @@ func (repo FakeSpaceRepository) FindAll() (spaces []cf.Space, apiResponse net.Ap
 	return
 }
 
+func (repo FakeSpaceRepository) FindAllInOrg(org cf.Organization) (spaces []cf.Space, apiResponse net.ApiResponse) {
+	spaces = repo.SpacesByOrgGuid[org.Guid]
+	return
+}
+
+func (repo FakeSpaceRepository) ListSpaces(cb func([]cf.Space) bool) (apiResponse net.ApiResponse) {
+	cb(repo.Spaces)
+	return
+}
+
 func (repo *FakeSpaceRepository) FindByName(name string) (space cf.Space, apiResponse net.ApiResponse) {
 	repo.FindByNameName = name
 	space = repo.FindByNameSpace
@@ -51,8 +65,27 @@ func (repo *FakeSpaceRepository) FindByName(name string) (space cf.Space, apiRes
 	return
 }
 
+func (repo *FakeSpaceRepository) FindByNameInOrg(name string, org cf.Organization) (space cf.Space, apiResponse net.ApiResponse) {
+	for _, space = range repo.SpacesByOrgGuid[org.Guid] {
+		if strings.EqualFold(space.Name, name) {
+			return
+		}
+	}
+
+	space = cf.Space{}
+	apiResponse = net.NewNotFoundApiStatus("Space", name)
+	return
+}
+
 func (repo *FakeSpaceRepository) GetSummary() (space cf.Space, apiResponse net.ApiResponse) {
 	space = repo.SummarySpace
+	apiResponse = repo.SummaryErr
+	return
+}
+
+func (repo *FakeSpaceRepository) GetSummaryForSpace(space cf.Space) (summary cf.Space, apiResponse net.ApiResponse) {
+	summary = repo.SummariesByGuid[space.Guid]
+	apiResponse = repo.SummaryErr
 	return
 }
 
@@ -24,3 +24,8 @@ func (repo *FakeStackRepository) FindAll() (stacks []cf.Stack, apiResponse net.A
 	return
 }
 
+func (repo *FakeStackRepository) ListStacks(cb func([]cf.Stack) bool) (apiResponse net.ApiResponse) {
+	cb(repo.FindAllStacks)
+	return
+}
+
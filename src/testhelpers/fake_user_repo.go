@@ -0,0 +1,105 @@
+package testhelpers
+
+import (
+	"cf"
+	"cf/net"
+)
+
+type FakeUserRepository struct {
+	FindByUsernameUsername string
+	FindByUsernameUser     cf.User
+	FindByUsernameNotFound bool
+
+	FindAllInOrgByRoleOrganization cf.Organization
+	FindAllInOrgByRoleUsersByRole  map[string][]cf.User
+
+	FindAllInSpaceByRoleSpace       cf.Space
+	FindAllInSpaceByRoleUsersByRole map[string][]cf.User
+
+	CreateUserUsername string
+	CreateUserPassword string
+	CreateUserExists   bool
+
+	DeletedUser cf.User
+
+	SetOrgRoleUser         cf.User
+	SetOrgRoleOrganization cf.Organization
+	SetOrgRoleRole         string
+
+	UnsetOrgRoleUser         cf.User
+	UnsetOrgRoleOrganization cf.Organization
+	UnsetOrgRoleRole         string
+
+	SetSpaceRoleUser  cf.User
+	SetSpaceRoleSpace cf.Space
+	SetSpaceRoleRole  string
+
+	UnsetSpaceRoleUser  cf.User
+	UnsetSpaceRoleSpace cf.Space
+	UnsetSpaceRoleRole  string
+}
+
+func (repo *FakeUserRepository) FindByUsername(username string) (user cf.User, apiResponse net.ApiResponse) {
+	repo.FindByUsernameUsername = username
+	user = repo.FindByUsernameUser
+
+	if repo.FindByUsernameNotFound {
+		apiResponse = net.NewNotFoundApiStatus("User", username)
+	}
+	return
+}
+
+func (repo *FakeUserRepository) FindAllInOrgByRole(org cf.Organization) (usersByRole map[string][]cf.User, apiResponse net.ApiResponse) {
+	repo.FindAllInOrgByRoleOrganization = org
+	usersByRole = repo.FindAllInOrgByRoleUsersByRole
+	return
+}
+
+func (repo *FakeUserRepository) FindAllInSpaceByRole(space cf.Space) (usersByRole map[string][]cf.User, apiResponse net.ApiResponse) {
+	repo.FindAllInSpaceByRoleSpace = space
+	usersByRole = repo.FindAllInSpaceByRoleUsersByRole
+	return
+}
+
+func (repo *FakeUserRepository) Create(username, password string) (apiResponse net.ApiResponse) {
+	repo.CreateUserUsername = username
+	repo.CreateUserPassword = password
+
+	if repo.CreateUserExists {
+		apiResponse = net.NewApiStatus("User already exists", "scim_resource_already_exists", 409)
+	}
+	return
+}
+
+func (repo *FakeUserRepository) Delete(user cf.User) (apiResponse net.ApiResponse) {
+	repo.DeletedUser = user
+	return
+}
+
+func (repo *FakeUserRepository) SetOrgRole(user cf.User, org cf.Organization, role string) (apiResponse net.ApiResponse) {
+	repo.SetOrgRoleUser = user
+	repo.SetOrgRoleOrganization = org
+	repo.SetOrgRoleRole = role
+	return
+}
+
+func (repo *FakeUserRepository) UnsetOrgRole(user cf.User, org cf.Organization, role string) (apiResponse net.ApiResponse) {
+	repo.UnsetOrgRoleUser = user
+	repo.UnsetOrgRoleOrganization = org
+	repo.UnsetOrgRoleRole = role
+	return
+}
+
+func (repo *FakeUserRepository) SetSpaceRole(user cf.User, space cf.Space, role string) (apiResponse net.ApiResponse) {
+	repo.SetSpaceRoleUser = user
+	repo.SetSpaceRoleSpace = space
+	repo.SetSpaceRoleRole = role
+	return
+}
+
+func (repo *FakeUserRepository) UnsetSpaceRole(user cf.User, space cf.Space, role string) (apiResponse net.ApiResponse) {
+	repo.UnsetSpaceRoleUser = user
+	repo.UnsetSpaceRoleSpace = space
+	repo.UnsetSpaceRoleRole = role
+	return
+}
@@ -1,13 +1,24 @@
 package testhelpers
 
-import "bytes"
+import (
+	"bytes"
+	"cf"
+	"io"
+)
 
 type FakeZipper struct {
 	ZippedDir string
+	ZippedFiles []cf.AppFile
 	ZippedBuffer *bytes.Buffer
 }
 
-func (zipper *FakeZipper) Zip(dir string) (zipBuffer *bytes.Buffer, err error) {
+func (zipper *FakeZipper) Zip(dir string, appFiles []cf.AppFile, target io.Writer, onProgress func(bytesZipped int64)) (err error) {
 	zipper.ZippedDir = dir
-	return zipper.ZippedBuffer, nil
+	zipper.ZippedFiles = appFiles
+
+	_, err = target.Write(zipper.ZippedBuffer.Bytes())
+	if onProgress != nil {
+		onProgress(int64(zipper.ZippedBuffer.Len()))
+	}
+	return
 }
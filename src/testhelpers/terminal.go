@@ -40,6 +40,12 @@ type FakeUI struct {
 	PasswordPrompts []string
 	Inputs  []string
 	FailedWithUsage bool
+	StructuredOutput bool
+	DisplayedData interface{}
+	FailureCode string
+	FailureStatus int
+	ProgressCurrent int64
+	ProgressTotal int64
 }
 
 func (ui *FakeUI) Say(message string, args ...interface{}) {
@@ -83,10 +89,24 @@ func (ui *FakeUI) Ok() {
 func (ui *FakeUI) Failed(message string, args ...interface{}) {
 	message = fmt.Sprintf(message, args...)
 	ui.Say("FAILED")
-	ui.Say(message)
+	ui.Say("%s", message)
 	return
 }
 
+func (ui *FakeUI) ApiFailure(message string, errorCode string, statusCode int) {
+	ui.FailureCode = errorCode
+	ui.FailureStatus = statusCode
+	ui.Failed("%s", message)
+}
+
+func (ui *FakeUI) IsStructuredOutput() bool {
+	return ui.StructuredOutput
+}
+
+func (ui *FakeUI) DisplayData(data interface{}) {
+	ui.DisplayedData = data
+}
+
 func (ui *FakeUI) ConfigFailure(err error) {
 	ui.Failed("Error loading config file.\n%s",err.Error())
 }
@@ -128,6 +148,11 @@ func (ui *FakeUI) ShowConfiguration(config *configuration.Configuration) {
 func (ui FakeUI) LoadingIndication() {
 }
 
+func (ui *FakeUI) ShowProgress(current, total int64) {
+	ui.ProgressCurrent = current
+	ui.ProgressTotal = total
+}
+
 func (c FakeUI) Wait(duration time.Duration) {
 	time.Sleep(duration)
 }